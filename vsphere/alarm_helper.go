@@ -0,0 +1,114 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// alarmReferenceFromID returns a ManagedObjectReference for an Alarm from its
+// managed object ID.
+func alarmReferenceFromID(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "Alarm",
+		Value: id,
+	}
+}
+
+// alarmManagerReference returns the reference to the AlarmManager, which is
+// only available on vCenter.
+func alarmManagerReference(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.ServiceContent.AlarmManager == nil {
+		return types.ManagedObjectReference{}, errors.New("alarm manager is not available on this connection")
+	}
+	return *client.ServiceContent.AlarmManager, nil
+}
+
+// alarmProperties fetches the Alarm MO for the alarm with the supplied managed
+// object ID.
+func alarmProperties(client *govmomi.Client, id string) (*mo.Alarm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.Alarm
+	alarm := object.NewCommon(client.Client, alarmReferenceFromID(id))
+	if err := alarm.Properties(ctx, alarm.Reference(), []string{"info"}, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createAlarm creates an alarm definition on the supplied entity, and returns
+// the managed object ID of the new alarm.
+func createAlarm(client *govmomi.Client, entity types.ManagedObjectReference, spec *types.AlarmSpec) (string, error) {
+	ref, err := alarmManagerReference(client)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.CreateAlarm{
+		This:   ref,
+		Entity: entity,
+		Spec:   spec,
+	}
+	res, err := methods.CreateAlarm(ctx, client, req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval.Value, nil
+}
+
+// reconfigureAlarm replaces the spec of the alarm with the supplied managed
+// object ID.
+func reconfigureAlarm(client *govmomi.Client, id string, spec *types.AlarmSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ReconfigureAlarm{
+		This: alarmReferenceFromID(id),
+		Spec: spec,
+	}
+	_, err := methods.ReconfigureAlarm(ctx, client, req)
+	return err
+}
+
+// removeAlarm removes the alarm with the supplied managed object ID.
+func removeAlarm(client *govmomi.Client, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.RemoveAlarm{
+		This: alarmReferenceFromID(id),
+	}
+	_, err := methods.RemoveAlarm(ctx, client, req)
+	return err
+}
+
+// perfCounterNames returns the performance counters available on the
+// connection, indexed by their full name in group.name.rollup format, ie:
+// cpu.usage.average.
+func perfCounterNames(client *govmomi.Client) (map[string]int32, error) {
+	if client.ServiceContent.PerfManager == nil {
+		return nil, errors.New("performance manager is not available on this connection")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.PerformanceManager
+	pm := object.NewCommon(client.Client, *client.ServiceContent.PerfManager)
+	if err := pm.Properties(ctx, pm.Reference(), []string{"perfCounter"}, &props); err != nil {
+		return nil, err
+	}
+	counters := make(map[string]int32)
+	for _, c := range props.PerfCounter {
+		name := fmt.Sprintf("%s.%s.%s", c.GroupInfo.GetElementDescription().Key, c.NameInfo.GetElementDescription().Key, c.RollupType)
+		counters[name] = c.Key
+	}
+	return counters, nil
+}
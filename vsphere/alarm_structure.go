@@ -0,0 +1,565 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	alarmExpressionOperatorOr  = "or"
+	alarmExpressionOperatorAnd = "and"
+)
+
+var alarmExpressionOperatorAllowedValues = []string{
+	alarmExpressionOperatorOr,
+	alarmExpressionOperatorAnd,
+}
+
+const (
+	alarmMetricOperatorIsAbove = "is_above"
+	alarmMetricOperatorIsBelow = "is_below"
+)
+
+var alarmMetricOperatorAllowedValues = []string{
+	alarmMetricOperatorIsAbove,
+	alarmMetricOperatorIsBelow,
+}
+
+const (
+	alarmActionTypeEmail     = "email"
+	alarmActionTypeSNMP      = "snmp"
+	alarmActionTypeRunScript = "run_script"
+)
+
+var alarmActionTypeAllowedValues = []string{
+	alarmActionTypeEmail,
+	alarmActionTypeSNMP,
+	alarmActionTypeRunScript,
+}
+
+var alarmEventStatusAllowedValues = []string{
+	string(types.ManagedEntityStatusGreen),
+	string(types.ManagedEntityStatusYellow),
+	string(types.ManagedEntityStatusRed),
+}
+
+// alarmEntityTypeAllowedValues are the types of managed entities that alarm
+// definitions can be created on.
+var alarmEntityTypeAllowedValues = []string{
+	"Folder",
+	"Datacenter",
+	"ClusterComputeResource",
+	"ComputeResource",
+	"HostSystem",
+	"ResourcePool",
+	"VirtualMachine",
+	"Datastore",
+	"StoragePod",
+	"Network",
+	"DistributedVirtualPortgroup",
+	"VmwareDistributedVirtualSwitch",
+}
+
+// alarmTransitions are the alarm state transitions that an action can be
+// triggered on, mapped to their start and final states.
+var alarmTransitions = []struct {
+	key   string
+	start types.ManagedEntityStatus
+	final types.ManagedEntityStatus
+}{
+	{"green_to_yellow", types.ManagedEntityStatusGreen, types.ManagedEntityStatusYellow},
+	{"yellow_to_red", types.ManagedEntityStatusYellow, types.ManagedEntityStatusRed},
+	{"red_to_yellow", types.ManagedEntityStatusRed, types.ManagedEntityStatusYellow},
+	{"yellow_to_green", types.ManagedEntityStatusYellow, types.ManagedEntityStatusGreen},
+}
+
+// schemaAlarmMetricExpression returns the schema for a metric based alarm
+// expression.
+func schemaAlarmMetricExpression() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"object_type": {
+			Type:        schema.TypeString,
+			Description: "The type of object that the metric is collected on, ie: VirtualMachine, HostSystem, or Datastore.",
+			Required:    true,
+		},
+		"metric": {
+			Type:        schema.TypeString,
+			Description: "The name of the performance counter to monitor, in group.name.rollup format, ie: cpu.usage.average.",
+			Required:    true,
+		},
+		"instance": {
+			Type:        schema.TypeString,
+			Description: "The instance of the performance counter to monitor. An empty string means the aggregate of all instances.",
+			Optional:    true,
+		},
+		"operator": {
+			Type:         schema.TypeString,
+			Description:  "Whether the alarm triggers when the metric is above or below the thresholds. Can be one of is_above or is_below.",
+			Required:     true,
+			ValidateFunc: validation.StringInSlice(alarmMetricOperatorAllowedValues, false),
+		},
+		"yellow": {
+			Type:        schema.TypeInt,
+			Description: "The threshold for the yellow (warning) state, in the units of the performance counter. Percentages are expressed in hundredths of a percent.",
+			Optional:    true,
+		},
+		"yellow_interval": {
+			Type:         schema.TypeInt,
+			Description:  "The time in seconds that the metric needs to be past the yellow threshold before the alarm triggers.",
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"red": {
+			Type:        schema.TypeInt,
+			Description: "The threshold for the red (alert) state, in the units of the performance counter. Percentages are expressed in hundredths of a percent.",
+			Optional:    true,
+		},
+		"red_interval": {
+			Type:         schema.TypeInt,
+			Description:  "The time in seconds that the metric needs to be past the red threshold before the alarm triggers.",
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+	}
+}
+
+// schemaAlarmEventExpression returns the schema for an event based alarm
+// expression.
+func schemaAlarmEventExpression() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"event_type_id": {
+			Type:        schema.TypeString,
+			Description: "The ID of the event that triggers the alarm, ie: vim.event.VmPoweredOffEvent.",
+			Required:    true,
+		},
+		"event_type": {
+			Type:        schema.TypeString,
+			Description: "The type of the event, ie: Event, EventEx, or ExtendedEvent.",
+			Optional:    true,
+			Default:     "Event",
+		},
+		"object_type": {
+			Type:        schema.TypeString,
+			Description: "The type of object that the event is logged on, ie: VirtualMachine.",
+			Optional:    true,
+		},
+		"status": {
+			Type:         schema.TypeString,
+			Description:  "The status that the alarm is set to when the event occurs. Can be one of green, yellow, or red.",
+			Optional:     true,
+			Default:      string(types.ManagedEntityStatusRed),
+			ValidateFunc: validation.StringInSlice(alarmEventStatusAllowedValues, false),
+		},
+	}
+}
+
+// schemaAlarmAction returns the schema for an alarm action.
+func schemaAlarmAction() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"type": {
+			Type:         schema.TypeString,
+			Description:  "The type of action. Can be one of email, snmp, or run_script.",
+			Required:     true,
+			ValidateFunc: validation.StringInSlice(alarmActionTypeAllowedValues, false),
+		},
+		"email_to": {
+			Type:        schema.TypeString,
+			Description: "A comma-separated list of addresses to send the email to, for the email action.",
+			Optional:    true,
+		},
+		"email_cc": {
+			Type:        schema.TypeString,
+			Description: "A comma-separated list of addresses to copy the email to, for the email action.",
+			Optional:    true,
+		},
+		"email_subject": {
+			Type:        schema.TypeString,
+			Description: "The subject of the email, for the email action.",
+			Optional:    true,
+		},
+		"email_body": {
+			Type:        schema.TypeString,
+			Description: "The body of the email, for the email action.",
+			Optional:    true,
+		},
+		"script": {
+			Type:        schema.TypeString,
+			Description: "The command to run on the vCenter server, for the run_script action.",
+			Optional:    true,
+		},
+		"repeat": {
+			Type:        schema.TypeBool,
+			Description: "Repeat the action at the interval set in action_frequency while the alarm stays in the final state of a transition.",
+			Optional:    true,
+		},
+	}
+	for _, t := range alarmTransitions {
+		s[t.key] = &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: fmt.Sprintf("Run the action when the alarm changes from %s to %s.", t.start, t.final),
+			Optional:    true,
+			Default:     t.key == "yellow_to_red",
+		}
+	}
+	return s
+}
+
+// expandAlarmMetricExpression reads a metric_expression entry and returns the
+// appropriate MetricAlarmExpression. The metric name is resolved to its
+// counter ID using the supplied counter map.
+func expandAlarmMetricExpression(m map[string]interface{}, counters map[string]int32, index int) (*types.MetricAlarmExpression, error) {
+	metric := m["metric"].(string)
+	id, ok := counters[metric]
+	if !ok {
+		return nil, fmt.Errorf("metric_expression.%d: performance counter %q not found", index, metric)
+	}
+	yellow := m["yellow"].(int)
+	red := m["red"].(int)
+	if yellow == 0 && red == 0 {
+		return nil, fmt.Errorf("metric_expression.%d: at least one of yellow or red is required", index)
+	}
+	operator := types.MetricAlarmOperatorIsAbove
+	if m["operator"].(string) == alarmMetricOperatorIsBelow {
+		operator = types.MetricAlarmOperatorIsBelow
+	}
+	if yellow != 0 && red != 0 {
+		if operator == types.MetricAlarmOperatorIsAbove && yellow > red {
+			return nil, fmt.Errorf("metric_expression.%d: yellow (%d) cannot be higher than red (%d) with is_above", index, yellow, red)
+		}
+		if operator == types.MetricAlarmOperatorIsBelow && yellow < red {
+			return nil, fmt.Errorf("metric_expression.%d: yellow (%d) cannot be lower than red (%d) with is_below", index, yellow, red)
+		}
+	}
+	return &types.MetricAlarmExpression{
+		Operator: operator,
+		Type:     m["object_type"].(string),
+		Metric: types.PerfMetricId{
+			CounterId: id,
+			Instance:  m["instance"].(string),
+		},
+		Yellow:         int32(yellow),
+		YellowInterval: int32(m["yellow_interval"].(int)),
+		Red:            int32(red),
+		RedInterval:    int32(m["red_interval"].(int)),
+	}, nil
+}
+
+// flattenAlarmMetricExpression returns the metric_expression entry for a
+// MetricAlarmExpression. The counter ID is resolved to its name using the
+// supplied counter map.
+func flattenAlarmMetricExpression(obj *types.MetricAlarmExpression, counters map[string]int32) map[string]interface{} {
+	var metric string
+	for k, v := range counters {
+		if v == obj.Metric.CounterId {
+			metric = k
+			break
+		}
+	}
+	operator := alarmMetricOperatorIsAbove
+	if obj.Operator == types.MetricAlarmOperatorIsBelow {
+		operator = alarmMetricOperatorIsBelow
+	}
+	return map[string]interface{}{
+		"object_type":     obj.Type,
+		"metric":          metric,
+		"instance":        obj.Metric.Instance,
+		"operator":        operator,
+		"yellow":          int(obj.Yellow),
+		"yellow_interval": int(obj.YellowInterval),
+		"red":             int(obj.Red),
+		"red_interval":    int(obj.RedInterval),
+	}
+}
+
+// expandAlarmEventExpression reads an event_expression entry and returns the
+// appropriate EventAlarmExpression.
+func expandAlarmEventExpression(m map[string]interface{}) *types.EventAlarmExpression {
+	return &types.EventAlarmExpression{
+		EventType:   m["event_type"].(string),
+		EventTypeId: m["event_type_id"].(string),
+		ObjectType:  m["object_type"].(string),
+		Status:      types.ManagedEntityStatus(m["status"].(string)),
+	}
+}
+
+// flattenAlarmEventExpression returns the event_expression entry for an
+// EventAlarmExpression.
+func flattenAlarmEventExpression(obj *types.EventAlarmExpression) map[string]interface{} {
+	return map[string]interface{}{
+		"event_type":    obj.EventType,
+		"event_type_id": obj.EventTypeId,
+		"object_type":   obj.ObjectType,
+		"status":        string(obj.Status),
+	}
+}
+
+// expandAlarmExpression reads the expression settings of an alarm definition
+// and returns the combined expression. The individual expressions are always
+// wrapped in an OrAlarmExpression or AndAlarmExpression, depending on
+// expression_operator.
+func expandAlarmExpression(d *schema.ResourceData, counters map[string]int32) (types.BaseAlarmExpression, error) {
+	var exprs []types.BaseAlarmExpression
+	for i, v := range d.Get("metric_expression").([]interface{}) {
+		expr, err := expandAlarmMetricExpression(v.(map[string]interface{}), counters, i)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	for _, v := range d.Get("event_expression").([]interface{}) {
+		exprs = append(exprs, expandAlarmEventExpression(v.(map[string]interface{})))
+	}
+	if len(exprs) < 1 {
+		return nil, fmt.Errorf("at least one metric_expression or event_expression is required")
+	}
+	if d.Get("expression_operator").(string) == alarmExpressionOperatorAnd {
+		return &types.AndAlarmExpression{Expression: exprs}, nil
+	}
+	return &types.OrAlarmExpression{Expression: exprs}, nil
+}
+
+// flattenAlarmExpression saves the expression settings of an alarm definition
+// to the supplied ResourceData. Expressions of types that are not supported
+// by the resource are skipped.
+func flattenAlarmExpression(d *schema.ResourceData, base types.BaseAlarmExpression, counters map[string]int32) error {
+	operator := alarmExpressionOperatorOr
+	exprs := []types.BaseAlarmExpression{base}
+	switch e := base.(type) {
+	case *types.OrAlarmExpression:
+		exprs = e.Expression
+	case *types.AndAlarmExpression:
+		operator = alarmExpressionOperatorAnd
+		exprs = e.Expression
+	}
+
+	var metrics, events []interface{}
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case *types.MetricAlarmExpression:
+			metrics = append(metrics, flattenAlarmMetricExpression(e, counters))
+		case *types.EventAlarmExpression:
+			events = append(events, flattenAlarmEventExpression(e))
+		default:
+			log.Printf("[DEBUG] %s: skipping unsupported alarm expression type %T", d.Id(), expr)
+		}
+	}
+	d.Set("expression_operator", operator)
+	if err := d.Set("metric_expression", metrics); err != nil {
+		return err
+	}
+	return d.Set("event_expression", events)
+}
+
+// expandAlarmAction reads an action entry and returns the appropriate
+// AlarmTriggeringAction.
+func expandAlarmAction(m map[string]interface{}, index int) (*types.AlarmTriggeringAction, error) {
+	var action types.BaseAction
+	actionType := m["type"].(string)
+	switch actionType {
+	case alarmActionTypeEmail:
+		if m["email_to"].(string) == "" {
+			return nil, fmt.Errorf("action.%d: email_to is required for the email action", index)
+		}
+		action = &types.SendEmailAction{
+			ToList:  m["email_to"].(string),
+			CcList:  m["email_cc"].(string),
+			Subject: m["email_subject"].(string),
+			Body:    m["email_body"].(string),
+		}
+	case alarmActionTypeSNMP:
+		action = &types.SendSNMPAction{}
+	case alarmActionTypeRunScript:
+		if m["script"].(string) == "" {
+			return nil, fmt.Errorf("action.%d: script is required for the run_script action", index)
+		}
+		action = &types.RunScriptAction{
+			Script: m["script"].(string),
+		}
+	}
+	if actionType != alarmActionTypeEmail {
+		for _, k := range []string{"email_to", "email_cc", "email_subject", "email_body"} {
+			if m[k].(string) != "" {
+				return nil, fmt.Errorf("action.%d: %s can only be set for the email action", index, k)
+			}
+		}
+	}
+	if actionType != alarmActionTypeRunScript && m["script"].(string) != "" {
+		return nil, fmt.Errorf("action.%d: script can only be set for the run_script action", index)
+	}
+
+	obj := &types.AlarmTriggeringAction{
+		Action: action,
+	}
+	for _, t := range alarmTransitions {
+		if !m[t.key].(bool) {
+			continue
+		}
+		obj.TransitionSpecs = append(obj.TransitionSpecs, types.AlarmTriggeringActionTransitionSpec{
+			StartState: t.start,
+			FinalState: t.final,
+			Repeats:    m["repeat"].(bool),
+		})
+	}
+	if len(obj.TransitionSpecs) < 1 {
+		return nil, fmt.Errorf("action.%d: at least one state transition must be enabled", index)
+	}
+	// The legacy transition flags are still required to be set consistently
+	// with the transition specs on older versions of vCenter.
+	obj.Green2yellow = m["green_to_yellow"].(bool)
+	obj.Yellow2red = m["yellow_to_red"].(bool)
+	obj.Red2yellow = m["red_to_yellow"].(bool)
+	obj.Yellow2green = m["yellow_to_green"].(bool)
+	return obj, nil
+}
+
+// flattenAlarmAction returns the action entry for an AlarmTriggeringAction.
+// The second return value is false if the action is of a type that is not
+// supported by the resource.
+func flattenAlarmAction(obj *types.AlarmTriggeringAction) (map[string]interface{}, bool) {
+	m := map[string]interface{}{
+		"email_to":      "",
+		"email_cc":      "",
+		"email_subject": "",
+		"email_body":    "",
+		"script":        "",
+		"repeat":        false,
+	}
+	switch a := obj.Action.(type) {
+	case *types.SendEmailAction:
+		m["type"] = alarmActionTypeEmail
+		m["email_to"] = a.ToList
+		m["email_cc"] = a.CcList
+		m["email_subject"] = a.Subject
+		m["email_body"] = a.Body
+	case *types.SendSNMPAction:
+		m["type"] = alarmActionTypeSNMP
+	case *types.RunScriptAction:
+		m["type"] = alarmActionTypeRunScript
+		m["script"] = a.Script
+	default:
+		return nil, false
+	}
+
+	if len(obj.TransitionSpecs) < 1 {
+		m["green_to_yellow"] = obj.Green2yellow
+		m["yellow_to_red"] = obj.Yellow2red
+		m["red_to_yellow"] = obj.Red2yellow
+		m["yellow_to_green"] = obj.Yellow2green
+		return m, true
+	}
+	for _, t := range alarmTransitions {
+		m[t.key] = false
+		for _, spec := range obj.TransitionSpecs {
+			if spec.StartState == t.start && spec.FinalState == t.final {
+				m[t.key] = true
+				if spec.Repeats {
+					m["repeat"] = true
+				}
+			}
+		}
+	}
+	return m, true
+}
+
+// expandAlarmActions reads the action entries of an alarm definition and
+// returns the combined action. Multiple actions are wrapped in a
+// GroupAlarmAction. A nil action is returned if there are no actions.
+func expandAlarmActions(d *schema.ResourceData) (types.BaseAlarmAction, error) {
+	var actions []types.BaseAlarmAction
+	for i, v := range d.Get("action").([]interface{}) {
+		action, err := expandAlarmAction(v.(map[string]interface{}), i)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	switch len(actions) {
+	case 0:
+		return nil, nil
+	case 1:
+		return actions[0], nil
+	}
+	return &types.GroupAlarmAction{Action: actions}, nil
+}
+
+// flattenAlarmActions saves the action entries of an alarm definition to the
+// supplied ResourceData.
+func flattenAlarmActions(d *schema.ResourceData, base types.BaseAlarmAction) error {
+	var actions []types.BaseAlarmAction
+	switch a := base.(type) {
+	case nil:
+	case *types.GroupAlarmAction:
+		actions = a.Action
+	default:
+		actions = []types.BaseAlarmAction{base}
+	}
+
+	var result []interface{}
+	for _, action := range actions {
+		t, ok := action.(*types.AlarmTriggeringAction)
+		if !ok {
+			log.Printf("[DEBUG] %s: skipping unsupported alarm action type %T", d.Id(), action)
+			continue
+		}
+		m, ok := flattenAlarmAction(t)
+		if !ok {
+			log.Printf("[DEBUG] %s: skipping unsupported alarm action type %T", d.Id(), t.Action)
+			continue
+		}
+		result = append(result, m)
+	}
+	return d.Set("action", result)
+}
+
+// expandAlarmSpec reads the settings of an alarm definition and returns the
+// appropriate AlarmSpec.
+func expandAlarmSpec(d *schema.ResourceData, counters map[string]int32) (*types.AlarmSpec, error) {
+	expr, err := expandAlarmExpression(d, counters)
+	if err != nil {
+		return nil, err
+	}
+	action, err := expandAlarmActions(d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AlarmSpec{
+		Name:            d.Get("name").(string),
+		Description:     d.Get("description").(string),
+		Enabled:         d.Get("enabled").(bool),
+		Expression:      expr,
+		Action:          action,
+		ActionFrequency: int32(d.Get("action_frequency").(int)),
+		Setting: &types.AlarmSetting{
+			ToleranceRange:     int32(d.Get("tolerance_range").(int)),
+			ReportingFrequency: int32(d.Get("reporting_frequency").(int)),
+		},
+	}, nil
+}
+
+// flattenAlarmInfo saves the settings of an alarm definition to the supplied
+// ResourceData.
+func flattenAlarmInfo(d *schema.ResourceData, info *types.AlarmInfo, counters map[string]int32) error {
+	d.Set("name", info.Name)
+	d.Set("description", info.Description)
+	d.Set("enabled", info.Enabled)
+	d.Set("entity_id", info.Entity.Value)
+	d.Set("entity_type", info.Entity.Type)
+	d.Set("action_frequency", info.ActionFrequency)
+	d.Set("tolerance_range", 0)
+	d.Set("reporting_frequency", 0)
+	if info.Setting != nil {
+		d.Set("tolerance_range", info.Setting.ToleranceRange)
+		d.Set("reporting_frequency", info.Setting.ReportingFrequency)
+	}
+	if err := flattenAlarmExpression(d, info.Expression, counters); err != nil {
+		return fmt.Errorf("error setting alarm expression: %s", err)
+	}
+	if err := flattenAlarmActions(d, info.Action); err != nil {
+		return fmt.Errorf("error setting alarm actions: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func testAlarmActionMap(actionType string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            actionType,
+		"email_to":        "",
+		"email_cc":        "",
+		"email_subject":   "",
+		"email_body":      "",
+		"script":          "",
+		"repeat":          false,
+		"green_to_yellow": false,
+		"yellow_to_red":   true,
+		"red_to_yellow":   false,
+		"yellow_to_green": false,
+	}
+}
+
+type testExpandAlarmAction struct {
+	Name string
+
+	action      map[string]interface{}
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testExpandAlarmAction) Test(t *testing.T) {
+	obj, err := expandAlarmAction(tc.action, 0)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	actual, ok := flattenAlarmAction(obj)
+	if !ok {
+		t.Fatalf("could not flatten action %T", obj.Action)
+	}
+	if !reflect.DeepEqual(tc.action, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.action, actual)
+	}
+}
+
+func TestExpandAlarmAction(t *testing.T) {
+	email := testAlarmActionMap("email")
+	email["email_to"] = "ops@example.com"
+	email["email_subject"] = "Datastore usage"
+	email["green_to_yellow"] = true
+	email["repeat"] = true
+	script := testAlarmActionMap("run_script")
+	script["script"] = "/usr/local/bin/notify.sh"
+	noEmailTo := testAlarmActionMap("email")
+	scriptOnSNMP := testAlarmActionMap("snmp")
+	scriptOnSNMP["script"] = "/usr/local/bin/notify.sh"
+	noTransitions := testAlarmActionMap("snmp")
+	noTransitions["yellow_to_red"] = false
+	cases := []testExpandAlarmAction{
+		{
+			Name:   "email",
+			action: email,
+		},
+		{
+			Name:   "snmp",
+			action: testAlarmActionMap("snmp"),
+		},
+		{
+			Name:   "run script",
+			action: script,
+		},
+		{
+			Name:        "email without recipient",
+			action:      noEmailTo,
+			expectedErr: regexp.MustCompile("email_to is required for the email action"),
+		},
+		{
+			Name:        "script on snmp action",
+			action:      scriptOnSNMP,
+			expectedErr: regexp.MustCompile("script can only be set for the run_script action"),
+		},
+		{
+			Name:        "no transitions",
+			action:      noTransitions,
+			expectedErr: regexp.MustCompile("at least one state transition must be enabled"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestExpandAlarmMetricExpression(t *testing.T) {
+	counters := map[string]int32{"disk.used.latest": 240}
+	expr := func(metric, operator string, yellow, red int) map[string]interface{} {
+		return map[string]interface{}{
+			"object_type":     "Datastore",
+			"metric":          metric,
+			"instance":        "",
+			"operator":        operator,
+			"yellow":          yellow,
+			"yellow_interval": 0,
+			"red":             red,
+			"red_interval":    0,
+		}
+	}
+	cases := []struct {
+		Name        string
+		expr        map[string]interface{}
+		expectedErr *regexp.Regexp
+	}{
+		{
+			Name: "basic",
+			expr: expr("disk.used.latest", "is_above", 7500, 8500),
+		},
+		{
+			Name:        "unknown counter",
+			expr:        expr("disk.nope.latest", "is_above", 7500, 8500),
+			expectedErr: regexp.MustCompile(`performance counter "disk.nope.latest" not found`),
+		},
+		{
+			Name:        "no thresholds",
+			expr:        expr("disk.used.latest", "is_above", 0, 0),
+			expectedErr: regexp.MustCompile("at least one of yellow or red is required"),
+		},
+		{
+			Name:        "yellow above red",
+			expr:        expr("disk.used.latest", "is_above", 9000, 8500),
+			expectedErr: regexp.MustCompile("yellow \\(9000\\) cannot be higher than red \\(8500\\)"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			obj, err := expandAlarmMetricExpression(tc.expr, counters, 0)
+			if tc.expectedErr != nil {
+				testMatchError(t, err, tc.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if actual := flattenAlarmMetricExpression(obj, counters); !reflect.DeepEqual(tc.expr, actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expr, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package vsphere
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// apiIdempotentMethodPrefixes are the prefixes of the names of the API methods
+// that only read data, and can be safely sent again if a call fails. Methods
+// that start tasks, and methods that page through a collector, are never
+// retried as the server may have already acted on the failed call.
+var apiIdempotentMethodPrefixes = []string{
+	"Retrieve",
+	"Find",
+	"Query",
+	"CurrentTime",
+}
+
+// apiTransientHTTPStatuses are the HTTP status lines that are returned by a
+// loaded or restarting vCenter server, and are treated as transient.
+var apiTransientHTTPStatuses = []string{
+	"502 ",
+	"503 ",
+	"504 ",
+}
+
+// apiRetryRoundTripper is a soap.RoundTripper that retries idempotent API
+// calls that fail with transient errors, backing off exponentially between
+// attempts.
+type apiRetryRoundTripper struct {
+	roundTripper soap.RoundTripper
+
+	// The maximum number of times a call is retried.
+	maxRetries int
+
+	// The delay before the first retry. The delay is doubled on each
+	// subsequent retry.
+	backoff time.Duration
+}
+
+// newAPIRetryRoundTripper wraps a soap.RoundTripper in an
+// apiRetryRoundTripper. rt is returned unchanged if maxRetries is less than 1.
+func newAPIRetryRoundTripper(rt soap.RoundTripper, maxRetries int, backoff time.Duration) soap.RoundTripper {
+	if maxRetries < 1 {
+		return rt
+	}
+	return &apiRetryRoundTripper{
+		roundTripper: rt,
+		maxRetries:   maxRetries,
+		backoff:      backoff,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for apiRetryRoundTripper.
+func (r *apiRetryRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method := apiMethodName(req)
+	for attempt := 0; ; attempt++ {
+		err := r.roundTripper.RoundTrip(ctx, req, res)
+		if err == nil || attempt >= r.maxRetries || !isIdempotentAPIMethod(method) || !isTransientAPIError(err) {
+			return err
+		}
+		delay := r.backoff << uint(attempt)
+		log.Printf("[WARN] Transient error calling %s, retrying in %s (retry %d of %d): %s", method, delay, attempt+1, r.maxRetries, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		resetSOAPResponse(res)
+	}
+}
+
+// resetSOAPResponse clears any fault or partial result decoded into a SOAP
+// response body from a failed call, so that it does not carry over when the
+// call is sent again.
+func resetSOAPResponse(res soap.HasFault) {
+	v := reflect.ValueOf(res).Elem()
+	v.Set(reflect.Zero(v.Type()))
+}
+
+// apiMethodName returns the name of the API method that a SOAP request body
+// is for, ie: RetrievePropertiesEx.
+func apiMethodName(req soap.HasFault) string {
+	return strings.TrimSuffix(reflect.Indirect(reflect.ValueOf(req)).Type().Name(), "Body")
+}
+
+// isIdempotentAPIMethod returns true if the API method with the supplied name
+// can be safely retried.
+func isIdempotentAPIMethod(method string) bool {
+	if strings.HasSuffix(method, "_Task") {
+		return false
+	}
+	for _, p := range apiIdempotentMethodPrefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientAPIError returns true if an error returned by an API call is
+// likely to go away if the call is retried. This covers temporary network
+// errors, dropped connections, gateway and availability errors from the
+// server, and host communication faults.
+func isTransientAPIError(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	if nerr, ok := err.(net.Error); ok && (nerr.Temporary() || nerr.Timeout()) {
+		return true
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	for _, s := range apiTransientHTTPStatuses {
+		if strings.HasPrefix(err.Error(), s) {
+			return true
+		}
+	}
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.HostCommunication); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// testAPIRoundTripper is a soap.RoundTripper that fails the first failures
+// calls with err.
+type testAPIRoundTripper struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (rt *testAPIRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.calls++
+	if rt.calls <= rt.failures {
+		return rt.err
+	}
+	return nil
+}
+
+type testAPIRetryRoundTripper struct {
+	Name string
+
+	req           soap.HasFault
+	res           soap.HasFault
+	failures      int
+	err           error
+	expectedCalls int
+	expectError   bool
+}
+
+func (tc *testAPIRetryRoundTripper) Test(t *testing.T) {
+	inner := &testAPIRoundTripper{
+		failures: tc.failures,
+		err:      tc.err,
+	}
+	rt := newAPIRetryRoundTripper(inner, 2, 0)
+	err := rt.RoundTrip(context.Background(), tc.req, tc.res)
+	if tc.expectError && err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if !tc.expectError && err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if inner.calls != tc.expectedCalls {
+		t.Fatalf("expected %d calls, got %d", tc.expectedCalls, inner.calls)
+	}
+}
+
+func TestAPIRetryRoundTripper(t *testing.T) {
+	cases := []testAPIRetryRoundTripper{
+		{
+			Name:          "read retried after service unavailable",
+			req:           &methods.RetrievePropertiesExBody{},
+			res:           &methods.RetrievePropertiesExBody{},
+			failures:      1,
+			err:           errors.New("503 Service Unavailable"),
+			expectedCalls: 2,
+		},
+		{
+			Name:          "read gives up after max retries",
+			req:           &methods.RetrievePropertiesExBody{},
+			res:           &methods.RetrievePropertiesExBody{},
+			failures:      5,
+			err:           io.EOF,
+			expectedCalls: 3,
+			expectError:   true,
+		},
+		{
+			Name:          "permanent error not retried",
+			req:           &methods.RetrievePropertiesExBody{},
+			res:           &methods.RetrievePropertiesExBody{},
+			failures:      1,
+			err:           errors.New("403 Forbidden"),
+			expectedCalls: 1,
+			expectError:   true,
+		},
+		{
+			Name:          "task not retried",
+			req:           &methods.PowerOnVM_TaskBody{},
+			res:           &methods.PowerOnVM_TaskBody{},
+			failures:      1,
+			err:           errors.New("503 Service Unavailable"),
+			expectedCalls: 1,
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestIsIdempotentAPIMethod(t *testing.T) {
+	cases := map[string]bool{
+		"RetrievePropertiesEx":         true,
+		"FindByUuid":                   true,
+		"QueryEvents":                  true,
+		"ContinueRetrievePropertiesEx": false,
+		"PowerOnVM_Task":               false,
+		"CreateFilter":                 false,
+	}
+	for method, expected := range cases {
+		if actual := isIdempotentAPIMethod(method); actual != expected {
+			t.Fatalf("expected %s to be %t, got %t", method, expected, actual)
+		}
+	}
+}
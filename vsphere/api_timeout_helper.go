@@ -0,0 +1,77 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// apiTimeoutRoundTripper is a soap.RoundTripper that sets a deadline on every
+// API call, and reports calls that run past it with a timeout error.
+type apiTimeoutRoundTripper struct {
+	roundTripper soap.RoundTripper
+
+	// The maximum amount of time that a single API call, or a wait on a task,
+	// can take.
+	timeout time.Duration
+}
+
+// newAPITimeoutRoundTripper wraps a soap.RoundTripper in an
+// apiTimeoutRoundTripper. rt is returned unchanged if timeout is zero.
+func newAPITimeoutRoundTripper(rt soap.RoundTripper, timeout time.Duration) soap.RoundTripper {
+	if timeout <= 0 {
+		return rt
+	}
+	return &apiTimeoutRoundTripper{
+		roundTripper: rt,
+		timeout:      timeout,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for apiTimeoutRoundTripper.
+func (r *apiTimeoutRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	tctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.roundTripper.RoundTrip(tctx, req, res)
+	// Only errors caused by our own deadline are reported as an API timeout.
+	// Errors caused by a deadline on ctx are returned as-is.
+	if err != nil && tctx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return fmt.Errorf("timeout calling %s: no response after %s (api_timeout)", apiMethodName(req), r.timeout)
+	}
+	return err
+}
+
+// apiTimeout returns the API timeout configured for the supplied client, or
+// zero if there is none.
+func apiTimeout(c *vim25.Client) time.Duration {
+	if rt, ok := c.RoundTripper.(*apiTimeoutRoundTripper); ok {
+		return rt.timeout
+	}
+	return 0
+}
+
+// waitForTask waits for a task to complete and returns its result. If an API
+// timeout is configured for the task's client, the wait is aborted once it has
+// gone on for longer than the timeout, and a timeout error is returned. The
+// task itself is not cancelled, and may still complete on the server.
+func waitForTask(task *object.Task) (*types.TaskInfo, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	timeout := apiTimeout(task.Client())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timeout waiting for task %s: not complete after %s (api_timeout)", task.Reference().Value, timeout)
+	}
+	return info, err
+}
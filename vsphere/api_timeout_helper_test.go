@@ -0,0 +1,51 @@
+package vsphere
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// testBlockingRoundTripper is a soap.RoundTripper that blocks until the
+// context of a call is done.
+type testBlockingRoundTripper struct{}
+
+func (rt *testBlockingRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestAPITimeoutRoundTripper(t *testing.T) {
+	rt := newAPITimeoutRoundTripper(&testBlockingRoundTripper{}, time.Millisecond*10)
+
+	err := rt.RoundTrip(context.Background(), &methods.RetrievePropertiesExBody{}, &methods.RetrievePropertiesExBody{})
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	expected := "timeout calling RetrievePropertiesEx"
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("expected error to start with %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAPITimeoutRoundTripperParentDeadline(t *testing.T) {
+	rt := newAPITimeoutRoundTripper(&testBlockingRoundTripper{}, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err := rt.RoundTrip(ctx, &methods.RetrievePropertiesExBody{}, &methods.RetrievePropertiesExBody{})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected %q, got %q", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAPITimeoutRoundTripperDisabled(t *testing.T) {
+	inner := &testBlockingRoundTripper{}
+	if rt := newAPITimeoutRoundTripper(inner, 0); rt != inner {
+		t.Fatalf("expected round tripper to be returned unchanged")
+	}
+}
@@ -0,0 +1,182 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// clusterComputeResourceFromID locates a ClusterComputeResource by its managed
+// object reference ID.
+func clusterComputeResourceFromID(client *govmomi.Client, id string) (*object.ClusterComputeResource, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "ClusterComputeResource",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("could not find cluster with id: %s on %s: %s", id, serverDescription(client), err)
+	}
+	return obj.(*object.ClusterComputeResource), nil
+}
+
+// clusterComputeResourceProperties is a convenience method that wraps fetching
+// the ClusterComputeResource MO from its higher-level object.
+func clusterComputeResourceProperties(cluster *object.ClusterComputeResource) (*mo.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ClusterComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// clusterComputeResourceHosts returns the HostSystem objects that are members
+// of the supplied cluster.
+func clusterComputeResourceHosts(cluster *object.ClusterComputeResource) ([]*object.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return cluster.Hosts(ctx)
+}
+
+// refreshClusterRecommendations asks DRS to recalculate the recommendations
+// for the supplied cluster.
+func refreshClusterRecommendations(client *govmomi.Client, cluster *object.ClusterComputeResource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.RefreshRecommendation{
+		This: cluster.Reference(),
+	}
+	_, err := methods.RefreshRecommendation(ctx, client, req)
+	return err
+}
+
+// applyClusterRecommendation applies the recommendation with the supplied key
+// on a cluster. The call returns as soon as DRS has accepted the
+// recommendation, before any of its actions have completed.
+func applyClusterRecommendation(client *govmomi.Client, cluster *object.ClusterComputeResource, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ApplyRecommendation{
+		This: cluster.Reference(),
+		Key:  key,
+	}
+	_, err := methods.ApplyRecommendation(ctx, client, req)
+	return err
+}
+
+// waitForClusterAction waits for the action of an applied cluster
+// recommendation to complete. Migration and placement actions are complete
+// once the virtual machine is running on the destination host. Other actions
+// are not waited on.
+func waitForClusterAction(client *govmomi.Client, action types.BaseClusterAction, timeout time.Duration) error {
+	var vm, dest *types.ManagedObjectReference
+	switch t := action.(type) {
+	case *types.ClusterMigrationAction:
+		if t.DrsMigration != nil {
+			vm, dest = &t.DrsMigration.Vm, &t.DrsMigration.Destination
+		}
+	case *types.ClusterInitialPlacementAction:
+		vm, dest = t.Target, &t.TargetHost
+	case *types.PlacementAction:
+		vm, dest = t.Vm, t.TargetHost
+	}
+	if vm == nil || dest == nil {
+		return nil
+	}
+
+	p := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := property.Wait(ctx, p, *vm, []string{"runtime.host"}, func(pc []types.PropertyChange) bool {
+		for _, c := range pc {
+			if c.Op != types.PropertyChangeOpAssign {
+				continue
+			}
+			if host, ok := c.Val.(types.ManagedObjectReference); ok && host.Value == dest.Value {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timeout waiting for virtual machine %q to move to host %q", vm.Value, dest.Value)
+		}
+		return err
+	}
+	return nil
+}
+
+// resourcePoolDrsCluster returns the cluster that owns the supplied resource
+// pool, if DRS is enabled on the cluster. nil is returned if the resource pool
+// belongs to a standalone host, or if DRS is disabled.
+func resourcePoolDrsCluster(client *govmomi.Client, rp *object.ResourcePool) (*object.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ResourcePool
+	if err := rp.Properties(ctx, rp.Reference(), []string{"owner"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Owner.Type != "ClusterComputeResource" {
+		return nil, nil
+	}
+	cluster := object.NewClusterComputeResource(client.Client, props.Owner)
+	cprops, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := cprops.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok || config.DrsConfig.Enabled == nil || !*config.DrsConfig.Enabled {
+		return nil, nil
+	}
+	return cluster, nil
+}
+
+// placeVMInCluster asks DRS for a placement recommendation for a virtual
+// machine in a cluster, and returns the host that DRS selected. nil is
+// returned if DRS did not recommend a host.
+func placeVMInCluster(client *govmomi.Client, cluster *object.ClusterComputeResource, spec types.PlacementSpec) (*types.ManagedObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.PlaceVm{
+		This:          cluster.Reference(),
+		PlacementSpec: spec,
+	}
+	res, err := methods.PlaceVm(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return placementResultHost(res.Returnval), nil
+}
+
+// placementResultHost returns the target host of the highest rated
+// recommendation in a PlacementResult that places the virtual machine on a
+// host.
+func placementResultHost(result types.PlacementResult) *types.ManagedObjectReference {
+	recs := append([]types.ClusterRecommendation{}, result.Recommendations...)
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Rating > recs[j].Rating })
+	for _, r := range recs {
+		for _, a := range r.Action {
+			if pa, ok := a.(*types.PlacementAction); ok && pa.TargetHost != nil {
+				return pa.TargetHost
+			}
+		}
+	}
+	return nil
+}
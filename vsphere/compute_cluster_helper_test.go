@@ -0,0 +1,76 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testPlacementResultHost struct {
+	Name string
+
+	result   types.PlacementResult
+	expected *types.ManagedObjectReference
+}
+
+func (tc *testPlacementResultHost) Test(t *testing.T) {
+	actual := placementResultHost(tc.result)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestPlacementResultHost(t *testing.T) {
+	host := func(id string) *types.ManagedObjectReference {
+		return &types.ManagedObjectReference{Type: "HostSystem", Value: id}
+	}
+	cases := []testPlacementResultHost{
+		{
+			Name:     "no recommendations",
+			result:   types.PlacementResult{},
+			expected: nil,
+		},
+		{
+			Name: "recommendation without a host",
+			result: types.PlacementResult{
+				Recommendations: []types.ClusterRecommendation{
+					{
+						Key:    "1",
+						Rating: 5,
+						Action: []types.BaseClusterAction{
+							&types.PlacementAction{},
+						},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			Name: "highest rated recommendation wins",
+			result: types.PlacementResult{
+				Recommendations: []types.ClusterRecommendation{
+					{
+						Key:    "1",
+						Rating: 2,
+						Action: []types.BaseClusterAction{
+							&types.PlacementAction{TargetHost: host("host-10")},
+						},
+					},
+					{
+						Key:    "2",
+						Rating: 4,
+						Action: []types.BaseClusterAction{
+							&types.PlacementAction{TargetHost: host("host-20")},
+						},
+					},
+				},
+			},
+			expected: host("host-20"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
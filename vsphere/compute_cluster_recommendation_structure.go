@@ -0,0 +1,167 @@
+package vsphere
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaClusterRecommendation returns the schema for a computed list of
+// cluster recommendations, as used by resources and data sources that read
+// back the recommendations of a cluster.
+func schemaClusterRecommendation() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "The pending recommendations for the cluster, highest rated first.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:        schema.TypeString,
+					Description: "The key of the recommendation. This can be used to apply the recommendation.",
+					Computed:    true,
+				},
+				"type": {
+					Type:        schema.TypeString,
+					Description: "The type of the recommendation, ie: migrate.",
+					Computed:    true,
+				},
+				"time": {
+					Type:        schema.TypeString,
+					Description: "The time the recommendation was made, in RFC3339 format.",
+					Computed:    true,
+				},
+				"rating": {
+					Type:        schema.TypeInt,
+					Description: "The rating of the recommendation, from 1 to 5. Higher ratings are more strongly recommended.",
+					Computed:    true,
+				},
+				"reason": {
+					Type:        schema.TypeString,
+					Description: "The reason for the recommendation, ie: fairnessCpuAvg.",
+					Computed:    true,
+				},
+				"reason_text": {
+					Type:        schema.TypeString,
+					Description: "A description of the reason for the recommendation.",
+					Computed:    true,
+				},
+				"warning_text": {
+					Type:        schema.TypeString,
+					Description: "Any warnings about applying the recommendation.",
+					Computed:    true,
+				},
+				"target_id": {
+					Type:        schema.TypeString,
+					Description: "The managed object ID of the entity the recommendation is for.",
+					Computed:    true,
+				},
+				"action": {
+					Type:        schema.TypeList,
+					Description: "The actions that are run when the recommendation is applied.",
+					Computed:    true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"type": {
+								Type:        schema.TypeString,
+								Description: "The type of the action, ie: migrate or powerOn.",
+								Computed:    true,
+							},
+							"target_id": {
+								Type:        schema.TypeString,
+								Description: "The managed object ID of the entity the action runs against.",
+								Computed:    true,
+							},
+							"target_type": {
+								Type:        schema.TypeString,
+								Description: "The managed object type of the entity the action runs against.",
+								Computed:    true,
+							},
+							"source_host_id": {
+								Type:        schema.TypeString,
+								Description: "The managed object ID of the host a virtual machine is migrated from, for migration actions.",
+								Computed:    true,
+							},
+							"destination_host_id": {
+								Type:        schema.TypeString,
+								Description: "The managed object ID of the host a virtual machine is migrated or placed on, for migration and placement actions.",
+								Computed:    true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// flattenClusterRecommendations converts a list of ClusterRecommendation into
+// the format used by schemaClusterRecommendation. The recommendations are
+// sorted by rating, highest first, and then by key.
+func flattenClusterRecommendations(recs []types.ClusterRecommendation) []map[string]interface{} {
+	sorted := make([]types.ClusterRecommendation, len(recs))
+	copy(sorted, recs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Rating != sorted[j].Rating {
+			return sorted[i].Rating > sorted[j].Rating
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	var result []map[string]interface{}
+	for _, r := range sorted {
+		var actions []map[string]interface{}
+		for _, a := range r.Action {
+			actions = append(actions, flattenClusterAction(a))
+		}
+		var target string
+		if r.Target != nil {
+			target = r.Target.Value
+		}
+		result = append(result, map[string]interface{}{
+			"key":          r.Key,
+			"type":         r.Type,
+			"time":         r.Time.UTC().Format(time.RFC3339),
+			"rating":       int(r.Rating),
+			"reason":       r.Reason,
+			"reason_text":  r.ReasonText,
+			"warning_text": r.WarningText,
+			"target_id":    target,
+			"action":       actions,
+		})
+	}
+	return result
+}
+
+// flattenClusterAction converts a cluster recommendation action into the
+// format used by the action sub-resource of schemaClusterRecommendation.
+func flattenClusterAction(a types.BaseClusterAction) map[string]interface{} {
+	base := a.GetClusterAction()
+	m := map[string]interface{}{
+		"type":                base.Type,
+		"target_id":           "",
+		"target_type":         "",
+		"source_host_id":      "",
+		"destination_host_id": "",
+	}
+	if base.Target != nil {
+		m["target_id"] = base.Target.Value
+		m["target_type"] = base.Target.Type
+	}
+	switch t := a.(type) {
+	case *types.ClusterMigrationAction:
+		if t.DrsMigration != nil {
+			m["source_host_id"] = t.DrsMigration.Source.Value
+			m["destination_host_id"] = t.DrsMigration.Destination.Value
+		}
+	case *types.ClusterInitialPlacementAction:
+		m["destination_host_id"] = t.TargetHost.Value
+	case *types.PlacementAction:
+		if t.TargetHost != nil {
+			m["destination_host_id"] = t.TargetHost.Value
+		}
+	}
+	return m
+}
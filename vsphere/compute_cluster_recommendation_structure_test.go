@@ -0,0 +1,93 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestFlattenClusterRecommendations(t *testing.T) {
+	vm := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-20"}
+	recs := []types.ClusterRecommendation{
+		{
+			Key:        "2",
+			Type:       "migrate",
+			Time:       time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC),
+			Rating:     3,
+			Reason:     "fairnessCpuAvg",
+			ReasonText: "Balance average CPU loads.",
+			Target:     &types.ManagedObjectReference{Type: "ClusterComputeResource", Value: "domain-c7"},
+			Action: []types.BaseClusterAction{
+				&types.ClusterMigrationAction{
+					ClusterAction: types.ClusterAction{Type: "migrate", Target: &vm},
+					DrsMigration: &types.ClusterDrsMigration{
+						Vm:          vm,
+						Source:      types.ManagedObjectReference{Type: "HostSystem", Value: "host-10"},
+						Destination: types.ManagedObjectReference{Type: "HostSystem", Value: "host-11"},
+					},
+				},
+			},
+		},
+		{
+			Key:    "1",
+			Type:   "powerOn",
+			Time:   time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC),
+			Rating: 5,
+			Reason: "powerOnVm",
+			Action: []types.BaseClusterAction{
+				&types.ClusterInitialPlacementAction{
+					ClusterAction: types.ClusterAction{Type: "powerOn", Target: &vm},
+					TargetHost:    types.ManagedObjectReference{Type: "HostSystem", Value: "host-12"},
+				},
+			},
+		},
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"key":          "1",
+			"type":         "powerOn",
+			"time":         "2017-10-01T12:00:00Z",
+			"rating":       5,
+			"reason":       "powerOnVm",
+			"reason_text":  "",
+			"warning_text": "",
+			"target_id":    "",
+			"action": []map[string]interface{}{
+				{
+					"type":                "powerOn",
+					"target_id":           "vm-20",
+					"target_type":         "VirtualMachine",
+					"source_host_id":      "",
+					"destination_host_id": "host-12",
+				},
+			},
+		},
+		{
+			"key":          "2",
+			"type":         "migrate",
+			"time":         "2017-10-01T12:00:00Z",
+			"rating":       3,
+			"reason":       "fairnessCpuAvg",
+			"reason_text":  "Balance average CPU loads.",
+			"warning_text": "",
+			"target_id":    "domain-c7",
+			"action": []map[string]interface{}{
+				{
+					"type":                "migrate",
+					"target_id":           "vm-20",
+					"target_type":         "VirtualMachine",
+					"source_host_id":      "host-10",
+					"destination_host_id": "host-11",
+				},
+			},
+		},
+	}
+
+	actual := flattenClusterRecommendations(recs)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
@@ -1,15 +1,25 @@
 package vsphere
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/debug"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/vic/pkg/vsphere/tags"
 	"golang.org/x/net/context"
 )
@@ -24,6 +34,34 @@ type VSphereClient struct {
 
 	// The specialized tags client SDK imported from vmware/vic.
 	tagsClient *tags.RestClient
+
+	// true if refreshes should make no changes on the server. Reads that would
+	// otherwise run a check or rescan fall back to the last known results.
+	readOnlyRefresh bool
+
+	// true if the VIM client logged in with a client certificate. The CIS REST
+	// API can only be logged in to with a password, so tags are not available.
+	certificateLogin bool
+
+	// A cache of the datacenters that have been looked up by name, shared by
+	// all resources. Looking up a datacenter by its inventory path takes
+	// several round trips, and is done on every read of some resources.
+	datacenters map[string]*object.Datacenter
+
+	// The datacenter lookups that are in flight, by name. Concurrent callers
+	// asking for the same datacenter wait for the one lookup, so that the
+	// cache lock does not need to be held over the network.
+	datacenterLookups map[string]*datacenterLookup
+
+	datacentersMu sync.Mutex
+}
+
+// datacenterLookup is a datacenter lookup that is in flight. done is closed
+// when dc and err are set.
+type datacenterLookup struct {
+	done chan struct{}
+	dc   *object.Datacenter
+	err  error
 }
 
 // TagsClient returns the embedded REST client used for tags, after determining
@@ -52,26 +90,95 @@ func (c *VSphereClient) TagsClient() (*tags.RestClient, error) {
 		return nil, err
 	}
 	if c.tagsClient == nil {
+		if c.certificateLogin {
+			return nil, errors.New("tags are not supported when logging in with a client certificate")
+		}
 		return nil, fmt.Errorf("tags require %s or higher", tagsMinVersion)
 	}
 	return c.tagsClient, nil
 }
 
+// Datacenter returns the datacenter with the supplied name, or the default
+// datacenter if the name is empty. The datacenter is looked up with
+// getDatacenter the first time, and is returned from a cache after that.
+// Failed lookups are not cached. Only one lookup is made at a time for each
+// name, and callers asking for a name that is being looked up wait for that
+// lookup to finish.
+func (c *VSphereClient) Datacenter(name string) (*object.Datacenter, error) {
+	c.datacentersMu.Lock()
+	if dc, ok := c.datacenters[name]; ok {
+		c.datacentersMu.Unlock()
+		return dc, nil
+	}
+	if l, ok := c.datacenterLookups[name]; ok {
+		c.datacentersMu.Unlock()
+		<-l.done
+		return l.dc, l.err
+	}
+	l := &datacenterLookup{done: make(chan struct{})}
+	if c.datacenterLookups == nil {
+		c.datacenterLookups = make(map[string]*datacenterLookup)
+	}
+	c.datacenterLookups[name] = l
+	c.datacentersMu.Unlock()
+
+	l.dc, l.err = getDatacenter(c.vimClient, name)
+
+	c.datacentersMu.Lock()
+	// The cache may have been cleared while the lookup was running, in which
+	// case the result is returned but not cached.
+	if c.datacenterLookups[name] == l {
+		delete(c.datacenterLookups, name)
+		if l.err == nil {
+			if c.datacenters == nil {
+				c.datacenters = make(map[string]*object.Datacenter)
+			}
+			c.datacenters[name] = l.dc
+		}
+	}
+	c.datacentersMu.Unlock()
+	close(l.done)
+	return l.dc, l.err
+}
+
+// forgetDatacenters clears the datacenter cache. This needs to be called when
+// a datacenter is removed, so that a datacenter that is created again with
+// the same name is looked up again.
+func (c *VSphereClient) forgetDatacenters() {
+	c.datacentersMu.Lock()
+	defer c.datacentersMu.Unlock()
+	c.datacenters = nil
+	c.datacenterLookups = nil
+}
+
 // Config holds the provider configuration, and delivers a populated
 // VSphereClient based off the contained settings.
 type Config struct {
-	User          string
-	Password      string
-	VSphereServer string
-	InsecureFlag  bool
-	Debug         bool
-	DebugPath     string
-	DebugPathRun  string
+	User            string
+	Password        string
+	VSphereServer   string
+	InsecureFlag    bool
+	CAFile          string
+	CAPEM           string
+	ProxyURL        string
+	ClientCert      string
+	ClientKey       string
+	Debug           bool
+	DebugPath       string
+	DebugPathRun    string
+	PersistSession  bool
+	VimSessionPath  string
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	KeepAlive       time.Duration
+	APITimeout      time.Duration
+	ReadOnlyRefresh bool
 }
 
 // Client returns a new client for accessing VMWare vSphere.
 func (c *Config) Client() (*VSphereClient, error) {
 	client := new(VSphereClient)
+	client.readOnlyRefresh = c.ReadOnlyRefresh
 
 	u, err := url.Parse("https://" + c.VSphereServer + "/sdk")
 	if err != nil {
@@ -85,15 +192,55 @@ func (c *Config) Client() (*VSphereClient, error) {
 		return nil, fmt.Errorf("Error setting up client debug: %s", err)
 	}
 
-	// Set up the VIM/govmomi client connection.
-	client.vimClient, err = govmomi.NewClient(context.TODO(), u, c.InsecureFlag)
+	// Set up the VIM/govmomi client connection. This is done by hand instead of
+	// with govmomi.NewClient so that the keep alive is in place before login,
+	// as it only starts once it sees a login.
+	soapClient := soap.NewClient(u, c.InsecureFlag)
+	if c.ClientCert != "" {
+		cert, err := c.loadClientCertificate()
+		if err != nil {
+			return nil, err
+		}
+		soapClient.SetCertificate(cert)
+		client.certificateLogin = true
+	}
+	if err := c.configureTransport(soapClient.Client.Transport); err != nil {
+		return nil, err
+	}
+	keepAlive := newSessionKeepAlive(soapClient, c.KeepAlive)
+	vimClient, err := vim25.NewClient(context.TODO(), keepAlive)
 	if err != nil {
 		return nil, fmt.Errorf("Error setting up client: %s", err)
 	}
+	// The SOAP client is only filled in by vim25.NewClient when it is passed in
+	// directly, and is needed for file transfers.
+	vimClient.Client = soapClient
+	client.vimClient = &govmomi.Client{
+		Client:         vimClient,
+		SessionManager: session.NewManager(vimClient),
+	}
+	if err := c.vimLogin(client.vimClient, keepAlive, u); err != nil {
+		return nil, fmt.Errorf("Error setting up client: %s", err)
+	}
+	// Log in again when the session expires, and retry idempotent calls that
+	// fail with transient errors. These are set up after login so that
+	// authentication failures are not retried. The API timeout wraps both, so
+	// that it covers the full time spent on a call, including retries.
+	login := passwordLogin(*vimClient.ServiceContent.SessionManager, u.User)
+	if client.certificateLogin {
+		login = certificateLogin(*vimClient.ServiceContent.SessionManager, c.User)
+	}
+	rt := newReloginRoundTripper(vimClient.RoundTripper, login)
+	rt = newAPIRetryRoundTripper(rt, c.MaxRetries, c.RetryBackoff)
+	vimClient.RoundTripper = newAPITimeoutRoundTripper(rt, c.APITimeout)
 
 	log.Printf("[INFO] VMWare vSphere Client configured for URL: %s", c.VSphereServer)
 
 	// Skip the rest of this function if we are not setting up the tags client. This is if
+	if client.certificateLogin {
+		log.Printf("[WARN] Tags are not supported when logging in with a client certificate")
+		return client, nil
+	}
 	if !isEligibleTagEndpoint(client.vimClient) {
 		log.Printf("[WARN] Connected endpoint does not support tags (%s)", parseVersionFromClient(client.vimClient))
 		return client, nil
@@ -102,6 +249,9 @@ func (c *Config) Client() (*VSphereClient, error) {
 	// Otherwise, connect to the CIS REST API for tagging.
 	log.Printf("[INFO] Logging in to CIS REST API endpoint on %s", c.VSphereServer)
 	client.tagsClient = tags.NewClient(u, c.InsecureFlag, "")
+	if err := c.configureTransport(client.tagsClient.HTTP.Transport); err != nil {
+		return nil, err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	if err := client.tagsClient.Login(ctx); err != nil {
@@ -113,6 +263,115 @@ func (c *Config) Client() (*VSphereClient, error) {
 	return client, nil
 }
 
+// vimLogin logs in the VIM client. If persist_session is set, a session saved
+// by an earlier run is restored instead if it is still valid, and the session
+// is saved after logging in, so that it can be restored by the next run.
+func (c *Config) vimLogin(client *govmomi.Client, keepAlive *sessionKeepAlive, u *url.URL) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if !c.PersistSession {
+		return c.login(ctx, client, u)
+	}
+
+	file := vimSessionFile(c.VimSessionPath, u)
+	ok, err := loadVimSession(client.Client.Client, file)
+	if err != nil {
+		log.Printf("[WARN] Could not load saved session, logging in again: %s", err)
+	}
+	if ok {
+		us, err := client.SessionManager.UserSession(ctx)
+		if err == nil && us != nil {
+			log.Printf("[DEBUG] Restored session for %s from %s", us.UserName, file)
+			keepAlive.start()
+			return nil
+		}
+		log.Printf("[DEBUG] Saved session in %s is no longer valid, logging in again", file)
+	}
+
+	if err := c.login(ctx, client, u); err != nil {
+		return err
+	}
+	if err := saveVimSession(client.Client.Client, file); err != nil {
+		log.Printf("[WARN] Could not save session to %s: %s", file, err)
+	}
+	return nil
+}
+
+// login logs in the VIM client, as the extension named in user if a client
+// certificate is set, or with the user name and password otherwise.
+func (c *Config) login(ctx context.Context, client *govmomi.Client, u *url.URL) error {
+	if c.ClientCert != "" {
+		return client.SessionManager.LoginExtensionByCertificate(ctx, c.User, "")
+	}
+	return client.Login(ctx, u.User)
+}
+
+// loadClientCertificate loads the client certificate and private key set on
+// the provider, and checks that the certificate is currently valid, so that
+// an unusable certificate is reported before it is sent to the server.
+func (c *Config) loadClientCertificate() (tls.Certificate, error) {
+	if c.ClientKey == "" {
+		return tls.Certificate{}, errors.New("client_private_key must be set when client_certificate is set")
+	}
+	cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error loading client certificate: %s", err)
+	}
+	x, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error parsing client certificate: %s", err)
+	}
+	now := time.Now()
+	if now.Before(x.NotBefore) || now.After(x.NotAfter) {
+		return tls.Certificate{}, fmt.Errorf("client certificate is only valid from %s to %s", x.NotBefore, x.NotAfter)
+	}
+	return cert, nil
+}
+
+// configureTransport adds the CA certificates and proxy set on the provider, if
+// any, to the HTTP transport of one of the API clients.
+func (c *Config) configureTransport(rt http.RoundTripper) error {
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected HTTP transport type %T", rt)
+	}
+
+	if c.CAFile != "" || c.CAPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Printf("[WARN] Could not load system CA certificates, only trusting the supplied CA: %s", err)
+			pool = x509.NewCertPool()
+		}
+		if c.CAFile != "" {
+			b, err := ioutil.ReadFile(c.CAFile)
+			if err != nil {
+				return fmt.Errorf("error reading ca_file: %s", err)
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return fmt.Errorf("no PEM-encoded certificates found in ca_file %q", c.CAFile)
+			}
+		}
+		if c.CAPEM != "" {
+			if !pool.AppendCertsFromPEM([]byte(c.CAPEM)) {
+				return errors.New("no PEM-encoded certificates found in ca_pem")
+			}
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("error parsing proxy_url: %s", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("proxy_url %q must be a full URL, ie: http://proxy.example.com:3128", c.ProxyURL)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return nil
+}
+
 // EnableDebug turns on govmomi API operation logging, if appropriate settings
 // are set on the provider.
 func (c *Config) EnableDebug() error {
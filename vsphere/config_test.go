@@ -0,0 +1,162 @@
+package vsphere
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testWriteClientCertificate writes a self-signed certificate valid from
+// notBefore to notAfter, and its private key, to files in dir, and returns
+// their paths.
+func testWriteClientCertificate(t *testing.T, dir string, notBefore, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "com.example.terraform"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	return certFile, keyFile
+}
+
+func TestConfigConfigureTransportCAPEM(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := &Config{
+		CAPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})),
+	}
+	tr := &http.Transport{TLSClientConfig: &tls.Config{}}
+	if err := c.configureTransport(tr); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	res, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected server certificate to be trusted, got: %s", err)
+	}
+	res.Body.Close()
+}
+
+func TestConfigConfigureTransportInvalidCAPEM(t *testing.T) {
+	c := &Config{
+		CAPEM: "not a certificate",
+	}
+	tr := &http.Transport{TLSClientConfig: &tls.Config{}}
+	if err := c.configureTransport(tr); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
+
+func TestConfigConfigureTransportProxyURL(t *testing.T) {
+	c := &Config{
+		ProxyURL: "http://proxy.example.com:3128",
+	}
+	tr := &http.Transport{TLSClientConfig: &tls.Config{}}
+	if err := c.configureTransport(tr); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	req, _ := http.NewRequest("GET", "https://vcenter.example.com/sdk", nil)
+	u, err := tr.Proxy(req)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if u == nil || u.String() != c.ProxyURL {
+		t.Fatalf("expected proxy %q, got %v", c.ProxyURL, u)
+	}
+}
+
+func TestConfigConfigureTransportInvalidProxyURL(t *testing.T) {
+	c := &Config{
+		ProxyURL: "proxy.example.com",
+	}
+	tr := &http.Transport{TLSClientConfig: &tls.Config{}}
+	if err := c.configureTransport(tr); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
+
+func TestConfigLoadClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-vsphere-cert")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		noKey     bool
+		expectErr bool
+	}{
+		{
+			name:      "valid",
+			notBefore: time.Now().Add(-time.Hour),
+			notAfter:  time.Now().Add(time.Hour),
+		},
+		{
+			name:      "expired",
+			notBefore: time.Now().Add(-time.Hour * 2),
+			notAfter:  time.Now().Add(-time.Hour),
+			expectErr: true,
+		},
+		{
+			name:      "missing private key",
+			notBefore: time.Now().Add(-time.Hour),
+			notAfter:  time.Now().Add(time.Hour),
+			noKey:     true,
+			expectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			certFile, keyFile := testWriteClientCertificate(t, dir, tc.notBefore, tc.notAfter)
+			c := &Config{
+				ClientCert: certFile,
+				ClientKey:  keyFile,
+			}
+			if tc.noKey {
+				c.ClientKey = ""
+			}
+			_, err := c.loadClientCertificate()
+			if tc.expectErr && err == nil {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package vsphere
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vmCompatibilityTestAllowedValues are the compatibility tests that can be run
+// with the vsphere_compatible_hosts data source.
+var vmCompatibilityTestAllowedValues = []string{
+	string(types.CheckTestTypeSourceTests),
+	string(types.CheckTestTypeHostTests),
+	string(types.CheckTestTypeResourcePoolTests),
+	string(types.CheckTestTypeDatastoreTests),
+	string(types.CheckTestTypeNetworkTests),
+}
+
+func dataSourceVSphereCompatibleHosts() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereCompatibleHostsRead,
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster to check the hosts of.",
+				Required:    true,
+			},
+			"virtual_machine_uuid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to check compatibility for.",
+				Required:    true,
+			},
+			"tests": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The compatibility tests to run. Can be one or more of sourceTests, hostTests, resourcePoolTests, datastoreTests, or networkTests. Default: hostTests, datastoreTests, and networkTests.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(vmCompatibilityTestAllowedValues, false),
+				},
+			},
+			"compatible_host_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the hosts that the virtual machine is compatible with, sorted by host name.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"hosts": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The results of the compatibility tests for every host in the cluster, sorted by host name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host_system_id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the host.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the host.",
+							Computed:    true,
+						},
+						"compatible": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not the virtual machine is compatible with the host.",
+							Computed:    true,
+						},
+						"errors": {
+							Type:        schema.TypeList,
+							Description: "The reasons the virtual machine is not compatible with the host.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"warnings": {
+							Type:        schema.TypeList,
+							Description: "Any warnings about running the virtual machine on the host.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereCompatibleHostsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	clusterID := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterComputeResourceFromID(client, clusterID)
+	if err != nil {
+		return err
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	hosts, err := clusterComputeResourceHosts(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster hosts: %s", err)
+	}
+	tests := sliceInterfacesToStrings(d.Get("tests").([]interface{}))
+	if len(tests) < 1 {
+		tests = []string{
+			string(types.CheckTestTypeHostTests),
+			string(types.CheckTestTypeDatastoreTests),
+			string(types.CheckTestTypeNetworkTests),
+		}
+	}
+
+	var results []map[string]interface{}
+	for _, host := range hosts {
+		checks, err := checkVMCompatibility(client, vm.Reference(), host.Reference(), tests)
+		if err != nil {
+			return fmt.Errorf("error checking compatibility with host %q: %s", host.Name(), err)
+		}
+		r := flattenCheckResults(checks)
+		r["host_system_id"] = host.Reference().Value
+		r["name"] = host.Name()
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i]["name"].(string) < results[j]["name"].(string) })
+
+	var compatible []string
+	for _, r := range results {
+		if r["compatible"].(bool) {
+			compatible = append(compatible, r["host_system_id"].(string))
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", clusterID, vm.Reference().Value))
+	if err := d.Set("compatible_host_ids", compatible); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("hosts", results); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// flattenCheckResults merges the errors and warnings of the compatibility
+// checks for a single host. The host is compatible if none of the checks
+// returned an error.
+func flattenCheckResults(checks []types.CheckResult) map[string]interface{} {
+	errs := make([]string, 0)
+	warnings := make([]string, 0)
+	for _, c := range checks {
+		for _, f := range c.Error {
+			errs = append(errs, localizedMethodFaultMessage(f))
+		}
+		for _, f := range c.Warning {
+			warnings = append(warnings, localizedMethodFaultMessage(f))
+		}
+	}
+	return map[string]interface{}{
+		"compatible": len(errs) < 1,
+		"errors":     errs,
+		"warnings":   warnings,
+	}
+}
+
+// localizedMethodFaultMessage returns the message of a LocalizedMethodFault,
+// or the type of the fault if it has no message.
+func localizedMethodFaultMessage(f types.LocalizedMethodFault) string {
+	if f.LocalizedMessage != "" {
+		return f.LocalizedMessage
+	}
+	if f.Fault == nil {
+		return "unknown fault"
+	}
+	return reflect.Indirect(reflect.ValueOf(f.Fault)).Type().Name()
+}
@@ -0,0 +1,155 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testFlattenCheckResults struct {
+	Name string
+
+	checks   []types.CheckResult
+	expected map[string]interface{}
+}
+
+func (tc *testFlattenCheckResults) Test(t *testing.T) {
+	actual := flattenCheckResults(tc.checks)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestFlattenCheckResults(t *testing.T) {
+	cases := []testFlattenCheckResults{
+		{
+			Name:   "no results",
+			checks: nil,
+			expected: map[string]interface{}{
+				"compatible": true,
+				"errors":     []string{},
+				"warnings":   []string{},
+			},
+		},
+		{
+			Name: "warnings only",
+			checks: []types.CheckResult{
+				{
+					Warning: []types.LocalizedMethodFault{
+						{LocalizedMessage: "The virtual machine has a snapshot."},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"compatible": true,
+				"errors":     []string{},
+				"warnings":   []string{"The virtual machine has a snapshot."},
+			},
+		},
+		{
+			Name: "errors across checks",
+			checks: []types.CheckResult{
+				{
+					Error: []types.LocalizedMethodFault{
+						{LocalizedMessage: "Network \"VM Network\" is not accessible."},
+					},
+				},
+				{
+					Error: []types.LocalizedMethodFault{
+						{Fault: &types.FeatureRequirementsNotMet{}},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"compatible": false,
+				"errors": []string{
+					"Network \"VM Network\" is not accessible.",
+					"FeatureRequirementsNotMet",
+				},
+				"warnings": []string{},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestAccDataSourceVSphereCompatibleHosts(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereCompatibleHostsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccDataSourceVSphereCompatibleHostsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereCompatibleHostsConfig(""),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestMatchResourceAttr("data.vsphere_compatible_hosts.hosts", "hosts.#", regexp.MustCompile("^[1-9][0-9]*$")),
+							resource.TestCheckResourceAttrSet("data.vsphere_compatible_hosts.hosts", "hosts.0.host_system_id"),
+							resource.TestCheckResourceAttrSet("data.vsphere_compatible_hosts.hosts", "hosts.0.compatible"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"invalid test type",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccDataSourceVSphereCompatibleHostsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccDataSourceVSphereCompatibleHostsConfig(`tests = ["badTests"]`),
+						ExpectError: regexp.MustCompile("expected tests.0 to be one of"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereCompatibleHostsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereCompatibleHostsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_compatible_hosts acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VM_UUID") == "" {
+		t.Skip("set VSPHERE_VM_UUID to run vsphere_compatible_hosts acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereCompatibleHostsConfig(extra string) string {
+	return fmt.Sprintf(`
+data "vsphere_compatible_hosts" "hosts" {
+  compute_cluster_id   = "%s"
+  virtual_machine_uuid = "%s"
+  %s
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"), os.Getenv("VSPHERE_VM_UUID"), extra)
+}
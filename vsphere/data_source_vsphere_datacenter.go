@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func dataSourceVSphereDatacenter() *schema.Resource {
@@ -16,6 +17,50 @@ func dataSourceVSphereDatacenter() *schema.Resource {
 				Description: "The name of the datacenter. This can be a name or path.	Can be omitted if there is only one datacenter in your inventory.",
 				Optional: true,
 			},
+			"vm_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root virtual machine folder of the datacenter.",
+				Computed:    true,
+			},
+			"host_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root host folder of the datacenter.",
+				Computed:    true,
+			},
+			"datastore_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root datastore folder of the datacenter.",
+				Computed:    true,
+			},
+			"network_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root network folder of the datacenter.",
+				Computed:    true,
+			},
+			"networks": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The networks in the datacenter.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the network.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the network.",
+							Computed:    true,
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Description: "The managed object type of the network.",
+							Computed:    true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -30,5 +75,37 @@ func dataSourceVSphereDatacenterRead(d *schema.ResourceData, meta interface{}) e
 	id := dc.Reference().Value
 	d.SetId(id)
 
+	props, err := datacenterProperties(dc)
+	if err != nil {
+		return fmt.Errorf("error fetching datacenter properties: %s", err)
+	}
+	d.Set("vm_folder_id", props.VmFolder.Value)
+	d.Set("host_folder_id", props.HostFolder.Value)
+	d.Set("datastore_folder_id", props.DatastoreFolder.Value)
+	d.Set("network_folder_id", props.NetworkFolder.Value)
+
+	names, err := networkNames(client, props.Network)
+	if err != nil {
+		return fmt.Errorf("error fetching datacenter networks: %s", err)
+	}
+	if err := d.Set("networks", flattenDatacenterNetworks(props.Network, names)); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
 	return nil
 }
+
+// flattenDatacenterNetworks returns the networks attribute of the
+// vsphere_datacenter data source for the networks in refs, in the order that
+// vSphere reports them. names maps the managed object IDs of the networks to
+// their names.
+func flattenDatacenterNetworks(refs []types.ManagedObjectReference, names map[string]string) []interface{} {
+	var networks []interface{}
+	for _, ref := range refs {
+		networks = append(networks, map[string]interface{}{
+			"id":   ref.Value,
+			"name": names[ref.Value],
+			"type": ref.Type,
+		})
+	}
+	return networks
+}
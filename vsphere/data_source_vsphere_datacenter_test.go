@@ -3,10 +3,12 @@ package vsphere
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 var testAccDataSourceVSphereDatacenterExpectedRegexp = regexp.MustCompile("^datacenter-")
@@ -34,6 +36,11 @@ func TestAccDataSourceVSphereDatacenter(t *testing.T) {
 								"id",
 								testAccDataSourceVSphereDatacenterExpectedRegexp,
 							),
+							resource.TestMatchResourceAttr("data.vsphere_datacenter.dc", "vm_folder_id", regexp.MustCompile("^group-v")),
+							resource.TestMatchResourceAttr("data.vsphere_datacenter.dc", "host_folder_id", regexp.MustCompile("^group-h")),
+							resource.TestMatchResourceAttr("data.vsphere_datacenter.dc", "datastore_folder_id", regexp.MustCompile("^group-s")),
+							resource.TestMatchResourceAttr("data.vsphere_datacenter.dc", "network_folder_id", regexp.MustCompile("^group-n")),
+							resource.TestMatchResourceAttr("data.vsphere_datacenter.dc", "networks.#", regexp.MustCompile("^[1-9]")),
 						),
 					},
 				},
@@ -71,6 +78,29 @@ func TestAccDataSourceVSphereDatacenter(t *testing.T) {
 	}
 }
 
+func TestFlattenDatacenterNetworks(t *testing.T) {
+	refs := []types.ManagedObjectReference{
+		{Type: "Network", Value: "network-1"},
+		{Type: "DistributedVirtualPortgroup", Value: "dvportgroup-2"},
+		{Type: "OpaqueNetwork", Value: "network-o3"},
+	}
+	names := map[string]string{
+		"network-1":     "VM Network",
+		"dvportgroup-2": "pg-1",
+		"network-o3":    "segment-1",
+	}
+	expected := []interface{}{
+		map[string]interface{}{"id": "network-1", "name": "VM Network", "type": "Network"},
+		map[string]interface{}{"id": "dvportgroup-2", "name": "pg-1", "type": "DistributedVirtualPortgroup"},
+		map[string]interface{}{"id": "network-o3", "name": "segment-1", "type": "OpaqueNetwork"},
+	}
+
+	actual := flattenDatacenterNetworks(refs, names)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
 func testAccDataSourceVSphereDatacenterPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_datacenter acceptance tests")
@@ -0,0 +1,219 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// datastoreFileTypeAllowedValues are the file types that can be searched for
+// with the vsphere_datastore_files data source. "file" is only used for files
+// that do not match any of the other types, and cannot be searched for
+// directly.
+var datastoreFileTypeAllowedValues = []string{
+	"disk",
+	"folder",
+	"iso",
+	"floppy",
+	"vm_config",
+	"vm_log",
+	"vm_nvram",
+	"vm_snapshot",
+}
+
+func dataSourceVSphereDatastoreFiles() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDatastoreFilesRead,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore to browse.",
+				Required:    true,
+			},
+			"path": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The path on the datastore to browse. Default: the root of the datastore.",
+				Optional:    true,
+			},
+			"match_patterns": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "A list of wildcard patterns to match file names against, ie: *.vmdk. Default: all files.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"file_types": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "A list of file types to restrict the search to. Can be one or more of disk, folder, iso, floppy, vm_config, vm_log, vm_nvram, or vm_snapshot. Default: all files.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(datastoreFileTypeAllowedValues, false),
+				},
+			},
+			"recursive": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Search all sub-folders of path as well.",
+				Optional:    true,
+			},
+			"files": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The files found by the search, sorted by path.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:        schema.TypeString,
+							Description: "The full datastore path of the file, ie: [datastore1] folder/disk.vmdk.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the file.",
+							Computed:    true,
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Description: "The size of the file in bytes.",
+							Computed:    true,
+						},
+						"modification_time": {
+							Type:        schema.TypeString,
+							Description: "The time the file was last modified, in RFC3339 format.",
+							Computed:    true,
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Description: "The type of the file. One of the values in file_types, or file if the file is none of these types.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDatastoreFilesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ds, err := datastoreFromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+
+	spec := &types.HostDatastoreBrowserSearchSpec{
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(false),
+		},
+		MatchPattern: sliceInterfacesToStrings(d.Get("match_patterns").([]interface{})),
+	}
+	for _, t := range sliceInterfacesToStrings(d.Get("file_types").([]interface{})) {
+		spec.Query = append(spec.Query, expandDatastoreFileQuery(t))
+	}
+
+	results, err := searchDatastore(ds, d.Get("path").(string), spec, d.Get("recursive").(bool))
+	if err != nil {
+		return fmt.Errorf("error searching datastore: %s", err)
+	}
+
+	var files []map[string]interface{}
+	for _, res := range results {
+		for _, f := range res.File {
+			info := f.GetFileInfo()
+			m := map[string]interface{}{
+				"path":              datastoreSearchResultPath(res.FolderPath, info.Path),
+				"name":              info.Path,
+				"size":              int(info.FileSize),
+				"modification_time": "",
+				"type":              flattenDatastoreFileType(f),
+			}
+			if info.Modification != nil {
+				m["modification_time"] = info.Modification.UTC().Format(time.RFC3339)
+			}
+			files = append(files, m)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i]["path"].(string) < files[j]["path"].(string) })
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("files", files); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// expandDatastoreFileQuery converts a file type in file_types to the
+// FileQuery used to search for it.
+func expandDatastoreFileQuery(t string) types.BaseFileQuery {
+	switch t {
+	case "disk":
+		return &types.VmDiskFileQuery{}
+	case "folder":
+		return &types.FolderFileQuery{}
+	case "iso":
+		return &types.IsoImageFileQuery{}
+	case "floppy":
+		return &types.FloppyImageFileQuery{}
+	case "vm_config":
+		return &types.VmConfigFileQuery{}
+	case "vm_log":
+		return &types.VmLogFileQuery{}
+	case "vm_nvram":
+		return &types.VmNvramFileQuery{}
+	case "vm_snapshot":
+		return &types.VmSnapshotFileQuery{}
+	}
+	return &types.FileQuery{}
+}
+
+// flattenDatastoreFileType returns the file type of a search result in the
+// format used by file_types.
+func flattenDatastoreFileType(f types.BaseFileInfo) string {
+	switch f.(type) {
+	case *types.VmDiskFileInfo:
+		return "disk"
+	case *types.FolderFileInfo:
+		return "folder"
+	case *types.IsoImageFileInfo:
+		return "iso"
+	case *types.FloppyImageFileInfo:
+		return "floppy"
+	case *types.VmConfigFileInfo:
+		return "vm_config"
+	case *types.VmLogFileInfo:
+		return "vm_log"
+	case *types.VmNvramFileInfo:
+		return "vm_nvram"
+	case *types.VmSnapshotFileInfo:
+		return "vm_snapshot"
+	}
+	return "file"
+}
+
+// datastoreSearchResultPath returns the full datastore path of a file in a
+// search result, given the folder path of the result. The folder path is in
+// "[datastore] path" format, and may or may not end in a slash.
+func datastoreSearchResultPath(folder, name string) string {
+	var dp object.DatastorePath
+	if !dp.FromString(folder) {
+		return name
+	}
+	switch {
+	case dp.Path == "":
+		dp.Path = name
+	case dp.Path[len(dp.Path)-1] == '/':
+		dp.Path += name
+	default:
+		dp.Path += "/" + name
+	}
+	return dp.String()
+}
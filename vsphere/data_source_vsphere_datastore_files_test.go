@@ -0,0 +1,128 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereDatastoreFiles(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereDatastoreFilesCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"vmdk files",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereDatastoreFilesPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereDatastoreFilesConfig(`match_patterns = ["terraform-test-files-*.vmdk"]`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.#", "1"),
+							resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.path", "[terraform-test-nas] terraform-test-files-disk.vmdk"),
+							resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.name", "terraform-test-files-disk.vmdk"),
+							resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.type", "disk"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"recursive folder search",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereDatastoreFilesPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereDatastoreFilesConfig(`file_types     = ["disk"]
+  recursive      = true`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereDatastoreFilesCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereDatastoreFilesPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_datastore_files acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NAS_HOST") == "" {
+		t.Skip("set VSPHERE_NAS_HOST to run vsphere_datastore_files acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH") == "" {
+		t.Skip("set VSPHERE_NFS_PATH to run vsphere_datastore_files acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereDatastoreFilesConfig(search string) string {
+	return fmt.Sprintf(`
+variable "nfs_host" {
+  type    = "string"
+  default = "%s"
+}
+
+variable "nfs_path" {
+  type    = "string"
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name            = "terraform-test-nas"
+  host_system_ids = ["${data.vsphere_host.esxi_host.id}"]
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+
+resource "vsphere_virtual_disk" "disk" {
+  size         = 1
+  vmdk_path    = "terraform-test-files-disk.vmdk"
+  datacenter   = "${data.vsphere_datacenter.datacenter.name}"
+  datastore    = "${vsphere_nas_datastore.datastore.name}"
+  type         = "thin"
+  adapter_type = "lsiLogic"
+}
+
+data "vsphere_datastore_files" "files" {
+  datastore_id = "${vsphere_nas_datastore.datastore.id}"
+  depends_on   = ["vsphere_virtual_disk.disk"]
+  %s
+}
+
+output "found" {
+  value = "${contains(data.vsphere_datastore_files.files.files.*.name, "terraform-test-files-disk.vmdk")}"
+}
+`, os.Getenv("VSPHERE_NAS_HOST"), os.Getenv("VSPHERE_NFS_PATH"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), search)
+}
@@ -0,0 +1,118 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereDrsRecommendations() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDrsRecommendationsRead,
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster to fetch DRS recommendations for.",
+				Required:    true,
+			},
+			"refresh": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Ask DRS to recalculate the recommendations for the cluster before reading them.",
+				Optional:    true,
+			},
+			"drs_enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether or not DRS is enabled on the cluster.",
+				Computed:    true,
+			},
+			"drs_automation_level": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The default DRS automation level of the cluster. One of manual, partiallyAutomated, or fullyAutomated.",
+				Computed:    true,
+			},
+			"ha_enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether or not vSphere HA is enabled on the cluster.",
+				Computed:    true,
+			},
+			"ha_current_failover_level": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of host failures that the cluster can currently tolerate.",
+				Computed:    true,
+			},
+			"current_balance": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The current load imbalance of the cluster, as calculated by DRS.",
+				Computed:    true,
+			},
+			"target_balance": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The load imbalance that DRS aims to keep the cluster under.",
+				Computed:    true,
+			},
+			"balanced": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether or not the current balance of the cluster is within the target balance.",
+				Computed:    true,
+			},
+			"overall_status": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The overall health status of the cluster. One of gray, green, yellow, or red.",
+				Computed:    true,
+			},
+			"recommendations": schemaClusterRecommendation(),
+		},
+	}
+}
+
+func dataSourceVSphereDrsRecommendationsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	id := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterComputeResourceFromID(client, id)
+	if err != nil {
+		return err
+	}
+	if d.Get("refresh").(bool) && meta.(*VSphereClient).readOnlyRefresh {
+		log.Printf("[WARN] Not refreshing DRS recommendations on cluster %q, as read_only_refresh is set", id)
+	} else if d.Get("refresh").(bool) {
+		if err := refreshClusterRecommendations(client, cluster); err != nil {
+			return fmt.Errorf("error refreshing DRS recommendations: %s", err)
+		}
+	}
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+
+	d.SetId(id)
+	flattenClusterDrsRuntimeState(d, props)
+	if err := d.Set("recommendations", flattenClusterRecommendations(props.Recommendation)); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// flattenClusterDrsRuntimeState saves the DRS and HA state of a cluster to the
+// vsphere_drs_recommendations data source.
+func flattenClusterDrsRuntimeState(d *schema.ResourceData, props *mo.ClusterComputeResource) {
+	if config, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx); ok {
+		d.Set("drs_enabled", config.DrsConfig.Enabled != nil && *config.DrsConfig.Enabled)
+		d.Set("drs_automation_level", string(config.DrsConfig.DefaultVmBehavior))
+		d.Set("ha_enabled", config.DasConfig.Enabled != nil && *config.DasConfig.Enabled)
+	}
+	if summary, ok := props.Summary.(*types.ClusterComputeResourceSummary); ok {
+		d.Set("ha_current_failover_level", summary.CurrentFailoverLevel)
+		d.Set("current_balance", summary.CurrentBalance)
+		d.Set("target_balance", summary.TargetBalance)
+		d.Set("balanced", summary.CurrentBalance <= summary.TargetBalance)
+		d.Set("overall_status", string(summary.OverallStatus))
+	}
+}
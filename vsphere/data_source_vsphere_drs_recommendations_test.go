@@ -0,0 +1,80 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereDrsRecommendations(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereDrsRecommendationsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccDataSourceVSphereDrsRecommendationsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereDrsRecommendationsConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_drs_recommendations.drs", "drs_enabled", "true"),
+							resource.TestCheckResourceAttrSet("data.vsphere_drs_recommendations.drs", "drs_automation_level"),
+							resource.TestCheckResourceAttrSet("data.vsphere_drs_recommendations.drs", "overall_status"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"refresh",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccDataSourceVSphereDrsRecommendationsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereDrsRecommendationsConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrSet("data.vsphere_drs_recommendations.drs", "recommendations.#"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereDrsRecommendationsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereDrsRecommendationsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_drs_recommendations acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereDrsRecommendationsConfig(refresh bool) string {
+	return fmt.Sprintf(`
+data "vsphere_drs_recommendations" "drs" {
+  compute_cluster_id = "%s"
+  refresh            = %t
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"), refresh)
+}
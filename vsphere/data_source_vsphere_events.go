@@ -0,0 +1,217 @@
+package vsphere
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var eventRecursionAllowedValues = []string{
+	string(types.EventFilterSpecRecursionOptionSelf),
+	string(types.EventFilterSpecRecursionOptionChildren),
+	string(types.EventFilterSpecRecursionOptionAll),
+}
+
+func dataSourceVSphereEvents() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereEventsRead,
+
+		Schema: map[string]*schema.Schema{
+			"entity_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to fetch events for. Default: events for all entities.",
+				Optional:    true,
+			},
+			"entity_type": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object type of the entity in entity_id, ie: VirtualMachine or HostSystem.",
+				Optional:    true,
+			},
+			"recursion": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Which events to include relative to the entity. Can be one of self, children, or all.",
+				Optional:     true,
+				Default:      string(types.EventFilterSpecRecursionOptionSelf),
+				ValidateFunc: validation.StringInSlice(eventRecursionAllowedValues, false),
+			},
+			"begin_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Only include events at or after this time, in RFC3339 format.",
+				Optional:     true,
+				ValidateFunc: validateRFC3339Time,
+			},
+			"end_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Only include events at or before this time, in RFC3339 format.",
+				Optional:     true,
+				ValidateFunc: validateRFC3339Time,
+			},
+			"event_types": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "A list of event types to restrict the search to, ie: VmPoweredOnEvent or TaskEvent. Default: all event types.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"users": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "A list of user names to restrict the search to. Default: events from all users.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"max_events": &schema.Schema{
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of events to return.",
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 10000),
+			},
+			"events": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The events found by the search, newest first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeInt,
+							Description: "The unique key of the event.",
+							Computed:    true,
+						},
+						"chain_id": {
+							Type:        schema.TypeInt,
+							Description: "The key of the event that started the chain of related events that this event belongs to.",
+							Computed:    true,
+						},
+						"time": {
+							Type:        schema.TypeString,
+							Description: "The time the event was created, in RFC3339 format.",
+							Computed:    true,
+						},
+						"user": {
+							Type:        schema.TypeString,
+							Description: "The user that caused the event.",
+							Computed:    true,
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Description: "The type of the event, ie: VmPoweredOnEvent.",
+							Computed:    true,
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Description: "The formatted message of the event.",
+							Computed:    true,
+						},
+						"target_id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the entity the event is about.",
+							Computed:    true,
+						},
+						"target_type": {
+							Type:        schema.TypeString,
+							Description: "The managed object type of the entity the event is about.",
+							Computed:    true,
+						},
+						"target_name": {
+							Type:        schema.TypeString,
+							Description: "The name of the entity the event is about.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereEventsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	filter, err := expandEventFilterSpec(d)
+	if err != nil {
+		return err
+	}
+
+	events, err := queryEvents(client, filter, d.Get("max_events").(int))
+	if err != nil {
+		return fmt.Errorf("error querying events: %s", err)
+	}
+
+	var results []map[string]interface{}
+	for _, e := range events {
+		results = append(results, flattenEvent(e))
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("events", results); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// expandEventFilterSpec reads the search arguments of the vsphere_events data
+// source and returns an EventFilterSpec.
+func expandEventFilterSpec(d *schema.ResourceData) (types.EventFilterSpec, error) {
+	var filter types.EventFilterSpec
+	id := d.Get("entity_id").(string)
+	typ := d.Get("entity_type").(string)
+	switch {
+	case id != "" && typ != "":
+		filter.Entity = &types.EventFilterSpecByEntity{
+			Entity: types.ManagedObjectReference{
+				Type:  typ,
+				Value: id,
+			},
+			Recursion: types.EventFilterSpecRecursionOption(d.Get("recursion").(string)),
+		}
+	case id != "" || typ != "":
+		return filter, fmt.Errorf("entity_id and entity_type must be set together")
+	}
+
+	begin, err := parseTimePtr(d.Get("begin_time").(string))
+	if err != nil {
+		return filter, fmt.Errorf("error parsing begin_time: %s", err)
+	}
+	end, err := parseTimePtr(d.Get("end_time").(string))
+	if err != nil {
+		return filter, fmt.Errorf("error parsing end_time: %s", err)
+	}
+	if begin != nil && end != nil && end.Before(*begin) {
+		return filter, fmt.Errorf("end_time cannot be before begin_time")
+	}
+	if begin != nil || end != nil {
+		filter.Time = &types.EventFilterSpecByTime{
+			BeginTime: begin,
+			EndTime:   end,
+		}
+	}
+
+	if users := sliceInterfacesToStrings(d.Get("users").([]interface{})); len(users) > 0 {
+		filter.UserName = &types.EventFilterSpecByUsername{
+			UserList: users,
+		}
+	}
+	filter.EventTypeId = sliceInterfacesToStrings(d.Get("event_types").([]interface{}))
+
+	return filter, nil
+}
+
+// flattenEvent converts an event into the format used by the events
+// attribute of the vsphere_events data source.
+func flattenEvent(e types.BaseEvent) map[string]interface{} {
+	base := e.GetEvent()
+	target, name := eventTarget(base)
+	return map[string]interface{}{
+		"key":         int(base.Key),
+		"chain_id":    int(base.ChainId),
+		"time":        base.CreatedTime.UTC().Format(time.RFC3339),
+		"user":        base.UserName,
+		"type":        eventTypeName(e),
+		"message":     base.FullFormattedMessage,
+		"target_id":   target.Value,
+		"target_type": target.Type,
+		"target_name": name,
+	}
+}
@@ -0,0 +1,104 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereEvents(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereEventsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"datacenter events",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereEventsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereEventsConfig(`recursion  = "all"
+  max_events = 5`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_events.events", "events.#", "5"),
+							resource.TestCheckResourceAttrSet("data.vsphere_events.events", "events.0.key"),
+							resource.TestCheckResourceAttrSet("data.vsphere_events.events", "events.0.time"),
+							resource.TestCheckResourceAttrSet("data.vsphere_events.events", "events.0.type"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"time window with no events",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereEventsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereEventsConfig(`begin_time = "1990-01-01T00:00:00Z"
+  end_time   = "1990-01-02T00:00:00Z"`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_events.events", "events.#", "0"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"end time before begin time",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereEventsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereEventsConfig(`begin_time = "2017-01-02T00:00:00Z"
+  end_time   = "2017-01-01T00:00:00Z"`),
+						ExpectError: regexp.MustCompile("end_time cannot be before begin_time"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereEventsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereEventsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_events acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereEventsConfig(search string) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_events" "events" {
+  entity_id   = "${data.vsphere_datacenter.datacenter.id}"
+  entity_type = "Datacenter"
+  %s
+}
+`, os.Getenv("VSPHERE_DATACENTER"), search)
+}
@@ -0,0 +1,106 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereGuestIDs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereGuestIDsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to list supported guests for. Conflicts with compute_cluster_id.",
+				Optional:      true,
+				ConflictsWith: []string{"compute_cluster_id"},
+			},
+			"compute_cluster_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the cluster to list supported guests for. Conflicts with host_system_id.",
+				Optional:      true,
+				ConflictsWith: []string{"host_system_id"},
+			},
+			"hardware_version": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The virtual machine hardware version to list supported guests for, ie: vmx-13. Defaults to the default hardware version of the host or cluster.",
+				Optional:    true,
+			},
+			"guest_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The IDs of the supported guests, sorted. These are the values that can be used in guest_id on a virtual machine.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"guests": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The supported guests, sorted by ID.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "The guest ID, ie: rhel7_64Guest.",
+							Computed:    true,
+						},
+						"full_name": {
+							Type:        schema.TypeString,
+							Description: "The full name of the guest operating system.",
+							Computed:    true,
+						},
+						"family": {
+							Type:        schema.TypeString,
+							Description: "The family of the guest operating system, ie: linuxGuest or windowsGuest.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereGuestIDsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id, eb, host, err := environmentBrowserFromResourceData(client, d)
+	if err != nil {
+		return err
+	}
+
+	descs, err := environmentBrowserGuestOsDescriptors(client, eb, d.Get("hardware_version").(string), host)
+	if err != nil {
+		return fmt.Errorf("error fetching supported guests: %s", err)
+	}
+
+	d.SetId(id)
+	ids, guests := flattenGuestOsDescriptors(descs)
+	if err := d.Set("guest_ids", ids); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("guests", guests); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// flattenGuestOsDescriptors converts a list of guest OS descriptors into the
+// formats used by the guest_ids and guests attributes of the vsphere_guest_ids
+// data source, sorted by ID.
+func flattenGuestOsDescriptors(descs []types.GuestOsDescriptor) ([]interface{}, []interface{}) {
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Id < descs[j].Id })
+	var ids, guests []interface{}
+	for _, desc := range descs {
+		ids = append(ids, desc.Id)
+		guests = append(guests, map[string]interface{}{
+			"id":        desc.Id,
+			"full_name": desc.FullName,
+			"family":    desc.Family,
+		})
+	}
+	return ids, guests
+}
@@ -0,0 +1,100 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereGuestIDs(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereGuestIDsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+						tp.Skip("set VSPHERE_ESXI_HOST to run vsphere_guest_ids acceptance tests")
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereGuestIDsConfigHost(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"cluster with hardware version",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+						tp.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_guest_ids acceptance tests")
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereGuestIDsConfigCluster(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereGuestIDsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereGuestIDsConfigHost() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_guest_ids" "guests" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_guest_ids.guests.guest_ids, "otherLinux64Guest")}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccDataSourceVSphereGuestIDsConfigCluster() string {
+	return fmt.Sprintf(`
+data "vsphere_guest_ids" "guests" {
+  compute_cluster_id = "%s"
+  hardware_version   = "vmx-10"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_guest_ids.guests.guests.*.id, "otherLinux64Guest")}"
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"))
+}
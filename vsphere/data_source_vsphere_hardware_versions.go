@@ -0,0 +1,167 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereHardwareVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHardwareVersionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to list hardware versions for. Conflicts with compute_cluster_id.",
+				Optional:      true,
+				ConflictsWith: []string{"compute_cluster_id"},
+			},
+			"compute_cluster_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the cluster to list hardware versions for. Conflicts with host_system_id.",
+				Optional:      true,
+				ConflictsWith: []string{"host_system_id"},
+			},
+			"supported_versions": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The hardware versions that new virtual machines can be created with, sorted from lowest to highest.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"max_version": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The highest hardware version that new virtual machines can be created with.",
+				Computed:    true,
+			},
+			"default_version": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The hardware version that new virtual machines are created with by default.",
+				Computed:    true,
+			},
+			"versions": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "All of the hardware versions known to the host or cluster, sorted from lowest to highest.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version": {
+							Type:        schema.TypeInt,
+							Description: "The hardware version, ie: 13.",
+							Computed:    true,
+						},
+						"key": {
+							Type:        schema.TypeString,
+							Description: "The hardware version key, ie: vmx-13.",
+							Computed:    true,
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Description: "The description of the hardware version.",
+							Computed:    true,
+						},
+						"create_supported": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not new virtual machines can be created with this hardware version.",
+							Computed:    true,
+						},
+						"run_supported": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not virtual machines with this hardware version can be powered on.",
+							Computed:    true,
+						},
+						"upgrade_supported": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not virtual machines can be upgraded to this hardware version.",
+							Computed:    true,
+						},
+						"default": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not this is the default hardware version.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHardwareVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id, eb, host, err := environmentBrowserFromResourceData(client, d)
+	if err != nil {
+		return err
+	}
+
+	descs, err := environmentBrowserConfigOptionDescriptors(client, eb)
+	if err != nil {
+		return fmt.Errorf("error fetching hardware versions: %s", err)
+	}
+
+	var supported, versions []interface{}
+	var max, def int
+	for _, desc := range filterConfigOptionDescriptors(descs, host) {
+		// Descriptors that are not hardware versions, if any, are skipped.
+		version, err := parseHardwareVersion(desc.Key)
+		if err != nil {
+			continue
+		}
+		create := desc.CreateSupported != nil && *desc.CreateSupported
+		isDefault := desc.DefaultConfigOption != nil && *desc.DefaultConfigOption
+		if create {
+			supported = append(supported, version)
+			if version > max {
+				max = version
+			}
+		}
+		if isDefault {
+			def = version
+		}
+		versions = append(versions, map[string]interface{}{
+			"version":           version,
+			"key":               desc.Key,
+			"description":       desc.Description,
+			"create_supported":  create,
+			"run_supported":     desc.RunSupported != nil && *desc.RunSupported,
+			"upgrade_supported": desc.UpgradeSupported != nil && *desc.UpgradeSupported,
+			"default":           isDefault,
+		})
+	}
+
+	d.SetId(id)
+	if err := d.Set("supported_versions", supported); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("versions", versions); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	d.Set("max_version", max)
+	d.Set("default_version", def)
+
+	return nil
+}
+
+// filterConfigOptionDescriptors returns the hardware version descriptors in
+// descs sorted by key. If host is not nil, only the descriptors that apply to
+// that host are returned. Descriptors that do not list any hosts apply to all
+// hosts.
+func filterConfigOptionDescriptors(descs []types.VirtualMachineConfigOptionDescriptor, host *types.ManagedObjectReference) []types.VirtualMachineConfigOptionDescriptor {
+	var result []types.VirtualMachineConfigOptionDescriptor
+	for _, desc := range descs {
+		if host == nil || len(desc.Host) < 1 {
+			result = append(result, desc)
+			continue
+		}
+		for _, ref := range desc.Host {
+			if ref.Value == host.Value {
+				result = append(result, desc)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
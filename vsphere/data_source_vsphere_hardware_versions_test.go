@@ -0,0 +1,132 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereHardwareVersions(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereHardwareVersionsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+						tp.Skip("set VSPHERE_ESXI_HOST to run vsphere_hardware_versions acceptance tests")
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHardwareVersionsConfigHost(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrSet("data.vsphere_hardware_versions.versions", "max_version"),
+							resource.TestCheckResourceAttrSet("data.vsphere_hardware_versions.versions", "default_version"),
+							resource.TestCheckResourceAttrSet("data.vsphere_hardware_versions.versions", "supported_versions.#"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"cluster",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+						tp.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_hardware_versions acceptance tests")
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHardwareVersionsConfigCluster(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrSet("data.vsphere_hardware_versions.versions", "max_version"),
+							resource.TestCheckResourceAttrSet("data.vsphere_hardware_versions.versions", "versions.0.key"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereHardwareVersionsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func TestFilterConfigOptionDescriptors(t *testing.T) {
+	host1 := types.ManagedObjectReference{Type: "HostSystem", Value: "host-1"}
+	host2 := types.ManagedObjectReference{Type: "HostSystem", Value: "host-2"}
+	descs := []types.VirtualMachineConfigOptionDescriptor{
+		{Key: "vmx-13", Host: []types.ManagedObjectReference{host1}},
+		{Key: "vmx-10", Host: []types.ManagedObjectReference{host1, host2}},
+		{Key: "vmx-11"},
+	}
+
+	keys := func(descs []types.VirtualMachineConfigOptionDescriptor) []string {
+		var result []string
+		for _, desc := range descs {
+			result = append(result, desc.Key)
+		}
+		return result
+	}
+
+	cases := []struct {
+		name     string
+		host     *types.ManagedObjectReference
+		expected []string
+	}{
+		{"all", nil, []string{"vmx-10", "vmx-11", "vmx-13"}},
+		{"host-1", &host1, []string{"vmx-10", "vmx-11", "vmx-13"}},
+		{"host-2", &host2, []string{"vmx-10", "vmx-11"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := keys(filterConfigOptionDescriptors(descs, tc.host))
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func testAccDataSourceVSphereHardwareVersionsConfigHost() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_hardware_versions" "versions" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccDataSourceVSphereHardwareVersionsConfigCluster() string {
+	return fmt.Sprintf(`
+data "vsphere_hardware_versions" "versions" {
+  compute_cluster_id = "%s"
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"))
+}
@@ -0,0 +1,157 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereHostDatastores() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostDatastoresRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to list datastores for. Conflicts with compute_cluster_id.",
+				Optional:      true,
+				ConflictsWith: []string{"compute_cluster_id"},
+			},
+			"compute_cluster_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the cluster to list datastores for. Conflicts with host_system_id.",
+				Optional:      true,
+				ConflictsWith: []string{"host_system_id"},
+			},
+			"datastores": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The datastores that are mounted on the host or cluster, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the datastore.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the datastore.",
+							Computed:    true,
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Description: "The type of the datastore, ie: VMFS, NFS, NFS41, vsan, or VVOL.",
+							Computed:    true,
+						},
+						"accessible": {
+							Type:        schema.TypeBool,
+							Description: "The connectivity status of the datastore. If this is false, the capacity and free space of the datastore may be out of date.",
+							Computed:    true,
+						},
+						"capacity": {
+							Type:        schema.TypeInt,
+							Description: "Maximum capacity of the datastore, in MB.",
+							Computed:    true,
+						},
+						"free_space": {
+							Type:        schema.TypeInt,
+							Description: "Available space of the datastore, in MB.",
+							Computed:    true,
+						},
+						"maintenance_mode": {
+							Type:        schema.TypeString,
+							Description: "The current maintenance mode state of the datastore.",
+							Computed:    true,
+						},
+						"multiple_host_access": {
+							Type:        schema.TypeBool,
+							Description: "If true, more than one host in the datacenter has been configured with access to the datastore.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostDatastoresRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id, refs, err := hostDatastoresReferences(client, d)
+	if err != nil {
+		return err
+	}
+
+	dss, err := datastoreSummaries(client, refs)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore summaries: %s", err)
+	}
+
+	d.SetId(id)
+	if err := d.Set("datastores", flattenHostDatastores(dss)); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
+
+// hostDatastoresReferences returns the datastores that are mounted on the
+// host or cluster given in the vsphere_host_datastores data source, along with
+// the ID of the host or cluster.
+func hostDatastoresReferences(client *govmomi.Client, d *schema.ResourceData) (string, []types.ManagedObjectReference, error) {
+	if id, ok := d.GetOk("host_system_id"); ok {
+		hs, err := hostSystemFromID(client, id.(string))
+		if err != nil {
+			return "", nil, fmt.Errorf("error loading host: %s", err)
+		}
+		props, err := hostSystemProperties(hs)
+		if err != nil {
+			return "", nil, fmt.Errorf("error fetching host properties: %s", err)
+		}
+		return id.(string), props.Datastore, nil
+	}
+	if id, ok := d.GetOk("compute_cluster_id"); ok {
+		cluster, err := clusterComputeResourceFromID(client, id.(string))
+		if err != nil {
+			return "", nil, err
+		}
+		props, err := clusterComputeResourceProperties(cluster)
+		if err != nil {
+			return "", nil, fmt.Errorf("error fetching cluster properties: %s", err)
+		}
+		return id.(string), props.Datastore, nil
+	}
+	return "", nil, errors.New("one of host_system_id or compute_cluster_id must be specified")
+}
+
+// flattenHostDatastores converts a list of datastores into the format used by
+// the datastores attribute of the vsphere_host_datastores data source, sorted
+// by name.
+func flattenHostDatastores(dss []mo.Datastore) []interface{} {
+	sort.Slice(dss, func(i, j int) bool { return dss[i].Summary.Name < dss[j].Summary.Name })
+	var result []interface{}
+	for _, ds := range dss {
+		s := ds.Summary
+		multipleHostAccess := false
+		if s.MultipleHostAccess != nil {
+			multipleHostAccess = *s.MultipleHostAccess
+		}
+		result = append(result, map[string]interface{}{
+			"id":                   ds.Reference().Value,
+			"name":                 s.Name,
+			"type":                 s.Type,
+			"accessible":           s.Accessible,
+			"capacity":             byteToMB(s.Capacity),
+			"free_space":           byteToMB(s.FreeSpace),
+			"maintenance_mode":     s.MaintenanceMode,
+			"multiple_host_access": multipleHostAccess,
+		})
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereHostDatastores(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereHostDatastoresCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereHostDatastoresPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHostDatastoresConfigHost(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+							resource.TestMatchResourceAttr("data.vsphere_host_datastores.datastores", "datastores.0.free_space", regexp.MustCompile("^[0-9]+$")),
+						),
+					},
+				},
+			},
+		},
+		{
+			"cluster",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccDataSourceVSphereHostDatastoresPreCheck(tp)
+					if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+						tp.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_host_datastores acceptance tests")
+					}
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHostDatastoresConfigCluster(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereHostDatastoresCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereHostDatastoresPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_datastores acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_host_datastores acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereHostDatastoresConfigHost() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host_datastores" "datastores" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_host_datastores.datastores.datastores.*.name, "%s")}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), os.Getenv("VSPHERE_DATASTORE"))
+}
+
+func testAccDataSourceVSphereHostDatastoresConfigCluster() string {
+	return fmt.Sprintf(`
+data "vsphere_host_datastores" "datastores" {
+  compute_cluster_id = "%s"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_host_datastores.datastores.datastores.*.name, "%s")}"
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"), os.Getenv("VSPHERE_DATASTORE"))
+}
@@ -0,0 +1,149 @@
+package vsphere
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereHostPhysicalNics() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostPhysicalNicsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to list physical NICs for.",
+				Required:    true,
+			},
+			"filter": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "A regular expression to filter the physical NICs against. Only NICs with device names that match will be included.",
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"physical_nics": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The physical NICs discovered on the host, sorted by device name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"device": {
+							Type:        schema.TypeString,
+							Description: "The device name of the NIC, ie: vmnic0.",
+							Computed:    true,
+						},
+						"mac": {
+							Type:        schema.TypeString,
+							Description: "The MAC address of the NIC.",
+							Computed:    true,
+						},
+						"driver": {
+							Type:        schema.TypeString,
+							Description: "The name of the driver for the NIC.",
+							Computed:    true,
+						},
+						"link_up": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not the NIC has a link.",
+							Computed:    true,
+						},
+						"link_speed": {
+							Type:        schema.TypeInt,
+							Description: "The current link speed of the NIC in megabits per second. 0 if the link is down.",
+							Computed:    true,
+						},
+						"full_duplex": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not the link is running in full duplex mode.",
+							Computed:    true,
+						},
+						"virtual_switch": {
+							Type:        schema.TypeString,
+							Description: "The name of the standard virtual switch that the NIC is bound to, if any.",
+							Computed:    true,
+						},
+						"distributed_virtual_switch": {
+							Type:        schema.TypeString,
+							Description: "The UUID of the distributed virtual switch that the NIC is bound to, if any.",
+							Computed:    true,
+						},
+						"in_use": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not the NIC is bound to a standard or distributed virtual switch.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostPhysicalNicsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	info, err := hostNetworkInfo(client, ns)
+	if err != nil {
+		return err
+	}
+
+	// Map the NICs to the switches they are bound to. Both types of switches
+	// refer to their NICs by key.
+	vswitches := make(map[string]string)
+	for _, sw := range info.Vswitch {
+		for _, key := range sw.Pnic {
+			vswitches[key] = sw.Name
+		}
+	}
+	proxySwitches := make(map[string]string)
+	for _, sw := range info.ProxySwitch {
+		for _, key := range sw.Pnic {
+			proxySwitches[key] = sw.DvsUuid
+		}
+	}
+
+	var pnics []types.PhysicalNic
+	for _, pnic := range info.Pnic {
+		if matched, _ := regexp.MatchString(d.Get("filter").(string), pnic.Device); matched {
+			pnics = append(pnics, pnic)
+		}
+	}
+	sort.Slice(pnics, func(i, j int) bool { return pnics[i].Device < pnics[j].Device })
+
+	var result []interface{}
+	for _, pnic := range pnics {
+		m := map[string]interface{}{
+			"device":                     pnic.Device,
+			"mac":                        pnic.Mac,
+			"driver":                     pnic.Driver,
+			"link_up":                    pnic.LinkSpeed != nil,
+			"link_speed":                 0,
+			"full_duplex":                false,
+			"virtual_switch":             vswitches[pnic.Key],
+			"distributed_virtual_switch": proxySwitches[pnic.Key],
+			"in_use":                     vswitches[pnic.Key] != "" || proxySwitches[pnic.Key] != "",
+		}
+		if pnic.LinkSpeed != nil {
+			m["link_speed"] = int(pnic.LinkSpeed.SpeedMb)
+			m["full_duplex"] = pnic.LinkSpeed.Duplex
+		}
+		result = append(result, m)
+	}
+
+	d.SetId(hsID)
+	if err := d.Set("physical_nics", result); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	return nil
+}
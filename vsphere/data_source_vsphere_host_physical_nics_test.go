@@ -0,0 +1,127 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereHostPhysicalNics(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereHostPhysicalNicsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereHostPhysicalNicsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHostPhysicalNicsConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckOutput("found", "true"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"with filter and virtual switch membership",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereHostPhysicalNicsPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereHostPhysicalNicsConfigFilter(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_host_physical_nics.nics", "physical_nics.#", "1"),
+							resource.TestCheckResourceAttr("data.vsphere_host_physical_nics.nics", "physical_nics.0.device", os.Getenv("VSPHERE_HOST_NIC0")),
+							resource.TestCheckResourceAttr("data.vsphere_host_physical_nics.nics", "physical_nics.0.virtual_switch", "vSwitchTerraformTest"),
+							resource.TestCheckResourceAttr("data.vsphere_host_physical_nics.nics", "physical_nics.0.in_use", "true"),
+							resource.TestMatchResourceAttr("data.vsphere_host_physical_nics.nics", "physical_nics.0.mac", regexp.MustCompile("^([0-9a-f]{2}:){5}[0-9a-f]{2}$")),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereHostPhysicalNicsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccDataSourceVSphereHostPhysicalNicsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_physical_nics acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_host_physical_nics acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereHostPhysicalNicsConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host_physical_nics" "nics" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_host_physical_nics.nics.physical_nics.*.device, "%s")}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), os.Getenv("VSPHERE_HOST_NIC0"))
+}
+
+func testAccDataSourceVSphereHostPhysicalNicsConfigFilter() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}"]
+  active_nics      = ["${var.host_nic0}"]
+  standby_nics     = []
+}
+
+data "vsphere_host_physical_nics" "nics" {
+  host_system_id = "${vsphere_host_virtual_switch.switch.host_system_id}"
+  filter         = "^${var.host_nic0}$"
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
@@ -0,0 +1,59 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceVSphereHostProfile() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostProfileRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the host profile.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the host profile.",
+				Computed:    true,
+			},
+			"reference_host_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the reference host for the host profile.",
+				Computed:    true,
+			},
+			"profile_config": {
+				Type:        schema.TypeString,
+				Description: "The serialized configuration of the host profile.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostProfileRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	props, err := hostProfileFromName(client, d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching host profile: %s", err)
+	}
+	config, err := exportHostProfile(client, props.Self.Value)
+	if err != nil {
+		return fmt.Errorf("error exporting host profile: %s", err)
+	}
+
+	d.SetId(props.Self.Value)
+	if err := flattenHostProfile(d, props); err != nil {
+		return err
+	}
+	d.Set("profile_config", config)
+	return nil
+}
@@ -0,0 +1,62 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereHostProfile(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostProfilePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereHostProfileConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_host_profile.profile", "id",
+						"vsphere_host_profile.profile", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_host_profile.profile", "reference_host_id",
+						"data.vsphere_host.esxi_host", "id",
+					),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_host_profile.profile",
+						"profile_config",
+						regexp.MustCompile(".+"),
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereHostProfileConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_profile" "profile" {
+  name              = "terraform-test-profile"
+  reference_host_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+data "vsphere_host_profile" "profile" {
+  name = "${vsphere_host_profile.profile.name}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
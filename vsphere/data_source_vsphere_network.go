@@ -1,10 +1,12 @@
 package vsphere
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func dataSourceVSphereNetwork() *schema.Resource {
@@ -27,6 +29,21 @@ func dataSourceVSphereNetwork() *schema.Resource {
 				Description: "The managed object type of the network.",
 				Computed:    true,
 			},
+			"backing_id": {
+				Type:        schema.TypeString,
+				Description: "The identifier that network interfaces use to refer to the network in their backing: the port group key for DVS port groups, the opaque network ID for opaque networks, or the name for standard port groups.",
+				Computed:    true,
+			},
+			"distributed_virtual_switch_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the distributed virtual switch of the network. Only set for DVS port groups.",
+				Computed:    true,
+			},
+			"opaque_network_type": {
+				Type:        schema.TypeString,
+				Description: "The type of the opaque network, ie: nsx.LogicalSwitch. Only set for opaque networks.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -50,8 +67,41 @@ func dataSourceVSphereNetworkRead(d *schema.ResourceData, meta interface{}) erro
 	if err != nil {
 		return fmt.Errorf("error fetching network: %s", err)
 	}
+	switch net.Reference().Type {
+	case "Network", "DistributedVirtualPortgroup", "OpaqueNetwork":
+	default:
+		return fmt.Errorf("object at path %q is not a network (type %s)", name, net.Reference().Type)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	backing, err := net.EthernetCardBackingInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching network backing: %s", err)
+	}
+	backingID, dvsUUID, opaqueType, err := flattenNetworkBacking(backing)
+	if err != nil {
+		return err
+	}
 
 	d.SetId(net.Reference().Value)
 	d.Set("type", net.Reference().Type)
+	d.Set("backing_id", backingID)
+	d.Set("distributed_virtual_switch_uuid", dvsUUID)
+	d.Set("opaque_network_type", opaqueType)
 	return nil
 }
+
+// flattenNetworkBacking returns the backing ID, DVS UUID, and opaque network
+// type that a virtual ethernet card backing refers to its network by. Only the
+// values that apply to the type of the backing are set.
+func flattenNetworkBacking(backing types.BaseVirtualDeviceBackingInfo) (string, string, string, error) {
+	switch b := backing.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		return b.DeviceName, "", "", nil
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		return b.Port.PortgroupKey, b.Port.SwitchUuid, "", nil
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkId, "", b.OpaqueNetworkType, nil
+	}
+	return "", "", "", fmt.Errorf("unsupported network backing type %T", backing)
+}
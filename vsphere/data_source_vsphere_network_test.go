@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func TestAccDataSourceVSphereNetwork(t *testing.T) {
@@ -31,6 +32,14 @@ func TestAccDataSourceVSphereNetwork(t *testing.T) {
 								"data.vsphere_network.net", "id",
 								"vsphere_distributed_port_group.pg", "id",
 							),
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_network.net", "backing_id",
+								"vsphere_distributed_port_group.pg", "key",
+							),
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_network.net", "distributed_virtual_switch_uuid",
+								"vsphere_distributed_virtual_switch.dvs", "id",
+							),
 						),
 					},
 				},
@@ -71,6 +80,7 @@ func TestAccDataSourceVSphereNetwork(t *testing.T) {
 						Config: testAccDataSourceVSphereNetworkConfigHostPortgroup(),
 						Check: resource.ComposeTestCheckFunc(
 							resource.TestCheckResourceAttr("data.vsphere_network.net", "type", "Network"),
+							resource.TestCheckResourceAttr("data.vsphere_network.net", "backing_id", "PGTerraformTest"),
 						),
 					},
 				},
@@ -97,6 +107,26 @@ func TestAccDataSourceVSphereNetwork(t *testing.T) {
 				},
 			},
 		},
+		{
+			"opaque network",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereNetworkOpaquePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereNetworkConfigOpaque(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vsphere_network.net", "type", "OpaqueNetwork"),
+							resource.TestCheckResourceAttr("data.vsphere_network.net", "opaque_network_type", "nsx.LogicalSwitch"),
+							resource.TestCheckResourceAttrSet("data.vsphere_network.net", "backing_id"),
+						),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testAccDataSourceVSphereNetworkCases {
@@ -107,6 +137,69 @@ func TestAccDataSourceVSphereNetwork(t *testing.T) {
 	}
 }
 
+func TestFlattenNetworkBacking(t *testing.T) {
+	cases := []struct {
+		Name               string
+		Backing            types.BaseVirtualDeviceBackingInfo
+		ExpectedBackingID  string
+		ExpectedDVSUUID    string
+		ExpectedOpaqueType string
+		ExpectError        bool
+	}{
+		{
+			Name: "standard port group",
+			Backing: &types.VirtualEthernetCardNetworkBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName: "VM Network",
+				},
+			},
+			ExpectedBackingID: "VM Network",
+		},
+		{
+			Name: "DVS port group",
+			Backing: &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+				Port: types.DistributedVirtualSwitchPortConnection{
+					PortgroupKey: "dvportgroup-21",
+					SwitchUuid:   "50 10 18 f2 a3 bb 5d 5c-4e 3a 2f 0e 8d 6d 02 a1",
+				},
+			},
+			ExpectedBackingID: "dvportgroup-21",
+			ExpectedDVSUUID:   "50 10 18 f2 a3 bb 5d 5c-4e 3a 2f 0e 8d 6d 02 a1",
+		},
+		{
+			Name: "opaque network",
+			Backing: &types.VirtualEthernetCardOpaqueNetworkBackingInfo{
+				OpaqueNetworkId:   "8a7e1b1c-4c6e-4a0b-9d1f-3b4a5e6f7a8b",
+				OpaqueNetworkType: "nsx.LogicalSwitch",
+			},
+			ExpectedBackingID:  "8a7e1b1c-4c6e-4a0b-9d1f-3b4a5e6f7a8b",
+			ExpectedOpaqueType: "nsx.LogicalSwitch",
+		},
+		{
+			Name:        "unsupported",
+			Backing:     &types.VirtualEthernetCardLegacyNetworkBackingInfo{},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			backingID, dvsUUID, opaqueType, err := flattenNetworkBacking(tc.Backing)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if backingID != tc.ExpectedBackingID || dvsUUID != tc.ExpectedDVSUUID || opaqueType != tc.ExpectedOpaqueType {
+				t.Fatalf("expected %q, %q, %q, got %q, %q, %q", tc.ExpectedBackingID, tc.ExpectedDVSUUID, tc.ExpectedOpaqueType, backingID, dvsUUID, opaqueType)
+			}
+		})
+	}
+}
+
 func testAccDataSourceVSphereNetworkPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_network acceptance tests")
@@ -125,6 +218,15 @@ func testAccDataSourceVSphereNetworkPreCheck(t *testing.T) {
 	}
 }
 
+func testAccDataSourceVSphereNetworkOpaquePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_network opaque network acceptance tests")
+	}
+	if os.Getenv("VSPHERE_OPAQUE_NETWORK") == "" {
+		t.Skip("set VSPHERE_OPAQUE_NETWORK to run vsphere_network opaque network acceptance tests")
+	}
+}
+
 func testAccDataSourceVSphereNetworkConfigDVSPortgroup() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -301,3 +403,19 @@ data "vsphere_network" "net" {
 		os.Getenv("VSPHERE_HOST_NIC1"),
 	)
 }
+
+func testAccDataSourceVSphereNetworkConfigOpaque() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_network" "net" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_OPAQUE_NETWORK"),
+	)
+}
@@ -0,0 +1,236 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereVirtualMachine() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The name or path of the virtual machine. Requires datacenter_id. Conflicts with uuid, instance_uuid, and mac_address.",
+				Optional:      true,
+				ConflictsWith: []string{"uuid", "instance_uuid", "mac_address"},
+			},
+			"datacenter_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datacenter to look for the virtual machine in. Required when looking up the virtual machine by name or MAC address.",
+				Optional:    true,
+			},
+			"uuid": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The BIOS UUID of the virtual machine. This is the same as the uuid of a vsphere_virtual_machine resource. Conflicts with name, instance_uuid, and mac_address.",
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name", "instance_uuid", "mac_address"},
+			},
+			"instance_uuid": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The vCenter instance UUID of the virtual machine. Conflicts with name, uuid, and mac_address.",
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name", "uuid", "mac_address"},
+			},
+			"mac_address": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The MAC address of one of the network interfaces of the virtual machine. Requires datacenter_id. Conflicts with name, uuid, and instance_uuid.",
+				Optional:      true,
+				ConflictsWith: []string{"name", "uuid", "instance_uuid"},
+			},
+			"moid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the virtual machine.",
+				Computed:    true,
+			},
+			"host_system_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host that the virtual machine is currently running on.",
+				Computed:    true,
+			},
+			"compute_cluster_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster that the host of the virtual machine belongs to. Empty if the host is standalone.",
+				Computed:    true,
+			},
+			"resource_pool_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the resource pool that the virtual machine is in.",
+				Computed:    true,
+			},
+			"datastore_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the datastores that the virtual machine's files and disks are on.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"guest_host_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The host name of the guest operating system, as reported by VMware Tools.",
+				Computed:    true,
+			},
+			"guest_full_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The full name of the guest operating system, as reported by VMware Tools.",
+				Computed:    true,
+			},
+			"guest_disks": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The filesystems of the guest operating system, as reported by VMware Tools. Empty if VMware Tools is not running.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disk_path": {
+							Type:        schema.TypeString,
+							Description: "The mount point or drive letter of the filesystem, ie: / or C:\\.",
+							Computed:    true,
+						},
+						"capacity": {
+							Type:        schema.TypeInt,
+							Description: "The total size of the filesystem, in bytes.",
+							Computed:    true,
+						},
+						"free_space": {
+							Type:        schema.TypeInt,
+							Description: "The free space on the filesystem, in bytes.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	var vm *object.VirtualMachine
+	var err error
+	switch {
+	case d.Get("name").(string) != "":
+		dc, derr := dataSourceVSphereVirtualMachineDatacenter(d, client, "name")
+		if derr != nil {
+			return derr
+		}
+		vm, err = virtualMachineFromName(client, d.Get("name").(string), dc)
+	case d.Get("mac_address").(string) != "":
+		dc, derr := dataSourceVSphereVirtualMachineDatacenter(d, client, "MAC address")
+		if derr != nil {
+			return derr
+		}
+		vm, err = virtualMachineFromMacAddress(client, d.Get("mac_address").(string), dc)
+	case d.Get("uuid").(string) != "":
+		vm, err = virtualMachineFromUUID(client, d.Get("uuid").(string))
+	case d.Get("instance_uuid").(string) != "":
+		vm, err = virtualMachineFromInstanceUUID(client, d.Get("instance_uuid").(string))
+	default:
+		return fmt.Errorf("one of name, uuid, instance_uuid, or mac_address must be specified")
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Config == nil {
+		return fmt.Errorf("virtual machine %q has no configuration, it may be orphaned or inaccessible", vm.InventoryPath)
+	}
+
+	hsID, rpID, dsIDs := flattenVirtualMachinePlacement(props)
+	var clusterID string
+	if hsID != "" {
+		host, err := hostSystemFromID(client, hsID)
+		if err != nil {
+			return fmt.Errorf("error fetching host of virtual machine: %s", err)
+		}
+		hprops, err := hostSystemProperties(host)
+		if err != nil {
+			return fmt.Errorf("error fetching host properties: %s", err)
+		}
+		if hprops.Parent != nil && hprops.Parent.Type == "ClusterComputeResource" {
+			clusterID = hprops.Parent.Value
+		}
+	}
+
+	d.SetId(props.Config.Uuid)
+	d.Set("uuid", props.Config.Uuid)
+	d.Set("instance_uuid", props.Config.InstanceUuid)
+	d.Set("moid", vm.Reference().Value)
+	d.Set("host_system_id", hsID)
+	d.Set("compute_cluster_id", clusterID)
+	d.Set("resource_pool_id", rpID)
+	if err := d.Set("datastore_ids", dsIDs); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+
+	hostName, fullName, disks := flattenVirtualMachineGuestInfo(props.Guest)
+	d.Set("guest_host_name", hostName)
+	d.Set("guest_full_name", fullName)
+	if err := d.Set("guest_disks", disks); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	return nil
+}
+
+// dataSourceVSphereVirtualMachineDatacenter returns the datacenter in
+// datacenter_id, which is required for lookups by the attribute described in
+// by.
+func dataSourceVSphereVirtualMachineDatacenter(d *schema.ResourceData, client *govmomi.Client, by string) (*object.Datacenter, error) {
+	dcID := d.Get("datacenter_id").(string)
+	if dcID == "" {
+		return nil, fmt.Errorf("datacenter_id is required when looking up a virtual machine by %s", by)
+	}
+	dc, err := datacenterFromID(client, dcID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching datacenter: %s", err)
+	}
+	return dc, nil
+}
+
+// flattenVirtualMachinePlacement returns the managed object IDs of the host,
+// resource pool, and datastores that a virtual machine is currently placed on,
+// as reported by its runtime information. Values that are not set, such as the
+// resource pool of a template, are returned empty.
+func flattenVirtualMachinePlacement(props *mo.VirtualMachine) (string, string, []string) {
+	var hsID, rpID string
+	if props.Runtime.Host != nil {
+		hsID = props.Runtime.Host.Value
+	}
+	if props.ResourcePool != nil {
+		rpID = props.ResourcePool.Value
+	}
+	var dsIDs []string
+	for _, ds := range props.Datastore {
+		dsIDs = append(dsIDs, ds.Value)
+	}
+	return hsID, rpID, dsIDs
+}
+
+// flattenVirtualMachineGuestInfo returns the host name, full operating system
+// name, and filesystems that VMware Tools reports for a guest, in the format
+// used by the guest attributes of the vsphere_virtual_machine data source. The
+// guest information is only trusted while VMware Tools is running. Otherwise,
+// everything is returned empty, as the last reported values may be stale.
+func flattenVirtualMachineGuestInfo(guest *types.GuestInfo) (string, string, []interface{}) {
+	if guest == nil || guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return "", "", nil
+	}
+	var disks []interface{}
+	for _, disk := range guest.Disk {
+		disks = append(disks, map[string]interface{}{
+			"disk_path":  disk.DiskPath,
+			"capacity":   int(disk.Capacity),
+			"free_space": int(disk.FreeSpace),
+		})
+	}
+	return guest.HostName, guest.GuestFullName, disks
+}
@@ -0,0 +1,256 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereVirtualMachine(t *testing.T) {
+	var tp *testing.T
+	testAccDataSourceVSphereVirtualMachineCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"by name",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereVirtualMachineConfigName(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestMatchResourceAttr(
+								"data.vsphere_virtual_machine.vm",
+								"moid",
+								regexp.MustCompile("^vm-"),
+							),
+							resource.TestMatchResourceAttr(
+								"data.vsphere_virtual_machine.vm",
+								"host_system_id",
+								regexp.MustCompile("^host-"),
+							),
+							resource.TestMatchResourceAttr(
+								"data.vsphere_virtual_machine.vm",
+								"datastore_ids.0",
+								regexp.MustCompile("^datastore-"),
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"by uuid",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereVirtualMachineConfigUUID(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_virtual_machine.vm",
+								"moid",
+								"data.vsphere_virtual_machine.by_name",
+								"moid",
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"by instance uuid",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccDataSourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccDataSourceVSphereVirtualMachineConfigInstanceUUID(),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_virtual_machine.vm",
+								"moid",
+								"data.vsphere_virtual_machine.by_name",
+								"moid",
+							),
+							resource.TestCheckResourceAttrPair(
+								"data.vsphere_virtual_machine.vm",
+								"uuid",
+								"data.vsphere_virtual_machine.by_name",
+								"uuid",
+							),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccDataSourceVSphereVirtualMachineCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func TestFlattenVirtualMachinePlacement(t *testing.T) {
+	props := &mo.VirtualMachine{
+		Runtime: types.VirtualMachineRuntimeInfo{
+			Host: &types.ManagedObjectReference{Type: "HostSystem", Value: "host-10"},
+		},
+		ResourcePool: &types.ManagedObjectReference{Type: "ResourcePool", Value: "resgroup-20"},
+		Datastore: []types.ManagedObjectReference{
+			{Type: "Datastore", Value: "datastore-30"},
+			{Type: "Datastore", Value: "datastore-31"},
+		},
+	}
+	hsID, rpID, dsIDs := flattenVirtualMachinePlacement(props)
+	if hsID != "host-10" {
+		t.Fatalf("expected host_system_id to be %q, got %q", "host-10", hsID)
+	}
+	if rpID != "resgroup-20" {
+		t.Fatalf("expected resource_pool_id to be %q, got %q", "resgroup-20", rpID)
+	}
+	expected := []string{"datastore-30", "datastore-31"}
+	if !reflect.DeepEqual(expected, dsIDs) {
+		t.Fatalf("expected datastore_ids to be %#v, got %#v", expected, dsIDs)
+	}
+}
+
+func TestFlattenVirtualMachinePlacementTemplate(t *testing.T) {
+	hsID, rpID, dsIDs := flattenVirtualMachinePlacement(&mo.VirtualMachine{})
+	if hsID != "" || rpID != "" || dsIDs != nil {
+		t.Fatalf("expected empty placement, got %q, %q, %#v", hsID, rpID, dsIDs)
+	}
+}
+
+func TestFlattenVirtualMachineGuestInfo(t *testing.T) {
+	cases := []struct {
+		name          string
+		guest         *types.GuestInfo
+		expectedHost  string
+		expectedName  string
+		expectedDisks []interface{}
+	}{
+		{
+			name:  "no guest info",
+			guest: nil,
+		},
+		{
+			name: "tools not running",
+			guest: &types.GuestInfo{
+				ToolsRunningStatus: string(types.VirtualMachineToolsRunningStatusGuestToolsNotRunning),
+				HostName:           "stale",
+				Disk:               []types.GuestDiskInfo{{DiskPath: "/", Capacity: 100, FreeSpace: 50}},
+			},
+		},
+		{
+			name: "tools running",
+			guest: &types.GuestInfo{
+				ToolsRunningStatus: string(types.VirtualMachineToolsRunningStatusGuestToolsRunning),
+				HostName:           "vm1",
+				GuestFullName:      "CentOS 7 (64-bit)",
+				Disk: []types.GuestDiskInfo{
+					{DiskPath: "/", Capacity: 10737418240, FreeSpace: 5368709120},
+					{DiskPath: "/boot", Capacity: 1073741824, FreeSpace: 805306368},
+				},
+			},
+			expectedHost: "vm1",
+			expectedName: "CentOS 7 (64-bit)",
+			expectedDisks: []interface{}{
+				map[string]interface{}{"disk_path": "/", "capacity": 10737418240, "free_space": 5368709120},
+				map[string]interface{}{"disk_path": "/boot", "capacity": 1073741824, "free_space": 805306368},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, name, disks := flattenVirtualMachineGuestInfo(tc.guest)
+			if host != tc.expectedHost {
+				t.Fatalf("expected guest_host_name to be %q, got %q", tc.expectedHost, host)
+			}
+			if name != tc.expectedName {
+				t.Fatalf("expected guest_full_name to be %q, got %q", tc.expectedName, name)
+			}
+			if !reflect.DeepEqual(tc.expectedDisks, disks) {
+				t.Fatalf("expected guest_disks to be %#v, got %#v", tc.expectedDisks, disks)
+			}
+		})
+	}
+}
+
+func testAccDataSourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine data source acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine data source acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereVirtualMachineConfigName() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_virtual_machine" "vm" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_TEMPLATE"))
+}
+
+func testAccDataSourceVSphereVirtualMachineConfigUUID() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_virtual_machine" "by_name" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "vm" {
+  uuid = "${data.vsphere_virtual_machine.by_name.uuid}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_TEMPLATE"))
+}
+
+func testAccDataSourceVSphereVirtualMachineConfigInstanceUUID() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_virtual_machine" "by_name" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "vm" {
+  instance_uuid = "${data.vsphere_virtual_machine.by_name.instance_uuid}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_TEMPLATE"))
+}
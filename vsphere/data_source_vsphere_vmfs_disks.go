@@ -3,6 +3,7 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"log"
 	"regexp"
 	"sort"
 	"time"
@@ -52,7 +53,9 @@ func dataSourceVSphereVmfsDisksRead(d *schema.ResourceData, meta interface{}) er
 		return fmt.Errorf("error loading host storage system: %s", err)
 	}
 
-	if d.Get("rescan").(bool) {
+	if d.Get("rescan").(bool) && meta.(*VSphereClient).readOnlyRefresh {
+		log.Printf("[WARN] Not rescanning storage adapters on host %q, as read_only_refresh is set", hsID)
+	} else if d.Get("rescan").(bool) {
 		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 		defer cancel()
 		if err := ss.RescanAllHba(ctx); err != nil {
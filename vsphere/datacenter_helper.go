@@ -2,10 +2,12 @@ package vsphere
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	"golang.org/x/net/context"
 )
@@ -43,7 +45,148 @@ func datacenterFromID(client *govmomi.Client, id string) (*object.Datacenter, er
 	defer cancel()
 	ds, err := finder.ObjectReference(ctx, ref)
 	if err != nil {
-		return nil, fmt.Errorf("could not find datacenter with id: %s: %s", id, err)
+		return nil, fmt.Errorf("could not find datacenter with id: %s on %s: %s", id, serverDescription(client), err)
 	}
 	return ds.(*object.Datacenter), nil
 }
+
+// datacenterProperties is a convenience method that wraps fetching the
+// Datacenter MO from its higher-level object.
+func datacenterProperties(dc *object.Datacenter) (*mo.Datacenter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.Datacenter
+	if err := dc.Properties(ctx, dc.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// datacenterRootFolder returns the root folder of the supplied folder type for
+// a datacenter. This is looked up through the datacenter's folder managed
+// objects directly, so it does not depend on the inventory path of the
+// datacenter being populated.
+func datacenterRootFolder(dc *object.Datacenter, ft vSphereFolderType) (*object.Folder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	folders, err := dc.Folders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch ft {
+	case vSphereFolderTypeVM:
+		return folders.VmFolder, nil
+	case vSphereFolderTypeHost:
+		return folders.HostFolder, nil
+	case vSphereFolderTypeDatastore:
+		return folders.DatastoreFolder, nil
+	case vSphereFolderTypeNetwork:
+		return folders.NetworkFolder, nil
+	}
+	return nil, fmt.Errorf("unsupported datacenter folder type %q", ft)
+}
+
+// datacenterChildFolder looks for a folder named name that is a direct child
+// of parent. nil is returned, with no error, if no such folder exists.
+func datacenterChildFolder(parent *object.Folder, name string) (*object.Folder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	children, err := parent.Children(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		f, ok := child.(*object.Folder)
+		if !ok {
+			continue
+		}
+		props, err := folderProperties(f)
+		if err != nil {
+			return nil, err
+		}
+		if props.Name == name {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// datacenterFolderFromPath walks the relative folder path p from the root
+// folder of type ft in the datacenter dc. nil is returned, with no error, if
+// any part of the path does not exist.
+func datacenterFolderFromPath(dc *object.Datacenter, ft vSphereFolderType, p string) (*object.Folder, error) {
+	f, err := datacenterRootFolder(dc, ft)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(normalizeFolderPath(p), "/") {
+		if f, err = datacenterChildFolder(f, name); err != nil || f == nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// createDatacenterFolderPath creates the relative folder path p under the root
+// folder of type ft in the datacenter dc. Any parent folders that are missing
+// are created as well, and folders that already exist are left alone.
+func createDatacenterFolderPath(dc *object.Datacenter, ft vSphereFolderType, p string) error {
+	f, err := datacenterRootFolder(dc, ft)
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(normalizeFolderPath(p), "/") {
+		child, err := datacenterChildFolder(f, name)
+		if err != nil {
+			return err
+		}
+		if child == nil {
+			if child, err = createDatacenterChildFolder(f, name); err != nil {
+				return fmt.Errorf("error creating folder %q: %s", name, err)
+			}
+		}
+		f = child
+	}
+	return nil
+}
+
+// createDatacenterChildFolder creates a folder named name directly under the
+// folder f.
+func createDatacenterChildFolder(f *object.Folder, name string) (*object.Folder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return f.CreateFolder(ctx, name)
+}
+
+// deleteDatacenterFolderPath removes the folder at the relative path p under
+// the root folder of type ft in the datacenter dc, but only if the folder is
+// empty. The return value indicates whether or not the folder was removed.  A
+// folder that does not exist is treated as already removed.
+func deleteDatacenterFolderPath(dc *object.Datacenter, ft vSphereFolderType, p string) (bool, error) {
+	f, err := datacenterFolderFromPath(dc, ft, p)
+	if err != nil {
+		return false, err
+	}
+	if f == nil {
+		return true, nil
+	}
+	ne, err := folderHasChildren(f)
+	if err != nil {
+		return false, fmt.Errorf("error checking for folder contents: %s", err)
+	}
+	if ne {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := f.Destroy(ctx)
+	if err != nil {
+		return false, fmt.Errorf("cannot delete folder: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return false, fmt.Errorf("error on waiting for deletion task completion: %s", err)
+	}
+	return true, nil
+}
@@ -2,6 +2,7 @@ package vsphere
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -43,6 +44,88 @@ func datastoreProperties(ds *object.Datastore) (*mo.Datastore, error) {
 	return &props, nil
 }
 
+// datastoreSummaries fetches the summary of each of the supplied datastores.
+// The summaries are retrieved a page at a time, so that the size of each
+// response stays bounded on large inventories.
+func datastoreSummaries(client *govmomi.Client, refs []types.ManagedObjectReference) ([]mo.Datastore, error) {
+	var dss []mo.Datastore
+	err := retrievePropertiesPaged(client, refs, []string{"summary"}, propertyCollectorPageSize, func(page []types.ObjectContent) error {
+		return mo.LoadRetrievePropertiesResponse(&types.RetrievePropertiesResponse{Returnval: page}, &dss)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dss, nil
+}
+
+// storagePodDatastores returns the datastores that are members of the
+// datastore cluster with the supplied managed object ID.
+func storagePodDatastores(client *govmomi.Client, id string) ([]types.ManagedObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	ref := types.ManagedObjectReference{Type: "StoragePod", Value: id}
+	var props mo.StoragePod
+	if err := client.PropertyCollector().RetrieveOne(ctx, ref, []string{"childEntity"}, &props); err != nil {
+		return nil, err
+	}
+	var refs []types.ManagedObjectReference
+	for _, child := range props.ChildEntity {
+		if child.Type == "Datastore" {
+			refs = append(refs, child)
+		}
+	}
+	return refs, nil
+}
+
+// leastUsedDatastore returns the datastore out of the supplied datastores
+// that has the most free space. Datastores that are not accessible or that are
+// not in normal maintenance mode are skipped.
+func leastUsedDatastore(client *govmomi.Client, refs []types.ManagedObjectReference) (*object.Datastore, error) {
+	dss, err := datastoreSummaries(client, refs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching datastore summaries: %s", err)
+	}
+	ds := leastUsedDatastoreSummary(dss)
+	if ds == nil {
+		return nil, fmt.Errorf("none of the %d candidate datastores are available for placement", len(refs))
+	}
+	return datastoreFromID(client, ds.Reference().Value)
+}
+
+// leastUsedDatastoreSummary returns the available datastore with the most
+// free space out of dss, or nil if none of the datastores are available. Ties
+// are broken by the order of dss, so the result is stable for the same input.
+func leastUsedDatastoreSummary(dss []mo.Datastore) *mo.Datastore {
+	var result *mo.Datastore
+	for i := range dss {
+		s := dss[i].Summary
+		if !s.Accessible {
+			continue
+		}
+		if s.MaintenanceMode != "" && s.MaintenanceMode != string(types.DatastoreSummaryMaintenanceModeStateNormal) {
+			continue
+		}
+		if result == nil || s.FreeSpace > result.Summary.FreeSpace {
+			result = &dss[i]
+		}
+	}
+	return result
+}
+
+// datastoreMountedHostSystemIDs returns the managed object IDs of the hosts
+// that a datastore is mounted on. Hosts that can see the datastore, but have
+// had it unmounted, are skipped.
+func datastoreMountedHostSystemIDs(props *mo.Datastore) []string {
+	var ids []string
+	for _, mount := range props.Host {
+		if mount.MountInfo.Mounted != nil && !*mount.MountInfo.Mounted {
+			continue
+		}
+		ids = append(ids, mount.Key.Value)
+	}
+	return ids
+}
+
 // moveDatastoreToFolder is a complex method that moves a datastore to a given
 // relative datastore folder path. "Relative" here means relative to a
 // datacenter, which is discovered from the current datastore path.
@@ -69,3 +152,37 @@ func moveDatastoreToFolderRelativeHostSystemID(client *govmomi.Client, ds *objec
 	}
 	return moveObjectToFolder(ds.Reference(), folder)
 }
+
+// searchDatastore searches a path on a datastore with the supplied search
+// spec, using the datastore's HostDatastoreBrowser. If recursive is set, all
+// sub-folders of the path are searched as well.
+func searchDatastore(ds *object.Datastore, path string, spec *types.HostDatastoreBrowserSearchSpec, recursive bool) ([]types.HostDatastoreBrowserSearchResults, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	b, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading datastore browser: %s", err)
+	}
+
+	var task *object.Task
+	if recursive {
+		task, err = b.SearchDatastoreSubFolders(ctx, ds.Path(path), spec)
+	} else {
+		task, err = b.SearchDatastore(ctx, ds.Path(path), spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res := info.Result.(type) {
+	case types.HostDatastoreBrowserSearchResults:
+		return []types.HostDatastoreBrowserSearchResults{res}, nil
+	case types.ArrayOfHostDatastoreBrowserSearchResults:
+		return res.HostDatastoreBrowserSearchResults, nil
+	}
+	return nil, fmt.Errorf("unexpected datastore search result type %T", info.Result)
+}
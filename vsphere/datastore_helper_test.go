@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testDatastoreSummary(id string, free int64, accessible bool, maintenanceMode string) mo.Datastore {
+	return mo.Datastore{
+		ManagedEntity: mo.ManagedEntity{
+			ExtensibleManagedObject: mo.ExtensibleManagedObject{
+				Self: types.ManagedObjectReference{Type: "Datastore", Value: id},
+			},
+		},
+		Summary: types.DatastoreSummary{
+			Name:            id,
+			FreeSpace:       free,
+			Accessible:      accessible,
+			MaintenanceMode: maintenanceMode,
+		},
+	}
+}
+
+type testLeastUsedDatastoreSummary struct {
+	Name string
+
+	datastores []mo.Datastore
+	expected   string
+}
+
+func (tc *testLeastUsedDatastoreSummary) Test(t *testing.T) {
+	actual := leastUsedDatastoreSummary(tc.datastores)
+	switch {
+	case tc.expected == "" && actual != nil:
+		t.Fatalf("expected no datastore, got %s", actual.Reference().Value)
+	case tc.expected != "" && actual == nil:
+		t.Fatalf("expected %s, got no datastore", tc.expected)
+	case actual != nil && actual.Reference().Value != tc.expected:
+		t.Fatalf("expected %s, got %s", tc.expected, actual.Reference().Value)
+	}
+}
+
+func TestLeastUsedDatastoreSummary(t *testing.T) {
+	cases := []testLeastUsedDatastoreSummary{
+		{
+			Name: "most free space",
+			datastores: []mo.Datastore{
+				testDatastoreSummary("datastore-1", 100, true, "normal"),
+				testDatastoreSummary("datastore-2", 300, true, "normal"),
+				testDatastoreSummary("datastore-3", 200, true, ""),
+			},
+			expected: "datastore-2",
+		},
+		{
+			Name: "ties go to the first candidate",
+			datastores: []mo.Datastore{
+				testDatastoreSummary("datastore-1", 300, true, "normal"),
+				testDatastoreSummary("datastore-2", 300, true, "normal"),
+			},
+			expected: "datastore-1",
+		},
+		{
+			Name: "unavailable datastores skipped",
+			datastores: []mo.Datastore{
+				testDatastoreSummary("datastore-1", 500, false, "normal"),
+				testDatastoreSummary("datastore-2", 400, true, "inMaintenance"),
+				testDatastoreSummary("datastore-3", 100, true, "normal"),
+			},
+			expected: "datastore-3",
+		},
+		{
+			Name: "no available datastores",
+			datastores: []mo.Datastore{
+				testDatastoreSummary("datastore-1", 500, false, "normal"),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestDatastoreMountedHostSystemIDs(t *testing.T) {
+	props := &mo.Datastore{
+		Host: []types.DatastoreHostMount{
+			{
+				Key:       types.ManagedObjectReference{Type: "HostSystem", Value: "host-1"},
+				MountInfo: types.HostMountInfo{Mounted: boolPtr(true)},
+			},
+			{
+				Key:       types.ManagedObjectReference{Type: "HostSystem", Value: "host-2"},
+				MountInfo: types.HostMountInfo{Mounted: boolPtr(false)},
+			},
+			{
+				Key: types.ManagedObjectReference{Type: "HostSystem", Value: "host-3"},
+			},
+		},
+	}
+	expected := []string{"host-1", "host-3"}
+	actual := datastoreMountedHostSystemIDs(props)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
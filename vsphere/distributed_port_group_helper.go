@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -100,3 +101,52 @@ func createDVPortgroup(client *govmomi.Client, dvs *object.VmwareDistributedVirt
 
 	return object.NewTask(client.Client, resp.Returnval.Reference()), nil
 }
+
+// dvPortgroupPortsInUse returns the number of ports in the portgroup with the
+// key pgKey that are connected to a virtual machine or host network adapter.
+// For early binding portgroups, this includes the ports of network adapters
+// that are currently disconnected, as their ports stay allocated to them.
+func dvPortgroupPortsInUse(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, pgKey string) (int, error) {
+	req := &types.FetchDVPorts{
+		This: dvs.Reference(),
+		Criteria: &types.DistributedVirtualSwitchPortCriteria{
+			Connected:    boolPtr(true),
+			Inside:       boolPtr(true),
+			PortgroupKey: []string{pgKey},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.FetchDVPorts(ctx, client, req)
+	if err != nil {
+		return 0, err
+	}
+	return len(resp.Returnval), nil
+}
+
+// validateDVPortgroupNumberOfPorts checks that number_of_ports is not being
+// decreased below the number of ports that are in use in the portgroup. vSphere
+// rejects this as well, but without saying how many ports are in use.
+func validateDVPortgroupNumberOfPorts(client *govmomi.Client, d *schema.ResourceData) error {
+	if !d.HasChange("number_of_ports") || d.Get("type").(string) == string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral) {
+		return nil
+	}
+	o, n := d.GetChange("number_of_ports")
+	if n.(int) >= o.(int) {
+		return nil
+	}
+	dvsID := d.Get("distributed_virtual_switch_uuid").(string)
+	dvs, err := dvsFromUUID(client, dvsID)
+	if err != nil {
+		return fmt.Errorf("could not find DVS %q: %s", dvsID, err)
+	}
+	inUse, err := dvPortgroupPortsInUse(client, dvs, d.Get("key").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching ports in use in portgroup: %s", err)
+	}
+	if n.(int) < inUse {
+		return fmt.Errorf("cannot decrease number_of_ports to %d, as %d ports of the portgroup are in use", n.(int), inUse)
+	}
+	return nil
+}
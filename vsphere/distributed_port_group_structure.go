@@ -72,7 +72,7 @@ func schemaDVPortgroupConfigSpec() map[string]*schema.Schema {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     true,
-			Description: "Auto-expands the port group beyond the port count configured in number_of_ports when necessary.",
+			Description: "Auto-expands the port group beyond the port count configured in number_of_ports when necessary. Ignored for ephemeral portgroups.",
 		},
 		"config_version": {
 			Type:        schema.TypeString,
@@ -93,7 +93,7 @@ func schemaDVPortgroupConfigSpec() map[string]*schema.Schema {
 			Type:         schema.TypeInt,
 			Optional:     true,
 			Computed:     true,
-			Description:  "The number of ports in this portgroup. The DVS will expand and shrink by modifying this setting.",
+			Description:  "The number of ports in this portgroup. The DVS will expand and shrink by modifying this setting. Ignored for ephemeral portgroups.",
 			ValidateFunc: validation.IntAtLeast(0),
 		},
 		"port_name_format": {
@@ -171,6 +171,13 @@ func expandDVPortgroupConfigSpec(d *schema.ResourceData) types.DVPortgroupConfig
 		AutoExpand:                   getBoolPtr(d, "auto_expand"),
 		VmVnicNetworkResourcePoolKey: d.Get("network_resource_pool_key").(string),
 	}
+	// Ephemeral portgroups create a port when a virtual machine connects and
+	// delete it when the virtual machine disconnects, so a port count and
+	// auto-expansion do not apply to them.
+	if obj.Type == string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral) {
+		obj.NumPorts = 0
+		obj.AutoExpand = nil
+	}
 	return obj
 }
 
@@ -181,11 +188,16 @@ func expandDVPortgroupConfigSpec(d *schema.ResourceData) types.DVPortgroupConfig
 func flattenDVPortgroupConfigInfo(d *schema.ResourceData, obj types.DVPortgroupConfigInfo) error {
 	d.Set("config_version", obj.ConfigVersion)
 	d.Set("name", obj.Name)
-	d.Set("number_of_ports", obj.NumPorts)
 	d.Set("port_name_format", obj.PortNameFormat)
 	d.Set("description", obj.Description)
 	d.Set("type", obj.Type)
-	setBoolPtr(d, "auto_expand", obj.AutoExpand)
+	// The port count of an ephemeral portgroup changes as virtual machines
+	// connect and disconnect, and is not sent on update, so neither it nor
+	// auto_expand is read back.
+	if obj.Type != string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral) {
+		d.Set("number_of_ports", obj.NumPorts)
+		setBoolPtr(d, "auto_expand", obj.AutoExpand)
+	}
 	d.Set("network_resource_pool_key", obj.VmVnicNetworkResourcePoolKey)
 
 	if err := flattenVMwareDVSPortSetting(d, obj.DefaultPortConfig.(*types.VMwareDVSPortSetting)); err != nil {
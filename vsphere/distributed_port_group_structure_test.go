@@ -0,0 +1,44 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestExpandDVPortgroupConfigSpecPortCount(t *testing.T) {
+	cases := []struct {
+		Name               string
+		Type               string
+		ExpectedNumPorts   int32
+		ExpectedAutoExpand bool
+	}{
+		{
+			Name:               "early binding",
+			Type:               string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+			ExpectedNumPorts:   16,
+			ExpectedAutoExpand: true,
+		},
+		{
+			Name: "ephemeral",
+			Type: string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, schemaDVPortgroupConfigSpec(), map[string]interface{}{
+				"name":            "terraform-test-pg",
+				"type":            tc.Type,
+				"number_of_ports": 16,
+			})
+			spec := expandDVPortgroupConfigSpec(d)
+			if spec.NumPorts != tc.ExpectedNumPorts {
+				t.Fatalf("expected NumPorts to be %d, got %d", tc.ExpectedNumPorts, spec.NumPorts)
+			}
+			if autoExpand := spec.AutoExpand != nil && *spec.AutoExpand; autoExpand != tc.ExpectedAutoExpand {
+				t.Fatalf("expected AutoExpand to be %t, got %t", tc.ExpectedAutoExpand, autoExpand)
+			}
+		})
+	}
+}
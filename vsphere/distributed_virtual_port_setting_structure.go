@@ -332,6 +332,16 @@ func flattenBaseVmwareDistributedVirtualSwitchVlanSpec(d *schema.ResourceData, o
 	if obj == nil {
 		return nil
 	}
+	if obj.GetVmwareDistributedVirtualSwitchVlanSpec().Inherited {
+		// The VLAN configuration is inherited from the parent, so clear out all
+		// of the VLAN keys so that they show up as unset.
+		for _, k := range []string{"vlan_id", "vlan_range", "port_private_secondary_vlan_id"} {
+			if err := d.Set(k, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
 	var err error
 
@@ -391,6 +401,12 @@ func flattenVMwareUplinkPortOrderPolicy(d *schema.ResourceData, obj *types.VMwar
 	if obj == nil {
 		return nil
 	}
+	if obj.Inherited {
+		if err := d.Set("active_uplinks", nil); err != nil {
+			return err
+		}
+		return d.Set("standby_uplinks", nil)
+	}
 
 	if err := d.Set("active_uplinks", obj.ActiveUplinkPort); err != nil {
 		return err
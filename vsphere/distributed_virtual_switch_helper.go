@@ -20,6 +20,87 @@ var dvsVersions = []string{
 	"6.5.0",
 }
 
+// dvsMinNetworkResourceControlVersion3 is the minimum DVS version that
+// supports version 3 of network I/O control.
+const dvsMinNetworkResourceControlVersion3 = "6.0.0"
+
+// dvsVersionIndex returns the position of the supplied version in
+// dvsVersions, or -1 if the version is not known to us.
+func dvsVersionIndex(version string) int {
+	for n, v := range dvsVersions {
+		if v == version {
+			return n
+		}
+	}
+	return -1
+}
+
+// dvsVersionUpgradeRequired compares the current version of a DVS with the
+// version it is being changed to. true is returned if the DVS needs to be
+// upgraded, and an error is returned if the change would be a downgrade, which
+// vSphere does not support.
+func dvsVersionUpgradeRequired(current, version string) (bool, error) {
+	ci := dvsVersionIndex(current)
+	if ci < 0 {
+		return false, fmt.Errorf("unsupported current DVS version %q", current)
+	}
+	ni := dvsVersionIndex(version)
+	if ni < 0 {
+		return false, fmt.Errorf("unsupported DVS version %q", version)
+	}
+	if ni < ci {
+		return false, fmt.Errorf("downgrading dvSwitches are not allowed (old: %s new: %s)", current, version)
+	}
+	return ni > ci, nil
+}
+
+// validateDVSNetworkResourceControlVersion checks to make sure that the
+// network I/O control version supplied is supported by the DVS version. An
+// empty value in either field skips the check, as this means the value is
+// being computed by vSphere. A DVS version that is not known to us also skips
+// the check, and is left to vSphere to validate.
+func validateDVSNetworkResourceControlVersion(version, niocVersion string) error {
+	if version == "" || niocVersion == "" {
+		return nil
+	}
+	if niocVersion != string(types.DistributedVirtualSwitchNetworkResourceControlVersionVersion3) {
+		return nil
+	}
+	vi := dvsVersionIndex(version)
+	if vi < 0 {
+		return nil
+	}
+	if vi < dvsVersionIndex(dvsMinNetworkResourceControlVersion3) {
+		return fmt.Errorf("network_resource_control_version %s requires DVS version %s or higher (version is %s)", niocVersion, dvsMinNetworkResourceControlVersion3, version)
+	}
+	return nil
+}
+
+// dvsMinMultipleLagVersion is the minimum DVS version that supports the
+// multipleLag LACP API version, and hence link aggregation groups.
+const dvsMinMultipleLagVersion = "5.5.0"
+
+// validateDVSLacpAPIVersion checks to make sure that link aggregation groups
+// are only defined when the LACP API version is multipleLag, and that the DVS
+// version supports it. An empty DVS version, or one that is not known to us,
+// skips the version check.
+func validateDVSLacpAPIVersion(version, lacpAPIVersion string, lagCount int) error {
+	if lagCount < 1 {
+		return nil
+	}
+	if lacpAPIVersion != string(types.VMwareDvsLacpApiVersionMultipleLag) {
+		return fmt.Errorf("lacp_group requires lacp_api_version to be %s", types.VMwareDvsLacpApiVersionMultipleLag)
+	}
+	vi := dvsVersionIndex(version)
+	if vi < 0 {
+		return nil
+	}
+	if vi < dvsVersionIndex(dvsMinMultipleLagVersion) {
+		return fmt.Errorf("lacp_api_version %s requires DVS version %s or higher (version is %s)", lacpAPIVersion, dvsMinMultipleLagVersion, version)
+	}
+	return nil
+}
+
 // dvsFromUUID gets a DVS object from its UUID.
 func dvsFromUUID(client *govmomi.Client, uuid string) (*object.VmwareDistributedVirtualSwitch, error) {
 	dvsm := types.ManagedObjectReference{Type: "DistributedVirtualSwitchManager", Value: "DVSManager"}
@@ -143,3 +224,48 @@ func enableDVSNetworkResourceManagement(client *govmomi.Client, dvs *object.Vmwa
 
 	return nil
 }
+
+// updateDVSLacpGroupConfig exposes the UpdateDVSLacpGroupConfig_Task method of
+// the VmwareDistributedVirtualSwitch MO, which is used to add, modify, or
+// remove link aggregation groups.
+func updateDVSLacpGroupConfig(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, specs []types.VMwareDvsLacpGroupSpec) error {
+	req := &types.UpdateDVSLacpGroupConfig_Task{
+		This:          dvs.Reference(),
+		LacpGroupSpec: specs,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.UpdateDVSLacpGroupConfig_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	if err := task.Wait(tctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// dvsUplinkPorts returns all of the uplink ports on the DVS, across all member
+// hosts.
+func dvsUplinkPorts(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch) ([]types.DistributedVirtualPort, error) {
+	req := &types.FetchDVPorts{
+		This: dvs.Reference(),
+		Criteria: &types.DistributedVirtualSwitchPortCriteria{
+			UplinkPort: boolPtr(true),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.FetchDVPorts(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Returnval, nil
+}
@@ -14,6 +14,29 @@ var lacpAPIVersionAllowedValues = []string{
 	string(types.VMwareDvsLacpApiVersionMultipleLag),
 }
 
+var lacpLoadBalanceAlgorithmAllowedValues = []string{
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestMac),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPort),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPortVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmDestIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIp),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmVlan),
+	string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcPortId),
+}
+
 var multicastFilteringModeAllowedValues = []string{
 	string(types.VMwareDvsMulticastFilteringModeLegacyFiltering),
 	string(types.VMwareDvsMulticastFilteringModeSnooping),
@@ -75,11 +98,37 @@ func schemaVMwareDVSConfigSpec() map[string]*schema.Schema {
 					// DistributedVirtualSwitchHostMemberPnicSpec
 					"devices": {
 						Type:        schema.TypeList,
-						Description: "Name of the physical NIC to be added to the proxy switch.",
-						Required:    true,
-						MinItems:    1,
+						Description: "Name of the physical NIC to be added to the proxy switch. Conflicts with uplink_mapping.",
+						Optional:    true,
 						Elem:        &schema.Schema{Type: schema.TypeString},
 					},
+					"uplink_mapping": {
+						Type:        schema.TypeSet,
+						Description: "An explicit mapping of physical NICs to uplinks on the DVS. Conflicts with devices.",
+						Optional:    true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"uplink": {
+									Type:         schema.TypeString,
+									Required:     true,
+									Description:  "The name of the uplink to connect the physical NIC to, as defined in uplinks.",
+									ValidateFunc: validation.NoZeroValues,
+								},
+								"device": {
+									Type:         schema.TypeString,
+									Required:     true,
+									Description:  "The name of the physical NIC.",
+									ValidateFunc: validation.NoZeroValues,
+								},
+							},
+						},
+					},
+					"migrate_from_standard_switch": {
+						Type:        schema.TypeBool,
+						Description: "Move the physical NICs from any standard virtual switches on the host to the DVS.",
+						Optional:    true,
+						Default:     false,
+					},
 					"host_system_id": {
 						Type:         schema.TypeString,
 						Required:     true,
@@ -181,6 +230,42 @@ func schemaVMwareDVSConfigSpec() map[string]*schema.Schema {
 			Description:  "The Link Aggregation Control Protocol group version in the switch. Can be one of singleLag or multipleLag.",
 			ValidateFunc: validation.StringInSlice(lacpAPIVersionAllowedValues, false),
 		},
+		// VMwareDvsLacpGroupConfig
+		"lacp_group": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "A link aggregation group (LAG) to create on the switch. Requires lacp_api_version to be multipleLag.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Description:  "The name of the LAG.",
+						ValidateFunc: validation.NoZeroValues,
+					},
+					"uplink_count": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The number of uplink ports in the LAG.",
+						ValidateFunc: validation.IntBetween(1, 32),
+					},
+					"mode": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      string(types.VMwareUplinkLacpModeActive),
+						Description:  "The LACP mode of the LAG. Can be one of active or passive.",
+						ValidateFunc: validation.StringInSlice(vmwareUplinkLacpPolicyModeAllowedValues, false),
+					},
+					"load_balancing_algorithm": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      string(types.VMwareDvsLacpLoadBalanceAlgorithmSrcDestIpTcpUdpPortVlan),
+						Description:  "The load balancing algorithm of the LAG.",
+						ValidateFunc: validation.StringInSlice(lacpLoadBalanceAlgorithmAllowedValues, false),
+					},
+				},
+			},
+		},
 		"max_mtu": {
 			Type:         schema.TypeInt,
 			Optional:     true,
@@ -235,6 +320,15 @@ func flattenDVSContactInfo(d *schema.ResourceData, obj types.DVSContactInfo) err
 
 // expandDistributedVirtualSwitchHostMemberConfigSpec reads certain keys from a
 // Set object map and returns a DistributedVirtualSwitchHostMemberConfigSpec.
+//
+// No uplink port keys are set in the physical NIC specs returned here - when
+// uplink_mapping is in use, the uplink assignments are applied after the host
+// has joined the DVS, as this is when the host's uplink ports exist. See
+// expandDistributedVirtualSwitchHostMemberPnicSpecs.
+//
+// Hosts with migrate_from_standard_switch set join the DVS without any
+// physical NICs, as their NICs are still in use by standard virtual switches.
+// The NICs are moved over afterwards with migrateDVSHostPhysicalNics.
 func expandDistributedVirtualSwitchHostMemberConfigSpec(d map[string]interface{}) types.DistributedVirtualSwitchHostMemberConfigSpec {
 	hostRef := &types.ManagedObjectReference{
 		Type:  "HostSystem",
@@ -242,7 +336,10 @@ func expandDistributedVirtualSwitchHostMemberConfigSpec(d map[string]interface{}
 	}
 
 	var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
-	nics := sliceInterfacesToStrings(d["devices"].([]interface{}))
+	var nics []string
+	if !d["migrate_from_standard_switch"].(bool) {
+		nics = distributedVirtualSwitchHostMemberDevices(d)
+	}
 	for _, nic := range nics {
 		pnSpec := types.DistributedVirtualSwitchHostMemberPnicSpec{
 			PnicDevice: nic,
@@ -260,30 +357,148 @@ func expandDistributedVirtualSwitchHostMemberConfigSpec(d map[string]interface{}
 	return obj
 }
 
-// flattenDistributedVirtualSwitchHostMemberConfigSpec reads various fields
-// from a DistributedVirtualSwitchHostMemberConfigSpec and returns a Set object
-// map.
+// expandDistributedVirtualSwitchHostMemberPnicSpecs reads the uplink_mapping
+// key from a host Set object map and returns a list of physical NIC specs
+// connected to their respective uplink ports. keys is a map of uplink name to
+// the uplink port key for the host, which can be obtained with
+// dvsHostUplinkPortKeysByName.
+func expandDistributedVirtualSwitchHostMemberPnicSpecs(d map[string]interface{}, keys map[string]string) ([]types.DistributedVirtualSwitchHostMemberPnicSpec, error) {
+	var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
+	for _, v := range d["uplink_mapping"].(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		uplink := m["uplink"].(string)
+		key, ok := keys[uplink]
+		if !ok {
+			return nil, fmt.Errorf("uplink %q not found on host %q", uplink, d["host_system_id"].(string))
+		}
+		pnSpecs = append(pnSpecs, types.DistributedVirtualSwitchHostMemberPnicSpec{
+			PnicDevice:    m["device"].(string),
+			UplinkPortKey: key,
+		})
+	}
+	return pnSpecs, nil
+}
+
+// distributedVirtualSwitchHostMemberDevices returns all of the physical NICs
+// defined in a host Set object map, either via devices or uplink_mapping.
+func distributedVirtualSwitchHostMemberDevices(d map[string]interface{}) []string {
+	if hostMemberUsesUplinkMapping(d) {
+		var nics []string
+		for _, v := range d["uplink_mapping"].(*schema.Set).List() {
+			nics = append(nics, v.(map[string]interface{})["device"].(string))
+		}
+		return nics
+	}
+	return sliceInterfacesToStrings(d["devices"].([]interface{}))
+}
+
+// hostMemberUsesUplinkMapping returns true if a host Set object map is using
+// uplink_mapping to assign physical NICs to uplinks.
+func hostMemberUsesUplinkMapping(d map[string]interface{}) bool {
+	v, ok := d["uplink_mapping"]
+	if !ok || v == nil {
+		return false
+	}
+	return v.(*schema.Set).Len() > 0
+}
+
+// validateDistributedVirtualSwitchHostMembers checks to make sure that exactly
+// one of devices or uplink_mapping has been defined for each host in the
+// supplied set.
+func validateDistributedVirtualSwitchHostMembers(hosts *schema.Set) error {
+	for _, v := range hosts.List() {
+		m := v.(map[string]interface{})
+		devices := len(m["devices"].([]interface{})) > 0
+		mapping := hostMemberUsesUplinkMapping(m)
+		switch {
+		case devices && mapping:
+			return fmt.Errorf("host %q: only one of devices or uplink_mapping can be defined", m["host_system_id"].(string))
+		case !devices && !mapping:
+			return fmt.Errorf("host %q: one of devices or uplink_mapping must be defined", m["host_system_id"].(string))
+		}
+	}
+	return nil
+}
+
+// flattenDistributedVirtualSwitchHostMember reads various fields from a
+// DistributedVirtualSwitchHostMember and returns a Set object map.
+//
+// old is the host's Set object map from the current state, if it exists. This
+// is used to determine if the physical NICs should be read back into devices
+// or uplink_mapping, and to preserve the ordering of devices if the NICs
+// attached to the host have not changed. names is a map of uplink port keys to
+// uplink names, which can be obtained with dvsUplinkPortNamesByKey.
 //
 // This is the flatten counterpart to
 // expandDistributedVirtualSwitchHostMemberConfigSpec.
-func flattenDistributedVirtualSwitchHostMember(obj types.DistributedVirtualSwitchHostMember) map[string]interface{} {
+func flattenDistributedVirtualSwitchHostMember(obj types.DistributedVirtualSwitchHostMember, old map[string]interface{}, names map[string]string) map[string]interface{} {
 	d := make(map[string]interface{})
 	d["host_system_id"] = obj.Config.Host.Value
+	d["migrate_from_standard_switch"] = false
+	if old != nil {
+		d["migrate_from_standard_switch"] = old["migrate_from_standard_switch"]
+	}
+
+	var specs []types.DistributedVirtualSwitchHostMemberPnicSpec
+	if backing, ok := obj.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking); ok {
+		specs = backing.PnicSpec
+	}
+
+	if old != nil && hostMemberUsesUplinkMapping(old) {
+		var mapping []interface{}
+		for _, spec := range specs {
+			mapping = append(mapping, map[string]interface{}{
+				"uplink": names[spec.UplinkPortKey],
+				"device": spec.PnicDevice,
+			})
+		}
+		d["uplink_mapping"] = mapping
+		return d
+	}
 
 	var devices []string
-	backing := obj.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking)
-	for _, spec := range backing.PnicSpec {
+	for _, spec := range specs {
 		devices = append(devices, spec.PnicDevice)
 	}
-
+	if old != nil {
+		// Preserve the order in state if the devices are the same, as the order
+		// that vSphere returns the NICs in is not guaranteed to match.
+		oldDevices := sliceInterfacesToStrings(old["devices"].([]interface{}))
+		if sameStringsIgnoringOrder(oldDevices, devices) {
+			devices = oldDevices
+		}
+	}
 	d["devices"] = devices
 
 	return d
 }
 
+// sameStringsIgnoringOrder returns true if a and b contain the same strings,
+// regardless of order.
+func sameStringsIgnoringOrder(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, v := range a {
+		counts[v]++
+	}
+	for _, v := range b {
+		if counts[v] < 1 {
+			return false
+		}
+		counts[v]--
+	}
+	return true
+}
+
 // expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec expands all host
 // entires for a VMware DVS, detecting if a host spec needs to be added,
 // removed, or updated as well. The whole slice is returned.
+//
+// Modified hosts that use uplink_mapping or migrate_from_standard_switch are
+// skipped, as their physical NIC configuration requires uplink port keys or a
+// host network update, and is applied separately.
 func expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec(d *schema.ResourceData) []types.DistributedVirtualSwitchHostMemberConfigSpec {
 	var specs []types.DistributedVirtualSwitchHostMemberConfigSpec
 	o, n := d.GetChange("host")
@@ -330,6 +545,9 @@ func expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec(d *schema.Resourc
 		if !found {
 			spec.Operation = string(types.ConfigSpecOperationAdd)
 		} else {
+			if hostMemberUsesUplinkMapping(nm) || nm["migrate_from_standard_switch"].(bool) {
+				continue
+			}
 			spec.Operation = string(types.ConfigSpecOperationEdit)
 		}
 		specs = append(specs, spec)
@@ -340,14 +558,21 @@ func expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec(d *schema.Resourc
 }
 
 // flattenSliceOfDistributedVirtualSwitchHostMember creates a set of all host
-// entries for a supplied slice of DistributedVirtualSwitchHostMember.
+// entries for a supplied slice of DistributedVirtualSwitchHostMember. names is
+// a map of uplink port keys to uplink names.
 //
 // This is the flatten counterpart to
 // expandSliceOfDistributedVirtualSwitchHostMemberConfigSpec.
-func flattenSliceOfDistributedVirtualSwitchHostMember(d *schema.ResourceData, members []types.DistributedVirtualSwitchHostMember) error {
+func flattenSliceOfDistributedVirtualSwitchHostMember(d *schema.ResourceData, members []types.DistributedVirtualSwitchHostMember, names map[string]string) error {
+	old := make(map[string]map[string]interface{})
+	for _, v := range d.Get("host").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		old[m["host_system_id"].(string)] = m
+	}
+
 	var hosts []map[string]interface{}
 	for _, m := range members {
-		hosts = append(hosts, flattenDistributedVirtualSwitchHostMember(m))
+		hosts = append(hosts, flattenDistributedVirtualSwitchHostMember(m, old[m.Config.Host.Value], names))
 	}
 	if err := d.Set("host", hosts); err != nil {
 		return err
@@ -355,6 +580,125 @@ func flattenSliceOfDistributedVirtualSwitchHostMember(d *schema.ResourceData, me
 	return nil
 }
 
+// dvsUplinkPortNamesByKey returns a map of uplink port keys to uplink names for
+// the supplied uplink ports.
+func dvsUplinkPortNamesByKey(ports []types.DistributedVirtualPort) map[string]string {
+	names := make(map[string]string)
+	for _, port := range ports {
+		names[port.Key] = port.Config.Name
+	}
+	return names
+}
+
+// dvsHostUplinkPortKeysByName returns a map of uplink names to uplink port
+// keys for the host with the supplied managed object ID.
+func dvsHostUplinkPortKeysByName(ports []types.DistributedVirtualPort, hsID string) map[string]string {
+	keys := make(map[string]string)
+	for _, port := range ports {
+		if port.ProxyHost == nil || port.ProxyHost.Value != hsID {
+			continue
+		}
+		keys[port.Config.Name] = port.Key
+	}
+	return keys
+}
+
+// expandVMwareDvsLacpGroupConfig reads certain keys from a Set object map and
+// returns a VMwareDvsLacpGroupConfig.
+func expandVMwareDvsLacpGroupConfig(d map[string]interface{}) types.VMwareDvsLacpGroupConfig {
+	obj := types.VMwareDvsLacpGroupConfig{
+		Name:                 d["name"].(string),
+		UplinkNum:            int32(d["uplink_count"].(int)),
+		Mode:                 d["mode"].(string),
+		LoadbalanceAlgorithm: d["load_balancing_algorithm"].(string),
+	}
+	return obj
+}
+
+// flattenVMwareDvsLacpGroupConfig reads various fields from a
+// VMwareDvsLacpGroupConfig and returns a Set object map.
+//
+// This is the flatten counterpart to expandVMwareDvsLacpGroupConfig.
+func flattenVMwareDvsLacpGroupConfig(obj types.VMwareDvsLacpGroupConfig) map[string]interface{} {
+	d := make(map[string]interface{})
+	d["name"] = obj.Name
+	d["uplink_count"] = obj.UplinkNum
+	d["mode"] = obj.Mode
+	d["load_balancing_algorithm"] = obj.LoadbalanceAlgorithm
+	return d
+}
+
+// expandSliceOfVMwareDvsLacpGroupSpec expands all LAG entries for a VMware
+// DVS, detecting if a LAG needs to be added, removed, or updated. LAGs are
+// matched by name, and the keys of existing LAGs are looked up in current,
+// which should be the LAG configuration currently on the DVS.
+func expandSliceOfVMwareDvsLacpGroupSpec(d *schema.ResourceData, current []types.VMwareDvsLacpGroupConfig) []types.VMwareDvsLacpGroupSpec {
+	var specs []types.VMwareDvsLacpGroupSpec
+	o, n := d.GetChange("lacp_group")
+	os := o.(*schema.Set)
+	ns := n.(*schema.Set)
+
+	keys := make(map[string]string)
+	for _, lag := range current {
+		keys[lag.Name] = lag.Key
+	}
+	names := make(map[string]bool)
+	for _, ne := range ns.List() {
+		names[ne.(map[string]interface{})["name"].(string)] = true
+	}
+
+	// Process removed LAGs first, so that any uplink ports that they are using
+	// are freed up for LAGs that are being added.
+	for _, oe := range os.Difference(ns).List() {
+		om := oe.(map[string]interface{})
+		name := om["name"].(string)
+		if names[name] {
+			continue
+		}
+		key, ok := keys[name]
+		if !ok {
+			continue
+		}
+		config := expandVMwareDvsLacpGroupConfig(om)
+		config.Key = key
+		specs = append(specs, types.VMwareDvsLacpGroupSpec{
+			LacpGroupConfig: config,
+			Operation:       string(types.ConfigSpecOperationRemove),
+		})
+	}
+
+	// Added and modified LAGs are only present in the new set.
+	for _, ne := range ns.Difference(os).List() {
+		config := expandVMwareDvsLacpGroupConfig(ne.(map[string]interface{}))
+		spec := types.VMwareDvsLacpGroupSpec{
+			LacpGroupConfig: config,
+			Operation:       string(types.ConfigSpecOperationAdd),
+		}
+		if key, ok := keys[config.Name]; ok {
+			spec.LacpGroupConfig.Key = key
+			spec.Operation = string(types.ConfigSpecOperationEdit)
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs
+}
+
+// flattenSliceOfVMwareDvsLacpGroupConfig creates a set of all LAG entries for
+// a supplied slice of VMwareDvsLacpGroupConfig.
+//
+// This is the flatten counterpart to expandSliceOfVMwareDvsLacpGroupSpec.
+func flattenSliceOfVMwareDvsLacpGroupConfig(d *schema.ResourceData, groups []types.VMwareDvsLacpGroupConfig) error {
+	var lags []map[string]interface{}
+	for _, lag := range groups {
+		lags = append(lags, flattenVMwareDvsLacpGroupConfig(lag))
+	}
+	if err := d.Set("lacp_group", lags); err != nil {
+		return err
+	}
+	return nil
+}
+
 // expandVMwareIpfixConfig reads certain ResourceData keys and
 // returns a VMwareIpfixConfig.
 func expandVMwareIpfixConfig(d *schema.ResourceData) *types.VMwareIpfixConfig {
@@ -560,7 +904,10 @@ func expandVMwareDVSConfigSpec(d *schema.ResourceData) *types.VMwareDVSConfigSpe
 // This is the flatten counterpart to expandVMwareDVSConfigSpec, as the
 // configuration info from a DVS comes back as this type instead of a specific
 // ConfigSpec.
-func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConfigInfo) error {
+//
+// uplinkPorts should contain the uplink ports for the DVS, which are used to
+// resolve the uplink names that host physical NICs are connected to.
+func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConfigInfo, uplinkPorts []types.DistributedVirtualPort) error {
 	d.Set("name", obj.Name)
 	d.Set("config_version", obj.ConfigVersion)
 	d.Set("description", obj.Description)
@@ -583,7 +930,7 @@ func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConf
 	if err := flattenVMwareDVSPortSetting(d, obj.DefaultPortConfig.(*types.VMwareDVSPortSetting)); err != nil {
 		return err
 	}
-	if err := flattenSliceOfDistributedVirtualSwitchHostMember(d, obj.Host); err != nil {
+	if err := flattenSliceOfDistributedVirtualSwitchHostMember(d, obj.Host, dvsUplinkPortNamesByKey(uplinkPorts)); err != nil {
 		return err
 	}
 	if err := flattenSliceOfDvsHostInfrastructureTrafficResource(d, obj.InfrastructureTrafficResourceConfig); err != nil {
@@ -598,6 +945,9 @@ func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConf
 	if err := flattenVMwareIpfixConfig(d, obj.IpfixConfig); err != nil {
 		return err
 	}
+	if err := flattenSliceOfVMwareDvsLacpGroupConfig(d, obj.LacpGroupConfig); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,98 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// computeResourceEnvironmentBrowser returns the environment browser of the
+// compute resource or cluster with the supplied reference.
+func computeResourceEnvironmentBrowser(client *govmomi.Client, ref types.ManagedObjectReference) (types.ManagedObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ComputeResource
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, ref, []string{"environmentBrowser"}, &props); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if props.EnvironmentBrowser == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("no environment browser found for %s", ref.Value)
+	}
+	return *props.EnvironmentBrowser, nil
+}
+
+// resourcePoolEnvironmentBrowser returns the environment browser of the
+// compute resource or cluster that owns the resource pool with the supplied
+// reference.
+func resourcePoolEnvironmentBrowser(client *govmomi.Client, ref types.ManagedObjectReference) (types.ManagedObjectReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ResourcePool
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, ref, []string{"owner"}, &props); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	return computeResourceEnvironmentBrowser(client, props.Owner)
+}
+
+// environmentBrowserFromResourceData returns the environment browser for the
+// host or cluster given in the host_system_id or compute_cluster_id attributes
+// of a data source, along with the ID of the host or cluster. If a host was
+// given, a reference to it is also returned, so that the results can be
+// limited to what the host supports.
+func environmentBrowserFromResourceData(client *govmomi.Client, d *schema.ResourceData) (string, types.ManagedObjectReference, *types.ManagedObjectReference, error) {
+	if id, ok := d.GetOk("host_system_id"); ok {
+		hs, err := hostSystemFromID(client, id.(string))
+		if err != nil {
+			return "", types.ManagedObjectReference{}, nil, fmt.Errorf("error loading host: %s", err)
+		}
+		props, err := hostSystemProperties(hs)
+		if err != nil {
+			return "", types.ManagedObjectReference{}, nil, fmt.Errorf("error fetching host properties: %s", err)
+		}
+		if props.Parent == nil {
+			return "", types.ManagedObjectReference{}, nil, fmt.Errorf("host %q has no parent compute resource", id)
+		}
+		eb, err := computeResourceEnvironmentBrowser(client, *props.Parent)
+		if err != nil {
+			return "", types.ManagedObjectReference{}, nil, fmt.Errorf("error fetching environment browser: %s", err)
+		}
+		ref := hs.Reference()
+		return id.(string), eb, &ref, nil
+	}
+	if id, ok := d.GetOk("compute_cluster_id"); ok {
+		cluster, err := clusterComputeResourceFromID(client, id.(string))
+		if err != nil {
+			return "", types.ManagedObjectReference{}, nil, err
+		}
+		eb, err := computeResourceEnvironmentBrowser(client, cluster.Reference())
+		if err != nil {
+			return "", types.ManagedObjectReference{}, nil, fmt.Errorf("error fetching environment browser: %s", err)
+		}
+		return id.(string), eb, nil, nil
+	}
+	return "", types.ManagedObjectReference{}, nil, errors.New("one of host_system_id or compute_cluster_id must be specified")
+}
+
+// environmentBrowserConfigOptionDescriptors returns the descriptors of the
+// virtual machine hardware versions that are known to an environment browser.
+func environmentBrowserConfigOptionDescriptors(client *govmomi.Client, eb types.ManagedObjectReference) ([]types.VirtualMachineConfigOptionDescriptor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryConfigOptionDescriptor{
+		This: eb,
+	}
+	res, err := methods.QueryConfigOptionDescriptor(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/event"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -136,3 +139,106 @@ func selectEventsForReference(client *govmomi.Client, ref types.ManagedObjectRef
 	mgr := event.NewManager(client.Client)
 	return mgr.QueryEvents(ctx, filter)
 }
+
+// eventPageSize is the number of events that are read from an event history
+// collector at once.
+const eventPageSize = 100
+
+// queryEvents returns the events that match the supplied filter, newest
+// first. The events are read a page at a time from an event history
+// collector until either maxCount events have been read, or there are no more
+// events left that match the filter.
+func queryEvents(client *govmomi.Client, filter types.EventFilterSpec, maxCount int) ([]types.BaseEvent, error) {
+	if client.ServiceContent.EventManager == nil {
+		return nil, errors.New("event manager is not available on this connection")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	m := event.NewManager(client.Client)
+	collector, err := m.CreateCollectorForEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer collector.Destroy(context.Background())
+
+	// The latest page of the collector is not part of the scrollable view, so
+	// it needs to be fetched separately before paging back through the rest of
+	// the events.
+	var props mo.EventHistoryCollector
+	if err := collector.Properties(ctx, collector.Reference(), []string{"latestPage"}, &props); err != nil {
+		return nil, err
+	}
+	events := props.LatestPage
+	if err := collector.Reset(ctx); err != nil {
+		return nil, err
+	}
+	for len(events) < maxCount {
+		page, err := collector.ReadPreviousEvents(ctx, eventPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) < 1 {
+			break
+		}
+		events = append(events, page...)
+	}
+
+	events = sortEventsNewestFirst(events)
+	if len(events) > maxCount {
+		events = events[:maxCount]
+	}
+	return events, nil
+}
+
+// sortEventsNewestFirst sorts a list of events by key, newest first, and
+// removes any duplicate events.
+func sortEventsNewestFirst(events []types.BaseEvent) []types.BaseEvent {
+	seen := make(map[int32]struct{})
+	var result []types.BaseEvent
+	for _, e := range events {
+		key := e.GetEvent().Key
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetEvent().Key > result[j].GetEvent().Key })
+	return result
+}
+
+// eventTypeName returns the type of an event. This is the name of the event
+// class, ie: VmPoweredOnEvent, except for extended events, where it is the
+// event type ID.
+func eventTypeName(e types.BaseEvent) string {
+	switch t := e.(type) {
+	case *types.EventEx:
+		return t.EventTypeId
+	case *types.ExtendedEvent:
+		return t.EventTypeId
+	}
+	return reflect.TypeOf(e).Elem().Name()
+}
+
+// eventTarget returns the managed object reference and name of the most
+// specific entity that an event is about. An empty reference is returned if
+// the event does not refer to an entity.
+func eventTarget(e *types.Event) (types.ManagedObjectReference, string) {
+	switch {
+	case e.Vm != nil:
+		return e.Vm.Vm, e.Vm.Name
+	case e.Host != nil:
+		return e.Host.Host, e.Host.Name
+	case e.Ds != nil:
+		return e.Ds.Datastore, e.Ds.Name
+	case e.Net != nil:
+		return e.Net.Network, e.Net.Name
+	case e.Dvs != nil:
+		return e.Dvs.Dvs, e.Dvs.Name
+	case e.ComputeResource != nil:
+		return e.ComputeResource.ComputeResource, e.ComputeResource.Name
+	case e.Datacenter != nil:
+		return e.Datacenter.Datacenter, e.Datacenter.Name
+	}
+	return types.ManagedObjectReference{}, ""
+}
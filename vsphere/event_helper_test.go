@@ -0,0 +1,107 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testFlattenEvent struct {
+	Name string
+
+	event    types.BaseEvent
+	expected map[string]interface{}
+}
+
+func (tc *testFlattenEvent) Test(t *testing.T) {
+	actual := flattenEvent(tc.event)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func testEvent(key int32) types.Event {
+	return types.Event{
+		Key:                  key,
+		ChainId:              key,
+		CreatedTime:          time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC),
+		UserName:             "VSPHERE.LOCAL\\Administrator",
+		FullFormattedMessage: "test message",
+	}
+}
+
+func testFlattenedEvent(key int, typ, targetID, targetType, targetName string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":         key,
+		"chain_id":    key,
+		"time":        "2017-10-01T12:00:00Z",
+		"user":        "VSPHERE.LOCAL\\Administrator",
+		"type":        typ,
+		"message":     "test message",
+		"target_id":   targetID,
+		"target_type": targetType,
+		"target_name": targetName,
+	}
+}
+
+func TestFlattenEvent(t *testing.T) {
+	vmEvent := &types.VmPoweredOnEvent{}
+	vmEvent.Event = testEvent(1)
+	vmEvent.Host = &types.HostEventArgument{
+		EntityEventArgument: types.EntityEventArgument{Name: "esxi1"},
+		Host:                types.ManagedObjectReference{Type: "HostSystem", Value: "host-10"},
+	}
+	vmEvent.Vm = &types.VmEventArgument{
+		EntityEventArgument: types.EntityEventArgument{Name: "vm1"},
+		Vm:                  types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-20"},
+	}
+
+	eventEx := &types.EventEx{
+		Event:       testEvent(2),
+		EventTypeId: "com.vmware.vc.test",
+	}
+
+	userEvent := &types.UserLoginSessionEvent{}
+	userEvent.Event = testEvent(3)
+
+	cases := []testFlattenEvent{
+		{
+			Name:     "virtual machine event",
+			event:    vmEvent,
+			expected: testFlattenedEvent(1, "VmPoweredOnEvent", "vm-20", "VirtualMachine", "vm1"),
+		},
+		{
+			Name:     "extended event",
+			event:    eventEx,
+			expected: testFlattenedEvent(2, "com.vmware.vc.test", "", "", ""),
+		},
+		{
+			Name:     "no target",
+			event:    userEvent,
+			expected: testFlattenedEvent(3, "UserLoginSessionEvent", "", "", ""),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestSortEventsNewestFirst(t *testing.T) {
+	newEvent := func(key int32) types.BaseEvent {
+		e := &types.GeneralUserEvent{}
+		e.Key = key
+		return e
+	}
+	events := []types.BaseEvent{newEvent(3), newEvent(5), newEvent(4), newEvent(1), newEvent(5)}
+
+	var actual []int32
+	for _, e := range sortEventsNewestFirst(events) {
+		actual = append(actual, e.GetEvent().Key)
+	}
+	expected := []int32{5, 4, 3, 1}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
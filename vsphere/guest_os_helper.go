@@ -0,0 +1,109 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// guestIDSuggestions is the maximum number of similar guest IDs that are
+// suggested when a guest ID is not supported.
+const guestIDSuggestions = 3
+
+// environmentBrowserGuestOsDescriptors returns the guest OS descriptors in the
+// configuration options of an environment browser for the hardware version
+// key, ie: vmx-13, or the default hardware version if key is empty. If host is
+// not nil, only the guests that are supported by that host are returned.
+func environmentBrowserGuestOsDescriptors(client *govmomi.Client, eb types.ManagedObjectReference, key string, host *types.ManagedObjectReference) ([]types.GuestOsDescriptor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryConfigOption{
+		This: eb,
+		Key:  key,
+		Host: host,
+	}
+	res, err := methods.QueryConfigOption(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Returnval == nil {
+		return nil, fmt.Errorf("no configuration options found for hardware version %q", key)
+	}
+	return res.Returnval.GuestOSDescriptor, nil
+}
+
+// validateGuestID checks that id is one of the guests in descs. If it is not,
+// the returned error names the supported guest IDs that are most similar to
+// it.
+func validateGuestID(id string, descs []types.GuestOsDescriptor) error {
+	var ids []string
+	for _, desc := range descs {
+		if desc.Id == id {
+			return nil
+		}
+		ids = append(ids, desc.Id)
+	}
+	similar := similarGuestIDs(id, ids, guestIDSuggestions)
+	if len(similar) < 1 {
+		return fmt.Errorf("guest_id %q is not supported by the target host or cluster", id)
+	}
+	return fmt.Errorf("guest_id %q is not supported by the target host or cluster. Similar supported guest IDs: %s", id, strings.Join(similar, ", "))
+}
+
+// similarGuestIDs returns up to n of the guest IDs in ids that are the most
+// similar to id, ordered from the most similar. Similarity is measured by the
+// edit distance between the IDs, ignoring case.
+func similarGuestIDs(id string, ids []string, n int) []string {
+	distances := make(map[string]int)
+	for _, s := range ids {
+		distances[s] = editDistance(strings.ToLower(id), strings.ToLower(s))
+	}
+	similar := make([]string, len(ids))
+	copy(similar, ids)
+	sort.SliceStable(similar, func(i, j int) bool {
+		if distances[similar[i]] != distances[similar[j]] {
+			return distances[similar[i]] < distances[similar[j]]
+		}
+		return similar[i] < similar[j]
+	})
+	if len(similar) > n {
+		similar = similar[:n]
+	}
+	return similar
+}
+
+// editDistance returns the Levenshtein distance between a and b: the number of
+// single character insertions, deletions, and substitutions that it takes to
+// turn one into the other.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
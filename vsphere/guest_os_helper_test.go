@@ -0,0 +1,55 @@
+package vsphere
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"", "", 0},
+		{"rhel7_64Guest", "rhel7_64Guest", 0},
+		{"", "abc", 3},
+		{"rhel7_64Guest", "rhel6_64Guest", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range cases {
+		if actual := editDistance(tc.a, tc.b); actual != tc.expected {
+			t.Fatalf("expected distance between %q and %q to be %d, got %d", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestSimilarGuestIDs(t *testing.T) {
+	ids := []string{"windows9_64Guest", "rhel6_64Guest", "rhel7Guest", "rhel7_64Guest", "otherLinux64Guest"}
+	expected := []string{"rhel7_64Guest", "rhel6_64Guest", "rhel7Guest"}
+	actual := similarGuestIDs("RHEL7_64guest", ids, 3)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestValidateGuestID(t *testing.T) {
+	descs := []types.GuestOsDescriptor{
+		{Id: "rhel7_64Guest"},
+		{Id: "rhel6_64Guest"},
+		{Id: "windows9_64Guest"},
+	}
+	if err := validateGuestID("rhel7_64Guest", descs); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	err := validateGuestID("rhel8_64Guest", descs)
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if !strings.Contains(err.Error(), "rhel6_64Guest, rhel7_64Guest") {
+		t.Fatalf("expected error to suggest similar guest IDs, got %q", err.Error())
+	}
+}
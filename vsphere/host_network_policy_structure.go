@@ -1,6 +1,8 @@
 package vsphere
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vmware/govmomi/vim25/types"
@@ -125,10 +127,14 @@ func expandHostNicFailureCriteria(d *schema.ResourceData) *types.HostNicFailureC
 
 // flattenHostNicFailureCriteria reads various fields from a
 // HostNicFailureCriteria into the passed in ResourceData.
+//
+// An unset value is read as an inherited setting, and cleared in state.
 func flattenHostNicFailureCriteria(d *schema.ResourceData, obj *types.HostNicFailureCriteria) error {
-	if obj.CheckBeacon != nil {
-		d.Set("check_beacon", obj.CheckBeacon)
+	if obj == nil || obj.CheckBeacon == nil {
+		d.Set("check_beacon", nil)
+		return nil
 	}
+	d.Set("check_beacon", *obj.CheckBeacon)
 	return nil
 }
 
@@ -147,9 +153,15 @@ func expandHostNicOrderPolicy(d *schema.ResourceData) *types.HostNicOrderPolicy
 }
 
 // flattenHostNicOrderPolicy reads various fields from a HostNicOrderPolicy
-// into the passed in ResourceData.
+// into the passed in ResourceData. The order of the adapters is preserved as
+// returned by the API, which is the order that failover happens in.
+//
+// A nil policy is read as an inherited setting, and both lists are cleared in
+// state.
 func flattenHostNicOrderPolicy(d *schema.ResourceData, obj *types.HostNicOrderPolicy) error {
 	if obj == nil {
+		d.Set("active_nics", nil)
+		d.Set("standby_nics", nil)
 		return nil
 	}
 	if err := d.Set("active_nics", sliceStringsToInterfaces(obj.ActiveNic)); err != nil {
@@ -185,13 +197,23 @@ func expandHostNicTeamingPolicy(d *schema.ResourceData) *types.HostNicTeamingPol
 
 // flattenHostNicTeamingPolicy reads various fields from a HostNicTeamingPolicy
 // into the passed in ResourceData.
+//
+// Any setting that is unset, or a nil policy, is read as inherited, and
+// cleared in state.
 func flattenHostNicTeamingPolicy(d *schema.ResourceData, obj *types.HostNicTeamingPolicy) error {
+	if obj == nil {
+		obj = &types.HostNicTeamingPolicy{}
+	}
 	if obj.RollingOrder != nil {
 		v := *obj.RollingOrder
 		d.Set("failback", !v)
+	} else {
+		d.Set("failback", nil)
 	}
 	if obj.NotifySwitches != nil {
-		d.Set("notify_switches", obj.NotifySwitches)
+		d.Set("notify_switches", *obj.NotifySwitches)
+	} else {
+		d.Set("notify_switches", nil)
 	}
 	d.Set("teaming_policy", obj.Policy)
 	if err := flattenHostNicFailureCriteria(d, obj.FailureCriteria); err != nil {
@@ -203,6 +225,52 @@ func flattenHostNicTeamingPolicy(d *schema.ResourceData, obj *types.HostNicTeami
 	return nil
 }
 
+// validateHostNicOrderPolicy checks the active and standby network adapters
+// in the supplied HostNicOrderPolicy against the adapters available on the
+// virtual switch. An adapter can only be listed once, and must be bound to the
+// virtual switch.
+func validateHostNicOrderPolicy(obj *types.HostNicOrderPolicy, nics []string) error {
+	if obj == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	for _, nic := range append(append([]string{}, obj.ActiveNic...), obj.StandbyNic...) {
+		if _, ok := seen[nic]; ok {
+			return fmt.Errorf("network adapter %q can only be listed once in active_nics and standby_nics", nic)
+		}
+		seen[nic] = struct{}{}
+		var found bool
+		for _, n := range nics {
+			if n == nic {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("network adapter %q is not bound to the virtual switch", nic)
+		}
+	}
+	return nil
+}
+
+// hostNicOrderPolicyUnusedNics returns the network adapters in nics that are
+// neither active or standby in the supplied HostNicOrderPolicy. These are the
+// adapters that are not used for traffic, even in the event of a failover.
+func hostNicOrderPolicyUnusedNics(obj *types.HostNicOrderPolicy, nics []string) []string {
+	used := make(map[string]struct{})
+	if obj != nil {
+		for _, nic := range append(append([]string{}, obj.ActiveNic...), obj.StandbyNic...) {
+			used[nic] = struct{}{}
+		}
+	}
+	unused := make([]string, 0)
+	for _, nic := range nics {
+		if _, ok := used[nic]; !ok {
+			unused = append(unused, nic)
+		}
+	}
+	return unused
+}
+
 // expandHostNetworkSecurityPolicy reads certain ResourceData keys and returns
 // a HostNetworkSecurityPolicy.
 func expandHostNetworkSecurityPolicy(d *schema.ResourceData) *types.HostNetworkSecurityPolicy {
@@ -248,11 +316,31 @@ func expandHostNetworkTrafficShapingPolicy(d *schema.ResourceData) *types.HostNe
 	return obj
 }
 
+// validateHostNetworkTrafficShapingPolicy checks the bandwidth settings in a
+// HostNetworkTrafficShapingPolicy for consistency. Bandwidth settings that are
+// not set are inherited, and are skipped.
+func validateHostNetworkTrafficShapingPolicy(obj *types.HostNetworkTrafficShapingPolicy) error {
+	if obj == nil || obj.AverageBandwidth == 0 || obj.PeakBandwidth == 0 {
+		return nil
+	}
+	if obj.PeakBandwidth < obj.AverageBandwidth {
+		return fmt.Errorf("shaping_peak_bandwidth (%d) cannot be less than shaping_average_bandwidth (%d)", obj.PeakBandwidth, obj.AverageBandwidth)
+	}
+	return nil
+}
+
 // flattenHostNetworkTrafficShapingPolicy reads various fields from a
 // HostNetworkTrafficShapingPolicy into the passed in ResourceData.
+//
+// Settings that are not set are read as inherited, and cleared in state.
 func flattenHostNetworkTrafficShapingPolicy(d *schema.ResourceData, obj *types.HostNetworkTrafficShapingPolicy) error {
+	if obj == nil {
+		obj = &types.HostNetworkTrafficShapingPolicy{}
+	}
 	if obj.Enabled != nil {
 		d.Set("shaping_enabled", *obj.Enabled)
+	} else {
+		d.Set("shaping_enabled", nil)
 	}
 	d.Set("shaping_average_bandwidth", obj.AverageBandwidth)
 	d.Set("shaping_burst_size", obj.BurstSize)
@@ -29,6 +29,22 @@ func hostNetworkSystemFromHostSystemID(client *govmomi.Client, hsID string) (*ob
 	return hostNetworkSystemFromHostSystem(hs)
 }
 
+// hostNetworkInfo fetches the full network information for the supplied
+// HostNetworkSystem.
+func hostNetworkInfo(client *govmomi.Client, ns *object.HostNetworkSystem) (*types.HostNetworkInfo, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+	if mns.NetworkInfo == nil {
+		return nil, fmt.Errorf("no network information returned for host network system %q", ns.Reference().Value)
+	}
+	return mns.NetworkInfo, nil
+}
+
 // hostVSwitchFromName locates a virtual switch on the supplied
 // HostNetworkSystem by name.
 func hostVSwitchFromName(client *govmomi.Client, ns *object.HostNetworkSystem, name string) (*types.HostVirtualSwitch, error) {
@@ -49,6 +65,204 @@ func hostVSwitchFromName(client *govmomi.Client, ns *object.HostNetworkSystem, n
 	return nil, fmt.Errorf("could not find virtual switch %s", name)
 }
 
+// hostManagementVirtualNics returns the devices of the VMkernel adapters on the
+// supplied host that are selected for management traffic.
+func hostManagementVirtualNics(hs *object.HostSystem) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	vnm, err := hs.ConfigManager().VirtualNicManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading virtual NIC manager: %s", err)
+	}
+	info, err := vnm.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual NIC manager properties: %s", err)
+	}
+
+	var devices []string
+	for _, config := range info.NetConfig {
+		if config.NicType != string(types.HostVirtualNicManagerNicTypeManagement) {
+			continue
+		}
+		for _, vnic := range config.CandidateVnic {
+			for _, key := range config.SelectedVnic {
+				if vnic.Key == key {
+					devices = append(devices, vnic.Device)
+				}
+			}
+		}
+	}
+	return devices, nil
+}
+
+// hostVSwitchManagementVirtualNic returns the first VMkernel adapter in
+// mgmtVnics that is connected to a port group on the named standard virtual
+// switch, or an empty string if there is none.
+func hostVSwitchManagementVirtualNic(info *types.HostNetworkInfo, mgmtVnics []string, name string) string {
+	for _, vnic := range info.Vnic {
+		if vnic.Portgroup == "" {
+			continue
+		}
+		var mgmt bool
+		for _, device := range mgmtVnics {
+			if vnic.Device == device {
+				mgmt = true
+			}
+		}
+		if !mgmt {
+			continue
+		}
+		for _, pg := range info.Portgroup {
+			if pg.Spec.Name == vnic.Portgroup && pg.Spec.VswitchName == name {
+				return vnic.Device
+			}
+		}
+	}
+	return ""
+}
+
+// expandHostNetworkConfigDVSMigration returns a HostNetworkConfig that removes
+// the physical NICs in pnSpecs from any standard virtual switches on the host
+// and connects them to the proxy switch for the DVS with the supplied UUID.
+// Both changes are made in the one update, so that the host is not left
+// without uplinks if the update fails. The NICs are also removed from the
+// teaming policy of any affected virtual switch. nil is returned if there is
+// nothing to change.
+//
+// An error is returned if the last physical NIC would be removed from a
+// virtual switch that carries one of the management VMkernel adapters in
+// mgmtVnics, as this would disconnect the host.
+func expandHostNetworkConfigDVSMigration(info *types.HostNetworkInfo, mgmtVnics []string, dvsUUID string, pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec) (*types.HostNetworkConfig, error) {
+	remove := func(nics []string) []string {
+		var result []string
+		for _, nic := range nics {
+			var found bool
+			for _, spec := range pnSpecs {
+				if nic == spec.PnicDevice {
+					found = true
+				}
+			}
+			if !found {
+				result = append(result, nic)
+			}
+		}
+		return result
+	}
+
+	config := new(types.HostNetworkConfig)
+	for _, sw := range info.Vswitch {
+		spec := sw.Spec
+		bridge, ok := spec.Bridge.(*types.HostVirtualSwitchBondBridge)
+		if !ok {
+			continue
+		}
+		nics := remove(bridge.NicDevice)
+		if len(nics) == len(bridge.NicDevice) {
+			continue
+		}
+		if len(nics) > 0 {
+			nb := *bridge
+			nb.NicDevice = nics
+			spec.Bridge = &nb
+		} else {
+			if vnic := hostVSwitchManagementVirtualNic(info, mgmtVnics, sw.Name); vnic != "" {
+				return nil, fmt.Errorf("refusing to remove the last physical NIC from virtual switch %q, as it carries management interface %q", sw.Name, vnic)
+			}
+			spec.Bridge = nil
+		}
+		if spec.Policy != nil && spec.Policy.NicTeaming != nil && spec.Policy.NicTeaming.NicOrder != nil {
+			policy := *spec.Policy
+			teaming := *policy.NicTeaming
+			order := *teaming.NicOrder
+			order.ActiveNic = remove(order.ActiveNic)
+			order.StandbyNic = remove(order.StandbyNic)
+			teaming.NicOrder = &order
+			policy.NicTeaming = &teaming
+			spec.Policy = &policy
+		}
+		config.Vswitch = append(config.Vswitch, types.HostVirtualSwitchConfig{
+			ChangeOperation: string(types.HostConfigChangeOperationEdit),
+			Name:            sw.Name,
+			Spec:            &spec,
+		})
+	}
+
+	var proxy *types.HostProxySwitch
+	for i := range info.ProxySwitch {
+		if info.ProxySwitch[i].DvsUuid == dvsUUID {
+			proxy = &info.ProxySwitch[i]
+		}
+	}
+	if proxy == nil {
+		return nil, fmt.Errorf("host is not a member of DVS %q", dvsUUID)
+	}
+	current := make(map[string]string)
+	if backing, ok := proxy.Spec.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking); ok {
+		for _, spec := range backing.PnicSpec {
+			current[spec.PnicDevice] = spec.UplinkPortKey
+		}
+	}
+	changed := len(current) != len(pnSpecs)
+	for _, spec := range pnSpecs {
+		if key, ok := current[spec.PnicDevice]; !ok || key != spec.UplinkPortKey {
+			changed = true
+		}
+	}
+	if len(config.Vswitch) < 1 && !changed {
+		return nil, nil
+	}
+
+	config.ProxySwitch = []types.HostProxySwitchConfig{
+		{
+			ChangeOperation: string(types.HostConfigChangeOperationEdit),
+			Uuid:            dvsUUID,
+			Spec: &types.HostProxySwitchSpec{
+				Backing: &types.DistributedVirtualSwitchHostMemberPnicBacking{
+					PnicSpec: pnSpecs,
+				},
+			},
+		},
+	}
+	return config, nil
+}
+
+// migrateHostPhysicalNicsToDVS moves the physical NICs in pnSpecs from any
+// standard virtual switches on the host to the DVS with the supplied UUID,
+// with a single update of the host's network configuration. The host needs to
+// have already joined the DVS. See expandHostNetworkConfigDVSMigration.
+func migrateHostPhysicalNicsToDVS(client *govmomi.Client, hsID string, dvsUUID string, pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec) error {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host: %s", err)
+	}
+	ns, err := hostNetworkSystemFromHostSystem(hs)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	info, err := hostNetworkInfo(client, ns)
+	if err != nil {
+		return err
+	}
+	mgmtVnics, err := hostManagementVirtualNics(hs)
+	if err != nil {
+		return err
+	}
+	config, err := expandHostNetworkConfigDVSMigration(info, mgmtVnics, dvsUUID, pnSpecs)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if _, err := ns.UpdateNetworkConfig(ctx, *config, string(types.HostConfigChangeModeModify)); err != nil {
+		return fmt.Errorf("error updating host network configuration: %s", err)
+	}
+	return nil
+}
+
 // hostPortGroupFromName locates a port group on the supplied HostNetworkSystem
 // by name.
 func hostPortGroupFromName(client *govmomi.Client, ns *object.HostNetworkSystem, name string) (*types.HostPortGroup, error) {
@@ -69,6 +283,78 @@ func hostPortGroupFromName(client *govmomi.Client, ns *object.HostNetworkSystem,
 	return nil, fmt.Errorf("could not find port group %s", name)
 }
 
+// hostVirtualNicFromDevice locates a VMkernel adapter on the supplied
+// HostNetworkSystem by device name.
+func hostVirtualNicFromDevice(client *govmomi.Client, ns *object.HostNetworkSystem, device string) (*types.HostVirtualNic, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.vnic"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+
+	for _, nic := range mns.NetworkInfo.Vnic {
+		if nic.Device == device {
+			return &nic, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find VMkernel adapter %s", device)
+}
+
+// hostVSwitchVirtualNics returns the VMkernel adapters that are connected to
+// port groups on the standard virtual switch with the supplied name.
+func hostVSwitchVirtualNics(client *govmomi.Client, ns *object.HostNetworkSystem, name string) ([]types.HostVirtualNic, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.portgroup", "networkInfo.vnic"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+
+	pgs := make(map[string]struct{})
+	for _, pg := range mns.NetworkInfo.Portgroup {
+		if pg.Spec.VswitchName == name {
+			pgs[pg.Spec.Name] = struct{}{}
+		}
+	}
+	var nics []types.HostVirtualNic
+	for _, nic := range mns.NetworkInfo.Vnic {
+		if _, ok := pgs[nic.Portgroup]; ok {
+			nics = append(nics, nic)
+		}
+	}
+	return nics, nil
+}
+
+// moveHostVirtualNic moves a VMkernel adapter to a different standard or
+// distributed port group, in a single network configuration update so that
+// the adapter's configuration is carried over. When portgroup is set, the
+// adapter is moved to that standard port group, otherwise it is moved to the
+// distributed port group defined in spec.
+func moveHostVirtualNic(ns *object.HostNetworkSystem, device, portgroup string, spec types.HostVirtualNicSpec) error {
+	if portgroup != "" {
+		spec.Portgroup = portgroup
+		spec.DistributedVirtualPort = nil
+	}
+	config := types.HostNetworkConfig{
+		Vnic: []types.HostVirtualNicConfig{
+			{
+				ChangeOperation: string(types.HostConfigChangeOperationEdit),
+				Device:          device,
+				Portgroup:       portgroup,
+				Spec:            &spec,
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := ns.UpdateNetworkConfig(ctx, config, string(types.HostConfigChangeModeModify))
+	return err
+}
+
 // networkObjectFromHostSystem locates the network object in vCenter for a
 // specific HostSystem and network name.
 //
@@ -0,0 +1,130 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testHostNetworkInfoDVSMigration(vswitchNics []string, proxyNics []string) *types.HostNetworkInfo {
+	var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
+	for i, nic := range proxyNics {
+		pnSpecs = append(pnSpecs, types.DistributedVirtualSwitchHostMemberPnicSpec{
+			PnicDevice:    nic,
+			UplinkPortKey: []string{"10", "11"}[i],
+		})
+	}
+	return &types.HostNetworkInfo{
+		Vswitch: []types.HostVirtualSwitch{
+			{
+				Name: "vSwitch0",
+				Spec: types.HostVirtualSwitchSpec{
+					Bridge: &types.HostVirtualSwitchBondBridge{NicDevice: vswitchNics},
+					Policy: &types.HostNetworkPolicy{
+						NicTeaming: &types.HostNicTeamingPolicy{
+							NicOrder: &types.HostNicOrderPolicy{
+								ActiveNic:  vswitchNics,
+								StandbyNic: []string{},
+							},
+						},
+					},
+				},
+			},
+		},
+		Portgroup: []types.HostPortGroup{
+			{Spec: types.HostPortGroupSpec{Name: "Management Network", VswitchName: "vSwitch0"}},
+		},
+		Vnic: []types.HostVirtualNic{
+			{Device: "vmk0", Portgroup: "Management Network"},
+		},
+		ProxySwitch: []types.HostProxySwitch{
+			{
+				DvsUuid: "dvs-uuid",
+				Spec: types.HostProxySwitchSpec{
+					Backing: &types.DistributedVirtualSwitchHostMemberPnicBacking{PnicSpec: pnSpecs},
+				},
+			},
+		},
+	}
+}
+
+func TestExpandHostNetworkConfigDVSMigration(t *testing.T) {
+	pnSpecs := []types.DistributedVirtualSwitchHostMemberPnicSpec{
+		{PnicDevice: "vmnic1", UplinkPortKey: "10"},
+	}
+	cases := []struct {
+		Name         string
+		info         *types.HostNetworkInfo
+		mgmtVnics    []string
+		expectedNics []string
+		expectedNoop bool
+		expectedErr  *regexp.Regexp
+	}{
+		{
+			Name:         "remove one of two NICs",
+			info:         testHostNetworkInfoDVSMigration([]string{"vmnic0", "vmnic1"}, nil),
+			mgmtVnics:    []string{"vmk0"},
+			expectedNics: []string{"vmnic0"},
+		},
+		{
+			Name:        "last NIC of management switch",
+			info:        testHostNetworkInfoDVSMigration([]string{"vmnic1"}, nil),
+			mgmtVnics:   []string{"vmk0"},
+			expectedErr: regexp.MustCompile(`refusing to remove the last physical NIC from virtual switch "vSwitch0", as it carries management interface "vmk0"`),
+		},
+		{
+			Name: "last NIC of non-management switch",
+			info: testHostNetworkInfoDVSMigration([]string{"vmnic1"}, nil),
+		},
+		{
+			Name:         "already migrated",
+			info:         testHostNetworkInfoDVSMigration([]string{"vmnic0"}, []string{"vmnic1"}),
+			mgmtVnics:    []string{"vmk0"},
+			expectedNoop: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			config, err := expandHostNetworkConfigDVSMigration(tc.info, tc.mgmtVnics, "dvs-uuid", pnSpecs)
+			if tc.expectedErr != nil {
+				if err == nil || !tc.expectedErr.MatchString(err.Error()) {
+					t.Fatalf("expected error matching %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedNoop {
+				if config != nil {
+					t.Fatalf("expected no change, got %#v", config)
+				}
+				return
+			}
+			if len(config.Vswitch) != 1 {
+				t.Fatalf("expected 1 virtual switch change, got %d", len(config.Vswitch))
+			}
+			spec := config.Vswitch[0].Spec
+			var nics []string
+			if bridge, ok := spec.Bridge.(*types.HostVirtualSwitchBondBridge); ok {
+				nics = bridge.NicDevice
+			}
+			if !reflect.DeepEqual(nics, tc.expectedNics) {
+				t.Fatalf("expected bridge NICs %#v, got %#v", tc.expectedNics, nics)
+			}
+			if active := spec.Policy.NicTeaming.NicOrder.ActiveNic; !reflect.DeepEqual(active, tc.expectedNics) {
+				t.Fatalf("expected active NICs %#v, got %#v", tc.expectedNics, active)
+			}
+			if len(config.ProxySwitch) != 1 {
+				t.Fatalf("expected 1 proxy switch change, got %d", len(config.ProxySwitch))
+			}
+			backing := config.ProxySwitch[0].Spec.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking)
+			if !reflect.DeepEqual(backing.PnicSpec, pnSpecs) {
+				t.Fatalf("expected proxy switch NICs %#v, got %#v", pnSpecs, backing.PnicSpec)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostProfileReferenceFromID returns a ManagedObjectReference for a
+// HostProfile from its managed object ID.
+func hostProfileReferenceFromID(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "HostProfile",
+		Value: id,
+	}
+}
+
+// hostProfileProperties fetches the HostProfile MO for the host profile with
+// the supplied managed object ID.
+func hostProfileProperties(client *govmomi.Client, id string) (*mo.HostProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.HostProfile
+	hp := object.NewCommon(client.Client, hostProfileReferenceFromID(id))
+	if err := hp.Properties(ctx, hp.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// hostProfileManagerReference returns the reference to the
+// HostProfileManager, which is only available on vCenter.
+func hostProfileManagerReference(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.ServiceContent.HostProfileManager == nil {
+		return types.ManagedObjectReference{}, errors.New("host profile manager is not available on this connection")
+	}
+	return *client.ServiceContent.HostProfileManager, nil
+}
+
+// profileComplianceManagerReference returns the reference to the
+// ProfileComplianceManager, which is only available on vCenter.
+func profileComplianceManagerReference(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.ServiceContent.ComplianceManager == nil {
+		return types.ManagedObjectReference{}, errors.New("profile compliance manager is not available on this connection")
+	}
+	return *client.ServiceContent.ComplianceManager, nil
+}
+
+// associateHostProfile attaches the host profile with the supplied ID to the
+// entity referenced by ref.
+func associateHostProfile(client *govmomi.Client, id string, ref types.ManagedObjectReference) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.AssociateProfile{
+		This:   hostProfileReferenceFromID(id),
+		Entity: []types.ManagedObjectReference{ref},
+	}
+	_, err := methods.AssociateProfile(ctx, client, req)
+	return err
+}
+
+// dissociateHostProfile detaches the host profile with the supplied ID from
+// the entity referenced by ref.
+func dissociateHostProfile(client *govmomi.Client, id string, ref types.ManagedObjectReference) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.DissociateProfile{
+		This:   hostProfileReferenceFromID(id),
+		Entity: []types.ManagedObjectReference{ref},
+	}
+	_, err := methods.DissociateProfile(ctx, client, req)
+	return err
+}
+
+// hostProfileIsAssociated checks to see if the entity referenced by ref is
+// attached to the supplied host profile.
+func hostProfileIsAssociated(props *mo.HostProfile, ref types.ManagedObjectReference) bool {
+	for _, e := range props.Entity {
+		if e == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHostProfileCompliance runs a compliance check for the host profile with
+// the supplied ID against the entity referenced by ref, and returns the
+// results.
+func checkHostProfileCompliance(client *govmomi.Client, id string, ref types.ManagedObjectReference) ([]types.ComplianceResult, error) {
+	cm, err := profileComplianceManagerReference(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.CheckCompliance_Task{
+		This:    cm,
+		Profile: []types.ManagedObjectReference{hostProfileReferenceFromID(id)},
+		Entity:  []types.ManagedObjectReference{ref},
+	}
+	res, err := methods.CheckCompliance_Task(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if info.Result == nil {
+		return nil, nil
+	}
+	return info.Result.(types.ArrayOfComplianceResult).ComplianceResult, nil
+}
+
+// queryHostProfileCompliance returns the results of the last compliance check
+// for the host profile with the supplied ID against the entity referenced by
+// ref, without running a new check.
+func queryHostProfileCompliance(client *govmomi.Client, id string, ref types.ManagedObjectReference) ([]types.ComplianceResult, error) {
+	cm, err := profileComplianceManagerReference(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryComplianceStatus{
+		This:    cm,
+		Profile: []types.ManagedObjectReference{hostProfileReferenceFromID(id)},
+		Entity:  []types.ManagedObjectReference{ref},
+	}
+	res, err := methods.QueryComplianceStatus(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
+
+// executeHostProfile runs the host profile with the supplied ID against a
+// host, returning the configuration that would need to be applied to bring
+// the host into compliance.
+//
+// An error is returned if the profile needs more input than what was supplied
+// in input, or if there was an error generating the configuration.
+func executeHostProfile(client *govmomi.Client, id string, host *object.HostSystem, input []types.ProfileDeferredPolicyOptionParameter) (*types.ProfileExecuteResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ExecuteHostProfile{
+		This:          hostProfileReferenceFromID(id),
+		Host:          host.Reference(),
+		DeferredParam: input,
+	}
+	res, err := methods.ExecuteHostProfile(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	result := res.Returnval.GetProfileExecuteResult()
+	switch types.ProfileExecuteResultStatus(result.Status) {
+	case types.ProfileExecuteResultStatusNeedInput:
+		var paths []string
+		for _, ri := range result.RequireInput {
+			paths = append(paths, hostProfilePropertyPathString(ri.InputPath))
+		}
+		return nil, fmt.Errorf("host profile requires additional input for the following paths: %s", strings.Join(paths, ", "))
+	case types.ProfileExecuteResultStatusError:
+		var msgs []string
+		for _, e := range result.Error {
+			msgs = append(msgs, e.Message.Message)
+		}
+		return nil, fmt.Errorf("host profile execution failed: %s", strings.Join(msgs, "; "))
+	}
+	return result, nil
+}
+
+// applyHostProfile brings a host into compliance with the host profile with
+// the supplied ID, using the answer file input supplied in input.
+//
+// If the generated configuration requires maintenance mode, the host needs to
+// be in maintenance mode already, otherwise an error is returned.
+func applyHostProfile(client *govmomi.Client, id string, host *object.HostSystem, input []types.ProfileDeferredPolicyOptionParameter) error {
+	hpm, err := hostProfileManagerReference(client)
+	if err != nil {
+		return err
+	}
+	result, err := executeHostProfile(client, id, host, input)
+	if err != nil {
+		return err
+	}
+	if result.ConfigSpec == nil {
+		// Nothing to do
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	treq := &types.GenerateConfigTaskList{
+		This:       hpm,
+		ConfigSpec: *result.ConfigSpec,
+		Host:       host.Reference(),
+	}
+	tres, err := methods.GenerateConfigTaskList(ctx, client, treq)
+	if err != nil {
+		return fmt.Errorf("error generating host profile task list: %s", err)
+	}
+	for _, r := range tres.Returnval.TaskListRequirement {
+		if types.HostProfileManagerTaskListRequirement(r) != types.HostProfileManagerTaskListRequirementMaintenanceModeRequired {
+			continue
+		}
+		props, err := hostSystemProperties(host)
+		if err != nil {
+			return fmt.Errorf("error fetching host properties: %s", err)
+		}
+		if !props.Runtime.InMaintenanceMode {
+			return fmt.Errorf("host %q needs to be in maintenance mode to apply this host profile", props.Name)
+		}
+	}
+
+	spec := result.ConfigSpec
+	if tres.Returnval.ConfigSpec != nil {
+		spec = tres.Returnval.ConfigSpec
+	}
+	actx, acancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer acancel()
+	areq := &types.ApplyHostConfig_Task{
+		This:       hpm,
+		Host:       host.Reference(),
+		ConfigSpec: *spec,
+		UserInput:  input,
+	}
+	ares, err := methods.ApplyHostConfig_Task(actx, client, areq)
+	if err != nil {
+		return fmt.Errorf("error applying host profile: %s", err)
+	}
+	task := object.NewTask(client.Client, ares.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// hostProfilePropertyPathString renders a ProfilePropertyPath in a format
+// suitable for displaying in errors.
+func hostProfilePropertyPathString(p types.ProfilePropertyPath) string {
+	s := p.ProfilePath
+	if p.PolicyId != "" {
+		s += "/" + p.PolicyId
+	}
+	if p.ParameterId != "" {
+		s += "/" + p.ParameterId
+	}
+	return s
+}
+
+// hostProfileFromName locates a host profile by its name.
+func hostProfileFromName(client *govmomi.Client, name string) (*mo.HostProfile, error) {
+	hpm, err := hostProfileManagerReference(client)
+	if err != nil {
+		return nil, err
+	}
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var mhpm mo.HostProfileManager
+	if err := pc.RetrieveOne(ctx, hpm, []string{"profile"}, &mhpm); err != nil {
+		return nil, fmt.Errorf("error fetching host profile manager properties: %s", err)
+	}
+	if len(mhpm.Profile) < 1 {
+		return nil, fmt.Errorf("could not find host profile %s", name)
+	}
+
+	var profiles []mo.HostProfile
+	rctx, rcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer rcancel()
+	if err := pc.Retrieve(rctx, mhpm.Profile, []string{"name"}, &profiles); err != nil {
+		return nil, fmt.Errorf("error fetching host profile properties: %s", err)
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return hostProfileProperties(client, profile.Self.Value)
+		}
+	}
+
+	return nil, fmt.Errorf("could not find host profile %s", name)
+}
+
+// exportHostProfile returns the serialized configuration of the host profile
+// with the supplied ID.
+func exportHostProfile(client *govmomi.Client, id string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ExportProfile{
+		This: hostProfileReferenceFromID(id),
+	}
+	res, err := methods.ExportProfile(ctx, client, req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval, nil
+}
+
+// createHostProfile creates a host profile from the supplied spec and returns
+// its managed object ID.
+func createHostProfile(client *govmomi.Client, spec types.BaseProfileCreateSpec) (string, error) {
+	hpm, err := hostProfileManagerReference(client)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.CreateProfile{
+		This:       hpm,
+		CreateSpec: spec,
+	}
+	res, err := methods.CreateProfile(ctx, client, req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval.Value, nil
+}
+
+// updateHostProfile updates the host profile with the supplied ID with the
+// supplied config spec.
+func updateHostProfile(client *govmomi.Client, id string, spec types.BaseHostProfileConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.UpdateHostProfile{
+		This:   hostProfileReferenceFromID(id),
+		Config: spec,
+	}
+	_, err := methods.UpdateHostProfile(ctx, client, req)
+	return err
+}
+
+// destroyHostProfile removes the host profile with the supplied ID.
+func destroyHostProfile(client *govmomi.Client, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.DestroyProfile{
+		This: hostProfileReferenceFromID(id),
+	}
+	_, err := methods.DestroyProfile(ctx, client, req)
+	return err
+}
@@ -0,0 +1,127 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// expandHostProfileDeferredPolicyOptionParameters reads the answer_file_input
+// blocks and returns the corresponding user input for host profile
+// application.
+func expandHostProfileDeferredPolicyOptionParameters(d *schema.ResourceData) []types.ProfileDeferredPolicyOptionParameter {
+	var input []types.ProfileDeferredPolicyOptionParameter
+	for _, v := range d.Get("answer_file_input").([]interface{}) {
+		m := v.(map[string]interface{})
+		obj := types.ProfileDeferredPolicyOptionParameter{
+			InputPath: types.ProfilePropertyPath{
+				ProfilePath: m["profile_path"].(string),
+				PolicyId:    m["policy_id"].(string),
+			},
+		}
+		for k, pv := range m["parameters"].(map[string]interface{}) {
+			obj.Parameter = append(obj.Parameter, types.KeyAnyValue{
+				Key:   k,
+				Value: pv.(string),
+			})
+		}
+		input = append(input, obj)
+	}
+	return input
+}
+
+// flattenHostProfileComplianceResults collapses a set of compliance results
+// into a single status and a list of failure messages. If any one result is
+// not compliant, the collapsed status is not compliant.
+func flattenHostProfileComplianceResults(results []types.ComplianceResult) (string, []string) {
+	status := string(types.ComplianceResultStatusUnknown)
+	var failures []string
+	for _, r := range results {
+		switch types.ComplianceResultStatus(r.ComplianceStatus) {
+		case types.ComplianceResultStatusNonCompliant:
+			status = r.ComplianceStatus
+		case types.ComplianceResultStatusCompliant:
+			if status == string(types.ComplianceResultStatusUnknown) {
+				status = r.ComplianceStatus
+			}
+		}
+		for _, f := range r.Failure {
+			failures = append(failures, strings.TrimSpace(f.Message.Message))
+		}
+	}
+	return status, failures
+}
+
+// flattenHostProfile saves the common attributes of a HostProfile MO to the
+// supplied ResourceData.
+func flattenHostProfile(d *schema.ResourceData, obj *mo.HostProfile) error {
+	d.Set("name", obj.Name)
+	if obj.Config != nil {
+		d.Set("description", obj.Config.GetProfileConfigInfo().Annotation)
+	}
+	if obj.ReferenceHost != nil {
+		d.Set("reference_host_id", obj.ReferenceHost.Value)
+	} else {
+		d.Set("reference_host_id", "")
+	}
+	return nil
+}
+
+// expandHostProfileCreateSpec reads the needed attributes from a
+// vsphere_host_profile resource and returns the create spec for the profile.
+// The profile is created from the reference host if one is supplied, and from
+// the serialized configuration otherwise.
+func expandHostProfileCreateSpec(d *schema.ResourceData) (types.BaseProfileCreateSpec, error) {
+	base := types.ProfileCreateSpec{
+		Name:       d.Get("name").(string),
+		Annotation: d.Get("description").(string),
+		Enabled:    boolPtr(true),
+	}
+	if v, ok := d.GetOk("reference_host_id"); ok {
+		return &types.HostProfileHostBasedConfigSpec{
+			HostProfileConfigSpec: types.HostProfileConfigSpec{
+				ProfileCreateSpec: base,
+			},
+			Host: types.ManagedObjectReference{
+				Type:  "HostSystem",
+				Value: v.(string),
+			},
+		}, nil
+	}
+	if v, ok := d.GetOk("profile_config"); ok {
+		return &types.HostProfileSerializedHostProfileSpec{
+			ProfileSerializedCreateSpec: types.ProfileSerializedCreateSpec{
+				ProfileCreateSpec:   base,
+				ProfileConfigString: v.(string),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("one of reference_host_id or profile_config must be set")
+}
+
+// expandHostProfileCompleteConfigSpec reads the name and description of a
+// vsphere_host_profile resource and returns a complete config spec for the
+// supplied host profile. The rest of the spec is taken from the current
+// configuration of the profile, as UpdateHostProfile replaces the whole
+// configuration with the spec that is sent.
+func expandHostProfileCompleteConfigSpec(d *schema.ResourceData, obj *mo.HostProfile) (*types.HostProfileCompleteConfigSpec, error) {
+	config, ok := obj.Config.(*types.HostProfileConfigInfo)
+	if !ok || config == nil {
+		return nil, fmt.Errorf("host profile %q has no configuration", obj.Reference().Value)
+	}
+	return &types.HostProfileCompleteConfigSpec{
+		HostProfileConfigSpec: types.HostProfileConfigSpec{
+			ProfileCreateSpec: types.ProfileCreateSpec{
+				Name:       d.Get("name").(string),
+				Annotation: d.Get("description").(string),
+				Enabled:    boolPtr(config.Enabled),
+			},
+		},
+		ApplyProfile:           config.ApplyProfile,
+		CustomComplyProfile:    config.CustomComplyProfile,
+		DisabledExpressionList: config.DisabledExpressionList,
+	}, nil
+}
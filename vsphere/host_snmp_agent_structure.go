@@ -0,0 +1,198 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostSnmpAgentOptionKeys maps the SNMPv3 related keys in the host SNMP
+// agent schema to the names of the agent options that they are stored in.
+var hostSnmpAgentOptionKeys = map[string]string{
+	"engine_id":               "engineid",
+	"authentication_protocol": "authProtocol",
+	"privacy_protocol":        "privProtocol",
+}
+
+var hostSnmpAgentAuthenticationProtocolAllowedValues = []string{
+	"none",
+	"MD5",
+	"SHA1",
+}
+
+var hostSnmpAgentPrivacyProtocolAllowedValues = []string{
+	"none",
+	"AES128",
+}
+
+// schemaHostSnmpConfigSpec returns schema items for resources that need to
+// work with a HostSnmpConfigSpec.
+func schemaHostSnmpConfigSpec() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"enabled": {
+			Type:        schema.TypeBool,
+			Description: "Whether or not the SNMP agent is enabled.",
+			Optional:    true,
+			Default:     true,
+		},
+		"port": {
+			Type:         schema.TypeInt,
+			Description:  "The UDP port that the SNMP agent listens on.",
+			Optional:     true,
+			Default:      161,
+			ValidateFunc: validation.IntBetween(1, 65535),
+		},
+		"read_only_communities": {
+			Type:        schema.TypeList,
+			Description: "The communities that have read-only access to the SNMP agent.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"trap_target": {
+			Type:        schema.TypeList,
+			Description: "The destinations that the SNMP agent sends traps to.",
+			Optional:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"host_name": {
+						Type:        schema.TypeString,
+						Description: "The host name or IP address of the trap target.",
+						Required:    true,
+					},
+					"port": {
+						Type:         schema.TypeInt,
+						Description:  "The UDP port of the trap target.",
+						Optional:     true,
+						Default:      162,
+						ValidateFunc: validation.IntBetween(1, 65535),
+					},
+					"community": {
+						Type:        schema.TypeString,
+						Description: "The community to send traps with.",
+						Required:    true,
+					},
+				},
+			},
+		},
+		"engine_id": {
+			Type:        schema.TypeString,
+			Description: "The SNMPv3 engine ID of the agent. If not set, the host generates one.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"authentication_protocol": {
+			Type:         schema.TypeString,
+			Description:  "The SNMPv3 authentication protocol. Can be one of none, MD5, or SHA1.",
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.StringInSlice(hostSnmpAgentAuthenticationProtocolAllowedValues, false),
+		},
+		"privacy_protocol": {
+			Type:         schema.TypeString,
+			Description:  "The SNMPv3 privacy protocol. Can be one of none or AES128.",
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.StringInSlice(hostSnmpAgentPrivacyProtocolAllowedValues, false),
+		},
+	}
+}
+
+// expandHostSnmpConfigSpec reads certain ResourceData keys and returns a
+// HostSnmpConfigSpec. The agent options in current that are not managed by
+// the resource are carried over, as the whole option list is replaced on
+// reconfiguration.
+func expandHostSnmpConfigSpec(d *schema.ResourceData, current []types.KeyValue) types.HostSnmpConfigSpec {
+	obj := types.HostSnmpConfigSpec{
+		Enabled:             boolPtr(d.Get("enabled").(bool)),
+		Port:                int32(d.Get("port").(int)),
+		ReadOnlyCommunities: sliceInterfacesToStrings(d.Get("read_only_communities").([]interface{})),
+	}
+	for _, v := range d.Get("trap_target").([]interface{}) {
+		m := v.(map[string]interface{})
+		obj.TrapTargets = append(obj.TrapTargets, types.HostSnmpDestination{
+			HostName:  m["host_name"].(string),
+			Port:      int32(m["port"].(int)),
+			Community: m["community"].(string),
+		})
+	}
+
+	managed := make(map[string]string)
+	for k, opt := range hostSnmpAgentOptionKeys {
+		if v, ok := d.GetOk(k); ok {
+			managed[opt] = v.(string)
+		}
+	}
+	for _, kv := range current {
+		if _, ok := managed[kv.Key]; !ok {
+			obj.Option = append(obj.Option, kv)
+		}
+	}
+	for _, opt := range []string{"engineid", "authProtocol", "privProtocol"} {
+		if v, ok := managed[opt]; ok {
+			obj.Option = append(obj.Option, types.KeyValue{Key: opt, Value: v})
+		}
+	}
+	return obj
+}
+
+// flattenHostSnmpConfigSpec reads various fields from a HostSnmpConfigSpec
+// into the passed in ResourceData.
+func flattenHostSnmpConfigSpec(d *schema.ResourceData, obj types.HostSnmpConfigSpec) error {
+	enabled := false
+	if obj.Enabled != nil {
+		enabled = *obj.Enabled
+	}
+	d.Set("enabled", enabled)
+	d.Set("port", obj.Port)
+	if err := d.Set("read_only_communities", obj.ReadOnlyCommunities); err != nil {
+		return err
+	}
+	var targets []interface{}
+	for _, t := range obj.TrapTargets {
+		targets = append(targets, map[string]interface{}{
+			"host_name": t.HostName,
+			"port":      int(t.Port),
+			"community": t.Community,
+		})
+	}
+	if err := d.Set("trap_target", targets); err != nil {
+		return err
+	}
+	for k, opt := range hostSnmpAgentOptionKeys {
+		d.Set(k, "")
+		for _, kv := range obj.Option {
+			if kv.Key == opt {
+				d.Set(k, kv.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// validateHostSnmpConfigSpec checks a HostSnmpConfigSpec against the limits
+// of the SNMP agent on the host.
+func validateHostSnmpConfigSpec(obj types.HostSnmpConfigSpec, limits types.HostSnmpSystemAgentLimits) error {
+	if limits.Capability == types.HostSnmpAgentCapabilityDIAGNOSTICS {
+		return fmt.Errorf("the SNMP agent on this host does not support configuration")
+	}
+	if limits.MaxReadOnlyCommunities > 0 && len(obj.ReadOnlyCommunities) > int(limits.MaxReadOnlyCommunities) {
+		return fmt.Errorf("host supports at most %d read-only communities, got %d", limits.MaxReadOnlyCommunities, len(obj.ReadOnlyCommunities))
+	}
+	if limits.MaxTrapDestinations > 0 && len(obj.TrapTargets) > int(limits.MaxTrapDestinations) {
+		return fmt.Errorf("host supports at most %d trap targets, got %d", limits.MaxTrapDestinations, len(obj.TrapTargets))
+	}
+	if limits.MaxCommunityLength > 0 {
+		communities := append([]string{}, obj.ReadOnlyCommunities...)
+		for _, t := range obj.TrapTargets {
+			communities = append(communities, t.Community)
+		}
+		for _, c := range communities {
+			if len(c) > int(limits.MaxCommunityLength) {
+				return fmt.Errorf("community %q is longer than the maximum of %d characters supported by the host", c, limits.MaxCommunityLength)
+			}
+		}
+	}
+	return nil
+}
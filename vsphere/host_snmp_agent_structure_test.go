@@ -0,0 +1,79 @@
+package vsphere
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestValidateHostSnmpConfigSpec(t *testing.T) {
+	limits := types.HostSnmpSystemAgentLimits{
+		MaxReadOnlyCommunities: 2,
+		MaxTrapDestinations:    1,
+		MaxCommunityLength:     8,
+		Capability:             types.HostSnmpAgentCapabilityCOMPLETE,
+	}
+	cases := []struct {
+		Name        string
+		spec        types.HostSnmpConfigSpec
+		limits      types.HostSnmpSystemAgentLimits
+		expectedErr *regexp.Regexp
+	}{
+		{
+			Name: "within limits",
+			spec: types.HostSnmpConfigSpec{
+				ReadOnlyCommunities: []string{"public", "private"},
+				TrapTargets:         []types.HostSnmpDestination{{HostName: "10.0.0.1", Port: 162, Community: "public"}},
+			},
+			limits: limits,
+		},
+		{
+			Name: "too many communities",
+			spec: types.HostSnmpConfigSpec{
+				ReadOnlyCommunities: []string{"a", "b", "c"},
+			},
+			limits:      limits,
+			expectedErr: regexp.MustCompile("host supports at most 2 read-only communities, got 3"),
+		},
+		{
+			Name: "too many trap targets",
+			spec: types.HostSnmpConfigSpec{
+				TrapTargets: []types.HostSnmpDestination{
+					{HostName: "10.0.0.1", Port: 162, Community: "public"},
+					{HostName: "10.0.0.2", Port: 162, Community: "public"},
+				},
+			},
+			limits:      limits,
+			expectedErr: regexp.MustCompile("host supports at most 1 trap targets, got 2"),
+		},
+		{
+			Name: "trap community too long",
+			spec: types.HostSnmpConfigSpec{
+				TrapTargets: []types.HostSnmpDestination{{HostName: "10.0.0.1", Port: 162, Community: "verylongcommunity"}},
+			},
+			limits:      limits,
+			expectedErr: regexp.MustCompile("longer than the maximum of 8 characters"),
+		},
+		{
+			Name: "diagnostics only",
+			spec: types.HostSnmpConfigSpec{},
+			limits: types.HostSnmpSystemAgentLimits{
+				Capability: types.HostSnmpAgentCapabilityDIAGNOSTICS,
+			},
+			expectedErr: regexp.MustCompile("does not support configuration"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := validateHostSnmpConfigSpec(tc.spec, tc.limits)
+			if tc.expectedErr != nil {
+				testMatchError(t, err, tc.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+		})
+	}
+}
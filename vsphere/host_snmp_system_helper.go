@@ -0,0 +1,55 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostSnmpSystemFromHostSystemID returns the reference to the HostSnmpSystem
+// of the host with the supplied managed object ID.
+func hostSnmpSystemFromHostSystemID(client *govmomi.Client, hsID string) (types.ManagedObjectReference, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	props, err := hostSystemProperties(hs)
+	if err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.SnmpSystem == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("host %q does not support SNMP configuration", props.Name)
+	}
+	return *props.ConfigManager.SnmpSystem, nil
+}
+
+// hostSnmpSystemProperties fetches the HostSnmpSystem MO for the supplied
+// reference.
+func hostSnmpSystemProperties(client *govmomi.Client, ref types.ManagedObjectReference) (*mo.HostSnmpSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.HostSnmpSystem
+	ss := object.NewCommon(client.Client, ref)
+	if err := ss.Properties(ctx, ss.Reference(), []string{"configuration", "limits"}, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// reconfigureHostSnmpAgent applies the supplied configuration to the SNMP
+// agent of a host.
+func reconfigureHostSnmpAgent(client *govmomi.Client, ref types.ManagedObjectReference, spec types.HostSnmpConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ReconfigureSnmpAgent{
+		This: ref,
+		Spec: spec,
+	}
+	_, err := methods.ReconfigureSnmpAgent(ctx, client, req)
+	return err
+}
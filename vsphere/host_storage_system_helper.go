@@ -2,9 +2,13 @@ package vsphere
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // hostStorageSystemFromHostSystemID locates a HostStorageSystem from a
@@ -18,3 +22,63 @@ func hostStorageSystemFromHostSystemID(client *govmomi.Client, hsID string) (*ob
 	defer cancel()
 	return hs.ConfigManager().StorageSystem(ctx)
 }
+
+// mountVmfsVolume mounts the VMFS volume with the supplied UUID on the host
+// with the supplied managed object ID. The host needs to be able to see the
+// disks that the volume is on, and the volume must have been unmounted from
+// the host before, as hosts that see a VMFS volume mount it by default.
+func mountVmfsVolume(client *govmomi.Client, hsID, uuid string) error {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.MountVmfsVolume{
+		This:     ss.Reference(),
+		VmfsUuid: uuid,
+	}
+	_, err = methods.MountVmfsVolume(ctx, client, req)
+	return err
+}
+
+// unmountVmfsVolume unmounts the VMFS volume with the supplied UUID from the
+// host with the supplied managed object ID. The volume stays unmounted on the
+// host until it is mounted again with mountVmfsVolume.
+func unmountVmfsVolume(client *govmomi.Client, hsID, uuid string) error {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.UnmountVmfsVolume{
+		This:     ss.Reference(),
+		VmfsUuid: uuid,
+	}
+	_, err = methods.UnmountVmfsVolume(ctx, client, req)
+	return err
+}
+
+// hostScsiDiskFromUUID locates a SCSI disk on the host with the supplied
+// managed object ID by its LUN UUID.
+func hostScsiDiskFromUUID(client *govmomi.Client, hsID, uuid string) (*types.HostScsiDisk, error) {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host storage system: %s", err)
+	}
+	var hss mo.HostStorageSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ss.Properties(ctx, ss.Reference(), []string{"storageDeviceInfo.scsiLun"}, &hss); err != nil {
+		return nil, fmt.Errorf("error querying storage system properties: %s", err)
+	}
+	if hss.StorageDeviceInfo != nil {
+		for _, lun := range hss.StorageDeviceInfo.ScsiLun {
+			if disk, ok := lun.(*types.HostScsiDisk); ok && disk.Uuid == uuid {
+				return disk, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("could not find SCSI disk with UUID %s", uuid)
+}
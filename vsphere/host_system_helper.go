@@ -3,10 +3,14 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -45,11 +49,23 @@ func hostSystemFromID(client *govmomi.Client, id string) (*object.HostSystem, er
 	defer cancel()
 	ds, err := finder.ObjectReference(ctx, ref)
 	if err != nil {
-		return nil, fmt.Errorf("could not find host system with id: %s: %s", id, err)
+		return nil, fmt.Errorf("could not find host system with id: %s on %s: %s", id, serverDescription(client), err)
 	}
 	return ds.(*object.HostSystem), nil
 }
 
+// hostSystemProperties is a convenience method that wraps fetching the
+// HostSystem MO from its higher-level object.
+func hostSystemProperties(host *object.HostSystem) (*mo.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.HostSystem
+	if err := host.Properties(ctx, host.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
 // hostSystemNameFromID returns the name of a host via its its managed object
 // reference ID.
 func hostSystemNameFromID(client *govmomi.Client, id string) (string, error) {
@@ -70,3 +86,101 @@ func hostSystemNameOrID(client *govmomi.Client, id string) string {
 	}
 	return name
 }
+
+// hostSystemEnterMaintenanceMode puts a host into maintenance mode and waits
+// for the task to complete. On vCenter, evacuate controls whether or not
+// powered off and suspended virtual machines are moved off the host along with
+// the powered on ones when DRS is enabled on the host's cluster.
+//
+// The timeout value is in minutes. A value of less than 1 waits indefinitely.
+func hostSystemEnterMaintenanceMode(host *object.HostSystem, timeout int, evacuate bool) error {
+	ctx, cancel := hostSystemMaintenanceModeContext(timeout)
+	defer cancel()
+	task, err := host.EnterMaintenanceMode(ctx, int32(timeout*60), evacuate, nil)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// hostSystemExitMaintenanceMode takes a host out of maintenance mode and waits
+// for the task to complete.
+//
+// The timeout value is in minutes. A value of less than 1 waits indefinitely.
+func hostSystemExitMaintenanceMode(host *object.HostSystem, timeout int) error {
+	ctx, cancel := hostSystemMaintenanceModeContext(timeout)
+	defer cancel()
+	task, err := host.ExitMaintenanceMode(ctx, int32(timeout*60))
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// hostSystemMaintenanceModeContext returns the context used to wait on
+// maintenance mode tasks, which can take much longer than defaultAPITimeout
+// while virtual machines are migrated off the host.
+func hostSystemMaintenanceModeContext(timeout int) (context.Context, context.CancelFunc) {
+	if timeout < 1 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Minute)
+}
+
+// hostSystemReboot reboots a host and waits for it to reconnect to vCenter.
+// The host needs to be in maintenance mode. As the connection to the host is
+// lost while it reboots, this is only supported on vCenter.
+//
+// The timeout value is in minutes, and covers both the reboot task and the
+// host reconnecting. A value of less than 1 waits indefinitely.
+func hostSystemReboot(client *govmomi.Client, host *object.HostSystem, timeout int) error {
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	ctx, cancel := hostSystemMaintenanceModeContext(timeout)
+	defer cancel()
+	req := &types.RebootHost_Task{
+		This:  host.Reference(),
+		Force: false,
+	}
+	res, err := methods.RebootHost_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	if err := task.Wait(ctx); err != nil {
+		return err
+	}
+
+	// The reboot task completes as soon as the host has accepted the reboot,
+	// so wait for vCenter to lose the connection to the host, and then for the
+	// host to come back.
+	var lost bool
+	p := client.PropertyCollector()
+	err = property.Wait(ctx, p, host.Reference(), []string{"runtime.connectionState"}, func(pc []types.PropertyChange) bool {
+		for _, c := range pc {
+			if c.Op != types.PropertyChangeOpAssign {
+				continue
+			}
+			state, ok := c.Val.(types.HostSystemConnectionState)
+			if !ok {
+				continue
+			}
+			if state != types.HostSystemConnectionStateConnected {
+				lost = true
+				continue
+			}
+			if lost {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timeout waiting for host to reconnect after reboot")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostVirtualNicIDPrefix = "tf-HostVirtualNic"
+
+// schemaHostVirtualNicSpec returns schema items for resources that need to
+// work with a HostVirtualNicSpec, such as VMkernel adapters.
+func schemaHostVirtualNicSpec() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		// HostVirtualNicSpec
+		"portgroup": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The name of the standard port group to attach this adapter to.",
+			ConflictsWith: []string{"distributed_switch_port", "distributed_port_group"},
+		},
+		"mac": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The MAC address of the adapter.",
+		},
+		"mtu": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1500,
+			Description:  "The maximum transmission unit (MTU) of the adapter in bytes.",
+			ValidateFunc: validation.IntBetween(1280, 9000),
+		},
+		"netstack": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    true,
+			Default:     "defaultTcpipStack",
+			Description: "The TCP/IP stack to attach the adapter to.",
+		},
+
+		// DistributedVirtualSwitchPortConnection
+		"distributed_switch_port": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The UUID of the DVS to attach this adapter to. Requires distributed_port_group.",
+			ConflictsWith: []string{"portgroup"},
+		},
+		"distributed_port_group": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The key of the distributed port group to attach this adapter to. Requires distributed_switch_port.",
+			ConflictsWith: []string{"portgroup"},
+		},
+
+		// HostIpConfig
+		"ipv4": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Description: "The IPv4 configuration of the adapter.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"dhcp": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Description: "Use DHCP to configure the adapter.",
+					},
+					"ip": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The IPv4 address of the adapter.",
+					},
+					"netmask": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The IPv4 subnet mask of the adapter.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandHostIPConfig reads certain ResourceData keys and returns a
+// HostIpConfig. If no IPv4 configuration has been defined, DHCP is used.
+func expandHostIPConfig(d *schema.ResourceData) *types.HostIpConfig {
+	l := d.Get("ipv4").([]interface{})
+	if len(l) < 1 || l[0] == nil {
+		return &types.HostIpConfig{Dhcp: true}
+	}
+	m := l[0].(map[string]interface{})
+	obj := &types.HostIpConfig{
+		Dhcp:       m["dhcp"].(bool),
+		IpAddress:  m["ip"].(string),
+		SubnetMask: m["netmask"].(string),
+	}
+	return obj
+}
+
+// flattenHostIPConfig reads various fields from a HostIpConfig into the
+// passed in ResourceData.
+func flattenHostIPConfig(d *schema.ResourceData, obj *types.HostIpConfig) error {
+	if obj == nil {
+		return nil
+	}
+	m := map[string]interface{}{
+		"dhcp":    obj.Dhcp,
+		"ip":      obj.IpAddress,
+		"netmask": obj.SubnetMask,
+	}
+	if obj.Dhcp {
+		// The address is assigned by DHCP, so don't track it.
+		m["ip"] = ""
+		m["netmask"] = ""
+	}
+	return d.Set("ipv4", []interface{}{m})
+}
+
+// expandDistributedVirtualSwitchPortConnection reads certain ResourceData
+// keys and returns a DistributedVirtualSwitchPortConnection. nil is returned
+// if the adapter is not being attached to a DVS.
+func expandDistributedVirtualSwitchPortConnection(d *schema.ResourceData) *types.DistributedVirtualSwitchPortConnection {
+	dvsID := d.Get("distributed_switch_port").(string)
+	if dvsID == "" {
+		return nil
+	}
+	obj := &types.DistributedVirtualSwitchPortConnection{
+		SwitchUuid:   dvsID,
+		PortgroupKey: d.Get("distributed_port_group").(string),
+	}
+	return obj
+}
+
+// expandHostVirtualNicSpec reads certain ResourceData keys and returns a
+// HostVirtualNicSpec.
+//
+// The standard port group is not set in the spec, as it is supplied
+// separately to the API calls that need it.
+func expandHostVirtualNicSpec(d *schema.ResourceData) *types.HostVirtualNicSpec {
+	obj := &types.HostVirtualNicSpec{
+		Ip:                     expandHostIPConfig(d),
+		Mac:                    d.Get("mac").(string),
+		Mtu:                    int32(d.Get("mtu").(int)),
+		DistributedVirtualPort: expandDistributedVirtualSwitchPortConnection(d),
+		NetStackInstanceKey:    d.Get("netstack").(string),
+	}
+	return obj
+}
+
+// flattenHostVirtualNic reads various fields from a HostVirtualNic into the
+// passed in ResourceData.
+func flattenHostVirtualNic(d *schema.ResourceData, obj *types.HostVirtualNic) error {
+	d.Set("device", obj.Device)
+	d.Set("portgroup", obj.Portgroup)
+	d.Set("mac", obj.Spec.Mac)
+	d.Set("mtu", obj.Spec.Mtu)
+	if obj.Spec.NetStackInstanceKey != "" {
+		d.Set("netstack", obj.Spec.NetStackInstanceKey)
+	}
+	if dvp := obj.Spec.DistributedVirtualPort; dvp != nil {
+		d.Set("distributed_switch_port", dvp.SwitchUuid)
+		d.Set("distributed_port_group", dvp.PortgroupKey)
+	} else {
+		d.Set("distributed_switch_port", "")
+		d.Set("distributed_port_group", "")
+	}
+	if err := flattenHostIPConfig(d, obj.Spec.Ip); err != nil {
+		return err
+	}
+	return nil
+}
+
+// saveHostVirtualNicID sets a special ID for a host VMkernel adapter, composed
+// of the MOID for the concerned HostSystem and the adapter's device name.
+func saveHostVirtualNicID(d *schema.ResourceData, hsID, device string) {
+	d.SetId(fmt.Sprintf("%s:%s:%s", hostVirtualNicIDPrefix, hsID, device))
+}
+
+// splitHostVirtualNicID splits a vsphere_vnic resource ID into its
+// counterparts: the prefix, the HostSystem ID, and the device name.
+func splitHostVirtualNicID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != hostVirtualNicIDPrefix || s[1] == "" || s[2] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], nil
+}
+
+// virtualNicIDsFromResourceID passes a resource's ID through
+// splitHostVirtualNicID.
+func virtualNicIDsFromResourceID(d *schema.ResourceData) (string, string, error) {
+	return splitHostVirtualNicID(d.Id())
+}
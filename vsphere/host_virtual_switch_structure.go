@@ -132,7 +132,7 @@ func schemaHostVirtualSwitchSpec() map[string]*schema.Schema {
 			Optional:     true,
 			Description:  "The maximum transmission unit (MTU) of the virtual switch in bytes.",
 			Default:      1500,
-			ValidateFunc: validation.IntBetween(1, 9000),
+			ValidateFunc: validation.IntBetween(1280, 9000),
 		},
 		"number_of_ports": &schema.Schema{
 			Type:         schema.TypeInt,
@@ -0,0 +1,149 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// iscsiManagerFromHostSystemID returns the reference to the IscsiManager of
+// the host with the supplied managed object ID.
+func iscsiManagerFromHostSystemID(client *govmomi.Client, hsID string) (types.ManagedObjectReference, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	props, err := hostSystemProperties(hs)
+	if err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.IscsiManager == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("host %q does not support iSCSI port binding", props.Name)
+	}
+	return *props.ConfigManager.IscsiManager, nil
+}
+
+// hostInternetScsiHbaFromDevice locates a software or dependent hardware iSCSI
+// adapter on the host with the supplied managed object ID by device name.
+func hostInternetScsiHbaFromDevice(client *govmomi.Client, hsID, device string) (*types.HostInternetScsiHba, error) {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host storage system: %s", err)
+	}
+	var hss mo.HostStorageSystem
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ss.Properties(ctx, ss.Reference(), []string{"storageDeviceInfo.hostBusAdapter"}, &hss); err != nil {
+		return nil, fmt.Errorf("error querying storage system properties: %s", err)
+	}
+	if hss.StorageDeviceInfo == nil {
+		return nil, fmt.Errorf("could not find iSCSI adapter %s", device)
+	}
+	for _, hba := range hss.StorageDeviceInfo.HostBusAdapter {
+		if hba.GetHostHostBusAdapter().Device != device {
+			continue
+		}
+		if iscsi, ok := hba.(*types.HostInternetScsiHba); ok {
+			return iscsi, nil
+		}
+		return nil, fmt.Errorf("adapter %s is not an iSCSI adapter", device)
+	}
+	return nil, fmt.Errorf("could not find iSCSI adapter %s", device)
+}
+
+// queryIscsiBoundVnics returns the VMkernel adapters that are bound to the
+// iSCSI adapter with the supplied device name.
+func queryIscsiBoundVnics(client *govmomi.Client, ref types.ManagedObjectReference, hba string) ([]types.IscsiPortInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryBoundVnics{
+		This:         ref,
+		IScsiHbaName: hba,
+	}
+	res, err := methods.QueryBoundVnics(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
+
+// queryIscsiCandidateNics returns the VMkernel adapters that can be bound to
+// the iSCSI adapter with the supplied device name.
+func queryIscsiCandidateNics(client *govmomi.Client, ref types.ManagedObjectReference, hba string) ([]types.IscsiPortInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryCandidateNics{
+		This:         ref,
+		IScsiHbaName: hba,
+	}
+	res, err := methods.QueryCandidateNics(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
+
+// bindIscsiVnic binds the VMkernel adapter with the supplied device name to
+// an iSCSI adapter.
+func bindIscsiVnic(client *govmomi.Client, ref types.ManagedObjectReference, hba, vnic string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.BindVnic{
+		This:         ref,
+		IScsiHbaName: hba,
+		VnicDevice:   vnic,
+	}
+	_, err := methods.BindVnic(ctx, client, req)
+	return err
+}
+
+// unbindIscsiVnic unbinds the VMkernel adapter with the supplied device name
+// from an iSCSI adapter. If force is set, the adapter is unbound even if
+// there are active sessions using it.
+func unbindIscsiVnic(client *govmomi.Client, ref types.ManagedObjectReference, hba, vnic string, force bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.UnbindVnic{
+		This:         ref,
+		IScsiHbaName: hba,
+		VnicDevice:   vnic,
+		Force:        force,
+	}
+	_, err := methods.UnbindVnic(ctx, client, req)
+	return err
+}
+
+// iscsiPortInfoFromVnic returns the entry for the VMkernel adapter with the
+// supplied device name from a list of IscsiPortInfo, or nil if it is not in
+// the list.
+func iscsiPortInfoFromVnic(ports []types.IscsiPortInfo, vnic string) *types.IscsiPortInfo {
+	for _, port := range ports {
+		if port.VnicDevice == vnic {
+			return &port
+		}
+	}
+	return nil
+}
+
+// iscsiStatusReasons renders the reasons in an IscsiStatus in a format
+// suitable for displaying in errors. An empty string is returned if the
+// status does not have any issues.
+func iscsiStatusReasons(status *types.IscsiStatus) string {
+	if status == nil {
+		return ""
+	}
+	var msgs []string
+	for _, r := range status.Reason {
+		msg := r.LocalizedMessage
+		if msg == "" {
+			msg = fmt.Sprintf("%T", r.Fault)
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -2,11 +2,13 @@ package vsphere
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // networkFromPath loads a network via its path.
@@ -69,3 +71,73 @@ func networkProperties(net *object.Network) (*mo.Network, error) {
 	}
 	return &props, nil
 }
+
+// networkNames returns the names of the supplied networks, keyed by managed
+// object ID. The networks can be of any of the network types, as the names are
+// retrieved separately for each type.
+func networkNames(client *govmomi.Client, refs []types.ManagedObjectReference) (map[string]string, error) {
+	byType := make(map[string][]types.ManagedObjectReference)
+	for _, ref := range refs {
+		byType[ref.Type] = append(byType[ref.Type], ref)
+	}
+	names := make(map[string]string)
+	for _, typeRefs := range byType {
+		err := retrievePropertiesPaged(client, typeRefs, []string{"name"}, propertyCollectorPageSize, func(page []types.ObjectContent) error {
+			for _, oc := range page {
+				for _, prop := range oc.PropSet {
+					if name, ok := prop.Val.(string); ok && prop.Name == "name" {
+						names[oc.Obj.Value] = name
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// opaqueNetworkNameFromID returns the name of the opaque network with the
+// opaque network ID id, out of the networks that vm is connected to. The
+// network backing of a virtual ethernet card on an opaque network only refers
+// to the network by this ID, and not by its managed object ID.
+func opaqueNetworkNameFromID(client *govmomi.Client, vm *object.VirtualMachine, id string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"network"}, &props); err != nil {
+		return "", err
+	}
+	var refs []types.ManagedObjectReference
+	for _, ref := range props.Network {
+		if ref.Type == "OpaqueNetwork" {
+			refs = append(refs, ref)
+		}
+	}
+	var nets []mo.OpaqueNetwork
+	err := retrievePropertiesPaged(client, refs, []string{"name", "summary"}, propertyCollectorPageSize, func(page []types.ObjectContent) error {
+		return mo.LoadRetrievePropertiesResponse(&types.RetrievePropertiesResponse{Returnval: page}, &nets)
+	})
+	if err != nil {
+		return "", err
+	}
+	net := opaqueNetworkWithID(nets, id)
+	if net == nil {
+		return "", fmt.Errorf("could not find opaque network with ID %q", id)
+	}
+	return net.Name, nil
+}
+
+// opaqueNetworkWithID returns the network out of nets that has the opaque
+// network ID id, or nil if there is no such network.
+func opaqueNetworkWithID(nets []mo.OpaqueNetwork, id string) *mo.OpaqueNetwork {
+	for i := range nets {
+		summary, ok := nets[i].Summary.(*types.OpaqueNetworkSummary)
+		if ok && summary.OpaqueNetworkId == id {
+			return &nets[i]
+		}
+	}
+	return nil
+}
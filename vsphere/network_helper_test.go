@@ -0,0 +1,36 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testOpaqueNetwork(name, id string) mo.OpaqueNetwork {
+	var net mo.OpaqueNetwork
+	net.Name = name
+	net.Summary = &types.OpaqueNetworkSummary{
+		OpaqueNetworkId:   id,
+		OpaqueNetworkType: "nsx.LogicalSwitch",
+	}
+	return net
+}
+
+func TestOpaqueNetworkWithID(t *testing.T) {
+	nets := []mo.OpaqueNetwork{
+		testOpaqueNetwork("segment-1", "8a7e1b1c-4c6e-4a0b-9d1f-3b4a5e6f7a8b"),
+		testOpaqueNetwork("segment-2", "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"),
+	}
+
+	net := opaqueNetworkWithID(nets, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0")
+	if net == nil {
+		t.Fatalf("expected network, got none")
+	}
+	if net.Name != "segment-2" {
+		t.Fatalf("expected segment-2, got %q", net.Name)
+	}
+	if net := opaqueNetworkWithID(nets, "dvportgroup-21"); net != nil {
+		t.Fatalf("expected no network, got %q", net.Name)
+	}
+}
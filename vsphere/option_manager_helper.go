@@ -0,0 +1,165 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vcenterOptionManager returns the OptionManager that holds the advanced
+// settings of the vCenter server that the client is connected to.
+func vcenterOptionManager(client *govmomi.Client) (*object.OptionManager, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	if client.ServiceContent.Setting == nil {
+		return nil, errors.New("option manager is not available on this connection")
+	}
+	return object.NewOptionManager(client.Client, *client.ServiceContent.Setting), nil
+}
+
+// optionManagerDefinitions returns the option definitions supported by the
+// supplied OptionManager, indexed by key.
+func optionManagerDefinitions(m *object.OptionManager) (map[string]types.OptionDef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.OptionManager
+	if err := m.Properties(ctx, m.Reference(), []string{"supportedOption"}, &props); err != nil {
+		return nil, err
+	}
+	defs := make(map[string]types.OptionDef)
+	for _, def := range props.SupportedOption {
+		defs[def.Key] = def
+	}
+	return defs, nil
+}
+
+// queryOptionValue returns the current value of the option with the supplied
+// key. A nil value is returned if the option does not exist.
+func queryOptionValue(m *object.OptionManager, key string) (*types.OptionValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	values, err := m.Query(ctx, key)
+	if err != nil {
+		if isInvalidNameError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, v := range values {
+		if ov := v.GetOptionValue(); ov.Key == key {
+			return ov, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateOptionValues sets the supplied options on an OptionManager.
+func updateOptionValues(m *object.OptionManager, values []types.BaseOptionValue) error {
+	if len(values) < 1 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return m.Update(ctx, values)
+}
+
+// parseOptionValue converts the string value of an option to the type given
+// by its definition. Values are range checked where the definition has a
+// range, and read-only options are rejected. Options without a definition are
+// passed through as strings.
+func parseOptionValue(def *types.OptionDef, key, raw string) (interface{}, error) {
+	if def == nil || def.OptionType == nil {
+		return raw, nil
+	}
+	if ro := def.OptionType.GetOptionType().ValueIsReadonly; ro != nil && *ro {
+		return nil, fmt.Errorf("option %q is read-only", key)
+	}
+	switch t := def.OptionType.(type) {
+	case *types.IntOption:
+		v, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: expected an integer, got %q", key, raw)
+		}
+		if t.Min != t.Max && (int32(v) < t.Min || int32(v) > t.Max) {
+			return nil, fmt.Errorf("option %q: value %d is outside of the allowed range %d-%d", key, v, t.Min, t.Max)
+		}
+		return int32(v), nil
+	case *types.LongOption:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: expected an integer, got %q", key, raw)
+		}
+		if t.Min != t.Max && (v < t.Min || v > t.Max) {
+			return nil, fmt.Errorf("option %q: value %d is outside of the allowed range %d-%d", key, v, t.Min, t.Max)
+		}
+		return v, nil
+	case *types.FloatOption:
+		v, err := strconv.ParseFloat(raw, 32)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: expected a number, got %q", key, raw)
+		}
+		if t.Min != t.Max && (float32(v) < t.Min || float32(v) > t.Max) {
+			return nil, fmt.Errorf("option %q: value %s is outside of the allowed range %g-%g", key, raw, t.Min, t.Max)
+		}
+		return float32(v), nil
+	case *types.BoolOption:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: expected a boolean, got %q", key, raw)
+		}
+		return v, nil
+	case *types.ChoiceOption:
+		for _, c := range t.ChoiceInfo {
+			if c.GetElementDescription().Key == raw {
+				return raw, nil
+			}
+		}
+		return nil, fmt.Errorf("option %q: %q is not a valid choice", key, raw)
+	}
+	return raw, nil
+}
+
+// optionDefaultValue returns the default value of an option from its
+// definition. The second return value is false if the definition does not
+// carry a default.
+func optionDefaultValue(def *types.OptionDef) (interface{}, bool) {
+	if def == nil || def.OptionType == nil {
+		return nil, false
+	}
+	switch t := def.OptionType.(type) {
+	case *types.IntOption:
+		return t.DefaultValue, true
+	case *types.LongOption:
+		return t.DefaultValue, true
+	case *types.FloatOption:
+		return t.DefaultValue, true
+	case *types.BoolOption:
+		return t.DefaultValue, true
+	case *types.StringOption:
+		return t.DefaultValue, true
+	case *types.ChoiceOption:
+		if int(t.DefaultIndex) < len(t.ChoiceInfo) {
+			return t.ChoiceInfo[t.DefaultIndex].GetElementDescription().Key, true
+		}
+	}
+	return nil, false
+}
+
+// formatOptionValue renders the value of an option as a string, suitable for
+// saving to state.
+func formatOptionValue(v interface{}) string {
+	switch t := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(t), 'g', -1, 32)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,110 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testParseOptionValue struct {
+	Name string
+
+	def         *types.OptionDef
+	raw         string
+	expected    interface{}
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testParseOptionValue) Test(t *testing.T) {
+	actual, err := parseOptionValue(tc.def, "test.option", tc.raw)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestParseOptionValue(t *testing.T) {
+	cases := []testParseOptionValue{
+		{
+			Name:     "no definition",
+			raw:      "64",
+			expected: "64",
+		},
+		{
+			Name:     "int",
+			def:      &types.OptionDef{OptionType: &types.IntOption{Min: 0, Max: 100}},
+			raw:      "64",
+			expected: int32(64),
+		},
+		{
+			Name:        "int out of range",
+			def:         &types.OptionDef{OptionType: &types.IntOption{Min: 0, Max: 100}},
+			raw:         "101",
+			expectedErr: regexp.MustCompile("outside of the allowed range 0-100"),
+		},
+		{
+			Name:        "bad int",
+			def:         &types.OptionDef{OptionType: &types.IntOption{}},
+			raw:         "abc",
+			expectedErr: regexp.MustCompile("expected an integer"),
+		},
+		{
+			Name:     "long",
+			def:      &types.OptionDef{OptionType: &types.LongOption{}},
+			raw:      "4294967296",
+			expected: int64(4294967296),
+		},
+		{
+			Name:     "bool",
+			def:      &types.OptionDef{OptionType: &types.BoolOption{}},
+			raw:      "true",
+			expected: true,
+		},
+		{
+			Name:        "bad bool",
+			def:         &types.OptionDef{OptionType: &types.BoolOption{}},
+			raw:         "yes please",
+			expectedErr: regexp.MustCompile("expected a boolean"),
+		},
+		{
+			Name: "choice",
+			def: &types.OptionDef{OptionType: &types.ChoiceOption{
+				ChoiceInfo: []types.BaseElementDescription{
+					&types.ElementDescription{Key: "low"},
+					&types.ElementDescription{Key: "high"},
+				},
+			}},
+			raw:      "high",
+			expected: "high",
+		},
+		{
+			Name: "bad choice",
+			def: &types.OptionDef{OptionType: &types.ChoiceOption{
+				ChoiceInfo: []types.BaseElementDescription{
+					&types.ElementDescription{Key: "low"},
+				},
+			}},
+			raw:         "high",
+			expectedErr: regexp.MustCompile("not a valid choice"),
+		},
+		{
+			Name: "read-only",
+			def: &types.OptionDef{OptionType: &types.StringOption{
+				OptionType: types.OptionType{ValueIsReadonly: boolPtr(true)},
+			}},
+			raw:         "foo",
+			expectedErr: regexp.MustCompile("read-only"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
@@ -0,0 +1,106 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// propertyCollectorPageSize is the maximum number of objects that are
+// requested in a single call by retrievePropertiesPaged.
+const propertyCollectorPageSize = 100
+
+// retrievePropertiesPaged retrieves the properties in ps for the supplied
+// managed objects a page at a time, using RetrievePropertiesEx with a maximum
+// of pageSize objects per page. fn is called with the results of each page as
+// they arrive, so that the full set of results never needs to be held in a
+// single response. All of the objects need to be of the same type, and all
+// properties are retrieved if ps is nil.
+//
+// If fn returns an error, the rest of the results are cancelled and the error
+// is returned.
+func retrievePropertiesPaged(client *govmomi.Client, objs []types.ManagedObjectReference, ps []string, pageSize int32, fn func([]types.ObjectContent) error) error {
+	if len(objs) < 1 {
+		return nil
+	}
+	propSpec := types.PropertySpec{
+		Type:    objs[0].Type,
+		PathSet: ps,
+	}
+	if ps == nil {
+		propSpec.All = types.NewBool(true)
+	}
+	var objectSet []types.ObjectSpec
+	for _, obj := range objs {
+		if obj.Type != propSpec.Type {
+			return errors.New("object references must have the same type")
+		}
+		objectSet = append(objectSet, types.ObjectSpec{
+			Obj:  obj,
+			Skip: types.NewBool(false),
+		})
+	}
+
+	pc := client.ServiceContent.PropertyCollector
+	req := types.RetrievePropertiesEx{
+		This: pc,
+		SpecSet: []types.PropertyFilterSpec{
+			{
+				ObjectSet: objectSet,
+				PropSet:   []types.PropertySpec{propSpec},
+			},
+		},
+		Options: types.RetrieveOptions{
+			MaxObjects: pageSize,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	res, err := methods.RetrievePropertiesEx(ctx, client, &req)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	for result := res.Returnval; result != nil; {
+		if err := fn(result.Objects); err != nil {
+			if result.Token != "" {
+				cancelRetrievePropertiesEx(client, result.Token)
+			}
+			return err
+		}
+		if result.Token == "" {
+			break
+		}
+		req := types.ContinueRetrievePropertiesEx{
+			This:  pc,
+			Token: result.Token,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		res, err := methods.ContinueRetrievePropertiesEx(ctx, client, &req)
+		cancel()
+		if err != nil {
+			return err
+		}
+		result = &res.Returnval
+	}
+	return nil
+}
+
+// cancelRetrievePropertiesEx releases the results that are left on the server
+// for a paged property retrieval that is not read to the end. Errors are only
+// logged, as the results expire on their own.
+func cancelRetrievePropertiesEx(client *govmomi.Client, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := types.CancelRetrievePropertiesEx{
+		This:  client.ServiceContent.PropertyCollector,
+		Token: token,
+	}
+	if _, err := methods.CancelRetrievePropertiesEx(ctx, client, &req); err != nil {
+		log.Printf("[DEBUG] Error cancelling property retrieval: %s", err)
+	}
+}
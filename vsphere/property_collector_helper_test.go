@@ -0,0 +1,117 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testPagedRoundTripper is a soap.RoundTripper that answers paged property
+// retrievals with pages of at most maxObjects of the requested objects.
+type testPagedRoundTripper struct {
+	objs       []types.ManagedObjectReference
+	maxObjects int
+	offset     int
+	calls      int
+	cancelled  bool
+}
+
+func (rt *testPagedRoundTripper) page() *types.RetrieveResult {
+	end := rt.offset + rt.maxObjects
+	if end > len(rt.objs) {
+		end = len(rt.objs)
+	}
+	var result types.RetrieveResult
+	for _, obj := range rt.objs[rt.offset:end] {
+		result.Objects = append(result.Objects, types.ObjectContent{Obj: obj})
+	}
+	rt.offset = end
+	if end < len(rt.objs) {
+		result.Token = fmt.Sprintf("token-%d", end)
+	}
+	return &result
+}
+
+func (rt *testPagedRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.calls++
+	switch r := req.(type) {
+	case *methods.RetrievePropertiesExBody:
+		for _, os := range r.Req.SpecSet[0].ObjectSet {
+			rt.objs = append(rt.objs, os.Obj)
+		}
+		rt.maxObjects = int(r.Req.Options.MaxObjects)
+		res.(*methods.RetrievePropertiesExBody).Res = &types.RetrievePropertiesExResponse{Returnval: rt.page()}
+	case *methods.ContinueRetrievePropertiesExBody:
+		res.(*methods.ContinueRetrievePropertiesExBody).Res = &types.ContinueRetrievePropertiesExResponse{Returnval: *rt.page()}
+	case *methods.CancelRetrievePropertiesExBody:
+		rt.cancelled = true
+		res.(*methods.CancelRetrievePropertiesExBody).Res = &types.CancelRetrievePropertiesExResponse{}
+	default:
+		return fmt.Errorf("unexpected request %T", req)
+	}
+	return nil
+}
+
+func testPagedClient(rt soap.RoundTripper) *govmomi.Client {
+	return &govmomi.Client{
+		Client: &vim25.Client{
+			RoundTripper: rt,
+			ServiceContent: types.ServiceContent{
+				PropertyCollector: types.ManagedObjectReference{Type: "PropertyCollector", Value: "propertyCollector"},
+			},
+		},
+	}
+}
+
+func testPagedObjects(n int) []types.ManagedObjectReference {
+	var objs []types.ManagedObjectReference
+	for i := 0; i < n; i++ {
+		objs = append(objs, types.ManagedObjectReference{Type: "Datastore", Value: fmt.Sprintf("datastore-%d", i)})
+	}
+	return objs
+}
+
+func TestRetrievePropertiesPaged(t *testing.T) {
+	rt := &testPagedRoundTripper{}
+	var pages, objects int
+	err := retrievePropertiesPaged(testPagedClient(rt), testPagedObjects(25), []string{"summary"}, 10, func(page []types.ObjectContent) error {
+		if len(page) > 10 {
+			t.Fatalf("expected at most 10 objects in page, got %d", len(page))
+		}
+		pages++
+		objects += len(page)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if pages != 3 || objects != 25 {
+		t.Fatalf("expected 25 objects in 3 pages, got %d objects in %d pages", objects, pages)
+	}
+	if rt.cancelled {
+		t.Fatalf("expected retrieval not to be cancelled")
+	}
+}
+
+func TestRetrievePropertiesPagedCancel(t *testing.T) {
+	rt := &testPagedRoundTripper{}
+	err := retrievePropertiesPaged(testPagedClient(rt), testPagedObjects(25), []string{"summary"}, 10, func(page []types.ObjectContent) error {
+		return errors.New("stop")
+	})
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if !rt.cancelled {
+		t.Fatalf("expected remaining results to be cancelled")
+	}
+	if rt.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", rt.calls)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -25,9 +26,24 @@ func Provider() terraform.ResourceProvider {
 
 			"password": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PASSWORD", nil),
-				Description: "The user password for vSphere API operations.",
+				Description: "The user password for vSphere API operations. Required unless client_certificate is set.",
+			},
+
+			"client_certificate": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("VSPHERE_CLIENT_CERTIFICATE", ""),
+				Description:   "The path to a PEM-encoded client certificate to log in with instead of a password. user is then the key of the extension to log in as.",
+				ConflictsWith: []string{"password", "proxy_url"},
+			},
+
+			"client_private_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_PRIVATE_KEY", ""),
+				Description: "The path to the PEM-encoded private key for client_certificate.",
 			},
 
 			"vsphere_server": &schema.Schema{
@@ -42,6 +58,24 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_ALLOW_UNVERIFIED_SSL", false),
 				Description: "If set, VMware vSphere client will permit unverifiable SSL certificates.",
 			},
+			"ca_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_FILE", ""),
+				Description: "The path to a PEM-encoded file of CA certificates to trust when verifying the vSphere server's SSL certificate.",
+			},
+			"ca_pem": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_PEM", ""),
+				Description: "PEM-encoded CA certificates to trust when verifying the vSphere server's SSL certificate.",
+			},
+			"proxy_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PROXY_URL", ""),
+				Description: "The URL of an HTTP proxy to connect to the vSphere server through. If unset, the proxy is taken from the environment.",
+			},
 			"vcenter_server": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -66,33 +100,101 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_PATH", ""),
 				Description: "govomomi debug path for debug",
 			},
+			"persist_session": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PERSIST_SESSION", false),
+				Description: "Save the vSphere session to disk, and reuse it in later runs while it is still valid.",
+			},
+			"vim_session_path": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_VIM_SESSION_PATH", ""),
+				Description: "The directory to save sessions in when persist_session is set. Defaults to ~/.govmomi/sessions.",
+			},
+			"api_max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_MAX_RETRIES", 3),
+				Description:  "The maximum number of times an idempotent API call is retried after a transient error. 0 disables retries.",
+				ValidateFunc: validation.IntBetween(0, 20),
+			},
+			"api_retry_backoff": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_RETRY_BACKOFF", 1),
+				Description:  "The delay in seconds before the first retry of an API call. The delay doubles on each subsequent retry.",
+				ValidateFunc: validation.IntBetween(1, 60),
+			},
+			"session_keep_alive": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_SESSION_KEEP_ALIVE", 10),
+				Description:  "The interval in minutes at which an idle session is kept alive. 0 disables the keep alive.",
+				ValidateFunc: validation.IntBetween(0, 60),
+			},
+			"read_only_refresh": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_READ_ONLY_REFRESH", false),
+				Description: "If set, refreshing resources and data sources makes no changes on the server, such as storage rescans or compliance checks.",
+			},
+			"api_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_TIMEOUT", 0),
+				Description:  "The maximum time in minutes that a single API call or task can take. 0 disables the timeout.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
+			"vsphere_alarm_definition":           resourceVSphereAlarmDefinition(),
 			"vsphere_datacenter":                 resourceVSphereDatacenter(),
 			"vsphere_distributed_port_group":     resourceVSphereDistributedPortGroup(),
 			"vsphere_distributed_virtual_switch": resourceVSphereDistributedVirtualSwitch(),
+			"vsphere_drs_recommendations_apply":  resourceVSphereDrsRecommendationsApply(),
 			"vsphere_file":                       resourceVSphereFile(),
 			"vsphere_folder":                     resourceVSphereFolder(),
+			"vsphere_host_iscsi_port_binding":    resourceVSphereHostIscsiPortBinding(),
+			"vsphere_host_maintenance_mode":      resourceVSphereHostMaintenanceMode(),
 			"vsphere_host_port_group":            resourceVSphereHostPortGroup(),
+			"vsphere_host_reboot":                resourceVSphereHostReboot(),
+			"vsphere_host_profile":               resourceVSphereHostProfile(),
+			"vsphere_host_profile_attachment":    resourceVSphereHostProfileAttachment(),
+			"vsphere_host_snmp_agent":            resourceVSphereHostSnmpAgent(),
 			"vsphere_host_virtual_switch":        resourceVSphereHostVirtualSwitch(),
 			"vsphere_license":                    resourceVSphereLicense(),
+			"vsphere_scheduled_task":             resourceVSphereScheduledTask(),
 			"vsphere_tag":                        resourceVSphereTag(),
 			"vsphere_tag_category":               resourceVSphereTagCategory(),
+			"vsphere_vcenter_advanced_settings":  resourceVSphereVCenterAdvancedSettings(),
+			"vsphere_vcenter_snmp_receivers":     resourceVSphereVCenterSnmpReceivers(),
 			"vsphere_virtual_disk":               resourceVSphereVirtualDisk(),
 			"vsphere_virtual_machine":            resourceVSphereVirtualMachine(),
 			"vsphere_nas_datastore":              resourceVSphereNasDatastore(),
 			"vsphere_vmfs_datastore":             resourceVSphereVmfsDatastore(),
 			"vsphere_virtual_machine_snapshot":   resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_vnic":                       resourceVSphereVNic(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
+			"vsphere_compatible_hosts":           dataSourceVSphereCompatibleHosts(),
 			"vsphere_datacenter":                 dataSourceVSphereDatacenter(),
+			"vsphere_datastore_files":            dataSourceVSphereDatastoreFiles(),
 			"vsphere_distributed_virtual_switch": dataSourceVSphereDistributedVirtualSwitch(),
+			"vsphere_drs_recommendations":        dataSourceVSphereDrsRecommendations(),
+			"vsphere_events":                     dataSourceVSphereEvents(),
+			"vsphere_guest_ids":                  dataSourceVSphereGuestIDs(),
+			"vsphere_hardware_versions":          dataSourceVSphereHardwareVersions(),
 			"vsphere_host":                       dataSourceVSphereHost(),
+			"vsphere_host_datastores":            dataSourceVSphereHostDatastores(),
+			"vsphere_host_physical_nics":         dataSourceVSphereHostPhysicalNics(),
+			"vsphere_host_profile":               dataSourceVSphereHostProfile(),
 			"vsphere_network":                    dataSourceVSphereNetwork(),
 			"vsphere_tag":                        dataSourceVSphereTag(),
 			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
+			"vsphere_virtual_machine":            dataSourceVSphereVirtualMachine(),
 			"vsphere_vmfs_disks":                 dataSourceVSphereVmfsDisks(),
 		},
 
@@ -115,14 +217,30 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 			"One of vsphere_server or [deprecated] vcenter_server must be provided.")
 	}
 
+	if d.Get("password").(string) == "" && d.Get("client_certificate").(string) == "" {
+		return nil, fmt.Errorf("One of password or client_certificate must be provided.")
+	}
+
 	config := Config{
-		User:          d.Get("user").(string),
-		Password:      d.Get("password").(string),
-		InsecureFlag:  d.Get("allow_unverified_ssl").(bool),
-		VSphereServer: server,
-		Debug:         d.Get("client_debug").(bool),
-		DebugPathRun:  d.Get("client_debug_path_run").(string),
-		DebugPath:     d.Get("client_debug_path").(string),
+		User:            d.Get("user").(string),
+		Password:        d.Get("password").(string),
+		InsecureFlag:    d.Get("allow_unverified_ssl").(bool),
+		CAFile:          d.Get("ca_file").(string),
+		CAPEM:           d.Get("ca_pem").(string),
+		ProxyURL:        d.Get("proxy_url").(string),
+		ClientCert:      d.Get("client_certificate").(string),
+		ClientKey:       d.Get("client_private_key").(string),
+		VSphereServer:   server,
+		Debug:           d.Get("client_debug").(bool),
+		DebugPathRun:    d.Get("client_debug_path_run").(string),
+		DebugPath:       d.Get("client_debug_path").(string),
+		PersistSession:  d.Get("persist_session").(bool),
+		VimSessionPath:  d.Get("vim_session_path").(string),
+		MaxRetries:      d.Get("api_max_retries").(int),
+		RetryBackoff:    time.Duration(d.Get("api_retry_backoff").(int)) * time.Second,
+		KeepAlive:       time.Duration(d.Get("session_keep_alive").(int)) * time.Minute,
+		APITimeout:      time.Duration(d.Get("api_timeout").(int)) * time.Minute,
+		ReadOnlyRefresh: d.Get("read_only_refresh").(bool),
 	}
 
 	return config.Client()
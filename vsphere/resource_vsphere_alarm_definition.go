@@ -0,0 +1,164 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereAlarmDefinition() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereAlarmDefinitionCreate,
+		Read:   resourceVSphereAlarmDefinitionRead,
+		Update: resourceVSphereAlarmDefinitionUpdate,
+		Delete: resourceVSphereAlarmDefinitionDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the alarm definition.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the alarm definition.",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the alarm is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"entity_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to define the alarm on. The alarm applies to the entity and all of its children.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"entity_type": {
+				Type:         schema.TypeString,
+				Description:  "The managed object type of the entity to define the alarm on, ie: Datacenter or Datastore.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(alarmEntityTypeAllowedValues, false),
+			},
+			"expression_operator": {
+				Type:         schema.TypeString,
+				Description:  "How multiple expressions are combined. Can be one of or or and.",
+				Optional:     true,
+				Default:      alarmExpressionOperatorOr,
+				ValidateFunc: validation.StringInSlice(alarmExpressionOperatorAllowedValues, false),
+			},
+			"metric_expression": {
+				Type:        schema.TypeList,
+				Description: "A list of metric based expressions that trigger the alarm.",
+				Optional:    true,
+				Elem:        &schema.Resource{Schema: schemaAlarmMetricExpression()},
+			},
+			"event_expression": {
+				Type:        schema.TypeList,
+				Description: "A list of event based expressions that trigger the alarm.",
+				Optional:    true,
+				Elem:        &schema.Resource{Schema: schemaAlarmEventExpression()},
+			},
+			"action": {
+				Type:        schema.TypeList,
+				Description: "A list of actions to run when the alarm changes state.",
+				Optional:    true,
+				Elem:        &schema.Resource{Schema: schemaAlarmAction()},
+			},
+			"action_frequency": {
+				Type:         schema.TypeInt,
+				Description:  "The interval in seconds at which repeating actions run. 0 means actions only run once per transition.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"tolerance_range": {
+				Type:         schema.TypeInt,
+				Description:  "The tolerance range for metric expressions, in hundredths of a percent.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"reporting_frequency": {
+				Type:         schema.TypeInt,
+				Description:  "The minimum interval in seconds between alarm state changes.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+	}
+}
+
+func resourceVSphereAlarmDefinitionCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	counters, err := perfCounterNames(client)
+	if err != nil {
+		return fmt.Errorf("error fetching performance counters: %s", err)
+	}
+	spec, err := expandAlarmSpec(d, counters)
+	if err != nil {
+		return err
+	}
+	entity := types.ManagedObjectReference{
+		Type:  d.Get("entity_type").(string),
+		Value: d.Get("entity_id").(string),
+	}
+
+	id, err := createAlarm(client, entity, spec)
+	if err != nil {
+		return fmt.Errorf("error creating alarm definition: %s", err)
+	}
+	d.SetId(id)
+
+	return resourceVSphereAlarmDefinitionRead(d, meta)
+}
+
+func resourceVSphereAlarmDefinitionRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	props, err := alarmProperties(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Alarm definition %q is gone, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching alarm definition: %s", err)
+	}
+	counters, err := perfCounterNames(client)
+	if err != nil {
+		return fmt.Errorf("error fetching performance counters: %s", err)
+	}
+	return flattenAlarmInfo(d, &props.Info, counters)
+}
+
+func resourceVSphereAlarmDefinitionUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	counters, err := perfCounterNames(client)
+	if err != nil {
+		return fmt.Errorf("error fetching performance counters: %s", err)
+	}
+	spec, err := expandAlarmSpec(d, counters)
+	if err != nil {
+		return err
+	}
+	if err := reconfigureAlarm(client, d.Id(), spec); err != nil {
+		return fmt.Errorf("error updating alarm definition: %s", err)
+	}
+
+	return resourceVSphereAlarmDefinitionRead(d, meta)
+}
+
+func resourceVSphereAlarmDefinitionDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := removeAlarm(client, d.Id()); err != nil {
+		if isManagedObjectNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error removing alarm definition: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereAlarmDefinition(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereAlarmDefinitionCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"metric expression",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereAlarmDefinitionPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereAlarmDefinitionExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereAlarmDefinitionConfigMetric(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereAlarmDefinitionExists(true),
+							testAccResourceVSphereAlarmDefinitionEnabled(true),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "metric_expression.0.metric", "disk.used.latest"),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "metric_expression.0.red", "8500"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable without recreating",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereAlarmDefinitionPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereAlarmDefinitionExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereAlarmDefinitionConfigMetric(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereAlarmDefinitionExists(true),
+							testAccResourceVSphereAlarmDefinitionEnabled(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereAlarmDefinitionConfigMetric(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereAlarmDefinitionExists(true),
+							testAccResourceVSphereAlarmDefinitionEnabled(false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"event expression with actions",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereAlarmDefinitionPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereAlarmDefinitionExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereAlarmDefinitionConfigEvent(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereAlarmDefinitionExists(true),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "event_expression.0.event_type_id", "vim.event.VmPoweredOffEvent"),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "action.#", "2"),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "action.0.type", "email"),
+							resource.TestCheckResourceAttr("vsphere_alarm_definition.alarm", "action.1.type", "snmp"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"unknown metric",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereAlarmDefinitionPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereAlarmDefinitionExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereAlarmDefinitionConfigBadMetric(),
+						ExpectError: regexp.MustCompile("performance counter \"disk.nope.latest\" not found"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereAlarmDefinitionCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereAlarmDefinitionPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_alarm_definition acceptance tests")
+	}
+}
+
+func testAccResourceVSphereAlarmDefinitionExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_alarm_definition.alarm")
+		if err != nil {
+			if !expected {
+				return nil
+			}
+			return err
+		}
+		_, err = alarmProperties(vars.client, vars.resourceID)
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected alarm definition %s to be missing", vars.resourceID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereAlarmDefinitionEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_alarm_definition.alarm")
+		if err != nil {
+			return err
+		}
+		props, err := alarmProperties(vars.client, vars.resourceID)
+		if err != nil {
+			return err
+		}
+		if props.Info.Enabled != expected {
+			return fmt.Errorf("expected alarm definition enabled to be %t, got %t", expected, props.Info.Enabled)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereAlarmDefinitionConfigMetric(enabled bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_alarm_definition" "alarm" {
+  name        = "terraform-test-alarm"
+  entity_id   = "${data.vsphere_datacenter.dc.id}"
+  entity_type = "Datacenter"
+  enabled     = %t
+
+  metric_expression {
+    object_type = "Datastore"
+    metric      = "disk.used.latest"
+    operator    = "is_above"
+    yellow      = 7500
+    red         = 8500
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		enabled,
+	)
+}
+
+func testAccResourceVSphereAlarmDefinitionConfigEvent() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_alarm_definition" "alarm" {
+  name        = "terraform-test-alarm"
+  entity_id   = "${data.vsphere_datacenter.dc.id}"
+  entity_type = "Datacenter"
+
+  event_expression {
+    event_type_id = "vim.event.VmPoweredOffEvent"
+    object_type   = "VirtualMachine"
+    status        = "yellow"
+  }
+
+  action {
+    type            = "email"
+    email_to        = "terraform@example.com"
+    email_subject   = "VM powered off"
+    green_to_yellow = true
+    yellow_to_red   = false
+  }
+
+  action {
+    type            = "snmp"
+    green_to_yellow = true
+    yellow_to_red   = false
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereAlarmDefinitionConfigBadMetric() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_alarm_definition" "alarm" {
+  name        = "terraform-test-alarm"
+  entity_id   = "${data.vsphere_datacenter.dc.id}"
+  entity_type = "Datacenter"
+
+  metric_expression {
+    object_type = "Datastore"
+    metric      = "disk.nope.latest"
+    operator    = "is_above"
+    red         = 8500
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
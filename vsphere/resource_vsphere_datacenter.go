@@ -3,6 +3,8 @@ package vsphere
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -14,28 +16,50 @@ import (
 	"golang.org/x/net/context"
 )
 
+// datacenterFolderKeys maps the resource attributes that manage folders
+// within a datacenter to the folder type that they manage.
+var datacenterFolderKeys = map[string]vSphereFolderType{
+	"vm_folders":        vSphereFolderTypeVM,
+	"host_folders":      vSphereFolderTypeHost,
+	"datastore_folders": vSphereFolderTypeDatastore,
+	"network_folders":   vSphereFolderTypeNetwork,
+}
+
 func resourceVSphereDatacenter() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"folder": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: true,
+		},
+
+		// Add tags schema
+		vSphereTagAttributeKey: tagsSchema(),
+	}
+	for k, ft := range datacenterFolderKeys {
+		s[k] = &schema.Schema{
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: fmt.Sprintf("A list of %s folder paths, relative to the datacenter, to create in the datacenter.", ft),
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateDatacenterFolderPath,
+			},
+		}
+	}
+
 	return &schema.Resource{
 		Create: resourceVSphereDatacenterCreate,
 		Read:   resourceVSphereDatacenterRead,
 		Update: resourceVSphereDatacenterUpdate,
 		Delete: resourceVSphereDatacenterDelete,
 
-		Schema: map[string]*schema.Schema{
-			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"folder": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-
-			// Add tags schema
-			vSphereTagAttributeKey: tagsSchema(),
-		},
+		Schema: s,
 	}
 }
 
@@ -87,6 +111,15 @@ func resourceVSphereDatacenterCreate(d *schema.ResourceData, meta interface{}) e
 		return fmt.Errorf("error waiting for datacenter (%s) to become ready: %s", name, err)
 	}
 
+	// Create any folders that have been requested.
+	for k, ft := range datacenterFolderKeys {
+		for _, p := range sliceInterfacesToStrings(d.Get(k).(*schema.Set).List()) {
+			if err := createDatacenterFolderPath(dc, ft, p); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, dc); err != nil {
@@ -140,6 +173,24 @@ func resourceVSphereDatacenterRead(d *schema.ResourceData, meta interface{}) err
 		return nil
 	}
 
+	// Read back the managed folders. Only folders that are currently tracked in
+	// state are checked, any other folders in the datacenter are ignored.
+	for k, ft := range datacenterFolderKeys {
+		var folders []string
+		for _, p := range sliceInterfacesToStrings(d.Get(k).(*schema.Set).List()) {
+			f, err := datacenterFolderFromPath(dc, ft, p)
+			if err != nil {
+				return fmt.Errorf("error reading %s folder %q: %s", ft, p, err)
+			}
+			if f != nil {
+				folders = append(folders, p)
+			}
+		}
+		if err := d.Set(k, folders); err != nil {
+			return fmt.Errorf("error setting %s: %s", k, err)
+		}
+	}
+
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
 		if err := readTagsForResource(tagsClient, dc, d); err != nil {
@@ -163,6 +214,29 @@ func resourceVSphereDatacenterUpdate(d *schema.ResourceData, meta interface{}) e
 		return fmt.Errorf("couldn't find the specified datacenter: %s", err)
 	}
 
+	// Create new folders, and remove any folders that have been removed from
+	// configuration. Folders that are not empty are left in place.
+	for k, ft := range datacenterFolderKeys {
+		if !d.HasChange(k) {
+			continue
+		}
+		o, n := d.GetChange(k)
+		for _, p := range sortDatacenterFolderPaths(o.(*schema.Set).Difference(n.(*schema.Set)).List()) {
+			deleted, err := deleteDatacenterFolderPath(dc, ft, p)
+			if err != nil {
+				return err
+			}
+			if !deleted {
+				log.Printf("[WARN] Not removing %s folder %q from datacenter %q as it is not empty", ft, p, dc.InventoryPath)
+			}
+		}
+		for _, p := range sliceInterfacesToStrings(n.(*schema.Set).Difference(o.(*schema.Set)).List()) {
+			if err := createDatacenterFolderPath(dc, ft, p); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, dc); err != nil {
@@ -190,6 +264,21 @@ func resourceVSphereDatacenterDelete(d *schema.ResourceData, meta interface{}) e
 		return nil
 	}
 
+	// Remove the folders that we manage before removing the datacenter itself.
+	// As destroying a datacenter destroys everything in it, we refuse to
+	// proceed if any of these folders have been populated.
+	for k, ft := range datacenterFolderKeys {
+		for _, p := range sortDatacenterFolderPaths(d.Get(k).(*schema.Set).List()) {
+			deleted, err := deleteDatacenterFolderPath(dc, ft, p)
+			if err != nil {
+				return err
+			}
+			if !deleted {
+				return fmt.Errorf("%s folder %q is not empty, please remove all items before deleting the datacenter", ft, p)
+			}
+		}
+	}
+
 	req := &types.Destroy_Task{
 		This: dc.Common.Reference(),
 	}
@@ -198,6 +287,7 @@ func resourceVSphereDatacenterDelete(d *schema.ResourceData, meta interface{}) e
 	if err != nil {
 		return fmt.Errorf("%s", err)
 	}
+	meta.(*VSphereClient).forgetDatacenters()
 
 	// Wait for the datacenter resource to be destroyed
 	stateConf := &resource.StateChangeConf{
@@ -216,3 +306,28 @@ func resourceVSphereDatacenterDelete(d *schema.ResourceData, meta interface{}) e
 
 	return nil
 }
+
+// validateDatacenterFolderPath is a ValidateFunc that checks that a managed
+// datacenter folder path is not empty.
+func validateDatacenterFolderPath(v interface{}, k string) (ws []string, errors []error) {
+	if pathIsEmpty(v.(string)) {
+		errors = append(errors, fmt.Errorf("%s: folder path cannot be empty", k))
+	}
+	return
+}
+
+// sortDatacenterFolderPaths returns the supplied folder paths sorted so that
+// the deepest paths come first. This is the order that folders need to be
+// removed in.
+func sortDatacenterFolderPaths(s []interface{}) []string {
+	paths := sliceInterfacesToStrings(s)
+	sort.Slice(paths, func(i, j int) bool {
+		ci := strings.Count(normalizeFolderPath(paths[i]), "/")
+		cj := strings.Count(normalizeFolderPath(paths[j]), "/")
+		if ci != cj {
+			return ci > cj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
@@ -81,6 +81,16 @@ resource "vsphere_datacenter" "testDC" {
 }
 `
 
+const testAccCheckVSphereDatacenterConfigFolders = `
+resource "vsphere_datacenter" "testDC" {
+  name              = "testDC"
+  vm_folders        = ["terraform-test-vms", "terraform-test-vms/nested"]
+  host_folders      = ["terraform-test-hosts"]
+  datastore_folders = ["terraform-test-datastores"]
+  network_folders   = ["terraform-test-networks"]
+}
+`
+
 // Create a datacenter on the root folder
 func TestAccVSphereDatacenter_createOnRootFolder(t *testing.T) {
 
@@ -119,6 +129,31 @@ func TestAccVSphereDatacenter_createOnSubfolder(t *testing.T) {
 	})
 }
 
+// Create a datacenter with a managed folder structure
+func TestAccVSphereDatacenter_createWithFolders(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereDatacenterDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereDatacenterConfigFolders,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereDatacenterExists(
+						testAccCheckVSphereDatacenterResourceName,
+						true,
+					),
+					testAccResourceVSphereDatacenterCheckFolder(vSphereFolderTypeVM, "terraform-test-vms/nested"),
+					testAccResourceVSphereDatacenterCheckFolder(vSphereFolderTypeHost, "terraform-test-hosts"),
+					testAccResourceVSphereDatacenterCheckFolder(vSphereFolderTypeDatastore, "terraform-test-datastores"),
+					testAccResourceVSphereDatacenterCheckFolder(vSphereFolderTypeNetwork, "terraform-test-networks"),
+					resource.TestCheckResourceAttr(testAccCheckVSphereDatacenterResourceName, "vm_folders.#", "2"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccVSphereDatacenterTags(t *testing.T) {
 	var tp *testing.T
 	testAccResourceVSphereNasDatastoreCases := []struct {
@@ -284,3 +319,27 @@ func testAccResourceVSphereDatacenterCheckTags(tagResName string) resource.TestC
 		return testObjectHasTags(s, tagsClient, dc, tagResName)
 	}
 }
+
+// testAccResourceVSphereDatacenterCheckFolder is a check to ensure that a
+// folder of the supplied type exists at the relative path p in the
+// datacenter.
+func testAccResourceVSphereDatacenterCheckFolder(ft vSphereFolderType, p string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, testAccCheckVSphereDatacenterResourceName)
+		if err != nil {
+			return err
+		}
+		dc, err := getDatacenter(vars.client, vars.resourceAttributes["name"])
+		if err != nil {
+			return err
+		}
+		f, err := datacenterFolderFromPath(dc, ft, p)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return fmt.Errorf("%s folder %q not found in datacenter", ft, p)
+		}
+		return nil
+	}
+}
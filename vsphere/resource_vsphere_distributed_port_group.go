@@ -130,6 +130,9 @@ func resourceVSphereDistributedPortGroupUpdate(d *schema.ResourceData, meta inte
 	if err != nil {
 		return fmt.Errorf("could not find portgroup %q: %s", pgID, err)
 	}
+	if err := validateDVPortgroupNumberOfPorts(client, d); err != nil {
+		return err
+	}
 	spec := expandDVPortgroupConfigSpec(d)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
@@ -196,7 +199,7 @@ func resourceVSphereDistributedPortGroupImport(d *schema.ResourceData, meta inte
 	if err != nil {
 		return nil, fmt.Errorf("error fetching portgroup properties: %s", err)
 	}
-	d.SetId(props.Key)
+	d.SetId(pg.Reference().Value)
 
 	// We need to populate the DVS UUID here as well or else our read calls will
 	// fail.
@@ -35,6 +35,66 @@ func TestAccResourceVSphereDistributedPortGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			"ephemeral",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigEphemeral(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "type", "ephemeral"),
+						),
+					},
+					{
+						Config:   testAccResourceVSphereDistributedPortGroupConfigEphemeral(),
+						PlanOnly: true,
+					},
+				},
+			},
+		},
+		{
+			"number of ports",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(16),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckNumPorts(16, false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(32),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckNumPorts(32, false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(8),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckNumPorts(8, false),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "number_of_ports", "8"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "auto_expand", "false"),
+						),
+					},
+				},
+			},
+		},
 		{
 			"inherit policy diff check",
 			resource.TestCase{
@@ -54,6 +114,86 @@ func TestAccResourceVSphereDistributedPortGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			"inherit security and teaming policy",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigPolicyInherit(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckPolicyInherited(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_distributed_port_group.pg",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pg, err := testGetDVPortgroup(s, "pg")
+							if err != nil {
+								return "", err
+							}
+							return pg.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereDistributedPortGroupConfigPolicyInherit(),
+					},
+					{
+						Config:   testAccResourceVSphereDistributedPortGroupConfigPolicyInherit(),
+						PlanOnly: true,
+					},
+				},
+			},
+		},
+		{
+			"override security and teaming policy",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigOverrideSecurityTeaming(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckPolicyInherited(false),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "allow_promiscuous", "false"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "teaming_policy", "loadbalance_srcid"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "active_uplinks.#", "1"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"link aggregation group as active uplink",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigLacpGroupUplink(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "active_uplinks.0", "terraform-test-lag"),
+						),
+					},
+				},
+			},
+		},
 		{
 			"inherit policy diff check (vlan range - typeset edition)",
 			resource.TestCase{
@@ -236,6 +376,40 @@ func testAccResourceVSphereDistributedPortGroupHasVlanRange(emin, emax int32) re
 	}
 }
 
+func testAccResourceVSphereDistributedPortGroupCheckNumPorts(expectedPorts int32, expectedAutoExpand bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		if props.Config.NumPorts != expectedPorts {
+			return fmt.Errorf("expected number of ports to be %d, got %d", expectedPorts, props.Config.NumPorts)
+		}
+		autoExpand := props.Config.AutoExpand != nil && *props.Config.AutoExpand
+		if autoExpand != expectedAutoExpand {
+			return fmt.Errorf("expected auto-expand to be %t, got %t", expectedAutoExpand, autoExpand)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupCheckPolicyInherited(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		pc := props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+		if pc.SecurityPolicy.Inherited != expected {
+			return fmt.Errorf("expected security policy inherited to be %t, got %t", expected, pc.SecurityPolicy.Inherited)
+		}
+		if pc.UplinkTeamingPolicy.Inherited != expected {
+			return fmt.Errorf("expected uplink teaming policy inherited to be %t, got %t", expected, pc.UplinkTeamingPolicy.Inherited)
+		}
+		return nil
+	}
+}
+
 func testAccResourceVSphereDistributedPortGroupCheckTags(tagResName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		dvs, err := testGetDVPortgroup(s, "pg")
@@ -274,6 +448,58 @@ resource "vsphere_distributed_port_group" "pg" {
 	)
 }
 
+func testAccResourceVSphereDistributedPortGroupConfigEphemeral() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  type                            = "ephemeral"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(ports int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  number_of_ports                 = %d
+  auto_expand                     = false
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		ports,
+	)
+}
+
 func testAccResourceVSphereDistributedPortGroupConfigPolicyInherit() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -424,6 +650,80 @@ resource "vsphere_distributed_port_group" "pg" {
 	)
 }
 
+func testAccResourceVSphereDistributedPortGroupConfigOverrideSecurityTeaming() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  active_uplinks  = ["uplink1", "uplink2"]
+  standby_uplinks = ["uplink3", "uplink4"]
+  teaming_policy  = "failover_explicit"
+
+  allow_forged_transmits = true
+  allow_mac_changes      = true
+  allow_promiscuous      = true
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+
+  active_uplinks  = ["uplink1"]
+  standby_uplinks = ["uplink2"]
+  teaming_policy  = "loadbalance_srcid"
+
+  allow_forged_transmits = false
+  allow_mac_changes      = false
+  allow_promiscuous      = false
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigLacpGroupUplink() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name             = "terraform-test-dvs"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  lacp_api_version = "multipleLag"
+
+  lacp_group {
+    name         = "terraform-test-lag"
+    uplink_count = 2
+  }
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+
+  active_uplinks  = ["terraform-test-lag"]
+  standby_uplinks = []
+  teaming_policy  = "failover_explicit"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
 func testAccResourceVSphereDistributedPortGroupConfigSingleTag() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -3,8 +3,10 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -54,6 +56,15 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 	if err != nil {
 		return err
 	}
+	if err := validateDVSNetworkResourceControlVersion(d.Get("version").(string), d.Get("network_resource_control_version").(string)); err != nil {
+		return err
+	}
+	if err := validateDVSLacpAPIVersion(d.Get("version").(string), d.Get("lacp_api_version").(string), d.Get("lacp_group").(*schema.Set).Len()); err != nil {
+		return err
+	}
+	if err := validateDistributedVirtualSwitchHostMembers(d.Get("host").(*schema.Set)); err != nil {
+		return err
+	}
 
 	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
 	if err != nil {
@@ -94,6 +105,25 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 		enableDVSNetworkResourceManagement(client, dvs, true)
 	}
 
+	// Move the physical NICs of any hosts that use migrate_from_standard_switch
+	// over to the DVS, now that the hosts have joined it.
+	if err := migrateDVSHostPhysicalNics(d, client, dvs); err != nil {
+		return err
+	}
+
+	// Assign physical NICs to uplinks on any hosts that use uplink_mapping.
+	if err := updateDVSHostUplinkMapping(d, client, dvs); err != nil {
+		return err
+	}
+
+	// Create any link aggregation groups. This can only be done after the DVS
+	// has been created with the multipleLag LACP API version.
+	if lagSpecs := expandSliceOfVMwareDvsLacpGroupSpec(d, nil); len(lagSpecs) > 0 {
+		if err := updateDVSLacpGroupConfig(client, dvs, lagSpecs); err != nil {
+			return fmt.Errorf("error creating link aggregation groups: %s", err)
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, object.NewReference(client.Client, dvs.Reference())); err != nil {
@@ -138,7 +168,11 @@ func resourceVSphereDistributedVirtualSwitchRead(d *schema.ResourceData, meta in
 	d.Set("folder", normalizeFolderPath(folder))
 
 	// Read in config info
-	if err := flattenVMwareDVSConfigInfo(d, props.Config.(*types.VMwareDVSConfigInfo)); err != nil {
+	uplinkPorts, err := dvsUplinkPorts(client, dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS uplink ports: %s", err)
+	}
+	if err := flattenVMwareDVSConfigInfo(d, props.Config.(*types.VMwareDVSConfigInfo), uplinkPorts); err != nil {
 		return err
 	}
 
@@ -167,32 +201,43 @@ func resourceVSphereDistributedVirtualSwitchUpdate(d *schema.ResourceData, meta
 		return fmt.Errorf("could not find DVS %q: %s", id, err)
 	}
 
-	// If we have a pending version upgrade, do that first.
+	if err := validateDVSNetworkResourceControlVersion(d.Get("version").(string), d.Get("network_resource_control_version").(string)); err != nil {
+		return err
+	}
+	if err := validateDVSLacpAPIVersion(d.Get("version").(string), d.Get("lacp_api_version").(string), d.Get("lacp_group").(*schema.Set).Len()); err != nil {
+		return err
+	}
+	if err := validateDistributedVirtualSwitchHostMembers(d.Get("host").(*schema.Set)); err != nil {
+		return err
+	}
+
+	// If we have a pending version upgrade, do that first. This needs to happen
+	// before the rest of the configuration is sent, as features such as version
+	// 3 of network I/O control are only available after the upgrade.
 	if d.HasChange("version") {
 		old, new := d.GetChange("version")
-		var ovi, nvi int
-		for n, v := range dvsVersions {
-			if old.(string) == v {
-				ovi = n
+		upgrade, err := dvsVersionUpgradeRequired(old.(string), new.(string))
+		if err != nil {
+			return err
+		}
+		if upgrade {
+			log.Printf("[DEBUG] %s: Upgrading DVS from version %s to %s", d.Id(), old, new)
+			if err := upgradeDVS(client, dvs, new.(string)); err != nil {
+				return fmt.Errorf("could not upgrade DVS: %s", err)
 			}
-			if new.(string) == v {
-				nvi = n
+			props, err := dvsProperties(dvs)
+			if err != nil {
+				return fmt.Errorf("could not get DVS properties after upgrade: %s", err)
 			}
+			info := props.Config.(*types.VMwareDVSConfigInfo)
+			if info.ProductInfo.Version != new.(string) {
+				return fmt.Errorf("DVS version is %s after upgrade, expected %s", info.ProductInfo.Version, new)
+			}
+			// ConfigVersion increments after a DVS upgrade, which means this needs
+			// to be updated before the post-update read to ensure that we don't run
+			// into ConcurrentAccess errors on the update operation below.
+			d.Set("config_version", info.ConfigVersion)
 		}
-		if nvi < ovi {
-			return fmt.Errorf("downgrading dvSwitches are not allowed (old: %s new: %s)", old, new)
-		}
-		if err := upgradeDVS(client, dvs, new.(string)); err != nil {
-			return fmt.Errorf("could not upgrade DVS: %s", err)
-		}
-		props, err := dvsProperties(dvs)
-		if err != nil {
-			return fmt.Errorf("could not get DVS properties after upgrade: %s", err)
-		}
-		// ConfigVersion increments after a DVS upgrade, which means this needs to
-		// be updated before the post-update read to ensure that we don't run into
-		// ConcurrentAccess errors on the update operation below.
-		d.Set("config_version", props.Config.(*types.VMwareDVSConfigInfo).ConfigVersion)
 	}
 
 	spec := expandVMwareDVSConfigSpec(d)
@@ -200,11 +245,37 @@ func resourceVSphereDistributedVirtualSwitchUpdate(d *schema.ResourceData, meta
 		return fmt.Errorf("could not update DVS: %s", err)
 	}
 
+	// Move the physical NICs of any hosts that use migrate_from_standard_switch
+	// over to the DVS, now that the hosts have joined it.
+	if err := migrateDVSHostPhysicalNics(d, client, dvs); err != nil {
+		return err
+	}
+
+	// Assign physical NICs to uplinks on any hosts that use uplink_mapping.
+	if err := updateDVSHostUplinkMapping(d, client, dvs); err != nil {
+		return err
+	}
+
 	// Modify network I/O control if necessary
 	if d.HasChange("network_resource_control_enabled") {
 		enableDVSNetworkResourceManagement(client, dvs, d.Get("network_resource_control_enabled").(bool))
 	}
 
+	// Update link aggregation groups. This is done after the main update so
+	// that any change to the LACP API version has been applied first.
+	if d.HasChange("lacp_group") {
+		props, err := dvsProperties(dvs)
+		if err != nil {
+			return fmt.Errorf("could not get DVS properties: %s", err)
+		}
+		lagSpecs := expandSliceOfVMwareDvsLacpGroupSpec(d, props.Config.(*types.VMwareDVSConfigInfo).LacpGroupConfig)
+		if len(lagSpecs) > 0 {
+			if err := updateDVSLacpGroupConfig(client, dvs, lagSpecs); err != nil {
+				return fmt.Errorf("could not update link aggregation groups: %s", err)
+			}
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, object.NewReference(client.Client, dvs.Reference())); err != nil {
@@ -261,3 +332,182 @@ func resourceVSphereDistributedVirtualSwitchImport(d *schema.ResourceData, meta
 	d.SetId(props.Uuid)
 	return []*schema.ResourceData{d}, nil
 }
+
+// migrateDVSHostPhysicalNics moves the physical NICs for any hosts that have
+// migrate_from_standard_switch set from the standard virtual switches on those
+// hosts to the DVS. This needs to happen after the hosts have joined the DVS,
+// so that the NICs are only removed from the standard virtual switches once
+// they have somewhere to go. Hosts whose physical NICs are already connected
+// correctly are skipped.
+func migrateDVSHostPhysicalNics(d *schema.ResourceData, client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch) error {
+	var hosts []map[string]interface{}
+	for _, v := range d.Get("host").(*schema.Set).List() {
+		if m := v.(map[string]interface{}); m["migrate_from_standard_switch"].(bool) {
+			hosts = append(hosts, m)
+		}
+	}
+	if len(hosts) < 1 {
+		return nil
+	}
+
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("could not get DVS properties: %s", err)
+	}
+	info := props.Config.(*types.VMwareDVSConfigInfo)
+	ports, err := dvsUplinkPorts(client, dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS uplink ports: %s", err)
+	}
+
+	for _, m := range hosts {
+		hsID := m["host_system_id"].(string)
+		keys := dvsHostUplinkPortKeysByName(ports, hsID)
+		var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
+		if hostMemberUsesUplinkMapping(m) {
+			pnSpecs, err = expandDistributedVirtualSwitchHostMemberPnicSpecs(m, keys)
+		} else {
+			pnSpecs, err = dvsHostMigrationPnicSpecs(info, hsID, distributedVirtualSwitchHostMemberDevices(m), keys)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] %s: Migrating physical NICs for host %q from standard virtual switches", d.Id(), hsID)
+		if err := migrateHostPhysicalNicsToDVS(client, hsID, props.Uuid, pnSpecs); err != nil {
+			return fmt.Errorf("error migrating physical NICs on host %q: %s", hsID, err)
+		}
+	}
+	return nil
+}
+
+// dvsHostMigrationPnicSpecs returns the physical NIC specs for the supplied
+// devices on a host that is being migrated from standard virtual switches.
+// Unlike a DVS reconfiguration, a host network update does not pick uplink
+// ports for the NICs, so NICs that are already connected keep their uplink
+// port, and the rest are connected to the first free uplinks, in the order the
+// uplinks are defined on the DVS. keys is a map of uplink name to the uplink
+// port key for the host.
+func dvsHostMigrationPnicSpecs(info *types.VMwareDVSConfigInfo, hsID string, devices []string, keys map[string]string) ([]types.DistributedVirtualSwitchHostMemberPnicSpec, error) {
+	current := make(map[string]string)
+	for _, member := range info.Host {
+		if member.Config.Host.Value != hsID {
+			continue
+		}
+		if backing, ok := member.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking); ok {
+			for _, spec := range backing.PnicSpec {
+				current[spec.PnicDevice] = spec.UplinkPortKey
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, device := range devices {
+		if key := current[device]; key != "" {
+			used[key] = true
+		}
+	}
+	var names []string
+	if policy, ok := info.UplinkPortPolicy.(*types.DVSNameArrayUplinkPortPolicy); ok {
+		names = policy.UplinkPortName
+	}
+
+	var pnSpecs []types.DistributedVirtualSwitchHostMemberPnicSpec
+	for _, device := range devices {
+		key := current[device]
+		for _, name := range names {
+			if key != "" {
+				break
+			}
+			if k, ok := keys[name]; ok && !used[k] {
+				key = k
+				used[k] = true
+			}
+		}
+		if key == "" {
+			return nil, fmt.Errorf("no free uplink on host %q for physical NIC %q", hsID, device)
+		}
+		pnSpecs = append(pnSpecs, types.DistributedVirtualSwitchHostMemberPnicSpec{
+			PnicDevice:    device,
+			UplinkPortKey: key,
+		})
+	}
+	return pnSpecs, nil
+}
+
+// updateDVSHostUplinkMapping connects the physical NICs of any hosts that use
+// uplink_mapping to their respective uplinks. This needs to happen after the
+// hosts have joined the DVS, as the uplink ports for a host do not exist
+// before then. Hosts whose physical NICs are already connected correctly are
+// skipped.
+func updateDVSHostUplinkMapping(d *schema.ResourceData, client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch) error {
+	var hosts []map[string]interface{}
+	for _, v := range d.Get("host").(*schema.Set).List() {
+		if m := v.(map[string]interface{}); hostMemberUsesUplinkMapping(m) {
+			hosts = append(hosts, m)
+		}
+	}
+	if len(hosts) < 1 {
+		return nil
+	}
+
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("could not get DVS properties: %s", err)
+	}
+	info := props.Config.(*types.VMwareDVSConfigInfo)
+	ports, err := dvsUplinkPorts(client, dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS uplink ports: %s", err)
+	}
+
+	var specs []types.DistributedVirtualSwitchHostMemberConfigSpec
+	for _, m := range hosts {
+		hsID := m["host_system_id"].(string)
+		pnSpecs, err := expandDistributedVirtualSwitchHostMemberPnicSpecs(m, dvsHostUplinkPortKeysByName(ports, hsID))
+		if err != nil {
+			return err
+		}
+		current := make(map[string]string)
+		for _, member := range info.Host {
+			if member.Config.Host.Value != hsID {
+				continue
+			}
+			if backing, ok := member.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking); ok {
+				for _, spec := range backing.PnicSpec {
+					current[spec.PnicDevice] = spec.UplinkPortKey
+				}
+			}
+		}
+		changed := len(current) != len(pnSpecs)
+		for _, spec := range pnSpecs {
+			if current[spec.PnicDevice] != spec.UplinkPortKey {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		log.Printf("[DEBUG] %s: Updating uplink mapping for host %q", d.Id(), hsID)
+		specs = append(specs, types.DistributedVirtualSwitchHostMemberConfigSpec{
+			Operation: string(types.ConfigSpecOperationEdit),
+			Host:      types.ManagedObjectReference{Type: "HostSystem", Value: hsID},
+			Backing: &types.DistributedVirtualSwitchHostMemberPnicBacking{
+				PnicSpec: pnSpecs,
+			},
+		})
+	}
+	if len(specs) < 1 {
+		return nil
+	}
+
+	spec := &types.VMwareDVSConfigSpec{
+		DVSConfigSpec: types.DVSConfigSpec{
+			ConfigVersion: info.ConfigVersion,
+			Host:          specs,
+		},
+	}
+	if err := updateDVSConfiguration(client, dvs, spec); err != nil {
+		return fmt.Errorf("could not update host uplink mapping: %s", err)
+	}
+	return nil
+}
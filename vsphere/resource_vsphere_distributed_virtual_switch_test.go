@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -159,6 +160,30 @@ func TestAccResourceVSphereDistributedVirtualSwitch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"downgrade version",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigStaticVersion("6.5.0"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasVersion("6.5.0"),
+						),
+					},
+					{
+						Config:      testAccResourceVSphereDistributedVirtualSwitchConfigStaticVersion("6.0.0"),
+						ExpectError: regexp.MustCompile("downgrading dvSwitches are not allowed"),
+					},
+				},
+			},
+		},
 		{
 			"network resource control",
 			resource.TestCase{
@@ -384,6 +409,79 @@ func TestAccResourceVSphereDistributedVirtualSwitch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"uplink mapping",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigUplinkMapping("tfup1", "tfup2"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasUplinkMapping(os.Getenv("VSPHERE_HOST_NIC0"), "tfup1"),
+							testAccResourceVSphereDistributedVirtualSwitchHasUplinkMapping(os.Getenv("VSPHERE_HOST_NIC1"), "tfup2"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigUplinkMapping("tfup2", "tfup1"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasUplinkMapping(os.Getenv("VSPHERE_HOST_NIC0"), "tfup2"),
+							testAccResourceVSphereDistributedVirtualSwitchHasUplinkMapping(os.Getenv("VSPHERE_HOST_NIC1"), "tfup1"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"link aggregation groups",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup("terraform-test-lag", 2),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(4),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup("terraform-test-lag", 4),
+						),
+					},
+				},
+			},
+		},
+		{
+			"link aggregation groups without multipleLag",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroupSingleLag(),
+						ExpectError: regexp.MustCompile("lacp_group requires lacp_api_version to be multipleLag"),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testAccResourceVSphereDistributedVirtualSwitchCases {
@@ -567,6 +665,56 @@ func testAccResourceVSphereDistributedVirtualSwitchHasVlanRange(emin, emax int32
 	}
 }
 
+func testAccResourceVSphereDistributedVirtualSwitchHasUplinkMapping(device, uplink string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		dvs, err := testGetDVS(s, "dvs")
+		if err != nil {
+			return err
+		}
+		props, err := dvsProperties(dvs)
+		if err != nil {
+			return err
+		}
+		ports, err := dvsUplinkPorts(testAccProvider.Meta().(*VSphereClient).vimClient, dvs)
+		if err != nil {
+			return err
+		}
+		names := dvsUplinkPortNamesByKey(ports)
+		for _, member := range props.Config.(*types.VMwareDVSConfigInfo).Host {
+			backing := member.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking)
+			for _, spec := range backing.PnicSpec {
+				if spec.PnicDevice != device {
+					continue
+				}
+				if actual := names[spec.UplinkPortKey]; actual != uplink {
+					return fmt.Errorf("expected %s to be connected to uplink %q, got %q", device, uplink, actual)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("could not find physical NIC %s on DVS", device)
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasLacpGroup(name string, uplinks int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		for _, lag := range props.Config.(*types.VMwareDVSConfigInfo).LacpGroupConfig {
+			if lag.Name != name {
+				continue
+			}
+			if lag.UplinkNum != uplinks {
+				return fmt.Errorf("expected LAG %q to have %d uplinks, got %d", name, uplinks, lag.UplinkNum)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find LAG %q", name)
+	}
+}
+
 func testAccResourceVSphereDistributedVirtualSwitchMatchInventoryPath(expected string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		dvs, err := testGetDVS(s, "dvs")
@@ -1153,3 +1301,111 @@ resource "vsphere_distributed_virtual_switch" "dvs" {
 		os.Getenv("VSPHERE_DATACENTER"),
 	)
 }
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigUplinkMapping(nic0Uplink, nic1Uplink string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "network_interfaces" {
+  default = [
+    "%s",
+    "%s",
+  ]
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  uplinks       = ["tfup1", "tfup2"]
+
+  host {
+    host_system_id = "${data.vsphere_host.host.id}"
+
+    uplink_mapping {
+      device = "${var.network_interfaces[0]}"
+      uplink = "%s"
+    }
+
+    uplink_mapping {
+      device = "${var.network_interfaces[1]}"
+      uplink = "%s"
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_HOST_NIC0"),
+		os.Getenv("VSPHERE_HOST_NIC1"),
+		nic0Uplink,
+		nic1Uplink,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroup(uplinks int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name             = "terraform-test-dvs"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  lacp_api_version = "multipleLag"
+
+  lacp_group {
+    name                     = "terraform-test-lag"
+    uplink_count             = %d
+    mode                     = "passive"
+    load_balancing_algorithm = "srcDestIpTcpUdpPort"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		uplinks,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigLacpGroupSingleLag() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name             = "terraform-test-dvs"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  lacp_api_version = "singleLag"
+
+  lacp_group {
+    name         = "terraform-test-lag"
+    uplink_count = 2
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
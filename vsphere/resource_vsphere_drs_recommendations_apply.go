@@ -0,0 +1,251 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const drsRecommendationsApplyIDPrefix = "tf-DrsRecommendationsApply"
+
+func resourceVSphereDrsRecommendationsApply() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDrsRecommendationsApplyCreate,
+		Read:   resourceVSphereDrsRecommendationsApplyRead,
+		Update: resourceVSphereDrsRecommendationsApplyUpdate,
+		Delete: resourceVSphereDrsRecommendationsApplyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the cluster to apply DRS recommendations on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"keys": {
+				Type:        schema.TypeList,
+				Description: "The keys of the recommendations to apply. Default: all pending recommendations.",
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"refresh": {
+				Type:        schema.TypeBool,
+				Description: "Ask DRS to recalculate the recommendations for the cluster before applying them.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "A map of arbitrary values that causes the recommendations to be applied again when any of them change.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the actions of each applied recommendation to complete.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"disconnect_client_devices": {
+				Type:        schema.TypeBool,
+				Description: "Disconnect the client cdrom, floppy, and USB devices of the virtual machines that a recommendation migrates before applying it, and connect them again once the migration is complete. Connected client devices block vMotion.",
+				Optional:    true,
+			},
+			"applied_keys": {
+				Type:        schema.TypeList,
+				Description: "The keys of the recommendations that were applied.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"recommendations": schemaClusterRecommendation(),
+		},
+	}
+}
+
+func resourceVSphereDrsRecommendationsApplyCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	id := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterComputeResourceFromID(client, id)
+	if err != nil {
+		return err
+	}
+	if d.Get("refresh").(bool) {
+		if err := refreshClusterRecommendations(client, cluster); err != nil {
+			return fmt.Errorf("error refreshing DRS recommendations: %s", err)
+		}
+	}
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+
+	recs := selectClusterRecommendations(props.Recommendation, sliceInterfacesToStrings(d.Get("keys").([]interface{})))
+	timeout := time.Duration(d.Get("timeout").(int)) * time.Minute
+	var applied, errs []string
+	for _, r := range recs {
+		var disconnected map[*object.VirtualMachine]object.VirtualDeviceList
+		if d.Get("disconnect_client_devices").(bool) {
+			var err error
+			disconnected, err = disconnectClusterRecommendationClientDevices(client, r)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("recommendation %q: %s", r.Key, err))
+				continue
+			}
+		}
+		log.Printf("[DEBUG] Applying DRS recommendation %q on cluster %q: %s", r.Key, id, r.ReasonText)
+		if err := applyClusterRecommendation(client, cluster, r.Key); err != nil {
+			errs = append(errs, fmt.Sprintf("recommendation %q: %s", r.Key, err))
+		} else {
+			applied = append(applied, r.Key)
+			for _, a := range r.Action {
+				if err := waitForClusterAction(client, a, timeout); err != nil {
+					errs = append(errs, fmt.Sprintf("recommendation %q: %s", r.Key, err))
+				}
+			}
+		}
+		// Connect the client devices again even if the recommendation failed,
+		// so that a failed apply does not leave them disconnected.
+		for vm, devices := range disconnected {
+			log.Printf("[DEBUG] Connecting client devices of virtual machine %q again", vm.Reference().Value)
+			if err := setVirtualMachineDevicesConnected(vm, devices, true); err != nil {
+				errs = append(errs, fmt.Sprintf("recommendation %q: error connecting client devices of virtual machine %q: %s", r.Key, vm.Reference().Value, err))
+			}
+		}
+	}
+	d.SetId(fmt.Sprintf("%s:%s", drsRecommendationsApplyIDPrefix, id))
+	if err := d.Set("applied_keys", applied); err != nil {
+		return fmt.Errorf("error saving applied recommendations to state: %s", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error applying DRS recommendations: %s", strings.Join(errs, "; "))
+	}
+
+	return resourceVSphereDrsRecommendationsApplyRead(d, meta)
+}
+
+func resourceVSphereDrsRecommendationsApplyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id, err := splitDrsRecommendationsApplyID(d.Id())
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterComputeResourceFromID(client, id)
+	if err != nil {
+		return err
+	}
+	props, err := clusterComputeResourceProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+
+	d.Set("compute_cluster_id", id)
+	if err := d.Set("recommendations", flattenClusterRecommendations(props.Recommendation)); err != nil {
+		return fmt.Errorf("error saving recommendations to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereDrsRecommendationsApplyUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only timeout and disconnect_client_devices can be changed without
+	// applying the recommendations again, so there is nothing to do on the
+	// cluster.
+	return resourceVSphereDrsRecommendationsApplyRead(d, meta)
+}
+
+func resourceVSphereDrsRecommendationsApplyDelete(d *schema.ResourceData, meta interface{}) error {
+	// Applied recommendations cannot be undone, so destroying the resource
+	// just removes it from state.
+	return nil
+}
+
+// selectClusterRecommendations returns the recommendations in recs that have
+// one of the supplied keys, or all of them if no keys are supplied. Keys that
+// do not match a pending recommendation are skipped, as the recommendation has
+// most likely been applied or superseded already.
+func selectClusterRecommendations(recs []types.ClusterRecommendation, keys []string) []types.ClusterRecommendation {
+	if len(keys) < 1 {
+		return recs
+	}
+	pending := make(map[string]types.ClusterRecommendation)
+	for _, r := range recs {
+		pending[r.Key] = r
+	}
+	var result []types.ClusterRecommendation
+	for _, k := range keys {
+		r, ok := pending[k]
+		if !ok {
+			log.Printf("[DEBUG] DRS recommendation %q is no longer pending, skipping", k)
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// disconnectClusterRecommendationClientDevices disconnects the connected
+// client devices of the virtual machines that the migration actions of a
+// recommendation move, and returns the devices that were disconnected for each
+// virtual machine so that they can be connected again afterwards. If
+// disconnecting fails, the devices that were already disconnected are
+// connected again.
+func disconnectClusterRecommendationClientDevices(client *govmomi.Client, r types.ClusterRecommendation) (map[*object.VirtualMachine]object.VirtualDeviceList, error) {
+	disconnected := make(map[*object.VirtualMachine]object.VirtualDeviceList)
+	for _, a := range r.Action {
+		t, ok := a.(*types.ClusterMigrationAction)
+		if !ok || t.DrsMigration == nil {
+			continue
+		}
+		vm := object.NewVirtualMachine(client.Client, t.DrsMigration.Vm)
+		err := func() error {
+			props, err := virtualMachineProperties(vm)
+			if err != nil {
+				return err
+			}
+			if props.Config == nil {
+				return nil
+			}
+			devices := virtualMachineClientDevices(props.Config.Hardware.Device)
+			if len(devices) < 1 {
+				return nil
+			}
+			log.Printf("[DEBUG] Disconnecting %d client devices of virtual machine %q", len(devices), vm.Reference().Value)
+			if err := setVirtualMachineDevicesConnected(vm, devices, false); err != nil {
+				return err
+			}
+			disconnected[vm] = devices
+			return nil
+		}()
+		if err != nil {
+			for vm, devices := range disconnected {
+				if cerr := setVirtualMachineDevicesConnected(vm, devices, true); cerr != nil {
+					log.Printf("[DEBUG] Error connecting client devices of virtual machine %q again: %s", vm.Reference().Value, cerr)
+				}
+			}
+			return nil, fmt.Errorf("error disconnecting client devices of virtual machine %q: %s", t.DrsMigration.Vm.Value, err)
+		}
+	}
+	return disconnected, nil
+}
+
+// splitDrsRecommendationsApplyID splits a vsphere_drs_recommendations_apply
+// resource ID and returns the cluster ID.
+func splitDrsRecommendationsApplyID(raw string) (string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] != drsRecommendationsApplyIDPrefix || s[1] == "" {
+		return "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], nil
+}
@@ -0,0 +1,79 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereDrsRecommendationsApply(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDrsRecommendationsApplyCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"apply all",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccResourceVSphereDrsRecommendationsApplyPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDrsRecommendationsApplyConfig(`refresh = true`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrSet("vsphere_drs_recommendations_apply.apply", "applied_keys.#"),
+							resource.TestCheckResourceAttrSet("vsphere_drs_recommendations_apply.apply", "recommendations.#"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"key no longer pending",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccResourceVSphereDrsRecommendationsApplyPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDrsRecommendationsApplyConfig(`keys = ["terraform-test-missing"]`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vsphere_drs_recommendations_apply.apply", "applied_keys.#", "0"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDrsRecommendationsApplyCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereDrsRecommendationsApplyPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_COMPUTE_CLUSTER_ID to run vsphere_drs_recommendations_apply acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDrsRecommendationsApplyConfig(extra string) string {
+	return fmt.Sprintf(`
+resource "vsphere_drs_recommendations_apply" "apply" {
+  compute_cluster_id = "%s"
+  %s
+}
+`, os.Getenv("VSPHERE_COMPUTE_CLUSTER_ID"), extra)
+}
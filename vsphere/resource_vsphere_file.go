@@ -3,6 +3,7 @@ package vsphere
 import (
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -69,6 +70,19 @@ func resourceVSphereFile() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+
+			"source_hash": {
+				Type:        schema.TypeString,
+				Description: "An arbitrary hash of the content of source_file. The file is uploaded or copied again when this changes.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+
+			"size": {
+				Type:        schema.TypeInt,
+				Description: "The size of the file on the datastore, in bytes.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -142,35 +156,42 @@ func createFile(client *govmomi.Client, f *file) error {
 		return fmt.Errorf("error %s", err)
 	}
 
+	if f.createDirectories {
+		if err := makeParentDirectory(client, ds, dc, f.destinationFile); err != nil {
+			return fmt.Errorf("error %s", err)
+		}
+	}
+
 	if f.copyFile {
-		// Copying file from withing vSphere
+		// Copying file from withing vSphere. The source datastore needs to be
+		// looked up in the source datacenter, which may differ from the
+		// destination.
 		source_dc, err := finder.Datacenter(context.TODO(), f.sourceDatacenter)
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
-		finder = finder.SetDatacenter(dc)
+		finder = finder.SetDatacenter(source_dc)
 
 		source_ds, err := getDatastore(finder, f.sourceDatastore)
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
 
-		fm := object.NewFileManager(client.Client)
-		if f.createDirectories {
-			directoryPathIndex := strings.LastIndex(f.destinationFile, "/")
-			path := f.destinationFile[0:directoryPathIndex]
-			err = fm.MakeDirectory(context.TODO(), ds.Path(path), dc, true)
-			if err != nil {
-				return fmt.Errorf("error %s", err)
-			}
+		var task *object.Task
+		if isVirtualDisk(client, source_ds.Path(f.sourceFile), source_dc) {
+			// Virtual disks are copied with the VirtualDiskManager so that the
+			// descriptor and its extents are copied together.
+			vdm := object.NewVirtualDiskManager(client.Client)
+			task, err = vdm.CopyVirtualDisk(context.TODO(), source_ds.Path(f.sourceFile), source_dc, ds.Path(f.destinationFile), dc, nil, true)
+		} else {
+			fm := object.NewFileManager(client.Client)
+			task, err = fm.CopyDatastoreFile(context.TODO(), source_ds.Path(f.sourceFile), source_dc, ds.Path(f.destinationFile), dc, true)
 		}
-		task, err := fm.CopyDatastoreFile(context.TODO(), source_ds.Path(f.sourceFile), source_dc, ds.Path(f.destinationFile), dc, true)
-
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
 
-		_, err = task.WaitForResult(context.TODO(), nil)
+		_, err = waitForTask(task)
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
@@ -187,6 +208,10 @@ func createFile(client *govmomi.Client, f *file) error {
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
+
+		if err := verifyUploadedFile(ds, f.sourceFile, f.destinationFile); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -241,7 +266,7 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("error %s", err)
 	}
 
-	_, err = ds.Stat(context.TODO(), f.destinationFile)
+	info, err := ds.Stat(context.TODO(), f.destinationFile)
 	if err != nil {
 		log.Printf("[DEBUG] resourceVSphereFileRead - stat failed on: %v", f.destinationFile)
 		d.SetId("")
@@ -250,7 +275,9 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 		if !ok {
 			return err
 		}
+		return nil
 	}
+	d.Set("size", info.GetFileInfo().FileSize)
 
 	return nil
 }
@@ -311,19 +338,31 @@ func resourceVSphereFileUpdate(d *schema.ResourceData, meta interface{}) error {
 			return fmt.Errorf("error %s", err)
 		}
 
+		if d.Get("create_directories").(bool) {
+			if err := makeParentDirectory(client, dsNew, dcNew, newDestinationFile); err != nil {
+				return err
+			}
+		}
+
 		// Move file between old/new dataceter, datastore and path (destination_file)
-		fm := object.NewFileManager(client.Client)
-		task, err := fm.MoveDatastoreFile(context.TODO(), dsOld.Path(oldDestinationFile), dcOld, dsNew.Path(newDestinationFile), dcNew, true)
+		var task *object.Task
+		if isVirtualDisk(client, dsOld.Path(oldDestinationFile), dcOld) {
+			vdm := object.NewVirtualDiskManager(client.Client)
+			task, err = vdm.MoveVirtualDisk(context.TODO(), dsOld.Path(oldDestinationFile), dcOld, dsNew.Path(newDestinationFile), dcNew, true)
+		} else {
+			fm := object.NewFileManager(client.Client)
+			task, err = fm.MoveDatastoreFile(context.TODO(), dsOld.Path(oldDestinationFile), dcOld, dsNew.Path(newDestinationFile), dcNew, true)
+		}
 		if err != nil {
 			return err
 		}
-		_, err = task.WaitForResult(context.TODO(), nil)
+		_, err = waitForTask(task)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return resourceVSphereFileRead(d, meta)
 }
 
 func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
@@ -379,13 +418,19 @@ func deleteFile(client *govmomi.Client, f *file) error {
 		return fmt.Errorf("error %s", err)
 	}
 
-	fm := object.NewFileManager(client.Client)
-	task, err := fm.DeleteDatastoreFile(context.TODO(), ds.Path(f.destinationFile), dc)
+	var task *object.Task
+	if isVirtualDisk(client, ds.Path(f.destinationFile), dc) {
+		vdm := object.NewVirtualDiskManager(client.Client)
+		task, err = vdm.DeleteVirtualDisk(context.TODO(), ds.Path(f.destinationFile), dc)
+	} else {
+		fm := object.NewFileManager(client.Client)
+		task, err = fm.DeleteDatastoreFile(context.TODO(), ds.Path(f.destinationFile), dc)
+	}
 	if err != nil {
 		return err
 	}
 
-	_, err = task.WaitForResult(context.TODO(), nil)
+	_, err = waitForTask(task)
 	if err != nil {
 		return err
 	}
@@ -403,3 +448,53 @@ func getDatastore(f *find.Finder, ds string) (*object.Datastore, error) {
 		return dso, err
 	}
 }
+
+// isVirtualDisk returns true if the file at the supplied datastore path is a
+// virtual disk that can be managed with the VirtualDiskManager. Files that
+// have a .vmdk extension but are not valid disks, such as descriptors with
+// missing extents, are treated as regular files.
+func isVirtualDisk(client *govmomi.Client, name string, dc *object.Datacenter) bool {
+	var dp object.DatastorePath
+	if !dp.FromString(name) || !dp.IsVMDK() {
+		return false
+	}
+	vdm := object.NewVirtualDiskManager(client.Client)
+	if _, err := vdm.QueryVirtualDiskUuid(context.TODO(), name, dc); err != nil {
+		log.Printf("[DEBUG] %s is not a virtual disk, treating as a regular file: %s", name, err)
+		return false
+	}
+	return true
+}
+
+// makeParentDirectory creates the parent directory of a file on a datastore,
+// including any missing parent directories. Nothing is done if the file is in
+// the root of the datastore, or if the directory already exists.
+func makeParentDirectory(client *govmomi.Client, ds *object.Datastore, dc *object.Datacenter, name string) error {
+	i := strings.LastIndex(name, "/")
+	if i < 1 {
+		return nil
+	}
+	fm := object.NewFileManager(client.Client)
+	err := fm.MakeDirectory(context.TODO(), ds.Path(name[0:i]), dc, true)
+	if err != nil && !isFileAlreadyExistsError(err) {
+		return err
+	}
+	return nil
+}
+
+// verifyUploadedFile checks that the size of an uploaded file on a datastore
+// matches the size of the local file it was uploaded from.
+func verifyUploadedFile(ds *object.Datastore, source, destination string) error {
+	local, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("error reading source file: %s", err)
+	}
+	remote, err := ds.Stat(context.TODO(), destination)
+	if err != nil {
+		return fmt.Errorf("error verifying uploaded file: %s", err)
+	}
+	if size := remote.GetFileInfo().FileSize; size != local.Size() {
+		return fmt.Errorf("size of uploaded file %s (%d bytes) does not match source file %s (%d bytes)", destination, size, source, local.Size())
+	}
+	return nil
+}
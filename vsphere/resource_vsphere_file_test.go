@@ -243,6 +243,99 @@ func TestAccVSphereFile_uploadAndCopyAndUpdate(t *testing.T) {
 	os.Remove(sourceFile)
 }
 
+// Copy a virtual disk within vSphere, ensuring that its extents are copied
+// along with the descriptor
+func TestAccVSphereFile_copyVirtualDisk(t *testing.T) {
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+	destinationFile := "tf_file_test_disk_copy.vmdk"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(
+					testAccCheckVSphereFileCopyVirtualDiskConfig,
+					datacenter,
+					datastore,
+					datacenter,
+					datacenter,
+					datastore,
+					datastore,
+					destinationFile,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists("vsphere_file.disk_copy", destinationFile, true),
+					testAccCheckVSphereFileExists("vsphere_file.disk_copy", "tf_file_test_disk_copy-flat.vmdk", true),
+				),
+			},
+		},
+	})
+}
+
+// file upload followed by a change of source_hash, which should upload the
+// file again
+func TestAccVSphereFile_sourceHash(t *testing.T) {
+	testVmdkFileData := []byte("# Disk DescriptorFile\n")
+	testVmdkFile := "/tmp/tf_test.vmdk"
+	err := ioutil.WriteFile(testVmdkFile, testVmdkFileData, 0644)
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+
+	datacenter := os.Getenv("VSPHERE_DATACENTER")
+	datastore := os.Getenv("VSPHERE_DATASTORE")
+	resourceName := "vsphere_file.hash"
+	destinationFile := "tf_file_test_hash.vmdk"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(
+					testAccCheckVSphereFileHashConfig,
+					datacenter,
+					datastore,
+					testVmdkFile,
+					destinationFile,
+					"hash1",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttr(resourceName, "size", fmt.Sprintf("%d", len(testVmdkFileData))),
+				),
+			},
+			{
+				PreConfig: func() {
+					testVmdkFileData = []byte("# Disk DescriptorFile\n# Updated\n")
+					if err := ioutil.WriteFile(testVmdkFile, testVmdkFileData, 0644); err != nil {
+						t.Fatalf("error %s", err)
+					}
+				},
+				Config: fmt.Sprintf(
+					testAccCheckVSphereFileHashConfig,
+					datacenter,
+					datastore,
+					testVmdkFile,
+					destinationFile,
+					"hash2",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttr(resourceName, "source_hash", "hash2"),
+					resource.TestCheckResourceAttr(resourceName, "size", fmt.Sprintf("%d", len("# Disk DescriptorFile\n# Updated\n"))),
+				),
+			},
+		},
+	})
+	os.Remove(testVmdkFile)
+}
+
 func testAccCheckVSphereFileDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*VSphereClient).vimClient
 	finder := find.NewFinder(client.Client, true)
@@ -347,3 +440,29 @@ resource "vsphere_file" "%s" {
 	destination_file = "%s"
 }
 `
+const testAccCheckVSphereFileCopyVirtualDiskConfig = `
+resource "vsphere_virtual_disk" "disk" {
+	size = 1
+	vmdk_path = "tf_file_test_disk.vmdk"
+	datacenter = "%s"
+	datastore = "%s"
+	type = "thin"
+}
+resource "vsphere_file" "disk_copy" {
+	source_datacenter = "%s"
+	datacenter = "%s"
+	source_datastore = "%s"
+	datastore = "%s"
+	source_file = "${vsphere_virtual_disk.disk.vmdk_path}"
+	destination_file = "%s"
+}
+`
+const testAccCheckVSphereFileHashConfig = `
+resource "vsphere_file" "hash" {
+	datacenter = "%s"
+	datastore = "%s"
+	source_file = "%s"
+	destination_file = "%s"
+	source_hash = "%s"
+}
+`
@@ -0,0 +1,199 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostIscsiPortBindingIDPrefix = "tf-HostIscsiPortBinding"
+
+func resourceVSphereHostIscsiPortBinding() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostIscsiPortBindingCreate,
+		Read:   resourceVSphereHostIscsiPortBindingRead,
+		Update: resourceVSphereHostIscsiPortBindingUpdate,
+		Delete: resourceVSphereHostIscsiPortBindingDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host that the iSCSI adapter is on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"iscsi_adapter": {
+				Type:        schema.TypeString,
+				Description: "The device name of the iSCSI adapter to bind the VMkernel adapter to, ie: vmhba65.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"vnic": {
+				Type:        schema.TypeString,
+				Description: "The device name of the VMkernel adapter to bind, ie: vmk1.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"force_unbind": {
+				Type:        schema.TypeBool,
+				Description: "Unbind the VMkernel adapter on destroy even if there are active iSCSI sessions using it.",
+				Optional:    true,
+				Default:     false,
+			},
+			"physical_nic": {
+				Type:        schema.TypeString,
+				Description: "The physical NIC that the VMkernel adapter is using.",
+				Computed:    true,
+			},
+			"port_group": {
+				Type:        schema.TypeString,
+				Description: "The name of the port group that the VMkernel adapter is connected to.",
+				Computed:    true,
+			},
+			"path_status": {
+				Type:        schema.TypeString,
+				Description: "The status of the storage paths that use this binding.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostIscsiPortBindingCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	hba := d.Get("iscsi_adapter").(string)
+	vnic := d.Get("vnic").(string)
+
+	if _, err := hostInternetScsiHbaFromDevice(client, hsID, hba); err != nil {
+		return err
+	}
+	ref, err := iscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+	if err := validateIscsiPortBindingVnic(client, ref, hsID, hba, vnic); err != nil {
+		return err
+	}
+
+	if err := bindIscsiVnic(client, ref, hba, vnic); err != nil {
+		return fmt.Errorf("error binding VMkernel adapter %q to iSCSI adapter %q: %s", vnic, hba, err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s:%s:%s", hostIscsiPortBindingIDPrefix, hsID, hba, vnic))
+
+	return resourceVSphereHostIscsiPortBindingRead(d, meta)
+}
+
+func resourceVSphereHostIscsiPortBindingRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, hba, vnic, err := splitHostIscsiPortBindingID(d.Id())
+	if err != nil {
+		return err
+	}
+	ref, err := iscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+
+	ports, err := queryIscsiBoundVnics(client, ref, hba)
+	if err != nil {
+		return fmt.Errorf("error querying bound VMkernel adapters: %s", err)
+	}
+	port := iscsiPortInfoFromVnic(ports, vnic)
+	if port == nil {
+		log.Printf("[DEBUG] %s: VMkernel adapter %q no longer bound to iSCSI adapter %q", d.Id(), vnic, hba)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("host_system_id", hsID)
+	d.Set("iscsi_adapter", hba)
+	d.Set("vnic", vnic)
+	d.Set("physical_nic", port.PnicDevice)
+	d.Set("port_group", port.PortgroupName)
+	d.Set("path_status", port.PathStatus)
+
+	return nil
+}
+
+func resourceVSphereHostIscsiPortBindingUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only force_unbind can be updated, and it only affects destroy, so there is
+	// nothing to do on the host.
+	return resourceVSphereHostIscsiPortBindingRead(d, meta)
+}
+
+func resourceVSphereHostIscsiPortBindingDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, hba, vnic, err := splitHostIscsiPortBindingID(d.Id())
+	if err != nil {
+		return err
+	}
+	ref, err := iscsiManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+
+	if err := unbindIscsiVnic(client, ref, hba, vnic, d.Get("force_unbind").(bool)); err != nil {
+		return fmt.Errorf("error unbinding VMkernel adapter %q from iSCSI adapter %q: %s", vnic, hba, err)
+	}
+	return nil
+}
+
+// validateIscsiPortBindingVnic checks to make sure that the supplied VMkernel
+// adapter can be bound to the iSCSI adapter. The adapter needs to be a
+// candidate for binding, and be compliant with the iSCSI port binding
+// requirements. For adapters on standard port groups, the effective teaming
+// policy of the port group also needs to have exactly one active uplink and no
+// standby uplinks.
+func validateIscsiPortBindingVnic(client *govmomi.Client, ref types.ManagedObjectReference, hsID, hba, vnic string) error {
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	nic, err := hostVirtualNicFromDevice(client, ns, vnic)
+	if err != nil {
+		return err
+	}
+	if nic.Portgroup != "" {
+		pg, err := hostPortGroupFromName(client, ns, nic.Portgroup)
+		if err != nil {
+			return fmt.Errorf("error fetching port group data: %s", err)
+		}
+		var active, standby []string
+		if pg.ComputedPolicy.NicTeaming != nil && pg.ComputedPolicy.NicTeaming.NicOrder != nil {
+			active = pg.ComputedPolicy.NicTeaming.NicOrder.ActiveNic
+			standby = pg.ComputedPolicy.NicTeaming.NicOrder.StandbyNic
+		}
+		if len(active) != 1 || len(standby) != 0 {
+			return fmt.Errorf("port group %q for VMkernel adapter %q must have exactly one active network adapter and no standby network adapters to be used for iSCSI port binding", nic.Portgroup, vnic)
+		}
+	}
+
+	candidates, err := queryIscsiCandidateNics(client, ref, hba)
+	if err != nil {
+		return fmt.Errorf("error querying candidate VMkernel adapters: %s", err)
+	}
+	port := iscsiPortInfoFromVnic(candidates, vnic)
+	if port == nil {
+		return fmt.Errorf("VMkernel adapter %q is not a candidate for binding to iSCSI adapter %q", vnic, hba)
+	}
+	if reasons := iscsiStatusReasons(port.ComplianceStatus); reasons != "" {
+		return fmt.Errorf("VMkernel adapter %q is not compliant for iSCSI port binding: %s", vnic, reasons)
+	}
+	return nil
+}
+
+// splitHostIscsiPortBindingID splits a vsphere_host_iscsi_port_binding
+// resource ID into its counterparts: the HostSystem ID, the iSCSI adapter
+// name, and the VMkernel adapter name.
+func splitHostIscsiPortBindingID(raw string) (string, string, string, error) {
+	s := strings.SplitN(raw, ":", 4)
+	if len(s) != 4 || s[0] != hostIscsiPortBindingIDPrefix || s[1] == "" || s[2] == "" || s[3] == "" {
+		return "", "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], s[3], nil
+}
@@ -0,0 +1,174 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostIscsiPortBinding(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostIscsiPortBindingCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostIscsiPortBindingPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostIscsiPortBindingExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostIscsiPortBindingConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostIscsiPortBindingExists(true),
+							resource.TestCheckResourceAttr("vsphere_host_iscsi_port_binding.binding", "physical_nic", os.Getenv("VSPHERE_HOST_NIC0")),
+							resource.TestCheckResourceAttr("vsphere_host_iscsi_port_binding.binding", "port_group", "PGTerraformTest"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"non-compliant port group",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostIscsiPortBindingPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostIscsiPortBindingExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereHostIscsiPortBindingConfig(true),
+						ExpectError: regexp.MustCompile("must have exactly one active network adapter"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostIscsiPortBindingCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostIscsiPortBindingPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_host_iscsi_port_binding acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC1") == "" {
+		t.Skip("set VSPHERE_HOST_NIC1 to run vsphere_host_iscsi_port_binding acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_iscsi_port_binding acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ISCSI_ADAPTER") == "" {
+		t.Skip("set VSPHERE_ISCSI_ADAPTER to run vsphere_host_iscsi_port_binding acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostIscsiPortBindingExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_iscsi_port_binding.binding")
+		if err != nil {
+			if !expected {
+				return nil
+			}
+			return err
+		}
+		hsID, hba, vnic, err := splitHostIscsiPortBindingID(vars.resourceID)
+		if err != nil {
+			return err
+		}
+		ref, err := iscsiManagerFromHostSystemID(vars.client, hsID)
+		if err != nil {
+			return err
+		}
+		ports, err := queryIscsiBoundVnics(vars.client, ref, hba)
+		if err != nil {
+			return err
+		}
+		bound := iscsiPortInfoFromVnic(ports, vnic) != nil
+		if expected != bound {
+			return fmt.Errorf("expected VMkernel adapter %s to be bound to %s to be %t, got %t", vnic, hba, expected, bound)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostIscsiPortBindingConfig(bothActive bool) string {
+	active := `["${var.host_nic0}"]`
+	if bothActive {
+		active = `["${var.host_nic0}", "${var.host_nic1}"]`
+	}
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+variable "iscsi_adapter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+
+  active_nics  = %s
+  standby_nics = []
+}
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+}
+
+resource "vsphere_host_iscsi_port_binding" "binding" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  iscsi_adapter  = "${var.iscsi_adapter}"
+  vnic           = "${vsphere_vnic.vnic.device}"
+}
+`,
+		os.Getenv("VSPHERE_HOST_NIC0"),
+		os.Getenv("VSPHERE_HOST_NIC1"),
+		os.Getenv("VSPHERE_ISCSI_ADAPTER"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		active,
+	)
+}
@@ -0,0 +1,175 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+const hostMaintenanceModeIDPrefix = "tf-HostMaintenanceMode"
+
+func resourceVSphereHostMaintenanceMode() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostMaintenanceModeCreate,
+		Read:   resourceVSphereHostMaintenanceModeRead,
+		Update: resourceVSphereHostMaintenanceModeUpdate,
+		Delete: resourceVSphereHostMaintenanceModeDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to manage the maintenance mode of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"maintenance_mode": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host is in maintenance mode.",
+				Optional:    true,
+				Default:     true,
+			},
+			"evacuate_powered_off_vms": {
+				Type:        schema.TypeBool,
+				Description: "When DRS is enabled on the host's cluster, also move powered off and suspended virtual machines off the host when entering maintenance mode. vCenter only.",
+				Optional:    true,
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the host to enter or exit maintenance mode. A value of 0 waits indefinitely.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"initial_maintenance_mode": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host was in maintenance mode when the resource was created. The host is only taken out of maintenance mode on destroy if this is false.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostMaintenanceModeCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	host, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	d.Set("initial_maintenance_mode", props.Runtime.InMaintenanceMode)
+
+	if err := resourceVSphereHostMaintenanceModeApply(d, meta, hsID); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", hostMaintenanceModeIDPrefix, hsID))
+
+	return resourceVSphereHostMaintenanceModeRead(d, meta)
+}
+
+func resourceVSphereHostMaintenanceModeRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, err := splitHostMaintenanceModeID(d.Id())
+	if err != nil {
+		return err
+	}
+	host, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+
+	d.Set("host_system_id", hsID)
+	d.Set("maintenance_mode", props.Runtime.InMaintenanceMode)
+
+	return nil
+}
+
+func resourceVSphereHostMaintenanceModeUpdate(d *schema.ResourceData, meta interface{}) error {
+	hsID, err := splitHostMaintenanceModeID(d.Id())
+	if err != nil {
+		return err
+	}
+	if d.HasChange("maintenance_mode") {
+		if err := resourceVSphereHostMaintenanceModeApply(d, meta, hsID); err != nil {
+			return err
+		}
+	}
+
+	return resourceVSphereHostMaintenanceModeRead(d, meta)
+}
+
+func resourceVSphereHostMaintenanceModeDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, err := splitHostMaintenanceModeID(d.Id())
+	if err != nil {
+		return err
+	}
+	host, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	// Only take the host out of maintenance mode if it was out of it before the
+	// resource was created, so that removing the resource does not put a host
+	// back into service that was in maintenance mode for another reason.
+	if !props.Runtime.InMaintenanceMode || d.Get("initial_maintenance_mode").(bool) {
+		log.Printf("[DEBUG] %s: Leaving host %q in its current maintenance state", d.Id(), props.Name)
+		return nil
+	}
+	if err := hostSystemExitMaintenanceMode(host, d.Get("timeout").(int)); err != nil {
+		return fmt.Errorf("error exiting maintenance mode on host %q: %s", props.Name, err)
+	}
+	return nil
+}
+
+// resourceVSphereHostMaintenanceModeApply enters or exits maintenance mode on
+// the host, depending on the value of maintenance_mode. Nothing is done if the
+// host is already in the requested state.
+func resourceVSphereHostMaintenanceModeApply(d *schema.ResourceData, meta interface{}, hsID string) error {
+	client := meta.(*VSphereClient).vimClient
+	host, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+
+	enabled := d.Get("maintenance_mode").(bool)
+	timeout := d.Get("timeout").(int)
+	switch {
+	case enabled && !props.Runtime.InMaintenanceMode:
+		if err := hostSystemEnterMaintenanceMode(host, timeout, d.Get("evacuate_powered_off_vms").(bool)); err != nil {
+			return fmt.Errorf("error entering maintenance mode on host %q: %s", props.Name, err)
+		}
+	case !enabled && props.Runtime.InMaintenanceMode:
+		if err := hostSystemExitMaintenanceMode(host, timeout); err != nil {
+			return fmt.Errorf("error exiting maintenance mode on host %q: %s", props.Name, err)
+		}
+	}
+	return nil
+}
+
+// splitHostMaintenanceModeID splits a vsphere_host_maintenance_mode resource
+// ID and returns the HostSystem ID.
+func splitHostMaintenanceModeID(raw string) (string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] != hostMaintenanceModeIDPrefix || s[1] == "" {
+		return "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], nil
+}
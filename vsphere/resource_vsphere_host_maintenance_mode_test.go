@@ -0,0 +1,118 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostMaintenanceMode(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostMaintenanceModeCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostMaintenanceModePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostMaintenanceModeCheck(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostMaintenanceModeConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostMaintenanceModeCheck(true),
+							resource.TestCheckResourceAttr("vsphere_host_maintenance_mode.maintenance", "initial_maintenance_mode", "false"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"enter and exit",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostMaintenanceModePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostMaintenanceModeCheck(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostMaintenanceModeConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostMaintenanceModeCheck(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostMaintenanceModeConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostMaintenanceModeCheck(false),
+							resource.TestCheckResourceAttr("vsphere_host_maintenance_mode.maintenance", "maintenance_mode", "false"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostMaintenanceModeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostMaintenanceModePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_maintenance_mode acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostMaintenanceModeCheck(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostSystemOrDefault(client, os.Getenv("VSPHERE_ESXI_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		props, err := hostSystemProperties(hs)
+		if err != nil {
+			return err
+		}
+		if props.Runtime.InMaintenanceMode != expected {
+			return fmt.Errorf("expected host maintenance mode to be %t, got %t", expected, props.Runtime.InMaintenanceMode)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostMaintenanceModeConfig(enabled bool) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_maintenance_mode" "maintenance" {
+  host_system_id   = "${data.vsphere_host.esxi_host.id}"
+  maintenance_mode = %t
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), enabled)
+}
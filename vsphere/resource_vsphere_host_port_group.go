@@ -6,6 +6,9 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func resourceVSphereHostPortGroup() *schema.Resource {
@@ -33,6 +36,12 @@ func resourceVSphereHostPortGroup() *schema.Resource {
 			MaxItems:    1,
 			Elem:        portGroupPortSchema(),
 		},
+		"unused_nics": &schema.Schema{
+			Type:        schema.TypeList,
+			Description: "The network adapters bound to the virtual switch that are not used by this port group, after inheritance.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
 	}
 	mergeSchema(s, schemaHostPortGroupSpec())
 
@@ -59,9 +68,16 @@ func resourceVSphereHostPortGroupCreate(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("error loading network system: %s", err)
 	}
 
+	spec := expandHostPortGroupSpec(d)
+	if err := validateHostPortGroupNicOrderPolicy(client, ns, spec); err != nil {
+		return err
+	}
+	if err := validateHostNetworkTrafficShapingPolicy(spec.Policy.ShapingPolicy); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	spec := expandHostPortGroupSpec(d)
 	if err := ns.AddPortGroup(ctx, *spec); err != nil {
 		return fmt.Errorf("error adding port group: %s", err)
 	}
@@ -81,7 +97,7 @@ func resourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("error loading host network system: %s", err)
 	}
 
-	pg, err := hostPortGroupFromName(meta.(*VSphereClient).vimClient, ns, name)
+	pg, err := hostPortGroupFromName(client, ns, name)
 	if err != nil {
 		return fmt.Errorf("error fetching port group data: %s", err)
 	}
@@ -102,6 +118,22 @@ func resourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("error setting port list: %s", err)
 	}
 
+	sw, err := hostVSwitchFromName(client, ns, pg.Spec.VswitchName)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual switch data: %s", err)
+	}
+	var nics []string
+	if bridge, ok := sw.Spec.Bridge.(*types.HostVirtualSwitchBondBridge); ok {
+		nics = bridge.NicDevice
+	}
+	var order *types.HostNicOrderPolicy
+	if pg.ComputedPolicy.NicTeaming != nil {
+		order = pg.ComputedPolicy.NicTeaming.NicOrder
+	}
+	if err := d.Set("unused_nics", sliceStringsToInterfaces(hostNicOrderPolicyUnusedNics(order, nics))); err != nil {
+		return fmt.Errorf("error setting unused_nics: %s", err)
+	}
+
 	return nil
 }
 
@@ -116,9 +148,16 @@ func resourceVSphereHostPortGroupUpdate(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("error loading host network system: %s", err)
 	}
 
+	spec := expandHostPortGroupSpec(d)
+	if err := validateHostPortGroupNicOrderPolicy(client, ns, spec); err != nil {
+		return err
+	}
+	if err := validateHostNetworkTrafficShapingPolicy(spec.Policy.ShapingPolicy); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	spec := expandHostPortGroupSpec(d)
 	if err := ns.UpdatePortGroup(ctx, name, *spec); err != nil {
 		return fmt.Errorf("error updating port group: %s", err)
 	}
@@ -145,3 +184,21 @@ func resourceVSphereHostPortGroupDelete(d *schema.ResourceData, meta interface{}
 
 	return nil
 }
+
+// validateHostPortGroupNicOrderPolicy validates any NIC order override in the
+// supplied port group spec against the network adapters bound to the port
+// group's virtual switch.
+func validateHostPortGroupNicOrderPolicy(client *govmomi.Client, ns *object.HostNetworkSystem, spec *types.HostPortGroupSpec) error {
+	if spec.Policy.NicTeaming == nil || spec.Policy.NicTeaming.NicOrder == nil {
+		return nil
+	}
+	sw, err := hostVSwitchFromName(client, ns, spec.VswitchName)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual switch data: %s", err)
+	}
+	var nics []string
+	if bridge, ok := sw.Spec.Bridge.(*types.HostVirtualSwitchBondBridge); ok {
+		nics = bridge.NicDevice
+	}
+	return validateHostNicOrderPolicy(spec.Policy.NicTeaming.NicOrder, nics)
+}
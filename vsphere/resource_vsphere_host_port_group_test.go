@@ -1,9 +1,11 @@
 package vsphere
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -87,6 +89,92 @@ func TestAccResourceVSphereHostPortGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			"teaming override with unused NIC, then inherit",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostPortGroupConfigTeamingOverride(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostPortGroupExists(true),
+							testAccResourceVSphereHostPortGroupCheckEffectiveActive([]string{os.Getenv("VSPHERE_HOST_NIC0")}),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "unused_nics.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "unused_nics.0", os.Getenv("VSPHERE_HOST_NIC1")),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "failback", "false"),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "check_beacon", "true"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostPortGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostPortGroupExists(true),
+							testAccResourceVSphereHostPortGroupCheckEffectiveActive([]string{os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1")}),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "unused_nics.#", "0"),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "active_nics.#", "0"),
+						),
+					},
+					{
+						Config:   testAccResourceVSphereHostPortGroupConfig(),
+						PlanOnly: true,
+					},
+				},
+			},
+		},
+		{
+			"traffic shaping override, then inherit",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostPortGroupConfigShaping(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostPortGroupExists(true),
+							testAccResourceVSphereHostPortGroupCheckEffectiveShaping(true, 50000000, 100000000, 1000000000),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostPortGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostPortGroupExists(true),
+							testAccResourceVSphereHostPortGroupCheckEffectiveShaping(false, 0, 0, 0),
+							resource.TestCheckResourceAttr("vsphere_host_port_group.pg", "shaping_enabled", "false"),
+						),
+					},
+					{
+						Config:   testAccResourceVSphereHostPortGroupConfig(),
+						PlanOnly: true,
+					},
+				},
+			},
+		},
+		{
+			"traffic shaping with peak less than average",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereHostPortGroupConfigShapingBadPeak(),
+						ExpectError: regexp.MustCompile("cannot be less than shaping_average_bandwidth"),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testAccResourceVSphereHostPortGroupCases {
@@ -188,6 +276,36 @@ func testAccResourceVSphereHostPortGroupCheckEffectivePromisc(expected bool) res
 	}
 }
 
+func testAccResourceVSphereHostPortGroupCheckEffectiveShaping(enabled bool, average, peak, burst int64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := "pg"
+		pg, err := testGetPortGroup(s, id)
+		if err != nil {
+			return err
+		}
+		policy := pg.ComputedPolicy.ShapingPolicy
+		if policy == nil {
+			return errors.New("port group has no effective traffic shaping policy")
+		}
+		if policy.Enabled == nil || *policy.Enabled != enabled {
+			return fmt.Errorf("expected effective shaping enabled to be %t, got %v", enabled, policy.Enabled)
+		}
+		if !enabled {
+			return nil
+		}
+		if policy.AverageBandwidth != average {
+			return fmt.Errorf("expected effective average bandwidth to be %d, got %d", average, policy.AverageBandwidth)
+		}
+		if policy.PeakBandwidth != peak {
+			return fmt.Errorf("expected effective peak bandwidth to be %d, got %d", peak, policy.PeakBandwidth)
+		}
+		if policy.BurstSize != burst {
+			return fmt.Errorf("expected effective burst size to be %d, got %d", burst, policy.BurstSize)
+		}
+		return nil
+	}
+}
+
 func testAccResourceVSphereHostPortGroupConfig() string {
 	return fmt.Sprintf(`
 variable "host_nic0" {
@@ -265,3 +383,126 @@ resource "vsphere_host_port_group" "pg" {
 }
 `, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
 }
+
+func testAccResourceVSphereHostPortGroupConfigTeamingOverride() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+
+  active_nics  = ["${var.host_nic0}"]
+  standby_nics = []
+  failback     = false
+  check_beacon = true
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostPortGroupConfigShaping() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+
+  shaping_enabled           = true
+  shaping_average_bandwidth = 50000000
+  shaping_peak_bandwidth    = 100000000
+  shaping_burst_size        = 1000000000
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostPortGroupConfigShapingBadPeak() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+
+  shaping_enabled           = true
+  shaping_average_bandwidth = 100000000
+  shaping_peak_bandwidth    = 50000000
+  shaping_burst_size        = 1000000000
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
@@ -0,0 +1,113 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceVSphereHostProfile() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostProfileCreate,
+		Read:   resourceVSphereHostProfileRead,
+		Update: resourceVSphereHostProfileUpdate,
+		Delete: resourceVSphereHostProfileDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the host profile.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the host profile.",
+				Optional:    true,
+			},
+			"reference_host_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to extract the host profile from.",
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"profile_config"},
+			},
+			"profile_config": {
+				Type:          schema.TypeString,
+				Description:   "The serialized configuration to create the host profile from, as exported from another host profile.",
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"reference_host_id"},
+			},
+			"exported_profile_config": {
+				Type:        schema.TypeString,
+				Description: "The serialized configuration of the host profile, as exported by vCenter.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostProfileCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	spec, err := expandHostProfileCreateSpec(d)
+	if err != nil {
+		return err
+	}
+	id, err := createHostProfile(client, spec)
+	if err != nil {
+		return fmt.Errorf("error creating host profile: %s", err)
+	}
+	d.SetId(id)
+	return resourceVSphereHostProfileRead(d, meta)
+}
+
+func resourceVSphereHostProfileRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	props, err := hostProfileProperties(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Host profile %q is gone, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching host profile: %s", err)
+	}
+	// vCenter does not export the configuration in the form that it was
+	// supplied in, so the export goes into its own attribute and
+	// profile_config is left as configured.
+	config, err := exportHostProfile(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error exporting host profile: %s", err)
+	}
+	d.Set("exported_profile_config", config)
+	return flattenHostProfile(d, props)
+}
+
+func resourceVSphereHostProfileUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	props, err := hostProfileProperties(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error fetching host profile: %s", err)
+	}
+	spec, err := expandHostProfileCompleteConfigSpec(d, props)
+	if err != nil {
+		return err
+	}
+	if err := updateHostProfile(client, d.Id(), spec); err != nil {
+		return fmt.Errorf("error updating host profile: %s", err)
+	}
+	return resourceVSphereHostProfileRead(d, meta)
+}
+
+func resourceVSphereHostProfileDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := destroyHostProfile(client, d.Id()); err != nil {
+		return fmt.Errorf("error deleting host profile: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostProfileAttachmentIDPrefix = "tf-HostProfileAttachment"
+
+func resourceVSphereHostProfileAttachment() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostProfileAttachmentCreate,
+		Read:   resourceVSphereHostProfileAttachmentRead,
+		Update: resourceVSphereHostProfileAttachmentUpdate,
+		Delete: resourceVSphereHostProfileAttachmentDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_profile_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host profile to attach.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host_system_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to attach the host profile to.",
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"compute_cluster_id"},
+			},
+			"compute_cluster_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the cluster to attach the host profile to.",
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"host_system_id"},
+			},
+			"remediate": {
+				Type:        schema.TypeBool,
+				Description: "Apply the host profile to any hosts that are not compliant with it.",
+				Optional:    true,
+			},
+			"answer_file_input": {
+				Type:        schema.TypeList,
+				Description: "User input to supply to the host profile when applying it.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"profile_path": {
+							Type:        schema.TypeString,
+							Description: "The path of the profile that requires this input.",
+							Required:    true,
+						},
+						"policy_id": {
+							Type:        schema.TypeString,
+							Description: "The ID of the policy that requires this input.",
+							Optional:    true,
+						},
+						"parameters": {
+							Type:        schema.TypeMap,
+							Description: "The parameters to supply to the policy, keyed by parameter name.",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"compliance_status": {
+				Type:        schema.TypeString,
+				Description: "The compliance status of the attached entity as of the last check.",
+				Computed:    true,
+			},
+			"compliance_failures": {
+				Type:        schema.TypeList,
+				Description: "The messages for any compliance failures found during the last check.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostProfileAttachmentCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	profileID := d.Get("host_profile_id").(string)
+	ref, err := hostProfileAttachmentEntityReference(d)
+	if err != nil {
+		return err
+	}
+	if _, err := hostProfileProperties(client, profileID); err != nil {
+		return fmt.Errorf("error fetching host profile: %s", err)
+	}
+
+	if err := associateHostProfile(client, profileID, ref); err != nil {
+		return fmt.Errorf("error attaching host profile: %s", err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s:%s", hostProfileAttachmentIDPrefix, profileID, ref.Value))
+
+	if d.Get("remediate").(bool) {
+		if err := remediateHostProfileAttachment(d, client, profileID, ref); err != nil {
+			return err
+		}
+	}
+
+	return resourceVSphereHostProfileAttachmentRead(d, meta)
+}
+
+func resourceVSphereHostProfileAttachmentRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	profileID := d.Get("host_profile_id").(string)
+	ref, err := hostProfileAttachmentEntityReference(d)
+	if err != nil {
+		return err
+	}
+
+	props, err := hostProfileProperties(client, profileID)
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Host profile %q is gone, removing attachment from state", d.Id(), profileID)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching host profile: %s", err)
+	}
+	if !hostProfileIsAssociated(props, ref) {
+		log.Printf("[DEBUG] %s: Entity %q no longer attached to host profile %q", d.Id(), ref.Value, profileID)
+		d.SetId("")
+		return nil
+	}
+
+	// A compliance check runs as a task and stores its results on the server,
+	// so in read-only refresh mode the results of the last check are read
+	// instead.
+	var results []types.ComplianceResult
+	if meta.(*VSphereClient).readOnlyRefresh {
+		log.Printf("[WARN] %s: Not checking compliance of %q, as read_only_refresh is set", d.Id(), ref.Value)
+		results, err = queryHostProfileCompliance(client, profileID, ref)
+	} else {
+		results, err = checkHostProfileCompliance(client, profileID, ref)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking host profile compliance: %s", err)
+	}
+	status, failures := flattenHostProfileComplianceResults(results)
+	d.Set("compliance_status", status)
+	if err := d.Set("compliance_failures", failures); err != nil {
+		return fmt.Errorf("error setting compliance_failures: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereHostProfileAttachmentUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	profileID := d.Get("host_profile_id").(string)
+	ref, err := hostProfileAttachmentEntityReference(d)
+	if err != nil {
+		return err
+	}
+
+	if d.Get("remediate").(bool) && (d.HasChange("remediate") || d.HasChange("answer_file_input") ||
+		d.Get("compliance_status").(string) == string(types.ComplianceResultStatusNonCompliant)) {
+		if err := remediateHostProfileAttachment(d, client, profileID, ref); err != nil {
+			return err
+		}
+	}
+
+	return resourceVSphereHostProfileAttachmentRead(d, meta)
+}
+
+func resourceVSphereHostProfileAttachmentDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	profileID := d.Get("host_profile_id").(string)
+	ref, err := hostProfileAttachmentEntityReference(d)
+	if err != nil {
+		return err
+	}
+
+	if err := dissociateHostProfile(client, profileID, ref); err != nil {
+		return fmt.Errorf("error detaching host profile: %s", err)
+	}
+	return nil
+}
+
+// hostProfileAttachmentEntityReference returns the reference for the entity
+// that this host profile attachment is for, validating that exactly one of
+// host_system_id or compute_cluster_id is set.
+func hostProfileAttachmentEntityReference(d *schema.ResourceData) (types.ManagedObjectReference, error) {
+	if v, ok := d.GetOk("host_system_id"); ok {
+		return types.ManagedObjectReference{Type: "HostSystem", Value: v.(string)}, nil
+	}
+	if v, ok := d.GetOk("compute_cluster_id"); ok {
+		return types.ManagedObjectReference{Type: "ClusterComputeResource", Value: v.(string)}, nil
+	}
+	return types.ManagedObjectReference{}, fmt.Errorf("one of host_system_id or compute_cluster_id must be set")
+}
+
+// remediateHostProfileAttachment applies the host profile to every host that
+// is covered by the attachment - either the host itself, or all hosts in the
+// cluster.
+func remediateHostProfileAttachment(d *schema.ResourceData, client *govmomi.Client, profileID string, ref types.ManagedObjectReference) error {
+	var hosts []*object.HostSystem
+	switch ref.Type {
+	case "HostSystem":
+		hs, err := hostSystemFromID(client, ref.Value)
+		if err != nil {
+			return err
+		}
+		hosts = append(hosts, hs)
+	case "ClusterComputeResource":
+		cluster, err := clusterComputeResourceFromID(client, ref.Value)
+		if err != nil {
+			return err
+		}
+		if hosts, err = clusterComputeResourceHosts(cluster); err != nil {
+			return fmt.Errorf("error fetching cluster hosts: %s", err)
+		}
+	}
+
+	input := expandHostProfileDeferredPolicyOptionParameters(d)
+	for _, hs := range hosts {
+		log.Printf("[DEBUG] %s: Applying host profile to host %q", d.Id(), hs.Reference().Value)
+		if err := applyHostProfile(client, profileID, hs, input); err != nil {
+			return fmt.Errorf("error applying host profile to host %q: %s", hs.Reference().Value, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostProfileAttachment(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostProfileAttachmentCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostProfileAttachmentPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostProfileAttachmentExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostProfileAttachmentConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostProfileAttachmentExists(true),
+							resource.TestCheckResourceAttrSet("vsphere_host_profile_attachment.attachment", "compliance_status"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostProfileAttachmentCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostProfileAttachmentPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_HOST_PROFILE_ID") == "" {
+		t.Skip("set VSPHERE_HOST_PROFILE_ID to run vsphere_host_profile_attachment acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_profile_attachment acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostProfileAttachmentExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_host_profile_attachment.attachment")
+		if err != nil {
+			if !expected {
+				return nil
+			}
+			return err
+		}
+		props, err := hostProfileProperties(vars.client, vars.resourceAttributes["host_profile_id"])
+		if err != nil {
+			return err
+		}
+		ref := types.ManagedObjectReference{
+			Type:  "HostSystem",
+			Value: vars.resourceAttributes["host_system_id"],
+		}
+		switch {
+		case hostProfileIsAssociated(props, ref) && !expected:
+			return fmt.Errorf("expected host %q to not be attached to host profile", ref.Value)
+		case !hostProfileIsAssociated(props, ref) && expected:
+			return fmt.Errorf("expected host %q to be attached to host profile", ref.Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostProfileAttachmentConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_profile_attachment" "attachment" {
+  host_profile_id = "%s"
+  host_system_id  = "${data.vsphere_host.esxi_host.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), os.Getenv("VSPHERE_HOST_PROFILE_ID"))
+}
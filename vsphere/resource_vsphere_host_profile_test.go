@@ -0,0 +1,193 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostProfile(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostProfileCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"from reference host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostProfilePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostProfileExists("vsphere_host_profile.profile", false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostProfileConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostProfileExists("vsphere_host_profile.profile", true),
+							resource.TestCheckResourceAttrPair(
+								"vsphere_host_profile.profile", "reference_host_id",
+								"data.vsphere_host.esxi_host", "id",
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostProfilePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostProfileExists("vsphere_host_profile.profile", false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostProfileConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostProfileExists("vsphere_host_profile.profile", true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostProfileConfigRenamed(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostProfileExists("vsphere_host_profile.profile", true),
+							resource.TestCheckResourceAttr("vsphere_host_profile.profile", "name", "terraform-test-profile-renamed"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"from serialized config",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostProfilePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostProfileExists("vsphere_host_profile.copy", false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostProfileConfigSerialized(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostProfileExists("vsphere_host_profile.copy", true),
+							resource.TestCheckResourceAttr("vsphere_host_profile.copy", "name", "terraform-test-profile-copy"),
+							resource.TestCheckResourceAttrSet("vsphere_host_profile.copy", "exported_profile_config"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostProfileCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostProfilePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_profile acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_profile acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostProfileExists(addr string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, addr)
+		if err != nil {
+			if !expected {
+				return nil
+			}
+			return err
+		}
+		_, err = hostProfileProperties(vars.client, vars.resourceID)
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected host profile %q to be missing", vars.resourceID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostProfileConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_profile" "profile" {
+  name              = "terraform-test-profile"
+  description       = "Managed by Terraform"
+  reference_host_id = "${data.vsphere_host.esxi_host.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostProfileConfigRenamed() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_profile" "profile" {
+  name              = "terraform-test-profile-renamed"
+  description       = "Managed by Terraform"
+  reference_host_id = "${data.vsphere_host.esxi_host.id}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostProfileConfigSerialized() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_profile" "profile" {
+  name              = "terraform-test-profile"
+  reference_host_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+data "vsphere_host_profile" "profile" {
+  name = "${vsphere_host_profile.profile.name}"
+}
+
+resource "vsphere_host_profile" "copy" {
+  name           = "terraform-test-profile-copy"
+  profile_config = "${data.vsphere_host_profile.profile.profile_config}"
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
@@ -0,0 +1,126 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+const hostRebootIDPrefix = "tf-HostReboot"
+
+func resourceVSphereHostReboot() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostRebootCreate,
+		Read:   resourceVSphereHostRebootRead,
+		Update: resourceVSphereHostRebootUpdate,
+		Delete: resourceVSphereHostRebootDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to reboot.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "A map of arbitrary values that causes the host to be rebooted again when any of them change.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"evacuate_powered_off_vms": {
+				Type:        schema.TypeBool,
+				Description: "When DRS is enabled on the host's cluster, also move powered off and suspended virtual machines off the host when entering maintenance mode.",
+				Optional:    true,
+			},
+			"exit_maintenance_mode": {
+				Type:        schema.TypeBool,
+				Description: "Take the host out of maintenance mode once it has reconnected after the reboot.",
+				Optional:    true,
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for each of entering maintenance mode, rebooting and reconnecting, and exiting maintenance mode. A value of 0 waits indefinitely.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+	}
+}
+
+func resourceVSphereHostRebootCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	hsID := d.Get("host_system_id").(string)
+	host, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	timeout := d.Get("timeout").(int)
+
+	if !props.Runtime.InMaintenanceMode {
+		log.Printf("[DEBUG] Entering maintenance mode on host %q", props.Name)
+		if err := hostSystemEnterMaintenanceMode(host, timeout, d.Get("evacuate_powered_off_vms").(bool)); err != nil {
+			return fmt.Errorf("error entering maintenance mode on host %q: %s", props.Name, err)
+		}
+	}
+	log.Printf("[DEBUG] Rebooting host %q", props.Name)
+	if err := hostSystemReboot(client, host, timeout); err != nil {
+		return fmt.Errorf("error rebooting host %q: %s", props.Name, err)
+	}
+	if d.Get("exit_maintenance_mode").(bool) {
+		log.Printf("[DEBUG] Exiting maintenance mode on host %q", props.Name)
+		if err := hostSystemExitMaintenanceMode(host, timeout); err != nil {
+			return fmt.Errorf("error exiting maintenance mode on host %q: %s", props.Name, err)
+		}
+	}
+	d.SetId(fmt.Sprintf("%s:%s", hostRebootIDPrefix, hsID))
+
+	return resourceVSphereHostRebootRead(d, meta)
+}
+
+func resourceVSphereHostRebootRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, err := splitHostRebootID(d.Id())
+	if err != nil {
+		return err
+	}
+	if _, err := hostSystemFromID(client, hsID); err != nil {
+		return err
+	}
+	d.Set("host_system_id", hsID)
+
+	return nil
+}
+
+func resourceVSphereHostRebootUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only the options that control how the next reboot runs can be updated,
+	// so there is nothing to do on the host.
+	return resourceVSphereHostRebootRead(d, meta)
+}
+
+func resourceVSphereHostRebootDelete(d *schema.ResourceData, meta interface{}) error {
+	// A reboot cannot be undone, so destroying the resource just removes it
+	// from state.
+	return nil
+}
+
+// splitHostRebootID splits a vsphere_host_reboot resource ID and returns the
+// HostSystem ID.
+func splitHostRebootID(raw string) (string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] != hostRebootIDPrefix || s[1] == "" {
+		return "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], nil
+}
@@ -0,0 +1,134 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostReboot(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostRebootCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"stay in maintenance mode",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccResourceVSphereHostRebootPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostRebootConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostRebootCheck(true),
+						),
+					},
+					{
+						// Take the host back out of maintenance mode for the next test.
+						Config: testAccResourceVSphereHostRebootConfigExitMaintenance(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostRebootCheck(false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"exit maintenance mode",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+					testAccResourceVSphereHostRebootPreCheck(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostRebootConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostRebootCheck(false),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostRebootCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostRebootPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_reboot acceptance tests")
+	}
+}
+
+// testAccResourceVSphereHostRebootCheck checks that the host is connected,
+// and in the expected maintenance mode state.
+func testAccResourceVSphereHostRebootCheck(maintenance bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostSystemOrDefault(client, os.Getenv("VSPHERE_ESXI_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		props, err := hostSystemProperties(hs)
+		if err != nil {
+			return err
+		}
+		if props.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+			return fmt.Errorf("expected host to be connected, got %s", props.Runtime.ConnectionState)
+		}
+		if props.Runtime.InMaintenanceMode != maintenance {
+			return fmt.Errorf("expected host maintenance mode to be %t, got %t", maintenance, props.Runtime.InMaintenanceMode)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostRebootConfig(exit bool) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_reboot" "reboot" {
+  host_system_id        = "${data.vsphere_host.esxi_host.id}"
+  exit_maintenance_mode = %t
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), exit)
+}
+
+func testAccResourceVSphereHostRebootConfigExitMaintenance() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_maintenance_mode" "maintenance" {
+  host_system_id   = "${data.vsphere_host.esxi_host.id}"
+  maintenance_mode = false
+}
+`, testAccResourceVSphereHostRebootConfig(false))
+}
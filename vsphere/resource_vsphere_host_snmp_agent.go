@@ -0,0 +1,138 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostSnmpAgentIDPrefix = "tf-HostSnmpAgent"
+
+func resourceVSphereHostSnmpAgent() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"host_system_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the host to configure the SNMP agent on.",
+			Required:    true,
+			ForceNew:    true,
+		},
+	}
+	mergeSchema(s, schemaHostSnmpConfigSpec())
+
+	return &schema.Resource{
+		Create: resourceVSphereHostSnmpAgentCreate,
+		Read:   resourceVSphereHostSnmpAgentRead,
+		Update: resourceVSphereHostSnmpAgentUpdate,
+		Delete: resourceVSphereHostSnmpAgentDelete,
+		Schema: s,
+	}
+}
+
+func resourceVSphereHostSnmpAgentCreate(d *schema.ResourceData, meta interface{}) error {
+	hsID := d.Get("host_system_id").(string)
+	if err := resourceVSphereHostSnmpAgentApply(d, meta, hsID); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", hostSnmpAgentIDPrefix, hsID))
+
+	return resourceVSphereHostSnmpAgentRead(d, meta)
+}
+
+func resourceVSphereHostSnmpAgentRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, err := splitHostSnmpAgentID(d.Id())
+	if err != nil {
+		return err
+	}
+	ref, err := hostSnmpSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSnmpSystemProperties(client, ref)
+	if err != nil {
+		return fmt.Errorf("error fetching SNMP agent configuration: %s", err)
+	}
+
+	d.Set("host_system_id", hsID)
+	if err := flattenHostSnmpConfigSpec(d, props.Configuration); err != nil {
+		return fmt.Errorf("error setting resource data: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereHostSnmpAgentUpdate(d *schema.ResourceData, meta interface{}) error {
+	hsID, err := splitHostSnmpAgentID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := resourceVSphereHostSnmpAgentApply(d, meta, hsID); err != nil {
+		return err
+	}
+
+	return resourceVSphereHostSnmpAgentRead(d, meta)
+}
+
+func resourceVSphereHostSnmpAgentDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, err := splitHostSnmpAgentID(d.Id())
+	if err != nil {
+		return err
+	}
+	ref, err := hostSnmpSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSnmpSystemProperties(client, ref)
+	if err != nil {
+		return fmt.Errorf("error fetching SNMP agent configuration: %s", err)
+	}
+
+	// Disable the agent and remove the communities and trap targets. The agent
+	// options, such as the SNMPv3 engine ID, are left as they are.
+	spec := types.HostSnmpConfigSpec{
+		Enabled: boolPtr(false),
+		Port:    props.Configuration.Port,
+		Option:  props.Configuration.Option,
+	}
+	if err := reconfigureHostSnmpAgent(client, ref, spec); err != nil {
+		return fmt.Errorf("error disabling SNMP agent: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereHostSnmpAgentApply validates the configuration in the
+// ResourceData against the limits of the SNMP agent on the host, and applies
+// it.
+func resourceVSphereHostSnmpAgentApply(d *schema.ResourceData, meta interface{}, hsID string) error {
+	client := meta.(*VSphereClient).vimClient
+	ref, err := hostSnmpSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return err
+	}
+	props, err := hostSnmpSystemProperties(client, ref)
+	if err != nil {
+		return fmt.Errorf("error fetching SNMP agent configuration: %s", err)
+	}
+
+	spec := expandHostSnmpConfigSpec(d, props.Configuration.Option)
+	if err := validateHostSnmpConfigSpec(spec, props.Limits); err != nil {
+		return err
+	}
+	if err := reconfigureHostSnmpAgent(client, ref, spec); err != nil {
+		return fmt.Errorf("error configuring SNMP agent: %s", err)
+	}
+	return nil
+}
+
+// splitHostSnmpAgentID splits a vsphere_host_snmp_agent resource ID and
+// returns the HostSystem ID.
+func splitHostSnmpAgentID(raw string) (string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] != hostSnmpAgentIDPrefix || s[1] == "" {
+		return "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], nil
+}
@@ -0,0 +1,131 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereHostSnmpAgent(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereHostSnmpAgentCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostSnmpAgentPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostSnmpAgentEnabled(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostSnmpAgentConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostSnmpAgentEnabled(true),
+							resource.TestCheckResourceAttr("vsphere_host_snmp_agent.snmp", "read_only_communities.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_host_snmp_agent.snmp", "trap_target.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_host_snmp_agent.snmp", "trap_target.0.host_name", "10.0.0.10"),
+							resource.TestCheckResourceAttrSet("vsphere_host_snmp_agent.snmp", "engine_id"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostSnmpAgentPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostSnmpAgentEnabled(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostSnmpAgentConfig(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostSnmpAgentEnabled(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereHostSnmpAgentConfig(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostSnmpAgentEnabled(false),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereHostSnmpAgentCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereHostSnmpAgentPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_snmp_agent acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostSnmpAgentEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostSystemOrDefault(client, os.Getenv("VSPHERE_ESXI_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		ref, err := hostSnmpSystemFromHostSystemID(client, hs.Reference().Value)
+		if err != nil {
+			return err
+		}
+		props, err := hostSnmpSystemProperties(client, ref)
+		if err != nil {
+			return err
+		}
+		actual := props.Configuration.Enabled != nil && *props.Configuration.Enabled
+		if actual != expected {
+			return fmt.Errorf("expected SNMP agent enabled to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostSnmpAgentConfig(enabled bool) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_snmp_agent" "snmp" {
+  host_system_id        = "${data.vsphere_host.esxi_host.id}"
+  enabled               = %t
+  read_only_communities = ["terraform"]
+
+  trap_target {
+    host_name = "10.0.0.10"
+    community = "terraform"
+  }
+}
+`, os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), enabled)
+}
@@ -6,6 +6,9 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func resourceVSphereHostVirtualSwitch() *schema.Resource {
@@ -22,6 +25,12 @@ func resourceVSphereHostVirtualSwitch() *schema.Resource {
 			Required:    true,
 			ForceNew:    true,
 		},
+		"unused_nics": &schema.Schema{
+			Type:        schema.TypeList,
+			Description: "The network adapters bound to this virtual switch that are not listed as active or standby.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
 	}
 	mergeSchema(s, schemaHostVirtualSwitchSpec())
 
@@ -59,9 +68,16 @@ func resourceVSphereHostVirtualSwitchCreate(d *schema.ResourceData, meta interfa
 		return fmt.Errorf("error loading host network system: %s", err)
 	}
 
+	spec := expandHostVirtualSwitchSpec(d)
+	if err := validateHostNicOrderPolicy(spec.Policy.NicTeaming.NicOrder, spec.Bridge.(*types.HostVirtualSwitchBondBridge).NicDevice); err != nil {
+		return err
+	}
+	if err := validateHostNetworkTrafficShapingPolicy(spec.Policy.ShapingPolicy); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	spec := expandHostVirtualSwitchSpec(d)
 	if err := ns.AddVirtualSwitch(ctx, name, spec); err != nil {
 		return fmt.Errorf("error adding host vSwitch: %s", err)
 	}
@@ -90,6 +106,17 @@ func resourceVSphereHostVirtualSwitchRead(d *schema.ResourceData, meta interface
 	if err := flattenHostVirtualSwitchSpec(d, &sw.Spec); err != nil {
 		return fmt.Errorf("error setting resource data: %s", err)
 	}
+	var nics []string
+	if bridge, ok := sw.Spec.Bridge.(*types.HostVirtualSwitchBondBridge); ok {
+		nics = bridge.NicDevice
+	}
+	var order *types.HostNicOrderPolicy
+	if sw.Spec.Policy != nil && sw.Spec.Policy.NicTeaming != nil {
+		order = sw.Spec.Policy.NicTeaming.NicOrder
+	}
+	if err := d.Set("unused_nics", sliceStringsToInterfaces(hostNicOrderPolicyUnusedNics(order, nics))); err != nil {
+		return fmt.Errorf("error setting unused_nics: %s", err)
+	}
 
 	return nil
 }
@@ -105,9 +132,22 @@ func resourceVSphereHostVirtualSwitchUpdate(d *schema.ResourceData, meta interfa
 		return fmt.Errorf("error loading host network system: %s", err)
 	}
 
+	spec := expandHostVirtualSwitchSpec(d)
+	if err := validateHostNicOrderPolicy(spec.Policy.NicTeaming.NicOrder, spec.Bridge.(*types.HostVirtualSwitchBondBridge).NicDevice); err != nil {
+		return err
+	}
+	if err := validateHostNetworkTrafficShapingPolicy(spec.Policy.ShapingPolicy); err != nil {
+		return err
+	}
+
+	if d.HasChange("mtu") {
+		if err := validateHostVirtualSwitchMtu(client, ns, name, spec.Mtu); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	spec := expandHostVirtualSwitchSpec(d)
 	if err := ns.UpdateVirtualSwitch(ctx, name, *spec); err != nil {
 		return fmt.Errorf("error updating host vSwitch: %s", err)
 	}
@@ -134,3 +174,19 @@ func resourceVSphereHostVirtualSwitchDelete(d *schema.ResourceData, meta interfa
 
 	return nil
 }
+
+// validateHostVirtualSwitchMtu checks to make sure that the MTU of a virtual
+// switch is not being lowered below the MTU of any VMkernel adapter connected
+// to it, which would cause the adapter to drop traffic.
+func validateHostVirtualSwitchMtu(client *govmomi.Client, ns *object.HostNetworkSystem, name string, mtu int32) error {
+	nics, err := hostVSwitchVirtualNics(client, ns, name)
+	if err != nil {
+		return err
+	}
+	for _, nic := range nics {
+		if nic.Spec.Mtu > mtu {
+			return fmt.Errorf("mtu (%d) cannot be lower than the MTU of VMkernel adapter %q (%d) on this virtual switch", mtu, nic.Device, nic.Spec.Mtu)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -104,6 +105,84 @@ func TestAccResourceVSphereHostVirtualSwitch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"unused NIC with beacon probing and no failback",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostVirtualSwitchConfigUnusedNIC(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostVirtualSwitchExists(true),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "unused_nics.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "unused_nics.0", os.Getenv("VSPHERE_HOST_NIC1")),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "check_beacon", "true"),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "failback", "false"),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "notify_switches", "false"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"active NIC not bound to switch",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereHostVirtualSwitchConfigUnboundNIC(),
+						ExpectError: regexp.MustCompile("is not bound to the virtual switch"),
+					},
+				},
+			},
+		},
+		{
+			"jumbo frames",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereHostVirtualSwitchConfigMtu(9000),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereHostVirtualSwitchExists(true),
+							resource.TestCheckResourceAttr("vsphere_host_virtual_switch.switch", "mtu", "9000"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"MTU out of range",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereHostVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereHostVirtualSwitchConfigMtu(1000),
+						ExpectError: regexp.MustCompile("expected mtu to be in the range"),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testAccResourceVSphereHostVirtualSwitchCases {
@@ -246,3 +325,96 @@ resource "vsphere_host_virtual_switch" "switch" {
 }
 `, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
 }
+
+func testAccResourceVSphereHostVirtualSwitchConfigUnusedNIC() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+
+  active_nics     = ["${var.host_nic0}"]
+  standby_nics    = []
+  check_beacon    = true
+  failback        = false
+  notify_switches = false
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostVirtualSwitchConfigUnboundNIC() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}"]
+
+  active_nics  = ["${var.host_nic0}"]
+  standby_nics = ["${var.host_nic1}"]
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereHostVirtualSwitchConfigMtu(mtu int) string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  mtu            = %d
+
+  network_adapters = ["${var.host_nic0}"]
+
+  active_nics  = ["${var.host_nic0}"]
+  standby_nics = []
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), mtu)
+}
@@ -0,0 +1,179 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceVSphereScheduledTask() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereScheduledTaskCreate,
+		Read:   resourceVSphereScheduledTaskRead,
+		Update: resourceVSphereScheduledTaskUpdate,
+		Delete: resourceVSphereScheduledTaskDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the scheduled task.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the scheduled task.",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the scheduled task is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"notification": {
+				Type:        schema.TypeString,
+				Description: "An email address to send a notification to when the task completes.",
+				Optional:    true,
+			},
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine that the task runs against.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"action": {
+				Type:         schema.TypeString,
+				Description:  "The action that the task runs. Can be one of power_on, power_off, create_snapshot, or remove_all_snapshots.",
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(scheduledTaskActionAllowedValues, false),
+			},
+			"snapshot_name": {
+				Type:        schema.TypeString,
+				Description: "The name of the snapshot to create, for the create_snapshot action.",
+				Optional:    true,
+			},
+			"snapshot_description": {
+				Type:        schema.TypeString,
+				Description: "The description of the snapshot to create, for the create_snapshot action.",
+				Optional:    true,
+			},
+			"snapshot_memory": {
+				Type:        schema.TypeBool,
+				Description: "Include the memory of the virtual machine in the snapshot, for the create_snapshot action.",
+				Optional:    true,
+			},
+			"snapshot_quiesce": {
+				Type:        schema.TypeBool,
+				Description: "Quiesce the file system of the virtual machine when taking the snapshot, for the create_snapshot action.",
+				Optional:    true,
+			},
+			"run_at": {
+				Type:             schema.TypeString,
+				Description:      "The time, in RFC3339 format, to run a one-time task at. Conflicts with recurrence.",
+				Optional:         true,
+				ConflictsWith:    []string{"recurrence"},
+				ValidateFunc:     validateRFC3339Time,
+				DiffSuppressFunc: suppressRFC3339TimeDifferences,
+			},
+			"recurrence": {
+				Type:          schema.TypeList,
+				Description:   "The schedule for a recurring task. Conflicts with run_at.",
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"run_at"},
+				Elem:          &schema.Resource{Schema: schemaScheduledTaskRecurrence()},
+			},
+			"next_run_time": {
+				Type:        schema.TypeString,
+				Description: "The time, in RFC3339 format, that the task is next scheduled to run at.",
+				Computed:    true,
+			},
+			"prev_run_time": {
+				Type:        schema.TypeString,
+				Description: "The time, in RFC3339 format, that the task last ran at.",
+				Computed:    true,
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Description: "The state of the last run of the task.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereScheduledTaskCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	spec, err := expandScheduledTaskSpec(d)
+	if err != nil {
+		return err
+	}
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+
+	id, err := createScheduledTask(client, vm.Reference(), spec)
+	if err != nil {
+		return fmt.Errorf("error creating scheduled task: %s", err)
+	}
+	d.SetId(id)
+
+	return resourceVSphereScheduledTaskRead(d, meta)
+}
+
+func resourceVSphereScheduledTaskRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	props, err := scheduledTaskProperties(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Scheduled task %q is gone, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching scheduled task: %s", err)
+	}
+	info := props.Info
+
+	d.Set("name", info.Name)
+	d.Set("description", info.Description)
+	d.Set("enabled", info.Enabled)
+	d.Set("notification", info.Notification)
+	d.Set("next_run_time", formatTimePtr(info.NextRunTime))
+	d.Set("prev_run_time", formatTimePtr(info.PrevRunTime))
+	d.Set("state", string(info.State))
+	if err := flattenScheduledTaskScheduler(d, info.Scheduler); err != nil {
+		return fmt.Errorf("error saving scheduled task schedule to state: %s", err)
+	}
+	if err := flattenScheduledTaskAction(d, info.Action); err != nil {
+		return fmt.Errorf("error saving scheduled task action to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereScheduledTaskUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	spec, err := expandScheduledTaskSpec(d)
+	if err != nil {
+		return err
+	}
+	if err := reconfigureScheduledTask(client, d.Id(), spec); err != nil {
+		return fmt.Errorf("error updating scheduled task: %s", err)
+	}
+
+	return resourceVSphereScheduledTaskRead(d, meta)
+}
+
+func resourceVSphereScheduledTaskDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := removeScheduledTask(client, d.Id()); err != nil {
+		if isManagedObjectNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error removing scheduled task: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereScheduledTask(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereScheduledTaskCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"one-time power off",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereScheduledTaskPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereScheduledTaskExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereScheduledTaskConfigOnce(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereScheduledTaskExists(true),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "action", "power_off"),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "next_run_time", "2030-01-01T02:00:00Z"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"recurring snapshot",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereScheduledTaskPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereScheduledTaskExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereScheduledTaskConfigRecurrence(`
+    frequency    = "weekly"
+    hour         = 22
+    days_of_week = ["monday", "friday"]
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereScheduledTaskExists(true),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "recurrence.0.frequency", "weekly"),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "recurrence.0.days_of_week.#", "2"),
+							resource.TestCheckResourceAttrSet("vsphere_scheduled_task.task", "next_run_time"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereScheduledTaskConfigRecurrence(`
+    frequency    = "monthly"
+    hour         = 22
+    day_of_month = 1
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereScheduledTaskExists(true),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "recurrence.0.frequency", "monthly"),
+							resource.TestCheckResourceAttr("vsphere_scheduled_task.task", "recurrence.0.day_of_month", "1"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"snapshot settings on power action",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereScheduledTaskPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereScheduledTaskExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereScheduledTaskConfigBadAction(),
+						ExpectError: regexp.MustCompile("snapshot_name can only be set when action is create_snapshot"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereScheduledTaskCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereScheduledTaskPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_scheduled_task acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_scheduled_task acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_scheduled_task acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_scheduled_task acceptance tests")
+	}
+}
+
+func testAccResourceVSphereScheduledTaskExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_scheduled_task.task")
+		if err != nil {
+			if !expected {
+				return nil
+			}
+			return err
+		}
+		_, err = scheduledTaskProperties(vars.client, vars.resourceID)
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected scheduled task %s to be missing", vars.resourceID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereScheduledTaskConfigVM() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name       = "terraform-test"
+  datacenter = "${var.datacenter}"
+  cluster    = "${var.cluster}"
+
+  vcpu   = 1
+  memory = 1024
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    size      = 1
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereScheduledTaskConfigOnce() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_scheduled_task" "task" {
+  name                 = "terraform-test-task"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  action               = "power_off"
+  run_at               = "2030-01-01T03:00:00+01:00"
+}
+`,
+		testAccResourceVSphereScheduledTaskConfigVM(),
+	)
+}
+
+func testAccResourceVSphereScheduledTaskConfigRecurrence(recurrence string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_scheduled_task" "task" {
+  name                 = "terraform-test-task"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  action               = "create_snapshot"
+  snapshot_name        = "terraform-test-snapshot"
+  snapshot_quiesce     = true
+
+  recurrence {
+%s
+  }
+}
+`,
+		testAccResourceVSphereScheduledTaskConfigVM(),
+		recurrence,
+	)
+}
+
+func testAccResourceVSphereScheduledTaskConfigBadAction() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_scheduled_task" "task" {
+  name                 = "terraform-test-task"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  action               = "power_on"
+  snapshot_name        = "terraform-test-snapshot"
+  run_at               = "2030-01-01T02:00:00Z"
+}
+`,
+		testAccResourceVSphereScheduledTaskConfigVM(),
+	)
+}
@@ -0,0 +1,191 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const vcenterAdvancedSettingsIDPrefix = "tf-VCenterAdvancedSettings"
+
+func resourceVSphereVCenterAdvancedSettings() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVCenterAdvancedSettingsCreate,
+		Read:   resourceVSphereVCenterAdvancedSettingsRead,
+		Update: resourceVSphereVCenterAdvancedSettingsUpdate,
+		Delete: resourceVSphereVCenterAdvancedSettingsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"settings": {
+				Type:        schema.TypeMap,
+				Description: "A map of vCenter advanced settings keys to their values, ie: config.vpxd.stats.maxQueryMetrics.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereVCenterAdvancedSettingsCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+	defs, err := optionManagerDefinitions(m)
+	if err != nil {
+		return fmt.Errorf("error fetching advanced settings definitions: %s", err)
+	}
+
+	values, err := expandVCenterAdvancedSettings(defs, d.Get("settings").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	if err := updateOptionValues(m, values); err != nil {
+		return fmt.Errorf("error updating advanced settings: %s", err)
+	}
+	d.SetId(fmt.Sprintf("%s:%s", vcenterAdvancedSettingsIDPrefix, client.ServiceContent.About.InstanceUuid))
+
+	return resourceVSphereVCenterAdvancedSettingsRead(d, meta)
+}
+
+func resourceVSphereVCenterAdvancedSettingsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+
+	// Only the settings that are managed by this resource are read back, as
+	// vCenter has a large number of settings that we do not want in state.
+	settings := make(map[string]interface{})
+	for key := range d.Get("settings").(map[string]interface{}) {
+		v, err := queryOptionValue(m, key)
+		if err != nil {
+			return fmt.Errorf("error querying advanced setting %q: %s", key, err)
+		}
+		if v == nil {
+			log.Printf("[DEBUG] %s: advanced setting %q no longer exists", d.Id(), key)
+			continue
+		}
+		settings[key] = formatOptionValue(v.Value)
+	}
+	if err := d.Set("settings", settings); err != nil {
+		return fmt.Errorf("error saving advanced settings to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereVCenterAdvancedSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+	defs, err := optionManagerDefinitions(m)
+	if err != nil {
+		return fmt.Errorf("error fetching advanced settings definitions: %s", err)
+	}
+
+	o, n := d.GetChange("settings")
+	oldSettings := o.(map[string]interface{})
+	newSettings := n.(map[string]interface{})
+	var removed []string
+	for key := range oldSettings {
+		if _, ok := newSettings[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	values, err := expandVCenterAdvancedSettings(defs, newSettings)
+	if err != nil {
+		return err
+	}
+	values = append(values, vcenterAdvancedSettingsDefaults(d.Id(), defs, removed)...)
+	if err := updateOptionValues(m, values); err != nil {
+		return fmt.Errorf("error updating advanced settings: %s", err)
+	}
+
+	return resourceVSphereVCenterAdvancedSettingsRead(d, meta)
+}
+
+func resourceVSphereVCenterAdvancedSettingsDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+	defs, err := optionManagerDefinitions(m)
+	if err != nil {
+		return fmt.Errorf("error fetching advanced settings definitions: %s", err)
+	}
+
+	var keys []string
+	for key := range d.Get("settings").(map[string]interface{}) {
+		keys = append(keys, key)
+	}
+	if err := updateOptionValues(m, vcenterAdvancedSettingsDefaults(d.Id(), defs, keys)); err != nil {
+		return fmt.Errorf("error restoring advanced settings defaults: %s", err)
+	}
+	return nil
+}
+
+// expandVCenterAdvancedSettings converts the settings map into a list of
+// OptionValue, converting each value to the type in its option definition.
+// The values are sorted by key so that errors are reported consistently.
+func expandVCenterAdvancedSettings(defs map[string]types.OptionDef, settings map[string]interface{}) ([]types.BaseOptionValue, error) {
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var values []types.BaseOptionValue
+	var errs []string
+	for _, key := range keys {
+		var def *types.OptionDef
+		if v, ok := defs[key]; ok {
+			def = &v
+		}
+		v, err := parseOptionValue(def, key, settings[key].(string))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		values = append(values, &types.OptionValue{
+			Key:   key,
+			Value: v,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid advanced settings: %s", strings.Join(errs, "; "))
+	}
+	return values, nil
+}
+
+// vcenterAdvancedSettingsDefaults returns the OptionValue entries that restore
+// the supplied keys to the defaults in their option definitions. Keys without
+// a definition have no known default and are left at their current value.
+func vcenterAdvancedSettingsDefaults(id string, defs map[string]types.OptionDef, keys []string) []types.BaseOptionValue {
+	sort.Strings(keys)
+	var values []types.BaseOptionValue
+	for _, key := range keys {
+		var def *types.OptionDef
+		if v, ok := defs[key]; ok {
+			def = &v
+		}
+		v, ok := optionDefaultValue(def)
+		if !ok {
+			log.Printf("[WARN] %s: advanced setting %q has no known default, leaving current value in place", id, key)
+			continue
+		}
+		values = append(values, &types.OptionValue{
+			Key:   key,
+			Value: v,
+		})
+	}
+	return values
+}
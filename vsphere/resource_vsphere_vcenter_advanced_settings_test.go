@@ -0,0 +1,120 @@
+package vsphere
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const testAccResourceVSphereVCenterAdvancedSettingsKey = "config.vpxd.stats.maxQueryMetrics"
+
+func TestAccResourceVSphereVCenterAdvancedSettings(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVCenterAdvancedSettingsCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVCenterAdvancedSettingsConfig(testAccResourceVSphereVCenterAdvancedSettingsKey, "64"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterAdvancedSettingsCheckValue(testAccResourceVSphereVCenterAdvancedSettingsKey, "64"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVCenterAdvancedSettingsConfig(testAccResourceVSphereVCenterAdvancedSettingsKey, "64"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterAdvancedSettingsCheckValue(testAccResourceVSphereVCenterAdvancedSettingsKey, "64"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVCenterAdvancedSettingsConfig(testAccResourceVSphereVCenterAdvancedSettingsKey, "128"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterAdvancedSettingsCheckValue(testAccResourceVSphereVCenterAdvancedSettingsKey, "128"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"bad value type",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers: testAccProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereVCenterAdvancedSettingsConfig("event.maxAge", "abc"),
+						ExpectError: regexp.MustCompile("expected an integer"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVCenterAdvancedSettingsCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVCenterAdvancedSettingsCheckValue(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_vcenter_advanced_settings.settings")
+		if err != nil {
+			return err
+		}
+		m, err := vcenterOptionManager(vars.client)
+		if err != nil {
+			return err
+		}
+		v, err := queryOptionValue(m, key)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			return fmt.Errorf("advanced setting %q not found", key)
+		}
+		if actual := formatOptionValue(v.Value); actual != expected {
+			return fmt.Errorf("expected advanced setting %q to be %q, got %q", key, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVCenterAdvancedSettingsConfig(key, value string) string {
+	return fmt.Sprintf(`
+resource "vsphere_vcenter_advanced_settings" "settings" {
+  settings {
+    "%s" = "%s"
+  }
+}
+`, key, value)
+}
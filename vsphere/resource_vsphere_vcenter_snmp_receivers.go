@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+)
+
+const vcenterSnmpReceiversIDPrefix = "tf-VCenterSnmpReceivers"
+
+// vcenterSnmpReceiverSlots is the number of SNMP receivers that can be
+// configured on vCenter.
+const vcenterSnmpReceiverSlots = 4
+
+func resourceVSphereVCenterSnmpReceivers() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVCenterSnmpReceiversCreate,
+		Read:   resourceVSphereVCenterSnmpReceiversRead,
+		Update: resourceVSphereVCenterSnmpReceiversUpdate,
+		Delete: resourceVSphereVCenterSnmpReceiversDelete,
+
+		Schema: map[string]*schema.Schema{
+			"receiver": {
+				Type:        schema.TypeList,
+				Description: "The SNMP receivers that vCenter sends traps to.",
+				Required:    true,
+				MaxItems:    vcenterSnmpReceiverSlots,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host_name": {
+							Type:        schema.TypeString,
+							Description: "The host name or IP address of the receiver.",
+							Required:    true,
+						},
+						"port": {
+							Type:         schema.TypeInt,
+							Description:  "The UDP port of the receiver.",
+							Optional:     true,
+							Default:      162,
+							ValidateFunc: validation.IntBetween(1, 65535),
+						},
+						"community": {
+							Type:        schema.TypeString,
+							Description: "The community to send traps with.",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVSphereVCenterSnmpReceiversCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := resourceVSphereVCenterSnmpReceiversApply(d, meta); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", vcenterSnmpReceiversIDPrefix, client.ServiceContent.About.InstanceUuid))
+
+	return resourceVSphereVCenterSnmpReceiversRead(d, meta)
+}
+
+func resourceVSphereVCenterSnmpReceiversRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+
+	var receivers []interface{}
+	for i := 1; i <= vcenterSnmpReceiverSlots; i++ {
+		values := make(map[string]string)
+		for _, k := range []string{"enabled", "name", "port", "community"} {
+			key := vcenterSnmpReceiverKey(i, k)
+			v, err := queryOptionValue(m, key)
+			if err != nil {
+				return fmt.Errorf("error querying advanced setting %q: %s", key, err)
+			}
+			if v != nil {
+				values[k] = formatOptionValue(v.Value)
+			}
+		}
+		if enabled, _ := strconv.ParseBool(values["enabled"]); !enabled {
+			continue
+		}
+		port, _ := strconv.Atoi(values["port"])
+		receivers = append(receivers, map[string]interface{}{
+			"host_name": values["name"],
+			"port":      port,
+			"community": values["community"],
+		})
+	}
+	if err := d.Set("receiver", receivers); err != nil {
+		return fmt.Errorf("error saving SNMP receivers to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereVCenterSnmpReceiversUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereVCenterSnmpReceiversApply(d, meta); err != nil {
+		return err
+	}
+
+	return resourceVSphereVCenterSnmpReceiversRead(d, meta)
+}
+
+func resourceVSphereVCenterSnmpReceiversDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+	if err := updateVCenterSnmpReceivers(m, nil); err != nil {
+		return fmt.Errorf("error removing SNMP receivers: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVCenterSnmpReceiversApply writes the receivers in the
+// ResourceData to the vCenter SNMP receiver settings.
+func resourceVSphereVCenterSnmpReceiversApply(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	m, err := vcenterOptionManager(client)
+	if err != nil {
+		return err
+	}
+	if err := updateVCenterSnmpReceivers(m, d.Get("receiver").([]interface{})); err != nil {
+		return fmt.Errorf("error updating SNMP receivers: %s", err)
+	}
+	return nil
+}
+
+// updateVCenterSnmpReceivers writes the supplied receivers to the vCenter
+// SNMP receiver settings, in order. Any slots that are not used by a receiver
+// are disabled and cleared.
+func updateVCenterSnmpReceivers(m *object.OptionManager, receivers []interface{}) error {
+	defs, err := optionManagerDefinitions(m)
+	if err != nil {
+		return fmt.Errorf("error fetching advanced settings definitions: %s", err)
+	}
+	settings := make(map[string]interface{})
+	for i := 1; i <= vcenterSnmpReceiverSlots; i++ {
+		settings[vcenterSnmpReceiverKey(i, "enabled")] = "false"
+		settings[vcenterSnmpReceiverKey(i, "name")] = ""
+		settings[vcenterSnmpReceiverKey(i, "port")] = "162"
+		settings[vcenterSnmpReceiverKey(i, "community")] = ""
+		if i > len(receivers) {
+			continue
+		}
+		r := receivers[i-1].(map[string]interface{})
+		settings[vcenterSnmpReceiverKey(i, "enabled")] = "true"
+		settings[vcenterSnmpReceiverKey(i, "name")] = r["host_name"].(string)
+		settings[vcenterSnmpReceiverKey(i, "port")] = strconv.Itoa(r["port"].(int))
+		settings[vcenterSnmpReceiverKey(i, "community")] = r["community"].(string)
+	}
+	values, err := expandVCenterAdvancedSettings(defs, settings)
+	if err != nil {
+		return err
+	}
+	return updateOptionValues(m, values)
+}
+
+// vcenterSnmpReceiverKey returns the advanced setting key for a field of the
+// SNMP receiver in the supplied slot, ie: snmp.receiver.1.name.
+func vcenterSnmpReceiverKey(slot int, field string) string {
+	return fmt.Sprintf("snmp.receiver.%d.%s", slot, field)
+}
@@ -0,0 +1,118 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVCenterSnmpReceivers(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVCenterSnmpReceiversCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVCenterSnmpReceiversCheckSlot(1, ""),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVCenterSnmpReceiversConfig(1),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterSnmpReceiversCheckSlot(1, "10.0.0.11"),
+							resource.TestCheckResourceAttr("vsphere_vcenter_snmp_receivers.receivers", "receiver.#", "1"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"add and remove receivers",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVCenterSnmpReceiversCheckSlot(1, ""),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVCenterSnmpReceiversConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterSnmpReceiversCheckSlot(1, "10.0.0.11"),
+							testAccResourceVSphereVCenterSnmpReceiversCheckSlot(2, "10.0.0.12"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVCenterSnmpReceiversConfig(1),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVCenterSnmpReceiversCheckSlot(1, "10.0.0.11"),
+							testAccResourceVSphereVCenterSnmpReceiversCheckSlot(2, ""),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVCenterSnmpReceiversCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+// testAccResourceVSphereVCenterSnmpReceiversCheckSlot checks the host name of
+// the SNMP receiver in the supplied slot. An empty host name checks that the
+// slot is disabled.
+func testAccResourceVSphereVCenterSnmpReceiversCheckSlot(slot int, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		m, err := vcenterOptionManager(client)
+		if err != nil {
+			return err
+		}
+		enabled, err := queryOptionValue(m, vcenterSnmpReceiverKey(slot, "enabled"))
+		if err != nil {
+			return err
+		}
+		name, err := queryOptionValue(m, vcenterSnmpReceiverKey(slot, "name"))
+		if err != nil {
+			return err
+		}
+		var actual string
+		if enabled != nil && formatOptionValue(enabled.Value) == "true" && name != nil {
+			actual = formatOptionValue(name.Value)
+		}
+		if actual != expected {
+			return fmt.Errorf("expected SNMP receiver %d to be %q, got %q", slot, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVCenterSnmpReceiversConfig(count int) string {
+	var receivers string
+	for i := 1; i <= count; i++ {
+		receivers += fmt.Sprintf(`
+  receiver {
+    host_name = "10.0.0.1%d"
+    community = "terraform"
+  }
+`, i)
+	}
+	return fmt.Sprintf(`
+resource "vsphere_vcenter_snmp_receivers" "receivers" {
+%s
+}
+`, receivers)
+}
@@ -6,15 +6,31 @@ import (
 
 	"errors"
 	"path"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/types"
 	"golang.org/x/net/context"
 )
 
+var virtualDiskTypeAllowedValues = []string{
+	"thin",
+	"thick",
+	"lazy",
+	"eagerZeroedThick",
+}
+
+var virtualDiskAdapterTypeAllowedValues = []string{
+	string(types.VirtualDiskAdapterTypeIde),
+	string(types.VirtualDiskAdapterTypeBusLogic),
+	string(types.VirtualDiskAdapterTypeLsiLogic),
+}
+
 type virtualDisk struct {
 	size        int
 	vmdkPath    string
@@ -29,14 +45,15 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualDiskCreate,
 		Read:   resourceVSphereVirtualDiskRead,
+		Update: resourceVSphereVirtualDiskUpdate,
 		Delete: resourceVSphereVirtualDiskDelete,
 
 		Schema: map[string]*schema.Schema{
 			// Size in GB
 			"size": &schema.Schema{
 				Type:     schema.TypeInt,
-				Required: true,
-				ForceNew: true, //TODO Can this be optional (resize)?
+				Optional: true,
+				Computed: true,
 			},
 
 			"vmdk_path": &schema.Schema{
@@ -46,42 +63,48 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 			},
 
 			"type": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.StringInSlice(virtualDiskTypeAllowedValues, false),
+				DiffSuppressFunc: suppressVirtualDiskTypeDiff,
+			},
+
+			"adapter_type": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.StringInSlice(virtualDiskAdapterTypeAllowedValues, false),
+				DiffSuppressFunc: suppressVirtualDiskAdapterTypeDiff,
+			},
+
+			"datacenter": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
-				Default:  "eagerZeroedThick",
-				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
-					value := v.(string)
-					if value != "thin" && value != "eagerZeroedThick" && value != "lazy" {
-						errors = append(errors, fmt.Errorf(
-							"only 'thin', 'eagerZeroedThick', and 'lazy' are supported values for 'type'"))
-					}
-					return
-				},
 			},
 
-			"adapter_type": &schema.Schema{
+			"datastore": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
-				Default:  "lsiLogic",
-				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
-					value := v.(string)
-					if value != "ide" && value != "busLogic" && value != "lsiLogic" {
-						errors = append(errors, fmt.Errorf(
-							"only 'ide', 'busLogic', and 'lsiLogic' are supported values for 'adapter_type'"))
-					}
-					return
-				},
 			},
 
-			"datacenter": &schema.Schema{
+			"source_path": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
 			},
 
-			"datastore": &schema.Schema{
+			"source_datacenter": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"source_datastore": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
@@ -120,7 +143,7 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 
 	finder := find.NewFinder(client.Client, true)
 
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return fmt.Errorf("Error finding Datacenter: %s: %s", vDisk.datacenter, err)
 	}
@@ -131,7 +154,20 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("Error finding Datastore: %s: %s", vDisk.datastore, err)
 	}
 
-	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	if v, ok := d.GetOk("source_path"); ok {
+		err = copyHardDisk(d, client, v.(string), dc, ds.Path(vDisk.vmdkPath), vDisk)
+	} else {
+		if vDisk.size == 0 {
+			return errors.New("size is required when not cloning from source_path")
+		}
+		if vDisk.initType == "" {
+			vDisk.initType = "eagerZeroedThick"
+		}
+		if vDisk.adapterType == "" {
+			vDisk.adapterType = "lsiLogic"
+		}
+		err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	}
 	if err != nil {
 		return err
 	}
@@ -170,7 +206,7 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		vDisk.datastore = v.(string)
 	}
 
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
@@ -193,8 +229,10 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	// search the datastore manually.
 	spec := types.HostDatastoreBrowserSearchSpec{
 		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
-			CapacityKb: true,
-			DiskType:   true,
+			CapacityKb:     true,
+			DiskType:       true,
+			ControllerType: types.NewBool(true),
+			Thin:           types.NewBool(true),
 		}}},
 		Details: &types.FileQueryFlags{
 			FileSize:     true,
@@ -213,9 +251,9 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		return err
 	}
 
-	info, err := task.WaitForResult(context.TODO(), nil)
+	info, err := waitForTask(task)
 	if err != nil {
-		if info == nil || info.Error != nil {
+		if info != nil && info.Error != nil {
 			_, ok := info.Error.Fault.(*types.FileNotFound)
 			if ok {
 				log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
@@ -240,9 +278,12 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		return errors.New("Datastore search did not return exactly one result")
 	}
 
-	fileInfo := res.File[0]
+	fileInfo, ok := res.File[0].(*types.VmDiskFileInfo)
+	if !ok {
+		return fmt.Errorf("%s is not a virtual disk", vDisk.vmdkPath)
+	}
 	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
-	size := fileInfo.(*types.VmDiskFileInfo).CapacityKb / 1024 / 1024
+	size := fileInfo.CapacityKb / 1024 / 1024
 
 	d.SetId(vDisk.vmdkPath)
 
@@ -250,12 +291,47 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	d.Set("vmdk_path", vDisk.vmdkPath)
 	d.Set("datacenter", d.Get("datacenter"))
 	d.Set("datastore", d.Get("datastore"))
-	// Todo collect and write type info
+	d.Set("type", flattenVirtualDiskType(fileInfo, vDisk.initType))
+	if adapterType := flattenVirtualDiskAdapterType(fileInfo.ControllerType); adapterType != "" {
+		d.Set("adapter_type", adapterType)
+	}
 
 	return nil
 
 }
 
+func resourceVSphereVirtualDiskUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	if d.HasChange("size") {
+		o, n := d.GetChange("size")
+		if n.(int) < o.(int) {
+			return fmt.Errorf("virtual disks cannot be shrunk: new size (%d) is lower than current size (%d)", n.(int), o.(int))
+		}
+
+		dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
+		if err != nil {
+			return err
+		}
+
+		finder := find.NewFinder(client.Client, true)
+		finder = finder.SetDatacenter(dc)
+
+		ds, err := getDatastore(finder, d.Get("datastore").(string))
+		if err != nil {
+			return err
+		}
+
+		diskPath := ds.Path(d.Get("vmdk_path").(string))
+		eagerZero := expandVirtualDiskType(d.Get("type").(string)) == string(types.VirtualDiskTypeEagerZeroedThick)
+		if err := extendHardDisk(client, n.(int), diskPath, eagerZero, dc); err != nil {
+			return err
+		}
+	}
+
+	return resourceVSphereVirtualDiskRead(d, meta)
+}
+
 func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 
@@ -268,7 +344,7 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 		vDisk.datastore = v.(string)
 	}
 
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
@@ -290,7 +366,7 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
-	_, err = task.WaitForResult(context.TODO(), nil)
+	_, err = waitForTask(task)
 	if err != nil {
 		log.Printf("[INFO] Failed to delete disk:  %v", err)
 		return err
@@ -303,21 +379,11 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 
 // createHardDisk creates a new Hard Disk.
 func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
-	var vDiskType string
-	switch diskType {
-	case "thin":
-		vDiskType = "thin"
-	case "eagerZeroedThick":
-		vDiskType = "eagerZeroedThick"
-	case "lazy":
-		vDiskType = "preallocated"
-	}
-
 	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
 	spec := &types.FileBackedVirtualDiskSpec{
 		VirtualDiskSpec: types.VirtualDiskSpec{
 			AdapterType: adapterType,
-			DiskType:    vDiskType,
+			DiskType:    expandVirtualDiskType(diskType),
 		},
 		CapacityKb: int64(1024 * 1024 * size),
 	}
@@ -332,7 +398,7 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 		return err
 	}
 
-	_, err = task.WaitForResult(context.TODO(), nil)
+	_, err = waitForTask(task)
 	if err != nil {
 		log.Printf("[INFO] Failed to create disk:  %v", err)
 		return err
@@ -341,3 +407,194 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 
 	return nil
 }
+
+// extendHardDisk grows the virtual disk at diskPath to size GB. If eagerZero
+// is set, the added space is zeroed out.
+func extendHardDisk(client *govmomi.Client, size int, diskPath string, eagerZero bool, dc *object.Datacenter) error {
+	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
+	req := types.ExtendVirtualDisk_Task{
+		This:          virtualDiskManager.Reference(),
+		Name:          diskPath,
+		NewCapacityKb: int64(1024 * 1024 * size),
+		EagerZero:     types.NewBool(eagerZero),
+	}
+	if dc != nil {
+		ref := dc.Reference()
+		req.Datacenter = &ref
+	}
+	log.Printf("[DEBUG] Extending disk %q to %dGB", diskPath, size)
+
+	res, err := methods.ExtendVirtualDisk_Task(context.TODO(), client, &req)
+	if err != nil {
+		return fmt.Errorf("error extending disk %q: %s", diskPath, err)
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error extending disk %q: %s", diskPath, err)
+	}
+	log.Printf("[INFO] Extended disk %q to %dGB", diskPath, size)
+
+	return nil
+}
+
+// copyHardDisk clones the virtual disk at sourcePath to diskPath. sourcePath
+// can either be a full datastore path, or a path relative to source_datastore
+// (or datastore if that is not set).
+//
+// The adapter and disk types of the source disk are kept unless adapter_type
+// or type are set, in which case the disk is converted during the copy. If
+// size is set, it cannot be lower than the size of the source disk, and the
+// copy is extended to size if it is higher.
+func copyHardDisk(d *schema.ResourceData, client *govmomi.Client, sourcePath string, dc *object.Datacenter, diskPath string, vDisk virtualDisk) error {
+	sourceDC := dc
+	if v, ok := d.GetOk("source_datacenter"); ok {
+		var err error
+		if sourceDC, err = getDatacenter(client, v.(string)); err != nil {
+			return fmt.Errorf("error finding source datacenter %q: %s", v.(string), err)
+		}
+	}
+
+	var dp object.DatastorePath
+	if !dp.FromString(sourcePath) {
+		finder := find.NewFinder(client.Client, true)
+		finder = finder.SetDatacenter(sourceDC)
+		name := d.Get("source_datastore").(string)
+		if name == "" {
+			name = vDisk.datastore
+		}
+		ds, err := getDatastore(finder, name)
+		if err != nil {
+			return fmt.Errorf("error finding source datastore %q: %s", name, err)
+		}
+		dp = object.DatastorePath{Datastore: ds.Name(), Path: sourcePath}
+	}
+
+	source, err := virtualDiskFileInfo(client, sourceDC, dp)
+	if err != nil {
+		return fmt.Errorf("error reading source disk %q: %s", dp.String(), err)
+	}
+	if vDisk.size != 0 && int64(vDisk.size) < source.CapacityKb/1024/1024 {
+		return fmt.Errorf("size (%d) cannot be lower than the size of source disk %q (%d) when cloning", vDisk.size, dp.String(), source.CapacityKb/1024/1024)
+	}
+
+	if vDisk.adapterType == "" {
+		vDisk.adapterType = flattenVirtualDiskAdapterType(source.ControllerType)
+		d.Set("adapter_type", vDisk.adapterType)
+	}
+	if vDisk.initType == "" {
+		vDisk.initType = flattenVirtualDiskType(source, "lazy")
+		d.Set("type", vDisk.initType)
+	}
+	spec := &types.VirtualDiskSpec{
+		AdapterType: vDisk.adapterType,
+		DiskType:    expandVirtualDiskType(vDisk.initType),
+	}
+	log.Printf("[DEBUG] Copying disk %q to %q, spec: %#v", dp.String(), diskPath, spec)
+
+	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
+	task, err := virtualDiskManager.CopyVirtualDisk(context.TODO(), dp.String(), sourceDC, diskPath, dc, spec, false)
+	if err != nil {
+		return err
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error copying disk %q to %q: %s", dp.String(), diskPath, err)
+	}
+	log.Printf("[INFO] Copied disk %q to %q", dp.String(), diskPath)
+
+	if vDisk.size != 0 && int64(vDisk.size) > source.CapacityKb/1024/1024 {
+		eagerZero := spec.DiskType == string(types.VirtualDiskTypeEagerZeroedThick)
+		return extendHardDisk(client, vDisk.size, diskPath, eagerZero, dc)
+	}
+
+	return nil
+}
+
+// virtualDiskFileInfo searches the datastore for the virtual disk at the
+// supplied datastore path and returns its disk details.
+func virtualDiskFileInfo(client *govmomi.Client, dc *object.Datacenter, dp object.DatastorePath) (*types.VmDiskFileInfo, error) {
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := finder.Datastore(context.TODO(), dp.Datastore)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := types.HostDatastoreBrowserSearchSpec{
+		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
+			CapacityKb:     true,
+			DiskType:       true,
+			ControllerType: types.NewBool(true),
+			Thin:           types.NewBool(true),
+		}}},
+		MatchPattern: []string{path.Base(dp.Path)},
+	}
+	res, err := searchDatastore(ds, path.Dir(dp.Path), &spec, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range res {
+		for _, f := range r.File {
+			if info, ok := f.(*types.VmDiskFileInfo); ok {
+				return info, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("could not find virtual disk %s", dp.String())
+}
+
+// expandVirtualDiskType converts a value of the type attribute into a
+// VirtualDiskType. lazy and thick are both lazy zeroed thick disks, which the
+// API calls preallocated.
+func expandVirtualDiskType(t string) string {
+	switch t {
+	case "lazy", "thick":
+		return string(types.VirtualDiskTypePreallocated)
+	}
+	return t
+}
+
+// flattenVirtualDiskType returns the type attribute for the supplied disk.
+// The API only reports whether or not a disk is thin provisioned, so thick
+// disks keep the type in current, or eagerZeroedThick if current is empty or
+// thin. current is returned as-is if the host did not report thin
+// provisioning at all.
+func flattenVirtualDiskType(info *types.VmDiskFileInfo, current string) string {
+	if info.Thin == nil {
+		return current
+	}
+	if *info.Thin {
+		return "thin"
+	}
+	if current == "" || current == "thin" {
+		return "eagerZeroedThick"
+	}
+	return current
+}
+
+// flattenVirtualDiskAdapterType converts the controller type of a disk, as
+// returned by a datastore search, to a value of the adapter_type attribute.
+// An empty string is returned for controller types that do not correspond
+// to a supported adapter type.
+func flattenVirtualDiskAdapterType(controllerType string) string {
+	switch controllerType {
+	case "VirtualIDEController":
+		return string(types.VirtualDiskAdapterTypeIde)
+	case "VirtualBusLogicController":
+		return string(types.VirtualDiskAdapterTypeBusLogic)
+	case "VirtualLsiLogicController":
+		return string(types.VirtualDiskAdapterTypeLsiLogic)
+	}
+	return ""
+}
+
+// suppressVirtualDiskTypeDiff suppresses diffs in the type attribute between
+// values that describe the same kind of disk.
+func suppressVirtualDiskTypeDiff(k, old, new string, d *schema.ResourceData) bool {
+	return expandVirtualDiskType(old) == expandVirtualDiskType(new)
+}
+
+// suppressVirtualDiskAdapterTypeDiff suppresses diffs in the adapter_type
+// attribute that only differ in case.
+func suppressVirtualDiskAdapterTypeDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -51,6 +52,78 @@ func TestAccVSphereVirtualDisk_basic(t *testing.T) {
 	})
 }
 
+func TestAccVSphereVirtualDisk_clone(t *testing.T) {
+	var datacenterOpt string
+	var datastoreOpt string
+
+	rString := acctest.RandString(5)
+
+	if v := os.Getenv("VSPHERE_DATACENTER"); v != "" {
+		datacenterOpt = v
+	}
+	if v := os.Getenv("VSPHERE_DATASTORE"); v != "" {
+		datastoreOpt = v
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_clone(rString, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.bar"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.bar", "size", "1"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.bar", "type", "thin"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.bar", "adapter_type", "busLogic"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVSphereVirtualDisk_grow(t *testing.T) {
+	var datacenterOpt string
+	var datastoreOpt string
+
+	rString := acctest.RandString(5)
+
+	if v := os.Getenv("VSPHERE_DATACENTER"); v != "" {
+		datacenterOpt = v
+	}
+	if v := os.Getenv("VSPHERE_DATASTORE"); v != "" {
+		datastoreOpt = v
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_size(rString, 1, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "1"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_size(rString, 2, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "2"),
+				),
+			},
+			{
+				Config:      testAccCheckVSphereVirtuaDiskConfig_size(rString, 1, datacenterOpt, datastoreOpt),
+				ExpectError: regexp.MustCompile("virtual disks cannot be shrunk"),
+			},
+		},
+	})
+}
+
 func testAccVSphereVirtualDiskExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -127,3 +200,35 @@ resource "vsphere_virtual_disk" "foo" {
 }
 `, rName, initTypeOpt, adapterTypeOpt, datacenterOpt, datastoreOpt)
 }
+
+func testAccCheckVSphereVirtuaDiskConfig_clone(rName, datacenterOpt, datastoreOpt string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+    size = 1
+    vmdk_path = "tfTestDisk-%s.vmdk"
+    type = "thin"
+    adapter_type = "busLogic"
+    datacenter = "%s"
+    datastore = "%s"
+}
+
+resource "vsphere_virtual_disk" "bar" {
+    vmdk_path = "tfTestDisk-%s-clone.vmdk"
+    source_path = "${vsphere_virtual_disk.foo.vmdk_path}"
+    datacenter = "%s"
+    datastore = "%s"
+}
+`, rName, datacenterOpt, datastoreOpt, rName, datacenterOpt, datastoreOpt)
+}
+
+func testAccCheckVSphereVirtuaDiskConfig_size(rName string, size int, datacenterOpt, datastoreOpt string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+    size = %d
+    vmdk_path = "tfTestDisk-%s.vmdk"
+    type = "thin"
+    datacenter = "%s"
+    datastore = "%s"
+}
+`, size, rName, datacenterOpt, datastoreOpt)
+}
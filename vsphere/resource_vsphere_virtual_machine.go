@@ -1,11 +1,15 @@
 package vsphere
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -34,6 +38,18 @@ var DiskControllerTypes = []string{
 	"scsi-paravirtual",
 	"scsi-lsi-sas",
 	"ide",
+	"sata",
+}
+
+var virtualDiskModeAllowedValues = []string{
+	string(types.VirtualDiskModePersistent),
+	string(types.VirtualDiskModeIndependent_persistent),
+	string(types.VirtualDiskModeIndependent_nonpersistent),
+}
+
+var rawDiskMappingCompatibilityModeAllowedValues = []string{
+	"physical",
+	"virtual",
 }
 
 var virtualMachineNetworkAdapterTypeAllowedValues = []string{
@@ -42,16 +58,21 @@ var virtualMachineNetworkAdapterTypeAllowedValues = []string{
 }
 
 type networkInterface struct {
-	deviceName       string
-	label            string
-	ipv4Address      string
-	ipv4PrefixLength int
-	ipv4Gateway      string
-	ipv6Address      string
-	ipv6PrefixLength int
-	ipv6Gateway      string
-	adapterType      string
-	macAddress       string
+	deviceName         string
+	label              string
+	ipv4Address        string
+	ipv4PrefixLength   int
+	ipv4Gateway        string
+	ipv6Address        string
+	ipv6PrefixLength   int
+	ipv6Gateway        string
+	adapterType        string
+	macAddress         string
+	pciSlotNumber      int32
+	resourceAllocation *types.VirtualEthernetCardResourceAllocation
+	uptEnabled         bool
+	connected          bool
+	startConnected     bool
 }
 
 type hardDisk struct {
@@ -61,7 +82,19 @@ type hardDisk struct {
 	initType   string
 	vmdkPath   string
 	controller string
+	busNumber  int
+	unitNumber int
 	bootable   bool
+	diskMode   string
+	rdm        *rawDiskMapping
+	datastore  string
+}
+
+// rawDiskMapping describes a host LUN that is attached to a virtual machine
+// as a raw device mapping.
+type rawDiskMapping struct {
+	lun               string
+	compatibilityMode string
 }
 
 //Additional options Vsphere can use clones of windows machines
@@ -74,12 +107,15 @@ type windowsOptConfig struct {
 }
 
 type cdrom struct {
-	datastore string
-	path      string
-}
-
-type memoryAllocation struct {
-	reservation int64
+	cdromType      string
+	datastore      string
+	path           string
+	deviceName     string
+	connected      bool
+	startConnected bool
+	busNumber      int
+	unitNumber     int
+	key            int32
 }
 
 type virtualMachine struct {
@@ -90,15 +126,30 @@ type virtualMachine struct {
 	cluster                  string
 	resourcePool             string
 	datastore                string
+	datastoreClusterID       string
+	datastorePolicy          string
+	candidateDatastoreIDs    []string
+	selectedDatastoreID      string
 	vcpu                     int32
 	nestedVirtualization     bool
+	vpmcEnabled              bool
+	cpuHotAddEnabled         bool
+	memoryHotAddEnabled      bool
 	memoryMb                 int64
-	memoryAllocation         memoryAllocation
+	cpuAllocation            *types.ResourceAllocationInfo
+	memoryAllocation         *types.ResourceAllocationInfo
 	annotation               string
+	guestID                  string
+	hardwareVersion          int
+	firmware                 string
 	template                 string
+	registerVmxPath          string
+	uuid                     string
+	instanceUUID             string
 	networkInterfaces        []networkInterface
 	hardDisks                []hardDisk
 	cdroms                   []cdrom
+	floppies                 []floppy
 	domain                   string
 	timeZone                 string
 	dnsSuffixes              []string
@@ -107,10 +158,14 @@ type virtualMachine struct {
 	linkedClone              bool
 	skipCustomization        bool
 	enableDiskUUID           bool
+	toolsUpgradePolicy       string
 	moid                     string
 	windowsOptionalConfig    windowsOptConfig
 	customConfigurations     map[string](types.AnyType)
 	customizationWaitTimeout int
+	questionAnswers          map[string]string
+	sataControllerCount      int
+	powerOn                  bool
 }
 
 func (v virtualMachine) Path() string {
@@ -125,14 +180,30 @@ func vmPath(folder string, name string) string {
 	return path + name
 }
 
+const (
+	datastoreSelectionPolicyStorageDrs = "storage_drs"
+	datastoreSelectionPolicyLeastUsed  = "least_used"
+)
+
+var datastoreSelectionPolicyAllowedValues = []string{
+	datastoreSelectionPolicyStorageDrs,
+	datastoreSelectionPolicyLeastUsed,
+}
+
+var virtualMachinePowerStateAllowedValues = []string{
+	string(types.VirtualMachinePowerStatePoweredOn),
+	string(types.VirtualMachinePowerStatePoweredOff),
+	string(types.VirtualMachinePowerStateSuspended),
+}
+
 func resourceVSphereVirtualMachine() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		Create: resourceVSphereVirtualMachineCreate,
 		Read:   resourceVSphereVirtualMachineRead,
 		Update: resourceVSphereVirtualMachineUpdate,
 		Delete: resourceVSphereVirtualMachineDelete,
 
-		SchemaVersion: 1,
+		SchemaVersion: 6,
 		MigrateState:  resourceVSphereVirtualMachineMigrateState,
 
 		Schema: map[string]*schema.Schema{
@@ -165,21 +236,82 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  false,
 			},
 
+			"vpmc_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"memory": &schema.Schema{
 				Type:     schema.TypeInt,
 				Required: true,
 			},
 
-			"memory_reservation": &schema.Schema{
-				Type:     schema.TypeInt,
+			"cpu_hot_add_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"memory_hot_add_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"auto_power_cycle": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"shutdown_wait_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 60),
+			},
+
+			"graceful_shutdown_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntBetween(0, 10),
+			},
+
+			"force_power_off_on_update": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"force_power_off_on_power_state": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"force_power_off_on_destroy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"question_answers": &schema.Schema{
+				Type:     schema.TypeMap,
 				Optional: true,
-				Default:  0,
-				ForceNew: true,
 			},
 
 			"annotation": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressAnnotationDifferences,
+			},
+
+			"guest_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
+				Computed: true,
 			},
 
 			"datacenter": &schema.Schema{
@@ -200,6 +332,32 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"datastore_cluster_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"candidate_datastore_ids"},
+			},
+
+			"datastore_selection_policy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      datastoreSelectionPolicyStorageDrs,
+				ValidateFunc: validation.StringInSlice(datastoreSelectionPolicyAllowedValues, false),
+			},
+
+			"candidate_datastore_ids": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"datastore_cluster_id"},
+			},
+
+			"selected_datastore_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"linked_clone": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -254,12 +412,68 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  10,
 			},
 
+			"wait_for_guest_ip_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
 			"wait_for_guest_net": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  true,
 			},
 
+			"tools_upgrade_policy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      string(types.UpgradePolicyManual),
+				ValidateFunc: validation.StringInSlice([]string{string(types.UpgradePolicyManual), string(types.UpgradePolicyUpgradeAtPowerCycle)}, false),
+			},
+
+			"upgrade_tools": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"tools_version": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"tools_version_status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"hardware_version": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(4),
+			},
+
+			"schedule_hardware_upgrade": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"firmware": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{string(types.GuestOsDescriptorFirmwareTypeBios), string(types.GuestOsDescriptorFirmwareTypeEfi)}, false),
+			},
+
+			"allow_firmware_change": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"enable_disk_uuid": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -268,8 +482,21 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			},
 
 			"uuid": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateVirtualMachineUUID,
+				DiffSuppressFunc: suppressUUIDCaseDifferences,
+			},
+
+			"instance_uuid": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateVirtualMachineUUID,
+				DiffSuppressFunc: suppressUUIDCaseDifferences,
 			},
 
 			"moid": &schema.Schema{
@@ -278,16 +505,44 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			},
 
 			"power_state": &schema.Schema{
-				Type:         schema.TypeString,
-				Optional:     true,
-				Default:      string(types.VirtualMachinePowerStatePoweredOn),
-				ValidateFunc: validation.StringInSlice([]string{string(types.VirtualMachinePowerStatePoweredOn)}, false),
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          string(types.VirtualMachinePowerStatePoweredOn),
+				ValidateFunc:     validation.StringInSlice(virtualMachinePowerStateAllowedValues, false),
+				DiffSuppressFunc: suppressPowerStateWithoutPowerOn,
+			},
+
+			"power_on": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"connection_state": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"repair_connection_state": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
 			},
 
 			"custom_configuration_parameters": &schema.Schema{
 				Type:     schema.TypeMap,
 				Optional: true,
-				ForceNew: true,
+			},
+
+			"managed_custom_configuration_keys": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"guestinfo": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
 			},
 
 			"windows_opt_config": &schema.Schema{
@@ -332,7 +587,6 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			"network_interface": &schema.Schema{
 				Type:     schema.TypeList,
 				Required: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"key": &schema.Schema{
@@ -343,13 +597,13 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 						"label": &schema.Schema{
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 
 						"ip_address": &schema.Schema{
 							Type:       schema.TypeString,
 							Optional:   true,
 							Computed:   true,
+							ForceNew:   true,
 							Deprecated: "Please use ipv4_address",
 						},
 
@@ -357,6 +611,7 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Type:       schema.TypeString,
 							Optional:   true,
 							Computed:   true,
+							ForceNew:   true,
 							Deprecated: "Please use ipv4_prefix_length",
 						},
 
@@ -364,6 +619,7 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Type:             schema.TypeString,
 							Optional:         true,
 							Computed:         true,
+							ForceNew:         true,
 							DiffSuppressFunc: suppressIpDifferences,
 						},
 
@@ -371,45 +627,97 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Type:     schema.TypeInt,
 							Optional: true,
 							Computed: true,
+							ForceNew: true,
 						},
 
 						"ipv4_gateway": &schema.Schema{
 							Type:             schema.TypeString,
 							Optional:         true,
 							Computed:         true,
+							ForceNew:         true,
 							DiffSuppressFunc: suppressIpDifferences},
 
 						"ipv6_address": &schema.Schema{
 							Type:             schema.TypeString,
 							Optional:         true,
 							Computed:         true,
+							ForceNew:         true,
 							DiffSuppressFunc: suppressIpDifferences},
 
 						"ipv6_prefix_length": &schema.Schema{
 							Type:     schema.TypeInt,
 							Optional: true,
 							Computed: true,
+							ForceNew: true,
 						},
 
 						"ipv6_gateway": &schema.Schema{
 							Type:             schema.TypeString,
 							Optional:         true,
 							Computed:         true,
+							ForceNew:         true,
 							DiffSuppressFunc: suppressIpDifferences},
 
 						"adapter_type": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
-							ForceNew:     true,
 							Default:      "vmxnet3",
 							ValidateFunc: validation.StringInSlice(virtualMachineNetworkAdapterTypeAllowedValues, false),
 						},
 
+						"use_static_mac": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Computed: true,
+						},
+
 						"mac_address": &schema.Schema{
-							Type:     schema.TypeString,
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validateVirtualMachineMacAddress,
+						},
+
+						"pci_slot_number": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+
+						"upt_compatibility_enabled": &schema.Schema{
+							Type:     schema.TypeBool,
 							Optional: true,
+							Default:  false,
+						},
+
+						"upt_active": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"port_key": &schema.Schema{
+							Type:     schema.TypeString,
 							Computed: true,
 						},
+
+						"connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"start_connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"wait_for_guest_ip": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"ipv4", "ipv6"}, false),
+						},
 					},
 				},
 			},
@@ -453,6 +761,11 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Optional: true,
 						},
 
+						"placed_datastore": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
 						"size": &schema.Schema{
 							Type:     schema.TypeInt,
 							Optional: true,
@@ -484,6 +797,24 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Optional: true,
 						},
 
+						"disk_mode": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      string(types.VirtualDiskModePersistent),
+							ValidateFunc: validation.StringInSlice(virtualDiskModeAllowedValues, false),
+						},
+
+						"rdm_lun": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"rdm_compatibility_mode": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(rawDiskMappingCompatibilityModeAllowedValues, false),
+						},
+
 						"controller_type": &schema.Schema{
 							Type:     schema.TypeString,
 							Optional: true,
@@ -503,6 +834,45 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 								return
 							},
 						},
+
+						"controller_bus_number": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 3),
+						},
+
+						"unit_number": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 29),
+						},
+					},
+				},
+			},
+
+			"sata_controller_count": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(0, 4),
+			},
+
+			"scsi_controllers": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bus_number": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -513,38 +883,144 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  false,
 			},
 
+			"register_vmx_path": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateVirtualMachineVmxPath,
+			},
+
+			"unregister_on_destroy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
 			"cdrom": &schema.Schema{
 				Type:     schema.TypeList,
 				Optional: true,
 				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice(cdromTypeAllowedValues, false),
+						},
+
 						"datastore": &schema.Schema{
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
 							ForceNew: true,
 						},
 
 						"path": &schema.Schema{
 							Type:     schema.TypeString,
-							Required: true,
+							Optional: true,
 							ForceNew: true,
 						},
-					},
-				},
-			},
-
-			// Tagging
-			vSphereTagAttributeKey: tagsSchema(),
-		},
-	}
-}
 
-func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
-	// flag if changes have to be applied
-	hasChanges := false
-	// flag if changes have to be done when powered off
-	rebootRequired := false
+						"device_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+
+						"connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"start_connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"controller_bus_number": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 1),
+						},
+
+						"unit_number": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 1),
+						},
+
+						"key": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"floppy": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 2,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      floppyTypeImage,
+							ValidateFunc: validation.StringInSlice(floppyTypeAllowedValues, false),
+						},
+
+						"datastore": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"path": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						"connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"start_connected": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"key": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			// Tagging
+			vSphereTagAttributeKey: tagsSchema(),
+		},
+	}
+	mergeSchema(r.Schema, schemaVirtualMachineResourceAllocation())
+	mergeSchema(r.Schema["network_interface"].Elem.(*schema.Resource).Schema, schemaVirtualEthernetCardResourceAllocation())
+	return r
+}
+
+func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
+	// flag if changes have to be applied
+	hasChanges := false
+	// flag if changes have to be done when powered off
+	rebootRequired := false
 
 	// make config spec
 	configSpec := types.VirtualMachineConfigSpec{}
@@ -552,6 +1028,11 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	if d.HasChange("vcpu") {
 		configSpec.NumCPUs = int32(d.Get("vcpu").(int))
 		hasChanges = true
+	}
+
+	if d.HasChange("cpu_hot_add_enabled") {
+		configSpec.CpuHotAddEnabled = boolPtr(d.Get("cpu_hot_add_enabled").(bool))
+		hasChanges = true
 		rebootRequired = true
 	}
 
@@ -561,14 +1042,101 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		rebootRequired = true
 	}
 
+	if d.HasChange("vpmc_enabled") {
+		configSpec.VPMCEnabled = boolPtr(d.Get("vpmc_enabled").(bool))
+		hasChanges = true
+		rebootRequired = true
+	}
+
 	if d.HasChange("memory") {
 		configSpec.MemoryMB = int64(d.Get("memory").(int))
 		hasChanges = true
+	}
+
+	if d.HasChange("memory_hot_add_enabled") {
+		configSpec.MemoryHotAddEnabled = boolPtr(d.Get("memory_hot_add_enabled").(bool))
+		hasChanges = true
 		rebootRequired = true
 	}
 
 	if d.HasChange("annotation") {
 		configSpec.Annotation = d.Get("annotation").(string)
+		if configSpec.Annotation == "" {
+			// Annotation is omitted from the spec when it is empty, which would
+			// leave the old notes in place. A single space clears them, and is
+			// suppressed against an empty annotation on the next plan.
+			configSpec.Annotation = " "
+		}
+		hasChanges = true
+	}
+
+	if d.HasChange("guest_id") {
+		configSpec.GuestId = d.Get("guest_id").(string)
+		hasChanges = true
+		rebootRequired = true
+	}
+
+	if d.HasChange("disk") {
+		o, n := d.GetChange("disk")
+		if err := validateDiskAddresses(n.(*schema.Set).List()); err != nil {
+			return err
+		}
+		if err := validateIDEAddresses(n.(*schema.Set).List(), d.Get("cdrom").([]interface{})); err != nil {
+			return err
+		}
+		if err := validateDiskControllerChanges(o.(*schema.Set).Difference(n.(*schema.Set)), n.(*schema.Set).Difference(o.(*schema.Set))); err != nil {
+			return err
+		}
+		for _, diskRaw := range n.(*schema.Set).Difference(o.(*schema.Set)).List() {
+			if err := validateRawDiskMappingConfig(diskRaw.(map[string]interface{})); err != nil {
+				return err
+			}
+		}
+		// Disk mode cannot be changed while the virtual machine is powered on.
+		if len(diskModeChanges(o.(*schema.Set).Difference(n.(*schema.Set)), n.(*schema.Set).Difference(o.(*schema.Set)))) > 0 {
+			rebootRequired = true
+		}
+	}
+
+	if d.HasChange("firmware") {
+		o, n := d.GetChange("firmware")
+		if err := validateVirtualMachineFirmwareChange(o.(string), n.(string), d.Get("allow_firmware_change").(bool)); err != nil {
+			return err
+		}
+		configSpec.Firmware = n.(string)
+		hasChanges = true
+		rebootRequired = true
+	}
+
+	if d.HasChange("tools_upgrade_policy") {
+		configSpec.Tools = &types.ToolsConfigInfo{
+			ToolsUpgradePolicy: d.Get("tools_upgrade_policy").(string),
+		}
+		hasChanges = true
+	}
+
+	if d.HasChange("custom_configuration_parameters") {
+		configSpec.ExtraConfig = customConfigurationChanges(d)
+		hasChanges = true
+	}
+
+	if d.HasChange("guestinfo") {
+		configSpec.ExtraConfig = append(configSpec.ExtraConfig, guestInfoChanges(d)...)
+		hasChanges = true
+	}
+
+	if err := validateVirtualMachineResourceAllocation(d); err != nil {
+		return err
+	}
+	if err := validateVirtualMachineGuestInfo(d); err != nil {
+		return err
+	}
+	if d.HasChange("cpu_reservation") || d.HasChange("cpu_limit") || d.HasChange("cpu_share_level") || d.HasChange("cpu_share_count") {
+		configSpec.CpuAllocation = expandVirtualMachineResourceAllocation(d, "cpu")
+		hasChanges = true
+	}
+	if d.HasChange("memory_reservation") || d.HasChange("memory_limit") || d.HasChange("memory_share_level") || d.HasChange("memory_share_count") {
+		configSpec.MemoryAllocation = expandVirtualMachineResourceAllocation(d, "memory")
 		hasChanges = true
 	}
 
@@ -581,7 +1149,7 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
@@ -593,6 +1161,62 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	if (d.HasChange("nested_virtualization") && d.Get("nested_virtualization").(bool)) || (d.HasChange("vpmc_enabled") && d.Get("vpmc_enabled").(bool)) {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		if err := validateVirtualMachineCPUFeatures(d.Get("nested_virtualization").(bool), d.Get("vpmc_enabled").(bool), props.Config.Version); err != nil {
+			return err
+		}
+	}
+
+	// Check the new guest ID against the guests supported by the VM's
+	// environment, rather than letting the reconfigure fail on it.
+	if d.HasChange("guest_id") {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		descs, err := environmentBrowserGuestOsDescriptors(client, props.EnvironmentBrowser, props.Config.Version, nil)
+		if err != nil {
+			return fmt.Errorf("error fetching supported guest IDs: %s", err)
+		}
+		if err := validateGuestID(d.Get("guest_id").(string), descs); err != nil {
+			return err
+		}
+	}
+
+	// Hardware versions can only be raised. A powered off VM is upgraded right
+	// away. A running VM is either power cycled to upgrade it, or has the
+	// upgrade scheduled for its next power cycle if schedule_hardware_upgrade
+	// is enabled.
+	upgradeHardware := false
+	if d.HasChange("hardware_version") {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		version := d.Get("hardware_version").(int)
+		upgrade, err := validateHardwareVersionUpgrade(props.Config.Version, version)
+		if err != nil {
+			return err
+		}
+		if upgrade {
+			if d.Get("schedule_hardware_upgrade").(bool) && props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+				log.Printf("[DEBUG] %s: Scheduling hardware version upgrade to %s at next power cycle", d.Id(), hardwareVersionKey(version))
+				configSpec.ScheduledHardwareUpgradeInfo = &types.ScheduledHardwareUpgradeInfo{
+					UpgradePolicy: string(types.ScheduledHardwareUpgradeInfoHardwareUpgradePolicyAlways),
+					VersionKey:    hardwareVersionKey(version),
+				}
+				hasChanges = true
+			} else {
+				upgradeHardware = true
+				rebootRequired = true
+			}
+		}
+	}
+
 	// Apply any pending tags now, before proceeding with any expensive VM updates
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, vm); err != nil {
@@ -600,6 +1224,96 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	if d.HasChange("network_interface") {
+		deviceChange, powerOff, err := buildNetworkInterfaceDeviceChange(d, client, finder, vm)
+		if err != nil {
+			return err
+		}
+		if len(deviceChange) > 0 {
+			configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChange...)
+			hasChanges = true
+		}
+		if powerOff {
+			// The MAC address and PCI slot of a network interface cannot be
+			// changed while the virtual machine is powered on.
+			rebootRequired = true
+		}
+	}
+
+	// Connecting and disconnecting cdroms does not need the virtual machine to
+	// be powered off.
+	if d.HasChange("cdrom") {
+		devices, err := vm.Device(context.TODO())
+		if err != nil {
+			return fmt.Errorf("error fetching devices: %s", err)
+		}
+		if deviceChange := buildCdromConnectionDeviceChange(devices, d.Get("cdrom").([]interface{})); len(deviceChange) > 0 {
+			configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChange...)
+			hasChanges = true
+		}
+	}
+
+	var addedFloppies map[int]*types.VirtualFloppy
+	if d.HasChange("floppy") {
+		devices, err := vm.Device(context.TODO())
+		if err != nil {
+			return fmt.Errorf("error fetching devices: %s", err)
+		}
+		o, n := d.GetChange("floppy")
+		deviceChange, added, powerOff, err := buildFloppyDeviceChange(client, dc, devices, o.([]interface{}), n.([]interface{}))
+		if err != nil {
+			return err
+		}
+		if len(deviceChange) > 0 {
+			configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChange...)
+			hasChanges = true
+		}
+		if powerOff {
+			rebootRequired = true
+		}
+		addedFloppies = added
+	}
+
+	// CPU and memory changes can only be applied to a running VM if they can be
+	// hot added. Otherwise, the VM needs to be power cycled, which is only done
+	// if auto_power_cycle is enabled. A SATA controller change already
+	// power cycles a running VM.
+	if !rebootRequired && !d.HasChange("sata_controller_count") && (d.HasChange("vcpu") || d.HasChange("memory")) {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		if props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+			if err := validateVirtualMachineHotAdd(d, client, props); err != nil {
+				if !d.Get("auto_power_cycle").(bool) {
+					return fmt.Errorf("cannot apply CPU or memory changes to running virtual machine and auto_power_cycle is disabled: %s", err)
+				}
+				log.Printf("[DEBUG] %s: Power cycling VM to apply CPU or memory changes: %s", d.Id(), err)
+				rebootRequired = true
+			}
+		}
+	}
+
+	// SATA controllers are added or removed right away, so that disks added to
+	// them below can find them. This requires the VM to be powered off, so a
+	// running VM is shut down and powered on again afterwards.
+	if d.HasChange("sata_controller_count") {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+			log.Printf("[INFO] Shutting down virtual machine to change SATA controllers: %s", d.Id())
+			if err := resourceVSphereVirtualMachineShutdown(d, client, vm, d.Get("force_power_off_on_update").(bool)); err != nil {
+				return err
+			}
+			rebootRequired = true
+		}
+		if err := setSATAControllerCount(vm, d.Get("sata_controller_count").(int)); err != nil {
+			return err
+		}
+	}
+
 	if d.HasChange("disk") {
 		hasChanges = true
 		oldDisks, newDisks := d.GetChange("disk")
@@ -609,6 +1323,18 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		addedDisks := newDiskSet.Difference(oldDiskSet)
 		removedDisks := oldDiskSet.Difference(newDiskSet)
 
+		// Disks that only had their disk_mode changed are edited in place,
+		// rather than being removed and added again.
+		modeChanges := diskModeChanges(removedDisks, addedDisks)
+		if len(modeChanges) > 0 {
+			deviceChange, err := buildDiskModeDeviceChange(vm, modeChanges)
+			if err != nil {
+				return err
+			}
+			configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChange...)
+			hasChanges = true
+		}
+
 		// Removed disks
 		for _, diskRaw := range removedDisks.List() {
 			if disk, ok := diskRaw.(map[string]interface{}); ok {
@@ -618,9 +1344,11 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 				}
 				virtualDisk := devices.FindByKey(int32(disk["key"].(int)))
 
-				keep := false
-				if v, ok := disk["keep_on_remove"].(bool); ok {
-					keep = v
+				// Disks attached from an existing vmdk are not managed by this
+				// resource, so they are always detached rather than deleted.
+				keep := disk["vmdk"] != ""
+				if v, ok := disk["keep_on_remove"].(bool); ok && v {
+					keep = true
 				}
 
 				err = vm.RemoveDevice(context.TODO(), keep, virtualDisk)
@@ -632,17 +1360,32 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		// Added disks
 		for _, diskRaw := range addedDisks.List() {
 			if disk, ok := diskRaw.(map[string]interface{}); ok {
+				// A vmdk given as a full "[datastore] path" reference lives on the
+				// datastore named in the reference, not the one set on the disk.
+				dsName := disk["datastore"].(string)
+				if dp, ok := vmdkDatastorePath(disk["vmdk"].(string)); ok {
+					dsName = dp.Datastore
+				}
 
 				var datastore *object.Datastore
-				if disk["datastore"] == "" {
+				podID := d.Get("datastore_cluster_id").(string)
+				sdrs := podID != "" && d.Get("datastore_selection_policy").(string) == datastoreSelectionPolicyStorageDrs
+				if id := d.Get("selected_datastore_id").(string); dsName == "" && id != "" && !sdrs {
+					// Disks without a datastore go on the datastore that was selected
+					// when the virtual machine was created.
+					datastore, err = datastoreFromID(client, id)
+					if err != nil {
+						return fmt.Errorf("error finding selected datastore %q: %s", id, err)
+					}
+				} else if dsName == "" {
 					datastore, err = finder.DefaultDatastore(context.TODO())
 					if err != nil {
 						return fmt.Errorf("[ERROR] Update Remove Disk - Error finding datastore: %v", err)
 					}
 				} else {
-					datastore, err = finder.Datastore(context.TODO(), disk["datastore"].(string))
+					datastore, err = finder.Datastore(context.TODO(), dsName)
 					if err != nil {
-						log.Printf("[ERROR] Couldn't find datastore %v.  %s", disk["datastore"].(string), err)
+						log.Printf("[ERROR] Couldn't find datastore %v.  %s", dsName, err)
 						return err
 					}
 				}
@@ -682,8 +1425,27 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 					initType = "thin"
 				}
 
+				var rdm *rawDiskMapping
+				if v := disk["rdm_lun"].(string); v != "" {
+					rdm = &rawDiskMapping{
+						lun:               v,
+						compatibilityMode: disk["rdm_compatibility_mode"].(string),
+					}
+				}
+
+				// Let Storage DRS place new disks that do not have a datastore set.
+				if dsName == "" && sdrs && disk["vmdk"] == "" && rdm == nil {
+					datastore, err = recommendDiskDatastore(client, vm, podID, size, initType)
+					if err != nil {
+						return err
+					}
+					if err := makeParentDirectory(client, datastore, dc, diskPath); err != nil {
+						return fmt.Errorf("error creating disk directory on datastore %q: %s", datastore.Name(), err)
+					}
+				}
+
 				log.Printf("[INFO] Attaching disk: %v", diskPath)
-				err = addHardDisk(vm, size, iops, initType, datastore, diskPath, controller_type)
+				err = addHardDisk(vm, size, iops, initType, datastore, diskPath, controller_type, disk["controller_bus_number"].(int), disk["unit_number"].(int), disk["disk_mode"].(string), rdm)
 				if err != nil {
 					log.Printf("[ERROR] Add Hard Disk Failed: %v", err)
 					return err
@@ -697,23 +1459,24 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 
 	log.Printf("[DEBUG] virtual machine config spec: %v", configSpec)
 
-	// We process power state changes here in addition to VM updates. The only
-	// "at rest" power state for a VM managed by Terraform is on, but depending
-	// on if the VM was powered off or not outside of Terraform, we need to
-	// handle rebootRequired in different ways.
+	// We process power state changes here in addition to VM updates. The old
+	// value of power_state is the power state the VM was in when it was last
+	// read, and we need to handle rebootRequired in different ways depending on
+	// if the VM was powered off or not, either in config or outside of
+	// Terraform.
 	o, _ := d.GetChange("power_state")
 	powerState := types.VirtualMachinePowerState(o.(string))
 
 	if rebootRequired && powerState != types.VirtualMachinePowerStatePoweredOff {
 		log.Printf("[INFO] Shutting down virtual machine: %s", d.Id())
-
-		task, err := vm.PowerOff(context.TODO())
-		if err != nil {
+		if err := resourceVSphereVirtualMachineShutdown(d, client, vm, d.Get("force_power_off_on_update").(bool)); err != nil {
 			return err
 		}
+		powerState = types.VirtualMachinePowerStatePoweredOff
+	}
 
-		err = task.Wait(context.TODO())
-		if err != nil {
+	if upgradeHardware {
+		if err := upgradeVirtualMachineHardware(vm, d.Get("hardware_version").(int)); err != nil {
 			return err
 		}
 	}
@@ -728,25 +1491,32 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 			return err
 		}
 
-		err = task.Wait(context.TODO())
+		_, err = waitForTask(task)
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
 			return err
 		}
 	}
 
-	if rebootRequired || powerState != types.VirtualMachinePowerStatePoweredOn {
-		task, err := vm.PowerOn(context.TODO())
+	if len(addedFloppies) > 0 {
+		devices, err := vm.Device(context.TODO())
 		if err != nil {
-			return err
+			return fmt.Errorf("error fetching devices: %s", err)
 		}
-
-		err = task.Wait(context.TODO())
-		if err != nil {
-			log.Printf("[ERROR] %s", err)
-			return err
+		floppies := d.Get("floppy").([]interface{})
+		for i, device := range addedFloppies {
+			floppies[i].(map[string]interface{})["key"] = int(findFloppyDeviceKey(devices, device))
 		}
+		if err := d.Set("floppy", floppies); err != nil {
+			return fmt.Errorf("error setting floppy: %s", err)
+		}
+	}
+
+	if err := resourceVSphereVirtualMachineSetPowerState(d, client, vm, powerState); err != nil {
+		return err
+	}
 
+	if powerState != types.VirtualMachinePowerStatePoweredOn && d.Get("power_state").(string) == string(types.VirtualMachinePowerStatePoweredOn) {
 		// Wait for VM guest networking before returning, so that Read can get
 		// accurate networking info for the state.
 		if d.Get("wait_for_guest_net").(bool) {
@@ -756,6 +1526,20 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 			}
 			log.Printf("[DEBUG] Guest has routeable network access.")
 		}
+
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		if err := waitForNetworkInterfaceIPs(d, client, vm, props); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("upgrade_tools") && d.Get("upgrade_tools").(bool) {
+		if err := upgradeVirtualMachineTools(vm); err != nil {
+			return err
+		}
 	}
 
 	return resourceVSphereVirtualMachineRead(d, meta)
@@ -770,20 +1554,45 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	if err := validateVirtualMachineResourceAllocation(d); err != nil {
+		return err
+	}
+	if err := validateVirtualMachineGuestInfo(d); err != nil {
+		return err
+	}
+
 	vm := virtualMachine{
-		name:     d.Get("name").(string),
-		vcpu:     int32(d.Get("vcpu").(int)),
-		memoryMb: int64(d.Get("memory").(int)),
-		memoryAllocation: memoryAllocation{
-			reservation: int64(d.Get("memory_reservation").(int)),
-		},
+		name:                     d.Get("name").(string),
+		vcpu:                     int32(d.Get("vcpu").(int)),
+		memoryMb:                 int64(d.Get("memory").(int)),
+		cpuAllocation:            expandVirtualMachineResourceAllocation(d, "cpu"),
+		memoryAllocation:         expandVirtualMachineResourceAllocation(d, "memory"),
 		customizationWaitTimeout: d.Get("wait_for_customization_timeout").(int),
+		questionAnswers:          virtualMachineQuestionAnswers(d.Get("question_answers").(map[string]interface{})),
+		sataControllerCount:      -1,
+		powerOn:                  d.Get("power_on").(bool),
+	}
+
+	if v, ok := d.GetOkExists("sata_controller_count"); ok {
+		vm.sataControllerCount = v.(int)
 	}
 
 	if v, ok := d.GetOk("nested_virtualization"); ok {
 		vm.nestedVirtualization = v.(bool)
 	}
 
+	if v, ok := d.GetOk("vpmc_enabled"); ok {
+		vm.vpmcEnabled = v.(bool)
+	}
+
+	if v, ok := d.GetOk("cpu_hot_add_enabled"); ok {
+		vm.cpuHotAddEnabled = v.(bool)
+	}
+
+	if v, ok := d.GetOk("memory_hot_add_enabled"); ok {
+		vm.memoryHotAddEnabled = v.(bool)
+	}
+
 	if v, ok := d.GetOk("hostname"); ok {
 		vm.hostname = v.(string)
 	}
@@ -804,6 +1613,12 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		vm.resourcePool = v.(string)
 	}
 
+	if v, ok := d.GetOk("datastore_cluster_id"); ok {
+		vm.datastoreClusterID = v.(string)
+	}
+	vm.datastorePolicy = d.Get("datastore_selection_policy").(string)
+	vm.candidateDatastoreIDs = sliceInterfacesToStrings(d.Get("candidate_datastore_ids").([]interface{}))
+
 	if v, ok := d.GetOk("domain"); ok {
 		vm.domain = v.(string)
 	}
@@ -818,6 +1633,18 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		vm.annotation = ""
 	}
 
+	if v, ok := d.GetOk("guest_id"); ok {
+		vm.guestID = v.(string)
+	}
+
+	if v, ok := d.GetOk("hardware_version"); ok {
+		vm.hardwareVersion = v.(int)
+	}
+
+	if v, ok := d.GetOk("firmware"); ok {
+		vm.firmware = v.(string)
+	}
+
 	if v, ok := d.GetOk("linked_clone"); ok {
 		vm.linkedClone = v.(bool)
 	}
@@ -830,6 +1657,10 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		vm.enableDiskUUID = v.(bool)
 	}
 
+	if v, ok := d.GetOk("tools_upgrade_policy"); ok {
+		vm.toolsUpgradePolicy = v.(string)
+	}
+
 	if raw, ok := d.GetOk("dns_suffixes"); ok {
 		for _, v := range raw.([]interface{}) {
 			vm.dnsSuffixes = append(vm.dnsSuffixes, v.(string))
@@ -861,8 +1692,18 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		}
 	}
 
-	if vL, ok := d.GetOk("network_interface"); ok {
-		networks := make([]networkInterface, len(vL.([]interface{})))
+	if vL, ok := d.GetOk("guestinfo"); ok {
+		if vm.customConfigurations == nil {
+			vm.customConfigurations = make(map[string]types.AnyType)
+		}
+		for k, v := range vL.(map[string]interface{}) {
+			vm.customConfigurations[guestInfoPrefix+k] = v
+		}
+		log.Printf("[DEBUG] guestinfo init: %v", vm.customConfigurations)
+	}
+
+	if vL, ok := d.GetOk("network_interface"); ok {
+		networks := make([]networkInterface, len(vL.([]interface{})))
 		for i, v := range vL.([]interface{}) {
 			network := v.(map[string]interface{})
 			networks[i].label = network["label"].(string)
@@ -903,9 +1744,32 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 			if v, ok := network["mac_address"].(string); ok && v != "" {
 				networks[i].macAddress = v
 			}
+			if network["use_static_mac"].(bool) && networks[i].macAddress == "" {
+				return fmt.Errorf("network_interface.%d: mac_address is required when use_static_mac is true", i)
+			}
 			if v, ok := network["adapter_type"].(string); ok && v != "" {
 				networks[i].adapterType = v
 			}
+			if v, ok := network["pci_slot_number"].(int); ok && v != 0 {
+				for n := 0; n < i; n++ {
+					if networks[n].pciSlotNumber == int32(v) {
+						return fmt.Errorf("network_interface.%d: pci_slot_number %d is already used by network_interface.%d", i, v, n)
+					}
+				}
+				networks[i].pciSlotNumber = int32(v)
+			}
+			if err := validateVirtualEthernetCardResourceAllocation(network, i); err != nil {
+				return err
+			}
+			networks[i].resourceAllocation = expandVirtualEthernetCardResourceAllocation(network)
+			if network["upt_compatibility_enabled"].(bool) {
+				if networks[i].adapterType != "vmxnet3" {
+					return fmt.Errorf("network_interface.%d: upt_compatibility_enabled is only supported on vmxnet3 network interfaces", i)
+				}
+				networks[i].uptEnabled = true
+			}
+			networks[i].connected = network["connected"].(bool)
+			networks[i].startConnected = network["start_connected"].(bool)
 		}
 		vm.networkInterfaces = networks
 		log.Printf("[DEBUG] network_interface init: %v", networks)
@@ -933,13 +1797,24 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		log.Printf("[DEBUG] windows config init: %v", winOpt)
 	}
 
+	if err := validateIDEAddresses(d.Get("disk").(*schema.Set).List(), d.Get("cdrom").([]interface{})); err != nil {
+		return err
+	}
+
 	if vL, ok := d.GetOk("disk"); ok {
 		if diskSet, ok := vL.(*schema.Set); ok {
+			if err := validateDiskAddresses(diskSet.List()); err != nil {
+				return err
+			}
 
 			disks := []hardDisk{}
 			for _, value := range diskSet.List() {
 				disk := value.(map[string]interface{})
-				newDisk := hardDisk{}
+				newDisk := hardDisk{busNumber: -1, unitNumber: -1}
+
+				if err := validateRawDiskMappingConfig(disk); err != nil {
+					return err
+				}
 
 				if v, ok := disk["template"].(string); ok && v != "" {
 					if v, ok := disk["name"].(string); ok && v != "" {
@@ -956,8 +1831,13 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 					newDisk.initType = v
 				}
 
+				if v, ok := disk["disk_mode"].(string); ok && v != "" {
+					newDisk.diskMode = v
+				}
+
 				if v, ok := disk["datastore"].(string); ok && v != "" {
 					vm.datastore = v
+					newDisk.datastore = v
 				}
 
 				if v, ok := disk["size"].(int); ok && v != 0 {
@@ -982,6 +1862,14 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 					newDisk.controller = v
 				}
 
+				if v, ok := disk["controller_bus_number"].(int); ok {
+					newDisk.busNumber = v
+				}
+
+				if v, ok := disk["unit_number"].(int); ok {
+					newDisk.unitNumber = v
+				}
+
 				if vVmdk, ok := disk["vmdk"].(string); ok && vVmdk != "" {
 					if v, ok := disk["template"].(string); ok && v != "" {
 						return fmt.Errorf("Cannot specify a vmdk for a template")
@@ -1001,6 +1889,13 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 					}
 					newDisk.vmdkPath = vVmdk
 				}
+
+				if v, ok := disk["rdm_lun"].(string); ok && v != "" {
+					newDisk.rdm = &rawDiskMapping{
+						lun:               v,
+						compatibilityMode: disk["rdm_compatibility_mode"].(string),
+					}
+				}
 				// Preserves order so bootable disk is first
 				if newDisk.bootable == true || disk["template"] != "" {
 					disks = append([]hardDisk{newDisk}, disks...)
@@ -1016,22 +1911,47 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 	if vL, ok := d.GetOk("cdrom"); ok {
 		cdroms := make([]cdrom, len(vL.([]interface{})))
 		for i, v := range vL.([]interface{}) {
-			c := v.(map[string]interface{})
-			if v, ok := c["datastore"].(string); ok && v != "" {
-				cdroms[i].datastore = v
-			} else {
-				return fmt.Errorf("Datastore argument must be specified when attaching a cdrom image.")
-			}
-			if v, ok := c["path"].(string); ok && v != "" {
-				cdroms[i].path = v
-			} else {
-				return fmt.Errorf("Path argument must be specified when attaching a cdrom image.")
+			cd, err := expandCdrom(v.(map[string]interface{}))
+			if err != nil {
+				return err
 			}
+			cdroms[i] = cd
 		}
 		vm.cdroms = cdroms
 		log.Printf("[DEBUG] cdrom init: %v", cdroms)
 	}
 
+	for _, v := range d.Get("floppy").([]interface{}) {
+		fl, err := expandFloppy(v.(map[string]interface{}))
+		if err != nil {
+			return err
+		}
+		vm.floppies = append(vm.floppies, fl)
+	}
+
+	if v, ok := d.GetOk("register_vmx_path"); ok {
+		if vm.template != "" {
+			return errors.New("register_vmx_path cannot be used together with a template disk")
+		}
+		vm.registerVmxPath = v.(string)
+	}
+
+	// vSphere generates a new UUID when the one in the configuration is already
+	// in use, so check for that here rather than ending up with a different
+	// UUID than the one asked for.
+	if v, ok := d.GetOk("uuid"); ok {
+		if err := validateVirtualMachineUUIDUnused(client, v.(string), false); err != nil {
+			return err
+		}
+		vm.uuid = v.(string)
+	}
+	if v, ok := d.GetOk("instance_uuid"); ok {
+		if err := validateVirtualMachineUUIDUnused(client, v.(string), true); err != nil {
+			return err
+		}
+		vm.instanceUUID = v.(string)
+	}
+
 	if err := vm.setupVirtualMachine(client); err != nil {
 		return err
 	}
@@ -1039,6 +1959,30 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 	d.SetId(vm.Path())
 	log.Printf("[INFO] Created virtual machine: %s", d.Id())
 
+	// The selected datastore is only recorded on creation, so that changes in
+	// free space do not cause the selection to change later on.
+	d.Set("selected_datastore_id", vm.selectedDatastoreID)
+
+	// Save the device keys of the new cdroms, so that they can be read back.
+	if len(vm.cdroms) > 0 {
+		cdroms := d.Get("cdrom").([]interface{})
+		for i, cd := range vm.cdroms {
+			cdroms[i].(map[string]interface{})["key"] = int(cd.key)
+		}
+		if err := d.Set("cdrom", cdroms); err != nil {
+			return fmt.Errorf("error setting cdrom: %s", err)
+		}
+	}
+	if len(vm.floppies) > 0 {
+		floppies := d.Get("floppy").([]interface{})
+		for i, fl := range vm.floppies {
+			floppies[i].(map[string]interface{})["key"] = int(fl.key)
+		}
+		if err := d.Set("floppy", floppies); err != nil {
+			return fmt.Errorf("error setting floppy: %s", err)
+		}
+	}
+
 	newVM, err := virtualMachineFromManagedObjectID(client, vm.moid)
 	if err != nil {
 		return err
@@ -1048,6 +1992,23 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// New virtual machines are left powered on, unless power_state asks for
+	// something else. They are left powered off when power_on is false.
+	if d.Get("power_on").(bool) && d.Get("power_state").(string) != string(types.VirtualMachinePowerStatePoweredOn) {
+		if err := resourceVSphereVirtualMachineSetPowerState(d, client, newVM, newProps.Runtime.PowerState); err != nil {
+			return err
+		}
+		if newProps, err = virtualMachineProperties(newVM); err != nil {
+			return err
+		}
+	}
+
+	if newProps.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if err := disconnectNetworkInterfaces(d, newVM, newProps); err != nil {
+			return err
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, newVM); err != nil {
@@ -1064,13 +2025,25 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		}
 		log.Printf("[DEBUG] Guest has routeable network access.")
 	}
+
+	if newProps.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if err := waitForNetworkInterfaceIPs(d, client, newVM, newProps); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("upgrade_tools").(bool) && newProps.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if err := upgradeVirtualMachineTools(newVM); err != nil {
+			return err
+		}
+	}
 	return resourceVSphereVirtualMachineRead(d, meta)
 }
 
 func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] virtual machine resource data: %#v", d)
 	client := meta.(*VSphereClient).vimClient
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
@@ -1096,6 +2069,33 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	// Orphaned and invalid virtual machines have no usable configuration, so
+	// they are either repaired first, or reported with an error that explains
+	// what happened to them.
+	d.Set("connection_state", mvm.Runtime.ConnectionState)
+	if err := virtualMachineConnectionStateError(d.Id(), mvm.Runtime.ConnectionState); err != nil {
+		if !d.Get("repair_connection_state").(bool) || !virtualMachineConnectionStateRepairable(mvm.Runtime.ConnectionState) {
+			return err
+		}
+		if meta.(*VSphereClient).readOnlyRefresh {
+			log.Printf("[WARN] Not repairing virtual machine %q, as read_only_refresh is set", d.Id())
+			return err
+		}
+		log.Printf("[WARN] %s. Attempting to repair it", err)
+		vm, err = repairVirtualMachineConnectionState(vm, mvm.Runtime.ConnectionState)
+		if err != nil {
+			return fmt.Errorf("error repairing virtual machine %q: %s", d.Id(), err)
+		}
+		mvm = mo.VirtualMachine{}
+		if err := collector.RetrieveOne(context.TODO(), vm.Reference(), []string{"guest", "summary", "datastore", "config", "runtime"}, &mvm); err != nil {
+			return err
+		}
+		d.Set("connection_state", mvm.Runtime.ConnectionState)
+		if err := virtualMachineConnectionStateError(d.Id(), mvm.Runtime.ConnectionState); err != nil {
+			return fmt.Errorf("virtual machine could not be repaired: %s", err)
+		}
+	}
+
 	log.Printf("[DEBUG] Datacenter - %#v", dc)
 	log.Printf("[DEBUG] mvm.Summary.Config - %#v", mvm.Summary.Config)
 	log.Printf("[DEBUG] mvm.Config - %#v", mvm.Config)
@@ -1108,6 +2108,12 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		log.Printf("[DEBUG] Set the moid: %#v", mvm.Reference().Value)
 	}
 
+	hardware := object.VirtualDeviceList(mvm.Config.Hardware.Device)
+	if err := d.Set("scsi_controllers", flattenSCSIControllers(hardware)); err != nil {
+		return fmt.Errorf("error setting scsi_controllers: %s", err)
+	}
+	d.Set("sata_controller_count", len(sataControllers(hardware)))
+
 	disks := make([]map[string]interface{}, 0)
 	templateDisk := make(map[string]interface{}, 1)
 	for _, device := range mvm.Config.Hardware.Device {
@@ -1118,28 +2124,61 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 			backingInfo := virtualDevice.Backing
 			var diskFullPath string
 			var diskUuid string
+			var diskMode string
 			if v, ok := backingInfo.(*types.VirtualDiskFlatVer2BackingInfo); ok {
 				diskFullPath = v.FileName
 				diskUuid = v.Uuid
+				diskMode = v.DiskMode
 			} else if v, ok := backingInfo.(*types.VirtualDiskSparseVer2BackingInfo); ok {
 				diskFullPath = v.FileName
 				diskUuid = v.Uuid
+				diskMode = v.DiskMode
+			}
+			rdmBacking, isRDM := backingInfo.(*types.VirtualDiskRawDiskMappingVer1BackingInfo)
+			if isRDM {
+				diskFullPath = rdmBacking.FileName
+				diskUuid = rdmBacking.Uuid
+				diskMode = rdmBacking.DiskMode
 			}
 			log.Printf("[DEBUG] resourceVSphereVirtualMachineRead - Analyzing disk: %v", diskFullPath)
 
 			// Separate datastore and path
-			diskFullPathSplit := strings.Split(diskFullPath, " ")
-			if len(diskFullPathSplit) != 2 {
+			dp, ok := vmdkDatastorePath(diskFullPath)
+			if !ok {
 				return fmt.Errorf("[ERROR] Failed trying to parse disk path: %v", diskFullPath)
 			}
-			diskPath := diskFullPathSplit[1]
+			diskPath := dp.Path
 			// Isolate filename
 			diskNameSplit := strings.Split(diskPath, "/")
 			diskName := diskNameSplit[len(diskNameSplit)-1]
 			// Remove possible extension
 			diskName = strings.Split(diskName, ".")[0]
 
-			if prevDisks, ok := d.GetOk("disk"); ok {
+			prevDisks, ok := d.GetOk("disk")
+			if !ok {
+				// There is no disk configuration in state (ie: on import), so
+				// record the disk as attached by its full backing path, or as a
+				// mapping of its LUN for RDMs.
+				disk := map[string]interface{}{
+					"key":              virtualDevice.Key,
+					"uuid":             diskUuid,
+					"vmdk":             dp.String(),
+					"disk_mode":        diskMode,
+					"placed_datastore": dp.Datastore,
+				}
+				if isRDM {
+					lun, err := rawDiskMappingLun(client, mvm.Runtime.Host, rdmBacking)
+					if err != nil {
+						return err
+					}
+					disk["vmdk"] = ""
+					disk["name"] = diskName
+					disk["rdm_lun"] = lun.CanonicalName
+					disk["rdm_compatibility_mode"] = flattenRawDiskMappingCompatibilityMode(rdmBacking.CompatibilityMode)
+				}
+				flattenDiskController(hardware, vd, disk, true)
+				disks = append(disks, disk)
+			} else {
 				if prevDiskSet, ok := prevDisks.(*schema.Set); ok {
 					for _, v := range prevDiskSet.List() {
 						prevDisk := v.(map[string]interface{})
@@ -1148,6 +2187,9 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 						if prevDisk["template"] != "" {
 							if len(templateDisk) == 0 {
 								templateDisk = prevDisk
+								templateDisk["disk_mode"] = diskMode
+								templateDisk["placed_datastore"] = dp.Datastore
+								flattenDiskController(hardware, vd, templateDisk, false)
 								disks = append(disks, templateDisk)
 								break
 							}
@@ -1156,11 +2198,21 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 						// It is enforced that prevDisk["name"] should only be set in the case
 						// of creating a new disk for the user.
 						// size case:  name was set by user, compare parsed filename from mo.filename (without path or .vmdk extension) with name
-						// vmdk case:  compare prevDisk["vmdk"] and mo.Filename
-						if diskName == prevDisk["name"] || diskPath == prevDisk["vmdk"] {
+						// vmdk case:  compare prevDisk["vmdk"] and mo.Filename, either as a
+						// path relative to the datastore or a full "[datastore] path"
+						if diskName == prevDisk["name"] || diskPath == prevDisk["vmdk"] || vmdkMatchesBacking(prevDisk["vmdk"].(string), dp) {
 
 							prevDisk["key"] = virtualDevice.Key
 							prevDisk["uuid"] = diskUuid
+							prevDisk["placed_datastore"] = dp.Datastore
+							if isRDM {
+								if err := flattenRawDiskMapping(client, mvm.Runtime.Host, rdmBacking, prevDisk); err != nil {
+									return err
+								}
+							} else {
+								prevDisk["disk_mode"] = diskMode
+							}
+							flattenDiskController(hardware, vd, prevDisk, false)
 
 							disks = append(disks, prevDisk)
 							break
@@ -1194,7 +2246,24 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		log.Printf("[DEBUG] device name %s", DeviceName)
 		networkInterface["label"] = DeviceName
 		networkInterface["mac_address"] = nic.GetVirtualEthernetCard().MacAddress
+		networkInterface["use_static_mac"] = nic.GetVirtualEthernetCard().AddressType == string(types.VirtualEthernetCardMacTypeManual)
 		networkInterface["key"] = virtualDevice.Key
+		if slot, ok := virtualDevice.SlotInfo.(*types.VirtualDevicePciBusSlotInfo); ok {
+			networkInterface["pci_slot_number"] = slot.PciSlotNumber
+		}
+		flattenVirtualEthernetCardResourceAllocation(networkInterface, nic.GetVirtualEthernetCard().ResourceAllocation)
+		uptEnabled := nic.GetVirtualEthernetCard().UptCompatibilityEnabled
+		networkInterface["upt_compatibility_enabled"] = uptEnabled != nil && *uptEnabled
+		networkInterface["upt_active"] = virtualEthernetCardUptActive(mvm.Runtime, virtualDevice.Key)
+		networkInterface["port_key"] = virtualEthernetCardPortKey(nic)
+		// The connected state of a device is only meaningful while the virtual
+		// machine is powered on, so it is only read back then.
+		if c := virtualDevice.Connectable; c != nil {
+			networkInterface["start_connected"] = c.StartConnected
+			if mvm.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+				networkInterface["connected"] = c.Connected
+			}
+		}
 		log.Printf("[DEBUG] networkInterface %#v", networkInterface)
 		networkInterfaces = append(networkInterfaces, networkInterface)
 	}
@@ -1283,6 +2352,27 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		}
 	}
 	log.Printf("[DEBUG] networkInterfaces: %#v", networkInterfaces)
+	// Keep the network interfaces in the order they are in state, so that a
+	// change in the order vSphere reports the devices in does not renumber
+	// them.
+	if prev, ok := d.Get("network_interface").([]interface{}); ok {
+		networkInterfaces = orderNetworkInterfaces(prev, networkInterfaces)
+		// wait_for_guest_ip is not part of the VM's configuration, so carry it
+		// over from the interface at the same index in state. The same goes for
+		// the connection settings that could not be read back above.
+		for i, networkInterface := range networkInterfaces {
+			if i < len(prev) {
+				if p, ok := prev[i].(map[string]interface{}); ok {
+					networkInterface["wait_for_guest_ip"] = p["wait_for_guest_ip"]
+					for _, k := range []string{"connected", "start_connected"} {
+						if _, ok := networkInterface[k]; !ok {
+							networkInterface[k] = p[k]
+						}
+					}
+				}
+			}
+		}
+	}
 	err = d.Set("network_interface", networkInterfaces)
 	if err != nil {
 		return fmt.Errorf("Invalid network interfaces to set: %#v", networkInterfaces)
@@ -1320,13 +2410,79 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 
 	d.Set("datacenter", dc)
 	d.Set("memory", mvm.Summary.Config.MemorySizeMB)
-	d.Set("memory_reservation", mvm.Summary.Config.MemoryReservation)
+	if err := flattenVirtualMachineResourceAllocation(d, mvm.Config.CpuAllocation, "cpu"); err != nil {
+		return fmt.Errorf("error setting CPU allocation: %s", err)
+	}
+	if err := flattenVirtualMachineResourceAllocation(d, mvm.Config.MemoryAllocation, "memory"); err != nil {
+		return fmt.Errorf("error setting memory allocation: %s", err)
+	}
+	if err := d.Set("custom_configuration_parameters", flattenCustomConfigurations(d, mvm.Config.ExtraConfig)); err != nil {
+		return fmt.Errorf("error setting custom_configuration_parameters: %s", err)
+	}
+	if err := d.Set("guestinfo", flattenGuestInfo(d, mvm.Config.ExtraConfig)); err != nil {
+		return fmt.Errorf("error setting guestinfo: %s", err)
+	}
+	if mvm.Config.Tools != nil && mvm.Config.Tools.ToolsUpgradePolicy != "" {
+		d.Set("tools_upgrade_policy", mvm.Config.Tools.ToolsUpgradePolicy)
+	}
+	if mvm.Guest != nil {
+		d.Set("tools_version", mvm.Guest.ToolsVersion)
+		d.Set("tools_version_status", mvm.Guest.ToolsVersionStatus2)
+	}
+	if mvm.Config.NestedHVEnabled != nil {
+		d.Set("nested_virtualization", *mvm.Config.NestedHVEnabled)
+	}
+	if mvm.Config.VPMCEnabled != nil {
+		d.Set("vpmc_enabled", *mvm.Config.VPMCEnabled)
+	}
+	if mvm.Config.CpuHotAddEnabled != nil {
+		d.Set("cpu_hot_add_enabled", *mvm.Config.CpuHotAddEnabled)
+	}
+	if mvm.Config.MemoryHotAddEnabled != nil {
+		d.Set("memory_hot_add_enabled", *mvm.Config.MemoryHotAddEnabled)
+	}
 	d.Set("cpu", mvm.Summary.Config.NumCpu)
 	d.Set("datastore", rootDatastore)
 	d.Set("uuid", mvm.Summary.Config.Uuid)
+	d.Set("instance_uuid", mvm.Config.InstanceUuid)
 	d.Set("annotation", mvm.Summary.Config.Annotation)
+	d.Set("guest_id", mvm.Config.GuestId)
+	d.Set("firmware", mvm.Config.Firmware)
+	if version, err := parseHardwareVersion(mvm.Config.Version); err == nil {
+		d.Set("hardware_version", version)
+	} else {
+		log.Printf("[WARN] %s: %s", d.Id(), err)
+	}
 	d.Set("power_state", mvm.Runtime.PowerState)
 
+	// Only cdroms created by this resource have a device key, cdroms from older
+	// versions of the provider are left as they are.
+	devices := object.VirtualDeviceList(mvm.Config.Hardware.Device)
+	poweredOn := mvm.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn
+	var cdroms []interface{}
+	for _, v := range d.Get("cdrom").([]interface{}) {
+		m := v.(map[string]interface{})
+		if device, ok := devices.FindByKey(int32(m["key"].(int))).(*types.VirtualCdrom); ok {
+			m = flattenCdrom(device, m, poweredOn)
+			flattenCdromAddress(devices, device, m)
+		}
+		cdroms = append(cdroms, m)
+	}
+	if err := d.Set("cdrom", cdroms); err != nil {
+		return fmt.Errorf("error setting cdrom: %s", err)
+	}
+	var floppies []interface{}
+	for _, v := range d.Get("floppy").([]interface{}) {
+		m := v.(map[string]interface{})
+		if device, ok := devices.FindByKey(int32(m["key"].(int))).(*types.VirtualFloppy); ok {
+			m = flattenFloppy(device, m, poweredOn)
+		}
+		floppies = append(floppies, m)
+	}
+	if err := d.Set("floppy", floppies); err != nil {
+		return fmt.Errorf("error setting floppy: %s", err)
+	}
+
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
 		if err := readTagsForResource(tagsClient, vm, d); err != nil {
@@ -1339,7 +2495,7 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 
 func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
-	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	dc, err := meta.(*VSphereClient).Datacenter(d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
@@ -1363,15 +2519,21 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	}
 
 	if state == types.VirtualMachinePowerStatePoweredOn {
-		task, err := vm.PowerOff(context.TODO())
-		if err != nil {
+		if err := resourceVSphereVirtualMachineShutdown(d, client, vm, d.Get("force_power_off_on_destroy").(bool)); err != nil {
 			return err
 		}
+	}
 
-		err = task.Wait(context.TODO())
-		if err != nil {
-			return err
+	// Unregistering keeps all of the files of the virtual machine, including
+	// its disks, so that it can be registered again from its .vmx file, by
+	// this or another vCenter.
+	if d.Get("unregister_on_destroy").(bool) {
+		log.Printf("[INFO] Unregistering virtual machine, keeping its files: %s", d.Id())
+		if err := vm.Unregister(context.TODO()); err != nil {
+			return fmt.Errorf("error unregistering virtual machine %q: %s", d.Id(), err)
 		}
+		d.SetId("")
+		return nil
 	}
 
 	// Safely eject any disks the user marked as keep_on_remove
@@ -1382,7 +2544,9 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 			for _, value := range diskSetList {
 				disk := value.(map[string]interface{})
 
-				if v, ok := disk["keep_on_remove"].(bool); ok && v == true {
+				// Disks attached from an existing vmdk are never destroyed with the
+				// virtual machine.
+				if v, ok := disk["keep_on_remove"].(bool); (ok && v == true) || disk["vmdk"] != "" {
 					log.Printf("[DEBUG] not destroying %v", disk["name"])
 					virtualDisk := devices.FindByKey(int32(disk["key"].(int)))
 					err = vm.RemoveDevice(context.TODO(), true, virtualDisk)
@@ -1430,7 +2594,7 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 		return err
 	}
 
-	err = task.Wait(context.TODO())
+	_, err = waitForTask(task)
 	if err != nil {
 		return err
 	}
@@ -1439,165 +2603,1470 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-// addHardDisk adds a new Hard Disk to the VirtualMachine.
-func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string) error {
-	devices, err := vm.Device(context.TODO())
+// resourceVSphereVirtualMachineShutdown shuts down the virtual machine using
+// the shutdown_wait_timeout and graceful_shutdown_retries settings of the
+// resource. force controls if the virtual machine is powered off when the
+// guest does not shut down, and comes from the force_power_off_on_* setting
+// for the operation that needs the shutdown. Pending questions are answered
+// using question_answers.
+func resourceVSphereVirtualMachineShutdown(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine, force bool) error {
+	timeout := time.Duration(d.Get("shutdown_wait_timeout").(int)) * time.Minute
+	retries := d.Get("graceful_shutdown_retries").(int)
+	answers := virtualMachineQuestionAnswers(d.Get("question_answers").(map[string]interface{}))
+	err := runWithVirtualMachineQuestionAnswers(client, vm, answers, func() error {
+		return shutdownVirtualMachine(vm, timeout, retries, force)
+	})
+	if err != nil {
+		return fmt.Errorf("error shutting down virtual machine %q: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineSetPowerState moves the virtual machine from
+// its current power state to the one set in power_state. Virtual machines are
+// powered off with a graceful shutdown where possible, and a powered off
+// virtual machine is powered on before it is suspended, as only running
+// virtual machines can be suspended.
+func resourceVSphereVirtualMachineSetPowerState(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine, current types.VirtualMachinePowerState) error {
+	desired := types.VirtualMachinePowerState(d.Get("power_state").(string))
+	if current == desired {
+		return nil
+	}
+	log.Printf("[INFO] Changing power state of virtual machine %s from %s to %s", d.Id(), current, desired)
+	switch desired {
+	case types.VirtualMachinePowerStatePoweredOff:
+		return resourceVSphereVirtualMachineShutdown(d, client, vm, d.Get("force_power_off_on_power_state").(bool))
+	case types.VirtualMachinePowerStatePoweredOn:
+		return resourceVSphereVirtualMachinePowerOn(d, client, vm)
+	case types.VirtualMachinePowerStateSuspended:
+		if current == types.VirtualMachinePowerStatePoweredOff {
+			if err := resourceVSphereVirtualMachinePowerOn(d, client, vm); err != nil {
+				return err
+			}
+		}
+		return suspendVirtualMachine(vm)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachinePowerOn powers on, or resumes, the virtual
+// machine in the datacenter of the resource. Pending questions are answered
+// using question_answers.
+func resourceVSphereVirtualMachinePowerOn(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) error {
+	dc, err := getDatacenter(client, d.Get("datacenter").(string))
 	if err != nil {
 		return err
 	}
-	log.Printf("[DEBUG] vm devices: %#v\n", devices)
+	answers := virtualMachineQuestionAnswers(d.Get("question_answers").(map[string]interface{}))
+	return runWithVirtualMachineQuestionAnswers(client, vm, answers, func() error {
+		return powerOnVirtualMachine(client, dc, vm)
+	})
+}
 
-	var controller types.BaseVirtualController
-	switch controller_type {
-	case "scsi":
-		controller, err = devices.FindDiskController(controller_type)
-	case "scsi-lsi-parallel":
-		controller = devices.PickController(&types.VirtualLsiLogicController{})
-	case "scsi-buslogic":
-		controller = devices.PickController(&types.VirtualBusLogicController{})
-	case "scsi-paravirtual":
-		controller = devices.PickController(&types.ParaVirtualSCSIController{})
-	case "scsi-lsi-sas":
-		controller = devices.PickController(&types.VirtualLsiLogicSASController{})
-	case "ide":
-		controller, err = devices.FindDiskController(controller_type)
-	default:
-		return fmt.Errorf("[ERROR] Unsupported disk controller provided: %v", controller_type)
+// validateVirtualMachineHotAdd checks to see if the vcpu and memory changes
+// in the resource data can be hot added to the running virtual machine
+// described by props. An error describing why the change cannot be hot added
+// is returned otherwise.
+func validateVirtualMachineHotAdd(d *schema.ResourceData, client *govmomi.Client, props *mo.VirtualMachine) error {
+	if props.Config == nil {
+		return errors.New("virtual machine configuration not available")
+	}
+	if err := validateHardwareVersion(props.Config.Version, 7, "hot add"); err != nil {
+		return err
+	}
+	guest, err := virtualMachineGuestOsDescriptor(client, props)
+	if err != nil {
+		return fmt.Errorf("error fetching guest OS capabilities: %s", err)
 	}
 
-	if err != nil || controller == nil {
-		// Check if max number of scsi controller are already used
-		diskControllers := getSCSIControllers(devices)
-		if len(diskControllers) >= 4 {
-			return fmt.Errorf("[ERROR] Maximum number of SCSI controllers created")
+	if d.HasChange("vcpu") {
+		o, n := d.GetChange("vcpu")
+		switch {
+		case n.(int) < o.(int):
+			return errors.New("CPUs cannot be removed from a running virtual machine")
+		case props.Config.CpuHotAddEnabled == nil || !*props.Config.CpuHotAddEnabled:
+			return errors.New("CPU hot add is not enabled")
+		case guest.SupportsCpuHotAdd == nil || !*guest.SupportsCpuHotAdd:
+			return fmt.Errorf("guest OS %q does not support CPU hot add", props.Config.GuestId)
 		}
+	}
 
-		log.Printf("[DEBUG] Couldn't find a %v controller.  Creating one..", controller_type)
+	if d.HasChange("memory") {
+		o, n := d.GetChange("memory")
+		added := int64(n.(int) - o.(int))
+		switch {
+		case added < 0:
+			return errors.New("memory cannot be removed from a running virtual machine")
+		case props.Config.MemoryHotAddEnabled == nil || !*props.Config.MemoryHotAddEnabled:
+			return errors.New("memory hot add is not enabled")
+		case guest.SupportsMemoryHotAdd == nil || !*guest.SupportsMemoryHotAdd:
+			return fmt.Errorf("guest OS %q does not support memory hot add", props.Config.GuestId)
+		case props.Config.HotPlugMemoryLimit != 0 && int64(n.(int)) > props.Config.HotPlugMemoryLimit:
+			return fmt.Errorf("memory (%d) is higher than the hot add limit of %d MB", n.(int), props.Config.HotPlugMemoryLimit)
+		case props.Config.HotPlugMemoryIncrementSize != 0 && added%props.Config.HotPlugMemoryIncrementSize != 0:
+			return fmt.Errorf("hot added memory must be a multiple of %d MB", props.Config.HotPlugMemoryIncrementSize)
+		}
+	}
+	return nil
+}
 
-		var c types.BaseVirtualDevice
-		switch controller_type {
-		case "scsi":
-			// Create scsi controller
-			c, err = devices.CreateSCSIController("scsi")
+// validateVirtualMachineMacAddress validates a mac_address on a network
+// interface. See validateStaticMacAddress for the addresses that are allowed.
+func validateVirtualMachineMacAddress(v interface{}, k string) ([]string, []error) {
+	if v.(string) == "" {
+		return nil, nil
+	}
+	if err := validateStaticMacAddress(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// buildNetworkInterfaceDeviceChange returns the device changes that bring the
+// virtual machine's ethernet cards in line with the network_interface list.
+// See matchNetworkInterfaceDevices for how network interfaces are matched to
+// the ethernet cards. Network interfaces without a card are added, cards
+// without a network interface are removed, and cards of a different adapter
+// type are replaced, as the adapter type of a card can't be changed. All other
+// settings are edited in place. When use_static_mac is disabled, the MAC
+// address is generated by vSphere again. The returned bool is true if any MAC
+// address or PCI slot settings were changed, as these can only be applied
+// while the virtual machine is powered off.
+func buildNetworkInterfaceDeviceChange(d *schema.ResourceData, client *govmomi.Client, finder *find.Finder, vm *object.VirtualMachine) ([]types.BaseVirtualDeviceConfigSpec, bool, error) {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return nil, false, fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+	matched := matchNetworkInterfaceDevices(d, devices)
+
+	var kept object.VirtualDeviceList
+	for i, v := range d.Get("network_interface").([]interface{}) {
+		device := matched[i]
+		if device == nil {
+			continue
+		}
+		if virtualEthernetCardAdapterType(device) != v.(map[string]interface{})["adapter_type"].(string) {
+			log.Printf("[DEBUG] %s: Replacing network interface %d to change its adapter type", d.Id(), i)
+			matched[i] = nil
+			continue
+		}
+		kept = append(kept, device)
+	}
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for _, device := range devices {
+		if kept.FindByKey(device.GetVirtualDevice().Key) == nil {
+			log.Printf("[DEBUG] %s: Removing network interface with key %d", d.Id(), device.GetVirtualDevice().Key)
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    device,
+			})
+		}
+	}
+
+	var powerOff bool
+	for i, v := range d.Get("network_interface").([]interface{}) {
+		network := v.(map[string]interface{})
+		prefix := fmt.Sprintf("network_interface.%d.", i)
+		device := matched[i]
+		if device == nil {
+			nd, err := buildNetworkInterfaceAddDeviceChange(d, client, finder, props, kept, i)
 			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+				return nil, false, err
 			}
-		case "scsi-lsi-parallel":
-			// Create scsi controller
-			c, err = devices.CreateSCSIController("lsilogic")
+			log.Printf("[DEBUG] %s: Adding network interface %d: %+v", d.Id(), i, nd.Device)
+			spec = append(spec, nd)
+			continue
+		}
+
+		card := device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		var changed bool
+		if d.HasChange(prefix + "label") {
+			nd, err := buildNetworkDevice(finder, network["label"].(string), network["adapter_type"].(string), "", 0)
 			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+				return nil, false, fmt.Errorf("network_interface.%d: %s", i, err)
 			}
-		case "scsi-buslogic":
-			// Create scsi controller
-			c, err = devices.CreateSCSIController("buslogic")
-			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			card.Backing = nd.Device.GetVirtualDevice().Backing
+			changed = true
+		}
+		if slot := int32(network["pci_slot_number"].(int)); slot != 0 && virtualDevicePciSlotNumber(device) != slot {
+			card.SlotInfo = &types.VirtualDevicePciBusSlotInfo{
+				PciSlotNumber: slot,
 			}
-		case "scsi-paravirtual":
-			// Create scsi controller
-			c, err = devices.CreateSCSIController("pvscsi")
-			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			changed = true
+			powerOff = true
+		}
+		if d.HasChange(prefix+"use_static_mac") || d.HasChange(prefix+"mac_address") {
+			addressType, mac := string(types.VirtualEthernetCardMacTypeGenerated), ""
+			if network["use_static_mac"].(bool) {
+				mac = network["mac_address"].(string)
+				if mac == "" {
+					return nil, false, fmt.Errorf("network_interface.%d: mac_address is required when use_static_mac is true", i)
+				}
+				if err := validateStaticMacAddress(mac); err != nil {
+					return nil, false, fmt.Errorf("network_interface.%d: %s", i, err)
+				}
+				addressType = string(types.VirtualEthernetCardMacTypeManual)
 			}
-		case "scsi-lsi-sas":
-			// Create scsi controller
-			c, err = devices.CreateSCSIController("lsilogic-sas")
-			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			// Settings that moved to another position in the list show up as
+			// changes, so the card is only edited if it actually differs.
+			if card.AddressType != addressType || (mac != "" && !strings.EqualFold(card.MacAddress, mac)) {
+				card.AddressType = addressType
+				card.MacAddress = mac
+				changed = true
+				powerOff = true
 			}
-		case "ide":
-			// Create ide controller
-			c, err = devices.CreateIDEController()
-			if err != nil {
-				return fmt.Errorf("[ERROR] Failed creating IDE controller: %v", err)
+		}
+		if d.HasChange(prefix+"bandwidth_limit") || d.HasChange(prefix+"bandwidth_reservation") || d.HasChange(prefix+"bandwidth_share_level") || d.HasChange(prefix+"bandwidth_share_count") {
+			if err := validateVirtualEthernetCardResourceAllocation(network, i); err != nil {
+				return nil, false, err
 			}
-		default:
-			return fmt.Errorf("[ERROR] Unsupported disk controller provided: %v", controller_type)
+			allocation := expandVirtualEthernetCardResourceAllocation(network)
+			if err := validateVirtualEthernetCardReservation(client, card.Backing, *allocation.Reservation, i); err != nil {
+				return nil, false, err
+			}
+			card.ResourceAllocation = allocation
+			changed = true
 		}
-
-		vm.AddDevice(context.TODO(), c)
-		// Update our devices list
-		devices, err := vm.Device(context.TODO())
-		if err != nil {
-			return err
+		if d.HasChange(prefix + "upt_compatibility_enabled") {
+			enabled := network["upt_compatibility_enabled"].(bool)
+			if enabled {
+				if err := validateVirtualEthernetCardUpt(client, props.Runtime.Host, device, i); err != nil {
+					return nil, false, err
+				}
+			}
+			card.UptCompatibilityEnabled = boolPtr(enabled)
+			changed = true
 		}
-		controller = devices.PickController(c.(types.BaseVirtualController))
-		if controller == nil {
-			log.Printf("[ERROR] Could not find the new %v controller", controller_type)
-			return fmt.Errorf("Could not find the new %v controller", controller_type)
+		if d.HasChange(prefix+"connected") || d.HasChange(prefix+"start_connected") {
+			if card.Connectable == nil {
+				card.Connectable = &types.VirtualDeviceConnectInfo{AllowGuestControl: true}
+			}
+			card.Connectable.Connected = network["connected"].(bool)
+			card.Connectable.StartConnected = network["start_connected"].(bool)
+			changed = true
+		}
+		if changed {
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationEdit,
+				Device:    device,
+			})
 		}
 	}
+	return spec, powerOff, nil
+}
 
-	log.Printf("[DEBUG] disk controller: %#v\n", controller)
-
-	// TODO Check if diskPath & datastore exist
-	// If diskPath is not specified, pass empty string to CreateDisk()
-	if diskPath == "" {
-		return fmt.Errorf("[ERROR] addHardDisk - No path proided")
-	} else {
-		diskPath = datastore.Path(diskPath)
+// buildNetworkInterfaceAddDeviceChange returns the device change that adds
+// the network interface at index i to an existing virtual machine, the same
+// way that it would be created with the virtual machine. kept is the list of
+// ethernet cards that stay on the virtual machine.
+//
+// A network interface that takes the position of another one in the list
+// inherits the computed MAC address of that interface in the resource data.
+// That address is only used if use_static_mac is set, and it is not in use by
+// one of the cards in kept.
+func buildNetworkInterfaceAddDeviceChange(d *schema.ResourceData, client *govmomi.Client, finder *find.Finder, props *mo.VirtualMachine, kept object.VirtualDeviceList, i int) (*types.VirtualDeviceConfigSpec, error) {
+	network := d.Get(fmt.Sprintf("network_interface.%d", i)).(map[string]interface{})
+	o, n := d.GetChange(fmt.Sprintf("network_interface.%d.mac_address", i))
+	mac := n.(string)
+	if o.(string) == mac && !network["use_static_mac"].(bool) {
+		mac = ""
 	}
-	log.Printf("[DEBUG] addHardDisk - diskPath: %v", diskPath)
-	disk := devices.CreateDisk(controller, datastore.Reference(), diskPath)
+	for _, device := range kept {
+		if mac != "" && strings.EqualFold(device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress, mac) {
+			mac = ""
+		}
+	}
+	if network["use_static_mac"].(bool) && n.(string) == "" {
+		return nil, fmt.Errorf("network_interface.%d: mac_address is required when use_static_mac is true", i)
+	}
+	if err := validateVirtualEthernetCardResourceAllocation(network, i); err != nil {
+		return nil, err
+	}
+
+	nd, err := buildNetworkDevice(finder, network["label"].(string), network["adapter_type"].(string), mac, int32(network["pci_slot_number"].(int)))
+	if err != nil {
+		return nil, fmt.Errorf("network_interface.%d: %s", i, err)
+	}
+	// Each device that is added in the same reconfigure needs its own
+	// temporary key.
+	nd.Device.GetVirtualDevice().Key = int32(-1 - i)
+	card := nd.Device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+	if allocation := expandVirtualEthernetCardResourceAllocation(network); !virtualEthernetCardResourceAllocationIsDefault(allocation) {
+		if err := validateVirtualEthernetCardReservation(client, card.Backing, *allocation.Reservation, i); err != nil {
+			return nil, err
+		}
+		card.ResourceAllocation = allocation
+	}
+	if network["upt_compatibility_enabled"].(bool) {
+		if network["adapter_type"].(string) != "vmxnet3" {
+			return nil, fmt.Errorf("network_interface.%d: upt_compatibility_enabled is only supported on vmxnet3 network interfaces", i)
+		}
+		if err := validateVirtualEthernetCardUpt(client, props.Runtime.Host, nd.Device, i); err != nil {
+			return nil, err
+		}
+		card.UptCompatibilityEnabled = boolPtr(true)
+	}
+	card.Connectable = &types.VirtualDeviceConnectInfo{
+		StartConnected:    network["start_connected"].(bool),
+		Connected:         network["connected"].(bool),
+		AllowGuestControl: true,
+	}
+	return nd, nil
+}
+
+// virtualEthernetCardAdapterType returns the adapter_type value for an
+// ethernet card, the same way it is read into state.
+func virtualEthernetCardAdapterType(device types.BaseVirtualDevice) string {
+	if _, ok := device.(*types.VirtualE1000); ok {
+		return "e1000"
+	}
+	return "vmxnet3"
+}
+
+// virtualDevicePciSlotNumber returns the PCI slot number of a device, or 0 if
+// it has none.
+func virtualDevicePciSlotNumber(device types.BaseVirtualDevice) int32 {
+	if slot, ok := device.GetVirtualDevice().SlotInfo.(*types.VirtualDevicePciBusSlotInfo); ok {
+		return slot.PciSlotNumber
+	}
+	return 0
+}
+
+// matchNetworkInterfaceDevices returns the ethernet card for each network
+// interface in the resource data, in the same order, with nil for network
+// interfaces that have no card. Network interfaces are matched by
+// pci_slot_number first, as this is what the guest numbers its interfaces
+// by, then by the device key saved in state, and by their position if there
+// is no key. Each card is only matched once.
+func matchNetworkInterfaceDevices(d *schema.ResourceData, devices object.VirtualDeviceList) []types.BaseVirtualDevice {
+	nics := d.Get("network_interface").([]interface{})
+	result := make([]types.BaseVirtualDevice, len(nics))
+	used := make(map[int32]bool)
+	take := func(i int, device types.BaseVirtualDevice) {
+		if device == nil || used[device.GetVirtualDevice().Key] {
+			return
+		}
+		result[i] = device
+		used[device.GetVirtualDevice().Key] = true
+	}
+	for i, v := range nics {
+		slot := int32(v.(map[string]interface{})["pci_slot_number"].(int))
+		if slot == 0 {
+			continue
+		}
+		for _, device := range devices {
+			if virtualDevicePciSlotNumber(device) == slot {
+				take(i, device)
+				break
+			}
+		}
+	}
+	for i, v := range nics {
+		if result[i] != nil {
+			continue
+		}
+		if key := v.(map[string]interface{})["key"].(int); key != 0 {
+			take(i, devices.FindByKey(int32(key)))
+		} else if i < len(devices) {
+			take(i, devices[i])
+		}
+	}
+	return result
+}
+
+// networkInterfaceDevice returns the ethernet card for the network interface
+// at index i, or nil if no device could be found. See
+// matchNetworkInterfaceDevices for how network interfaces are matched to
+// devices.
+func networkInterfaceDevice(d *schema.ResourceData, devices object.VirtualDeviceList, i int) types.BaseVirtualDevice {
+	matched := matchNetworkInterfaceDevices(d, devices)
+	if i < len(matched) {
+		return matched[i]
+	}
+	return nil
+}
+
+// disconnectNetworkInterfaces disconnects the ethernet cards of a powered on
+// virtual machine whose network interfaces have connected set to false. This
+// is needed after a power on, as vSphere connects the cards based on
+// start_connected. See networkInterfaceDevice for how network interfaces are
+// matched to the virtual machine's ethernet cards.
+func disconnectNetworkInterfaces(d *schema.ResourceData, vm *object.VirtualMachine, props *mo.VirtualMachine) error {
+	devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for i, v := range d.Get("network_interface").([]interface{}) {
+		if v.(map[string]interface{})["connected"].(bool) {
+			continue
+		}
+		device := networkInterfaceDevice(d, devices, i)
+		if device == nil {
+			continue
+		}
+		c := device.GetVirtualDevice().Connectable
+		if c == nil || !c.Connected {
+			continue
+		}
+		c.Connected = false
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    device,
+		})
+	}
+	if len(spec) < 1 {
+		return nil
+	}
+	task, err := vm.Reconfigure(context.TODO(), types.VirtualMachineConfigSpec{DeviceChange: spec})
+	if err != nil {
+		return fmt.Errorf("error disconnecting network interfaces: %s", err)
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error disconnecting network interfaces: %s", err)
+	}
+	return nil
+}
+
+// waitForNetworkInterfaceIPs waits for the network interfaces that have
+// wait_for_guest_ip set to get an address of the requested family. See
+// networkInterfaceDevice for how network interfaces are matched to the
+// virtual machine's ethernet cards.
+func waitForNetworkInterfaceIPs(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine, props *mo.VirtualMachine) error {
+	devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+	var waits []guestIPWait
+	for i, v := range d.Get("network_interface").([]interface{}) {
+		family := v.(map[string]interface{})["wait_for_guest_ip"].(string)
+		if family == "" {
+			continue
+		}
+		device := networkInterfaceDevice(d, devices, i)
+		if device == nil {
+			return fmt.Errorf("network interface %d not found on virtual machine", i)
+		}
+		waits = append(waits, guestIPWait{
+			index:  i,
+			key:    device.GetVirtualDevice().Key,
+			family: family,
+		})
+	}
+	if len(waits) == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Waiting for guest IP addresses on network interfaces: %+v", waits)
+	timeout := time.Duration(d.Get("wait_for_guest_ip_timeout").(int)) * time.Minute
+	return waitForGuestVMIPs(client, vm, waits, timeout)
+}
+
+// customConfigurationChanges returns the ExtraConfig entries needed to apply
+// the changes to custom_configuration_parameters. Keys that have been removed
+// are sent with an empty value, which removes them from the virtual machine.
+func customConfigurationChanges(d *schema.ResourceData) []types.BaseOptionValue {
+	o, n := d.GetChange("custom_configuration_parameters")
+	ov := extraConfigChanges(o.(map[string]interface{}), n.(map[string]interface{}), "")
+	log.Printf("[DEBUG] custom_configuration_parameters changes: %v", ov)
+	return ov
+}
+
+// guestInfoChanges returns the ExtraConfig entries needed to apply the changes
+// to guestinfo. The keys in guestinfo are given without the guestinfo. prefix,
+// which is added here.
+func guestInfoChanges(d *schema.ResourceData) []types.BaseOptionValue {
+	o, n := d.GetChange("guestinfo")
+	ov := extraConfigChanges(o.(map[string]interface{}), n.(map[string]interface{}), guestInfoPrefix)
+	log.Printf("[DEBUG] guestinfo changes: %v", ov)
+	return ov
+}
+
+// extraConfigChanges returns the ExtraConfig entries that change oldMap into
+// newMap, with prefix added to each key. Only keys that were added or changed
+// are sent, and keys that were removed are sent with an empty value.
+func extraConfigChanges(oldMap, newMap map[string]interface{}, prefix string) []types.BaseOptionValue {
+	var ov []types.BaseOptionValue
+	for k, v := range newMap {
+		if old, ok := oldMap[k]; ok && old == v {
+			continue
+		}
+		ov = append(ov, &types.OptionValue{
+			Key:   prefix + k,
+			Value: v,
+		})
+	}
+	for k := range oldMap {
+		if _, ok := newMap[k]; !ok {
+			ov = append(ov, &types.OptionValue{
+				Key:   prefix + k,
+				Value: "",
+			})
+		}
+	}
+	return ov
+}
+
+// flattenCustomConfigurations returns the custom_configuration_parameters
+// map from the ExtraConfig of a virtual machine. vSphere and VMware Tools add
+// plenty of their own keys to ExtraConfig, so only keys that are already in
+// custom_configuration_parameters, or are listed in
+// managed_custom_configuration_keys, are read back. This detects drift in the
+// keys that are managed by Terraform without producing diffs for the keys
+// that are not.
+func flattenCustomConfigurations(d *schema.ResourceData, extraConfig []types.BaseOptionValue) map[string]interface{} {
+	managed := make(map[string]struct{})
+	for k := range d.Get("custom_configuration_parameters").(map[string]interface{}) {
+		managed[k] = struct{}{}
+	}
+	for _, k := range d.Get("managed_custom_configuration_keys").(*schema.Set).List() {
+		managed[k.(string)] = struct{}{}
+	}
+
+	result := make(map[string]interface{})
+	for _, bov := range extraConfig {
+		opt := bov.GetOptionValue()
+		if _, ok := managed[opt.Key]; !ok {
+			continue
+		}
+		// Keys with an empty value have been removed.
+		if v := fmt.Sprintf("%v", opt.Value); v != "" {
+			result[opt.Key] = v
+		}
+	}
+	return result
+}
+
+// guestInfoPrefix is the prefix of the ExtraConfig keys that the guest can read
+// through VMware Tools, ie: with vmtoolsd --cmd "info-get guestinfo.foo".
+const guestInfoPrefix = "guestinfo."
+
+// flattenGuestInfo returns the guestinfo map from the ExtraConfig of a virtual
+// machine. Like custom_configuration_parameters, only the keys that are
+// already in guestinfo are read back, as the guest and VMware Tools can set
+// guestinfo keys of their own.
+func flattenGuestInfo(d *schema.ResourceData, extraConfig []types.BaseOptionValue) map[string]interface{} {
+	values := make(map[string]string)
+	for _, bov := range extraConfig {
+		opt := bov.GetOptionValue()
+		if strings.HasPrefix(opt.Key, guestInfoPrefix) {
+			values[strings.TrimPrefix(opt.Key, guestInfoPrefix)] = fmt.Sprintf("%v", opt.Value)
+		}
+	}
+
+	result := make(map[string]interface{})
+	for k := range d.Get("guestinfo").(map[string]interface{}) {
+		// Keys with an empty value have been removed.
+		if v := values[k]; v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// validateVirtualMachineGuestInfo checks that the keys in guestinfo are given
+// without the guestinfo. prefix, and that none of them are also set in
+// custom_configuration_parameters, where the two would fight over the value.
+func validateVirtualMachineGuestInfo(d *schema.ResourceData) error {
+	custom := d.Get("custom_configuration_parameters").(map[string]interface{})
+	for k := range d.Get("guestinfo").(map[string]interface{}) {
+		if strings.HasPrefix(k, guestInfoPrefix) {
+			return fmt.Errorf("guestinfo key %q must be given without the %q prefix", k, guestInfoPrefix)
+		}
+		if _, ok := custom[guestInfoPrefix+k]; ok {
+			return fmt.Errorf("guestinfo key %q is also set in custom_configuration_parameters", k)
+		}
+	}
+	return nil
+}
+
+// validateVirtualMachineCPUFeatures checks that the hardware version in
+// version supports the CPU features that are enabled. Both nested hardware
+// virtualization and virtual CPU performance counters require hardware
+// version 9 or higher.
+func validateVirtualMachineCPUFeatures(nestedHV, vpmc bool, version string) error {
+	if nestedHV {
+		if err := validateHardwareVersion(version, 9, "nested_virtualization"); err != nil {
+			return err
+		}
+	}
+	if vpmc {
+		if err := validateHardwareVersion(version, 9, "vpmc_enabled"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diskModeChanges looks for disks that have been removed and added again with
+// only their disk_mode changed, and returns the new disk modes indexed by the
+// device keys of the existing disks. The matched disks are removed from both
+// sets so that they are not processed further.
+func diskModeChanges(removed, added *schema.Set) map[int32]string {
+	changes := make(map[int32]string)
+	for _, oldRaw := range removed.List() {
+		oldDisk := oldRaw.(map[string]interface{})
+		for _, newRaw := range added.List() {
+			newDisk := newRaw.(map[string]interface{})
+			if !diskEqualIgnoringMode(oldDisk, newDisk) {
+				continue
+			}
+			changes[int32(oldDisk["key"].(int))] = newDisk["disk_mode"].(string)
+			removed.Remove(oldRaw)
+			added.Remove(newRaw)
+			break
+		}
+	}
+	return changes
+}
+
+// diskEqualIgnoringMode compares two disk configurations, ignoring the disk
+// mode and any attributes computed from the disk device.
+func diskEqualIgnoringMode(a, b map[string]interface{}) bool {
+	strip := func(m map[string]interface{}) map[string]interface{} {
+		n := make(map[string]interface{})
+		for k, v := range m {
+			switch k {
+			case "disk_mode", "key", "uuid":
+				continue
+			}
+			n[k] = v
+		}
+		return n
+	}
+	return reflect.DeepEqual(strip(a), strip(b))
+}
+
+// buildDiskModeDeviceChange builds the device changes required to change the
+// disk mode of the disks keyed in modes. The mode of a disk cannot be changed
+// while the virtual machine has snapshots.
+func buildDiskModeDeviceChange(vm *object.VirtualMachine, modes map[int32]string) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(context.TODO(), vm.Reference(), []string{"snapshot"}, &props); err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Snapshot != nil {
+		return nil, fmt.Errorf("disk_mode cannot be changed while virtual machine %q has snapshots", vm.InventoryPath)
+	}
+
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("could not get virtual device list: %s", err)
+	}
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for key, mode := range modes {
+		disk, ok := devices.FindByKey(key).(*types.VirtualDisk)
+		if !ok {
+			return nil, fmt.Errorf("could not find disk with key %d", key)
+		}
+		switch backing := disk.Backing.(type) {
+		case *types.VirtualDiskFlatVer2BackingInfo:
+			backing.DiskMode = mode
+		case *types.VirtualDiskSparseVer2BackingInfo:
+			backing.DiskMode = mode
+		case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+			if backing.CompatibilityMode == string(types.VirtualDiskCompatibilityModePhysicalMode) {
+				return nil, fmt.Errorf("disk_mode cannot be changed on disk with key %d, as it is a physical mode RDM", key)
+			}
+			backing.DiskMode = mode
+		default:
+			return nil, fmt.Errorf("disk_mode cannot be changed on disk with key %d (backing type %T)", key, disk.Backing)
+		}
+		log.Printf("[DEBUG] Changing mode of disk %d to %s", key, mode)
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    disk,
+		})
+	}
+	return spec, nil
+}
+
+// validateRawDiskMappingConfig checks the RDM settings of a disk
+// configuration. An RDM cannot be combined with a template or vmdk, and its
+// size is always the size of the mapped LUN, so it cannot be set or changed.
+// A name is required for the mapping file.
+func validateRawDiskMappingConfig(disk map[string]interface{}) error {
+	lun, _ := disk["rdm_lun"].(string)
+	if lun == "" {
+		if v, ok := disk["rdm_compatibility_mode"].(string); ok && v != "" {
+			return fmt.Errorf("rdm_compatibility_mode can only be set when rdm_lun is set")
+		}
+		return nil
+	}
+	if v, ok := disk["template"].(string); ok && v != "" {
+		return fmt.Errorf("cannot specify rdm_lun for a template")
+	}
+	if v, ok := disk["vmdk"].(string); ok && v != "" {
+		return fmt.Errorf("cannot specify both rdm_lun and vmdk")
+	}
+	if v, ok := disk["size"].(int); ok && v != 0 {
+		return fmt.Errorf("cannot specify size for RDM %q: the size of an RDM is the size of the mapped LUN, and RDMs cannot be resized", lun)
+	}
+	if v, ok := disk["name"].(string); !ok || v == "" {
+		return fmt.Errorf("name must be provided for the mapping file of RDM %q", lun)
+	}
+	return nil
+}
+
+// virtualMachineScsiDiskFromLun locates a LUN available for raw device mapping
+// on the host of a virtual machine, by either its canonical name (ie:
+// naa.600508b1001c3ad4) or its device path.
+func virtualMachineScsiDiskFromLun(vm *object.VirtualMachine, lun string) (*types.HostScsiDisk, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	target, err := vm.QueryConfigTarget(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error querying available devices for RDM: %s", err)
+	}
+	for _, info := range target.ScsiDisk {
+		if info.Disk == nil {
+			continue
+		}
+		if info.Disk.CanonicalName == lun || info.Disk.DevicePath == lun {
+			return info.Disk, nil
+		}
+	}
+	return nil, fmt.Errorf("LUN %q is not visible to the host of virtual machine %q, or is not available for raw device mapping", lun, vm.InventoryPath)
+}
+
+// setRawDiskMappingBacking replaces the backing of a new disk with a raw
+// device mapping backing for the LUN described in rdm. The mapping file is
+// created at the location of the disk's original backing.
+func setRawDiskMappingBacking(vm *object.VirtualMachine, disk *types.VirtualDisk, rdm *rawDiskMapping, diskMode string) error {
+	lun, err := virtualMachineScsiDiskFromLun(vm, rdm.lun)
+	if err != nil {
+		return err
+	}
+	mode := types.VirtualDiskCompatibilityModePhysicalMode
+	if rdm.compatibilityMode == "virtual" {
+		mode = types.VirtualDiskCompatibilityModeVirtualMode
+	}
+	switch {
+	case mode == types.VirtualDiskCompatibilityModePhysicalMode:
+		// Physical mode RDMs are never included in snapshots, which vSphere
+		// represents as an independent disk.
+		diskMode = string(types.VirtualDiskModeIndependent_persistent)
+	case diskMode == "":
+		diskMode = string(types.VirtualDiskModePersistent)
+	}
+	file := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).VirtualDeviceFileBackingInfo
+	disk.Backing = &types.VirtualDiskRawDiskMappingVer1BackingInfo{
+		VirtualDeviceFileBackingInfo: file,
+		DeviceName:                   lun.DevicePath,
+		LunUuid:                      lun.Uuid,
+		CompatibilityMode:            string(mode),
+		DiskMode:                     diskMode,
+	}
+	disk.CapacityInKB = lun.Capacity.Block * int64(lun.Capacity.BlockSize) / 1024
+	return nil
+}
+
+// rawDiskMappingLun returns the host LUN that an RDM backing maps to.
+func rawDiskMappingLun(client *govmomi.Client, host *types.ManagedObjectReference, backing *types.VirtualDiskRawDiskMappingVer1BackingInfo) (*types.HostScsiDisk, error) {
+	if host == nil {
+		return nil, fmt.Errorf("cannot read mapped LUN for RDM %s: virtual machine has no host", backing.FileName)
+	}
+	lun, err := hostScsiDiskFromUUID(client, host.Value, backing.LunUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mapped LUN for RDM %s: %s", backing.FileName, err)
+	}
+	return lun, nil
+}
+
+// flattenRawDiskMapping reads the mapped LUN and settings of an RDM backing
+// into an existing disk configuration. The configured LUN is kept if it refers
+// to the mapped LUN by either its canonical name or device path.
+func flattenRawDiskMapping(client *govmomi.Client, host *types.ManagedObjectReference, backing *types.VirtualDiskRawDiskMappingVer1BackingInfo, disk map[string]interface{}) error {
+	lun, err := rawDiskMappingLun(client, host, backing)
+	if err != nil {
+		return err
+	}
+	if v := disk["rdm_lun"]; v != lun.CanonicalName && v != lun.DevicePath {
+		disk["rdm_lun"] = lun.CanonicalName
+	}
+	if v := disk["rdm_compatibility_mode"]; v != "" || backing.CompatibilityMode != string(types.VirtualDiskCompatibilityModePhysicalMode) {
+		disk["rdm_compatibility_mode"] = flattenRawDiskMappingCompatibilityMode(backing.CompatibilityMode)
+	}
+	// The disk mode of a physical mode RDM is managed by vSphere.
+	if backing.CompatibilityMode == string(types.VirtualDiskCompatibilityModeVirtualMode) {
+		disk["disk_mode"] = backing.DiskMode
+	}
+	return nil
+}
+
+// flattenRawDiskMappingCompatibilityMode converts a VirtualDiskCompatibilityMode
+// to the value used in the rdm_compatibility_mode attribute.
+func flattenRawDiskMappingCompatibilityMode(mode string) string {
+	if mode == string(types.VirtualDiskCompatibilityModeVirtualMode) {
+		return "virtual"
+	}
+	return "physical"
+}
+
+// vmdkDatastorePath parses a vmdk reference given in full "[datastore]
+// path/to.vmdk" form. false is returned if the reference is not in this form,
+// ie: it is a path relative to a datastore.
+func vmdkDatastorePath(vmdk string) (object.DatastorePath, bool) {
+	var dp object.DatastorePath
+	ok := dp.FromString(vmdk)
+	return dp, ok
+}
+
+// validateVirtualMachineVmxPath validates register_vmx_path, which must be a
+// .vmx file given in full "[datastore] path/to.vmx" form.
+func validateVirtualMachineVmxPath(v interface{}, k string) ([]string, []error) {
+	if _, ok := vmdkDatastorePath(v.(string)); !ok || !strings.HasSuffix(v.(string), ".vmx") {
+		return nil, []error{fmt.Errorf("%s: must be the path to a .vmx file in \"[datastore] path/to.vmx\" form, got %q", k, v.(string))}
+	}
+	return nil, nil
+}
+
+// validateVirtualMachineUUID validates uuid and instance_uuid, which must be
+// given in 8-4-4-4-12 hexadecimal form.
+func validateVirtualMachineUUID(v interface{}, k string) ([]string, []error) {
+	if !virtualMachineUUIDRegexp.MatchString(v.(string)) {
+		return nil, []error{fmt.Errorf("%s: must be a UUID in 8-4-4-4-12 hexadecimal form, ie: 42010f4c-3a5c-57e1-8c26-4f0fb4b5c1d3, got %q", k, v.(string))}
+	}
+	return nil, nil
+}
+
+// vmdkMatchesBacking returns true if a vmdk reference in full "[datastore]
+// path" form refers to the disk backing at dp.
+func vmdkMatchesBacking(vmdk string, dp object.DatastorePath) bool {
+	vdp, ok := vmdkDatastorePath(vmdk)
+	if !ok {
+		return false
+	}
+	return vdp.Datastore == dp.Datastore && vdp.Path == dp.Path
+}
+
+// addHardDisk adds a new Hard Disk to the VirtualMachine.
+//
+// If busNumber is not -1, SCSI disks are added to the controller on that SCSI
+// bus, which is created if the bus is not in use yet. If unitNumber is not -1,
+// the disk is added at that unit number on the controller, which must be free.
+func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string, busNumber, unitNumber int, diskMode string, rdm *rawDiskMapping) error {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] vm devices: %#v\n", devices)
+
+	var controller types.BaseVirtualController
+	if controller_type == "sata" {
+		controller, err = sataControllerForDisk(devices, int32(busNumber))
+		if err != nil {
+			return err
+		}
+	} else if busNumber >= 0 && controller_type == "ide" {
+		c, err := ideControllerForDevice(devices, int32(busNumber), int32(unitNumber))
+		if err != nil {
+			return err
+		}
+		controller = c
+	} else if busNumber >= 0 && strings.HasPrefix(controller_type, "scsi") {
+		controller, err = scsiControllerForDisk(vm, controller_type, int32(busNumber))
+		if err != nil {
+			return err
+		}
+		devices, err = vm.Device(context.TODO())
+		if err != nil {
+			return err
+		}
+	} else {
+		switch controller_type {
+		case "scsi":
+			controller, err = devices.FindDiskController(controller_type)
+		case "scsi-lsi-parallel":
+			controller = devices.PickController(&types.VirtualLsiLogicController{})
+		case "scsi-buslogic":
+			controller = devices.PickController(&types.VirtualBusLogicController{})
+		case "scsi-paravirtual":
+			controller = devices.PickController(&types.ParaVirtualSCSIController{})
+		case "scsi-lsi-sas":
+			controller = devices.PickController(&types.VirtualLsiLogicSASController{})
+		case "ide":
+			controller, err = devices.FindDiskController(controller_type)
+		default:
+			return fmt.Errorf("[ERROR] Unsupported disk controller provided: %v", controller_type)
+		}
+	}
+
+	if err != nil || controller == nil {
+		// Check if max number of scsi controller are already used
+		diskControllers := getSCSIControllers(devices)
+		if len(diskControllers) >= 4 {
+			return fmt.Errorf("[ERROR] Maximum number of SCSI controllers created")
+		}
+
+		log.Printf("[DEBUG] Couldn't find a %v controller.  Creating one..", controller_type)
+
+		var c types.BaseVirtualDevice
+		switch controller_type {
+		case "scsi":
+			// Create scsi controller
+			c, err = devices.CreateSCSIController("scsi")
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			}
+		case "scsi-lsi-parallel":
+			// Create scsi controller
+			c, err = devices.CreateSCSIController("lsilogic")
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			}
+		case "scsi-buslogic":
+			// Create scsi controller
+			c, err = devices.CreateSCSIController("buslogic")
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			}
+		case "scsi-paravirtual":
+			// Create scsi controller
+			c, err = devices.CreateSCSIController("pvscsi")
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			}
+		case "scsi-lsi-sas":
+			// Create scsi controller
+			c, err = devices.CreateSCSIController("lsilogic-sas")
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating SCSI controller: %v", err)
+			}
+		case "ide":
+			// Create ide controller
+			c, err = devices.CreateIDEController()
+			if err != nil {
+				return fmt.Errorf("[ERROR] Failed creating IDE controller: %v", err)
+			}
+		default:
+			return fmt.Errorf("[ERROR] Unsupported disk controller provided: %v", controller_type)
+		}
+
+		vm.AddDevice(context.TODO(), c)
+		// Update our devices list
+		devices, err := vm.Device(context.TODO())
+		if err != nil {
+			return err
+		}
+		controller = devices.PickController(c.(types.BaseVirtualController))
+		if controller == nil {
+			log.Printf("[ERROR] Could not find the new %v controller", controller_type)
+			return fmt.Errorf("Could not find the new %v controller", controller_type)
+		}
+	}
+
+	log.Printf("[DEBUG] disk controller: %#v\n", controller)
+
+	// TODO Check if diskPath & datastore exist
+	// If diskPath is not specified, pass empty string to CreateDisk()
+	if diskPath == "" {
+		return fmt.Errorf("[ERROR] addHardDisk - No path proided")
+	} else if _, ok := vmdkDatastorePath(diskPath); !ok {
+		diskPath = datastore.Path(diskPath)
+	}
+	log.Printf("[DEBUG] addHardDisk - diskPath: %v", diskPath)
+	disk := devices.CreateDisk(controller, datastore.Reference(), diskPath)
+	if rdm != nil {
+		if err := setRawDiskMappingBacking(vm, disk, rdm, diskMode); err != nil {
+			return err
+		}
+	}
+
+	if strings.Contains(controller_type, "scsi") {
+		if unitNumber >= 0 {
+			if used := unitNumberDevice(devices, controller, int32(unitNumber)); used != nil {
+				return fmt.Errorf("unit number %d on SCSI bus %d is already in use by %s", unitNumber, busNumber, devices.Name(used))
+			}
+			*disk.UnitNumber = int32(unitNumber)
+		} else {
+			unitNumber, err := getNextUnitNumber(devices, controller)
+			if err != nil {
+				return err
+			}
+			*disk.UnitNumber = unitNumber
+		}
+	} else if controller_type == "sata" && unitNumber >= 0 {
+		if used := unitNumberDevice(devices, controller, int32(unitNumber)); used != nil {
+			return fmt.Errorf("unit number %d on SATA bus %d is already in use by %s", unitNumber, busNumber, devices.Name(used))
+		}
+		*disk.UnitNumber = int32(unitNumber)
+	} else if controller_type == "ide" && unitNumber >= 0 {
+		// ideControllerForDevice has already checked that the unit is free.
+		*disk.UnitNumber = int32(unitNumber)
+	}
+
+	existing := devices.SelectByBackingInfo(disk.Backing)
+	log.Printf("[DEBUG] disk: %#v\n", disk)
+
+	if len(existing) == 0 {
+		if rdm != nil {
+			log.Printf("[DEBUG] addHardDisk: Mapping LUN %s: %#v\n", rdm.lun, disk)
+			return vm.AddDevice(context.TODO(), disk)
+		}
+		disk.CapacityInKB = int64(size * 1024 * 1024)
+		if iops != 0 {
+			disk.StorageIOAllocation = &types.StorageIOAllocationInfo{
+				Limit: &iops,
+			}
+		}
+		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+
+		if diskType == "eager_zeroed" {
+			// eager zeroed thick virtual disk
+			backing.ThinProvisioned = types.NewBool(false)
+			backing.EagerlyScrub = types.NewBool(true)
+		} else if diskType == "lazy" {
+			// lazy zeroed thick virtual disk
+			backing.ThinProvisioned = types.NewBool(false)
+			backing.EagerlyScrub = types.NewBool(false)
+		} else if diskType == "thin" {
+			// thin provisioned virtual disk
+			backing.ThinProvisioned = types.NewBool(true)
+		}
+		if diskMode != "" {
+			backing.DiskMode = diskMode
+		}
+
+		log.Printf("[DEBUG] addHardDisk: %#v\n", disk)
+		log.Printf("[DEBUG] addHardDisk capacity: %#v\n", disk.CapacityInKB)
+
+		return vm.AddDevice(context.TODO(), disk)
+	} else {
+		log.Printf("[DEBUG] addHardDisk: Disk already present.\n")
+
+		return nil
+	}
+}
+
+func getSCSIControllers(vmDevices object.VirtualDeviceList) []*types.VirtualController {
+	// get virtual scsi controllers of all supported types
+	var scsiControllers []*types.VirtualController
+	for _, device := range vmDevices {
+		devType := vmDevices.Type(device)
+		switch devType {
+		case "scsi", "lsilogic", "buslogic", "pvscsi", "lsilogic-sas":
+			if c, ok := device.(types.BaseVirtualController); ok {
+				scsiControllers = append(scsiControllers, c.GetVirtualController())
+			}
+		}
+	}
+	return scsiControllers
+}
+
+// scsiControllerDeviceTypes maps the SCSI controller_type values of a disk to
+// the SCSI controller device types used by govmomi. The generic scsi type
+// matches a controller of any type, and creates an LSI Logic controller.
+var scsiControllerDeviceTypes = map[string]string{
+	"scsi":              "scsi",
+	"scsi-lsi-parallel": "lsilogic",
+	"scsi-buslogic":     "buslogic",
+	"scsi-paravirtual":  "pvscsi",
+	"scsi-lsi-sas":      "lsilogic-sas",
+}
+
+// scsiControllerDiskControllerType returns the disk controller_type value for
+// a SCSI controller device type, ie: scsi-paravirtual for pvscsi.
+func scsiControllerDiskControllerType(deviceType string) string {
+	for k, v := range scsiControllerDeviceTypes {
+		if k != "scsi" && v == deviceType {
+			return k
+		}
+	}
+	return "scsi"
+}
+
+// scsiControllerOnBus returns the SCSI controller on SCSI bus number bus, or
+// nil if the bus is not in use.
+func scsiControllerOnBus(devices object.VirtualDeviceList, bus int32) types.BaseVirtualController {
+	for _, device := range devices {
+		if c, ok := device.(types.BaseVirtualSCSIController); ok && c.GetVirtualSCSIController().BusNumber == bus {
+			return device.(types.BaseVirtualController)
+		}
+	}
+	return nil
+}
+
+// scsiControllerForDisk returns the SCSI controller on SCSI bus number bus for
+// a disk with the controller_type controllerType. If the bus is not in use, a
+// controller of that type is created on it. An error is returned if the
+// controller on the bus is of a different type.
+func scsiControllerForDisk(vm *object.VirtualMachine, controllerType string, bus int32) (types.BaseVirtualController, error) {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	if c := scsiControllerOnBus(devices, bus); c != nil {
+		actual := scsiControllerDiskControllerType(devices.Type(c.(types.BaseVirtualDevice)))
+		if controllerType != "scsi" && controllerType != actual {
+			return nil, fmt.Errorf("disk requires a %s controller on SCSI bus %d, but the controller on that bus is %s", controllerType, bus, actual)
+		}
+		return c, nil
+	}
+
+	if len(getSCSIControllers(devices)) >= 4 {
+		return nil, errors.New("maximum number of SCSI controllers created")
+	}
+	log.Printf("[DEBUG] Creating %s controller on SCSI bus %d", controllerType, bus)
+	c, err := devices.CreateSCSIController(scsiControllerDeviceTypes[controllerType])
+	if err != nil {
+		return nil, fmt.Errorf("error creating SCSI controller: %s", err)
+	}
+	c.(types.BaseVirtualSCSIController).GetVirtualSCSIController().BusNumber = bus
+	if err := vm.AddDevice(context.TODO(), c); err != nil {
+		return nil, fmt.Errorf("error adding SCSI controller: %s", err)
+	}
+	devices, err = vm.Device(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	if c := scsiControllerOnBus(devices, bus); c != nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("could not find the new SCSI controller on bus %d", bus)
+}
+
+// firstSCSIControllerType returns the SCSI controller device type to create
+// the first SCSI controller of a new virtual machine with. This is the type of
+// the first disk that is placed on SCSI bus 0 with a specific controller
+// type, or the default SCSI controller type if there is none.
+func firstSCSIControllerType(disks []hardDisk) string {
+	for _, disk := range disks {
+		if disk.busNumber == 0 && disk.controller != "scsi" {
+			if t, ok := scsiControllerDeviceTypes[disk.controller]; ok {
+				return t
+			}
+		}
+	}
+	return "scsi"
+}
+
+// flattenDiskController saves the type and bus number of the controller that
+// a disk is attached to, and the disk's unit number if unit_number is set, in
+// the disk's state. Only disks on SCSI, SATA, or IDE controllers that have
+// controller_bus_number set are updated, and the generic scsi controller type
+// is kept as it matches any SCSI controller, unless all is true, which is used
+// for disks that have no configuration in state.
+func flattenDiskController(devices object.VirtualDeviceList, vd *types.VirtualDisk, disk map[string]interface{}, all bool) {
+	var controllerType string
+	var bus int32
+	switch c := devices.FindByKey(vd.ControllerKey).(type) {
+	case types.BaseVirtualSCSIController:
+		controllerType = scsiControllerDiskControllerType(devices.Type(c.(types.BaseVirtualDevice)))
+		bus = c.GetVirtualSCSIController().BusNumber
+	case types.BaseVirtualSATAController:
+		controllerType = "sata"
+		bus = c.GetVirtualSATAController().BusNumber
+	case *types.VirtualIDEController:
+		controllerType = "ide"
+		bus = c.BusNumber
+	default:
+		return
+	}
+	if v, ok := disk["controller_bus_number"].(int); !all && (!ok || v < 0) {
+		return
+	}
+	if all || disk["controller_type"] != "scsi" {
+		disk["controller_type"] = controllerType
+	}
+	disk["controller_bus_number"] = int(bus)
+	if v, ok := disk["unit_number"].(int); vd.UnitNumber != nil && (all || (ok && v >= 0)) {
+		disk["unit_number"] = int(*vd.UnitNumber)
+	}
+}
+
+// flattenSCSIControllers returns the bus numbers and types of the SCSI
+// controllers in devices, for the scsi_controllers attribute.
+func flattenSCSIControllers(devices object.VirtualDeviceList) []interface{} {
+	var controllers []interface{}
+	for _, device := range devices {
+		if c, ok := device.(types.BaseVirtualSCSIController); ok {
+			controllers = append(controllers, map[string]interface{}{
+				"bus_number": int(c.GetVirtualSCSIController().BusNumber),
+				"type":       scsiControllerDiskControllerType(devices.Type(device)),
+			})
+		}
+	}
+	return controllers
+}
+
+// validateDiskControllerChanges looks for disks that have been removed and
+// added again with only their controller changed. Moving a disk to another
+// controller removes the disk and adds it again, which deletes it unless it
+// is detached, so this is refused for the template or bootable disk of a
+// virtual machine, which would no longer boot.
+func validateDiskControllerChanges(removed, added *schema.Set) error {
+	for _, oldRaw := range removed.List() {
+		oldDisk := oldRaw.(map[string]interface{})
+		for _, newRaw := range added.List() {
+			newDisk := newRaw.(map[string]interface{})
+			if !diskEqualIgnoringController(oldDisk, newDisk) {
+				continue
+			}
+			name := oldDisk["name"].(string)
+			if name == "" {
+				name = oldDisk["vmdk"].(string)
+			}
+			if v := oldDisk["template"].(string); v != "" {
+				name = fmt.Sprintf("cloned from template %s", v)
+			}
+			msg := fmt.Sprintf(
+				"changing the controller of disk %s from %s (bus %d, unit %d) to %s (bus %d, unit %d) requires the disk to be removed and added again",
+				name, oldDisk["controller_type"], oldDisk["controller_bus_number"], oldDisk["unit_number"],
+				newDisk["controller_type"], newDisk["controller_bus_number"], newDisk["unit_number"],
+			)
+			if oldDisk["template"].(string) != "" || oldDisk["bootable"].(bool) {
+				return fmt.Errorf("%s. This disk is the boot disk of the virtual machine, which must be recreated to change it", msg)
+			}
+			log.Printf("[WARN] %s", msg)
+		}
+	}
+	return nil
+}
+
+// diskEqualIgnoringController compares two disk configurations, ignoring the
+// controller and any attributes computed from the disk device.
+func diskEqualIgnoringController(a, b map[string]interface{}) bool {
+	strip := func(m map[string]interface{}) map[string]interface{} {
+		n := make(map[string]interface{})
+		for k, v := range m {
+			switch k {
+			case "controller_type", "controller_bus_number", "unit_number", "key", "uuid", "placed_datastore":
+				continue
+			}
+			n[k] = v
+		}
+		return n
+	}
+	return reflect.DeepEqual(strip(a), strip(b))
+}
+
+// sataControllers returns the SATA controllers in devices, sorted by bus
+// number.
+func sataControllers(devices object.VirtualDeviceList) []types.BaseVirtualController {
+	var controllers []types.BaseVirtualController
+	for _, device := range devices {
+		if _, ok := device.(types.BaseVirtualSATAController); ok {
+			controllers = append(controllers, device.(types.BaseVirtualController))
+		}
+	}
+	sort.Slice(controllers, func(i, j int) bool {
+		return controllers[i].GetVirtualController().BusNumber < controllers[j].GetVirtualController().BusNumber
+	})
+	return controllers
+}
+
+// sataControllerForDisk returns the SATA controller on SATA bus number bus for
+// a disk, or the SATA controller with the lowest bus number if bus is -1. SATA
+// controllers are not created for disks, they are managed with
+// sata_controller_count.
+func sataControllerForDisk(devices object.VirtualDeviceList, bus int32) (types.BaseVirtualController, error) {
+	for _, c := range sataControllers(devices) {
+		if bus < 0 || c.GetVirtualController().BusNumber == bus {
+			return c, nil
+		}
+	}
+	if bus < 0 {
+		return nil, errors.New("no SATA controller found: set sata_controller_count to add SATA controllers")
+	}
+	return nil, fmt.Errorf("no SATA controller found on bus %d: set sata_controller_count to add SATA controllers", bus)
+}
+
+// buildSATAControllerDeviceChange builds the device changes required to
+// bring the number of SATA controllers in devices to count. Controllers are
+// added on the lowest free bus numbers, and the controllers with the highest
+// bus numbers are removed first. A controller that still has devices attached
+// to it is not removed, and an error is returned instead.
+func buildSATAControllerDeviceChange(devices object.VirtualDeviceList, count int) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	controllers := sataControllers(devices)
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for i := len(controllers) - 1; i >= count; i-- {
+		c := controllers[i].GetVirtualController()
+		for _, device := range devices {
+			if device.GetVirtualDevice().ControllerKey == c.Key {
+				return nil, fmt.Errorf("cannot remove SATA controller on bus %d: %s is still attached to it", c.BusNumber, devices.Name(device))
+			}
+		}
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationRemove,
+			Device:    controllers[i].(types.BaseVirtualDevice),
+		})
+	}
+	used := make(map[int32]bool)
+	for _, c := range controllers {
+		used[c.GetVirtualController().BusNumber] = true
+	}
+	key := devices.NewKey()
+	for n, bus := len(controllers), int32(0); n < count && bus < 4; bus++ {
+		if used[bus] {
+			continue
+		}
+		c := &types.VirtualAHCIController{}
+		c.Key = key
+		c.BusNumber = bus
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    c,
+		})
+		key--
+		n++
+	}
+	return spec, nil
+}
+
+// setSATAControllerCount adds or removes SATA controllers on a powered off
+// virtual machine until it has count SATA controllers.
+func setSATAControllerCount(vm *object.VirtualMachine, count int) error {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error fetching devices: %s", err)
+	}
+	deviceChange, err := buildSATAControllerDeviceChange(devices, count)
+	if err != nil {
+		return err
+	}
+	if len(deviceChange) < 1 {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Changing the number of SATA controllers to %d", vm.InventoryPath, count)
+	task, err := vm.Reconfigure(context.TODO(), types.VirtualMachineConfigSpec{DeviceChange: deviceChange})
+	if err != nil {
+		return fmt.Errorf("error changing SATA controllers: %s", err)
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error changing SATA controllers: %s", err)
+	}
+	return nil
+}
 
-	if strings.Contains(controller_type, "scsi") {
-		unitNumber, err := getNextUnitNumber(devices, controller)
-		if err != nil {
-			return err
+// validateDiskAddresses checks the controller_bus_number and unit_number of
+// the disks in disks. A unit number can only be set on SCSI or SATA disks that
+// also have a bus number, and cannot be used by more than one disk on the same
+// bus. SCSI unit numbers go up to 15, and cannot be 7, which is used by the
+// SCSI controller itself. SATA unit numbers go up to 29.
+func validateDiskAddresses(disks []interface{}) error {
+	used := make(map[string]bool)
+	for _, raw := range disks {
+		disk := raw.(map[string]interface{})
+		unit, _ := disk["unit_number"].(int)
+		if unit < 0 {
+			continue
+		}
+		bus, _ := disk["controller_bus_number"].(int)
+		controllerType := disk["controller_type"].(string)
+		if controllerType == "ide" {
+			// IDE disks are checked together with the cdroms by
+			// validateIDEAddresses.
+			continue
+		}
+		var prefix string
+		switch {
+		case bus < 0:
+		case strings.HasPrefix(controllerType, "scsi"):
+			prefix = "SCSI"
+		case controllerType == "sata":
+			prefix = "SATA"
+		}
+		if prefix == "" {
+			return fmt.Errorf("unit_number %d can only be set on disks that have controller_bus_number set", unit)
+		}
+		if prefix == "SCSI" && unit > 15 {
+			return fmt.Errorf("unit_number %d on SCSI bus %d is out of range: SCSI unit numbers go up to 15", unit, bus)
+		}
+		if prefix == "SCSI" && unit == 7 {
+			return fmt.Errorf("unit_number 7 on SCSI bus %d is reserved for the SCSI controller", bus)
 		}
-		*disk.UnitNumber = unitNumber
+		addr := fmt.Sprintf("%s %d:%d", prefix, bus, unit)
+		if used[addr] {
+			return fmt.Errorf("more than one disk is placed at %s", addr)
+		}
+		used[addr] = true
 	}
+	return nil
+}
 
-	existing := devices.SelectByBackingInfo(disk.Backing)
-	log.Printf("[DEBUG] disk: %#v\n", disk)
+// validateIDEAddresses checks the controller_bus_number and unit_number of the
+// IDE disks in disks and the cdroms in cdroms, which are always attached to
+// IDE. A virtual machine always has two IDE controllers, on bus numbers 0 and
+// 1, that take two devices each, at unit numbers 0 and 1. A unit number can
+// only be set together with a bus number, and no more than two devices can be
+// placed on the same bus, or more than one at the same address.
+func validateIDEAddresses(disks, cdroms []interface{}) error {
+	var devices []map[string]interface{}
+	for _, raw := range disks {
+		if disk := raw.(map[string]interface{}); disk["controller_type"] == "ide" {
+			devices = append(devices, disk)
+		}
+	}
+	for _, raw := range cdroms {
+		devices = append(devices, raw.(map[string]interface{}))
+	}
+	if len(devices) > 4 {
+		return fmt.Errorf("%d IDE disks and cdroms are configured, but the two IDE controllers only take 4 devices", len(devices))
+	}
 
-	if len(existing) == 0 {
-		disk.CapacityInKB = int64(size * 1024 * 1024)
-		if iops != 0 {
-			disk.StorageIOAllocation = &types.StorageIOAllocationInfo{
-				Limit: &iops,
+	count := make(map[int]int)
+	used := make(map[string]bool)
+	for _, m := range devices {
+		bus, _ := m["controller_bus_number"].(int)
+		unit, _ := m["unit_number"].(int)
+		if bus > 1 {
+			return fmt.Errorf("controller_bus_number %d is out of range for IDE: IDE bus numbers go up to 1", bus)
+		}
+		if unit > 1 {
+			return fmt.Errorf("unit_number %d is out of range for IDE: IDE unit numbers go up to 1", unit)
+		}
+		if bus < 0 {
+			if unit >= 0 {
+				return fmt.Errorf("unit_number %d can only be set on IDE devices that have controller_bus_number set", unit)
 			}
+			continue
 		}
-		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
-
-		if diskType == "eager_zeroed" {
-			// eager zeroed thick virtual disk
-			backing.ThinProvisioned = types.NewBool(false)
-			backing.EagerlyScrub = types.NewBool(true)
-		} else if diskType == "lazy" {
-			// lazy zeroed thick virtual disk
-			backing.ThinProvisioned = types.NewBool(false)
-			backing.EagerlyScrub = types.NewBool(false)
-		} else if diskType == "thin" {
-			// thin provisioned virtual disk
-			backing.ThinProvisioned = types.NewBool(true)
+		count[bus]++
+		if count[bus] > 2 {
+			return fmt.Errorf("more than 2 devices are placed on IDE bus %d", bus)
 		}
-
-		log.Printf("[DEBUG] addHardDisk: %#v\n", disk)
-		log.Printf("[DEBUG] addHardDisk capacity: %#v\n", disk.CapacityInKB)
-
-		return vm.AddDevice(context.TODO(), disk)
-	} else {
-		log.Printf("[DEBUG] addHardDisk: Disk already present.\n")
-
-		return nil
+		if unit < 0 {
+			continue
+		}
+		addr := fmt.Sprintf("IDE %d:%d", bus, unit)
+		if used[addr] {
+			return fmt.Errorf("more than one device is placed at %s", addr)
+		}
+		used[addr] = true
 	}
+	return nil
 }
 
-func getSCSIControllers(vmDevices object.VirtualDeviceList) []*types.VirtualController {
-	// get virtual scsi controllers of all supported types
-	var scsiControllers []*types.VirtualController
-	for _, device := range vmDevices {
-		devType := vmDevices.Type(device)
-		switch devType {
-		case "scsi", "lsilogic", "buslogic", "pvscsi", "lsilogic-sas":
-			if c, ok := device.(types.BaseVirtualController); ok {
-				scsiControllers = append(scsiControllers, c.GetVirtualController())
+// ideControllerForDevice returns the IDE controller on IDE bus number bus for
+// a new disk or cdrom. If unit is not -1, an error is returned if a device is
+// already at that unit number, otherwise an error is returned if the
+// controller has no free unit number left. IDE controllers are never created
+// for a specific bus, as every virtual machine has both of them.
+func ideControllerForDevice(devices object.VirtualDeviceList, bus, unit int32) (*types.VirtualIDEController, error) {
+	for _, device := range devices.SelectByType((*types.VirtualIDEController)(nil)) {
+		c := device.(*types.VirtualIDEController)
+		if c.BusNumber != bus {
+			continue
+		}
+		if unit >= 0 {
+			if used := unitNumberDevice(devices, c, unit); used != nil {
+				return nil, fmt.Errorf("IDE %d:%d is already in use by %s", bus, unit, devices.Name(used))
 			}
+		} else if len(c.Device) >= 2 {
+			return nil, fmt.Errorf("IDE bus %d already has 2 devices", bus)
 		}
+		return c, nil
 	}
-	return scsiControllers
+	return nil, fmt.Errorf("no IDE controller found on bus %d", bus)
+}
+
+// unitNumberDevice returns the device at unit number unit on controller c, or
+// nil if the unit number is free.
+func unitNumberDevice(devices object.VirtualDeviceList, c types.BaseVirtualController, unit int32) types.BaseVirtualDevice {
+	key := c.GetVirtualController().Key
+	for _, device := range devices {
+		d := device.GetVirtualDevice()
+		if d.ControllerKey == key && d.UnitNumber != nil && *d.UnitNumber == unit {
+			return device
+		}
+	}
+	return nil
 }
 
 func getNextUnitNumber(devices object.VirtualDeviceList, c types.BaseVirtualController) (int32, error) {
@@ -1624,7 +4093,7 @@ func getNextUnitNumber(devices object.VirtualDeviceList, c types.BaseVirtualCont
 }
 
 // addCdrom adds a new virtual cdrom drive to the VirtualMachine and attaches an image (ISO) to it from a datastore path.
-func addCdrom(client *govmomi.Client, vm *object.VirtualMachine, datacenter *object.Datacenter, datastore, path string) error {
+func addCdrom(client *govmomi.Client, vm *object.VirtualMachine, datacenter *object.Datacenter, cd *cdrom) error {
 	devices, err := vm.Device(context.TODO())
 	if err != nil {
 		return err
@@ -1632,7 +4101,13 @@ func addCdrom(client *govmomi.Client, vm *object.VirtualMachine, datacenter *obj
 	log.Printf("[DEBUG] vm devices: %#v", devices)
 
 	var controller *types.VirtualIDEController
-	controller, err = devices.FindIDEController("")
+	if cd.busNumber >= 0 {
+		if controller, err = ideControllerForDevice(devices, int32(cd.busNumber), int32(cd.unitNumber)); err != nil {
+			return err
+		}
+	} else {
+		controller, err = devices.FindIDEController("")
+	}
 	if err != nil {
 		log.Printf("[DEBUG] Couldn't find a ide controller.  Creating one..")
 
@@ -1665,27 +4140,55 @@ func addCdrom(client *govmomi.Client, vm *object.VirtualMachine, datacenter *obj
 	if err != nil {
 		return err
 	}
-
-	finder := find.NewFinder(client.Client, true)
-	finder = finder.SetDatacenter(datacenter)
-	ds, err := getDatastore(finder, datastore)
-	if err != nil {
-		return err
+	if cd.unitNumber >= 0 {
+		*c.UnitNumber = int32(cd.unitNumber)
 	}
 
-	c = devices.InsertIso(c, ds.Path(path))
+	var iso string
+	if cd.cdromType == cdromTypeIso {
+		finder := find.NewFinder(client.Client, true)
+		finder = finder.SetDatacenter(datacenter)
+		ds, err := getDatastore(finder, cd.datastore)
+		if err != nil {
+			return err
+		}
+		iso = ds.Path(cd.path)
+	}
+	c.Backing = cdromBacking(*cd, iso)
+	c.Connectable.Connected = cd.connected
+	c.Connectable.StartConnected = cd.startConnected
 	log.Printf("[DEBUG] addCdrom: %#v", c)
 
-	return vm.AddDevice(context.TODO(), c)
+	if err := vm.AddDevice(context.TODO(), c); err != nil {
+		return err
+	}
+
+	// The device gets its key when it is added, so look it up by its slot on
+	// the controller.
+	devices, err = vm.Device(context.TODO())
+	if err != nil {
+		return err
+	}
+	for _, device := range devices.SelectByType((*types.VirtualCdrom)(nil)) {
+		vd := device.GetVirtualDevice()
+		if vd.ControllerKey == c.ControllerKey && vd.UnitNumber != nil && c.UnitNumber != nil && *vd.UnitNumber == *c.UnitNumber {
+			cd.key = vd.Key
+		}
+	}
+	return nil
 }
 
-// buildNetworkDevice builds VirtualDeviceConfigSpec for Network Device.
-func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress string) (*types.VirtualDeviceConfigSpec, error) {
+// buildNetworkDevice builds VirtualDeviceConfigSpec for Network Device. If
+// pciSlotNumber is non-zero, the device is placed in that PCI slot.
+func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress string, pciSlotNumber int32) (*types.VirtualDeviceConfigSpec, error) {
 	network, err := f.Network(context.TODO(), label)
 	if err != nil {
 		return nil, err
 	}
 
+	// For DVS portgroups, the backing only refers to the portgroup and not to a
+	// port. vSphere allocates a port from early binding portgroups when the
+	// device is added, and from ephemeral portgroups when it connects.
 	backing, err := network.EthernetCardBackingInfo(context.TODO())
 	if err != nil {
 		return nil, err
@@ -1698,18 +4201,25 @@ func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress st
 		address_type = string(types.VirtualEthernetCardMacTypeManual)
 	}
 
+	device := types.VirtualDevice{
+		Key:     -1,
+		Backing: backing,
+	}
+	if pciSlotNumber != 0 {
+		device.SlotInfo = &types.VirtualDevicePciBusSlotInfo{
+			PciSlotNumber: pciSlotNumber,
+		}
+	}
+
 	if adapterType == "vmxnet3" {
 		return &types.VirtualDeviceConfigSpec{
 			Operation: types.VirtualDeviceConfigSpecOperationAdd,
 			Device: &types.VirtualVmxnet3{
 				VirtualVmxnet: types.VirtualVmxnet{
 					VirtualEthernetCard: types.VirtualEthernetCard{
-						VirtualDevice: types.VirtualDevice{
-							Key:     -1,
-							Backing: backing,
-						},
-						AddressType: address_type,
-						MacAddress:  macAddress,
+						VirtualDevice: device,
+						AddressType:   address_type,
+						MacAddress:    macAddress,
 					},
 				},
 			},
@@ -1719,12 +4229,9 @@ func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress st
 			Operation: types.VirtualDeviceConfigSpecOperationAdd,
 			Device: &types.VirtualE1000{
 				VirtualEthernetCard: types.VirtualEthernetCard{
-					VirtualDevice: types.VirtualDevice{
-						Key:     -1,
-						Backing: backing,
-					},
-					AddressType: address_type,
-					MacAddress:  macAddress,
+					VirtualDevice: device,
+					AddressType:   address_type,
+					MacAddress:    macAddress,
 				},
 			},
 		}, nil
@@ -1734,7 +4241,7 @@ func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress st
 }
 
 // buildVMRelocateSpec builds VirtualMachineRelocateSpec to set a place for a new VirtualMachine.
-func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *object.VirtualMachine, linkedClone bool, initType string) (types.VirtualMachineRelocateSpec, error) {
+func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *object.VirtualMachine, linkedClone bool, initType string, diskMode string) (types.VirtualMachineRelocateSpec, error) {
 	var key int32
 	var moveType string
 	if linkedClone {
@@ -1756,6 +4263,9 @@ func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *obje
 
 	isThin := initType == "thin"
 	eagerScrub := initType == "eager_zeroed"
+	if diskMode == "" {
+		diskMode = string(types.VirtualDiskModePersistent)
+	}
 	rpr := rp.Reference()
 	dsr := ds.Reference()
 	return types.VirtualMachineRelocateSpec{
@@ -1766,7 +4276,7 @@ func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *obje
 			{
 				Datastore: dsr,
 				DiskBackingInfo: &types.VirtualDiskFlatVer2BackingInfo{
-					DiskMode:        "persistent",
+					DiskMode:        diskMode,
 					ThinProvisioned: types.NewBool(isThin),
 					EagerlyScrub:    types.NewBool(eagerScrub),
 				},
@@ -1861,6 +4371,58 @@ func buildStoragePlacementSpecClone(c *govmomi.Client, f *object.DatacenterFolde
 	return sps
 }
 
+// virtualMachineInitialPlacement returns the host that DRS recommends placing
+// a new virtual machine on. nil is returned when the resource pool is not in a
+// DRS enabled cluster, or DRS could not make a recommendation, in which case
+// vCenter places the virtual machine as usual.
+func virtualMachineInitialPlacement(c *govmomi.Client, rp *object.ResourcePool, ds *object.Datastore, template *object.VirtualMachine, name string, configSpec types.VirtualMachineConfigSpec, networkDevices []types.BaseVirtualDeviceConfigSpec) *types.ManagedObjectReference {
+	cluster, err := resourcePoolDrsCluster(c, rp)
+	if err != nil {
+		log.Printf("[WARN] Could not check DRS state for resource pool %q, skipping DRS placement: %s", rp.Reference().Value, err)
+		return nil
+	}
+	if cluster == nil {
+		return nil
+	}
+
+	rpr := rp.Reference()
+	dsr := ds.Reference()
+	spec := types.PlacementSpec{
+		RelocateSpec: &types.VirtualMachineRelocateSpec{
+			Pool:      &rpr,
+			Datastore: &dsr,
+		},
+	}
+	if template == nil {
+		// Network devices are added after the virtual machine is created, but
+		// they are included here so that DRS only picks hosts that can reach
+		// the networks of the virtual machine.
+		configSpec.DeviceChange = append(append([]types.BaseVirtualDeviceConfigSpec{}, configSpec.DeviceChange...), networkDevices...)
+		spec.PlacementType = string(types.PlacementSpecPlacementTypeCreate)
+		spec.ConfigSpec = &configSpec
+	} else {
+		vmr := template.Reference()
+		spec.PlacementType = string(types.PlacementSpecPlacementTypeClone)
+		spec.Vm = &vmr
+		spec.CloneName = name
+		spec.CloneSpec = &types.VirtualMachineCloneSpec{
+			Location: *spec.RelocateSpec,
+		}
+	}
+
+	host, err := placeVMInCluster(c, cluster, spec)
+	if err != nil {
+		log.Printf("[WARN] DRS placement for virtual machine %q failed, letting vCenter place it: %s", name, err)
+		return nil
+	}
+	if host == nil {
+		log.Printf("[DEBUG] DRS returned no placement recommendation for virtual machine %q", name)
+		return nil
+	}
+	log.Printf("[DEBUG] DRS placed virtual machine %q on host %q", name, host.Value)
+	return host
+}
+
 // findDatastore finds Datastore object.
 func findDatastore(c *govmomi.Client, sps types.StoragePlacementSpec) (*object.Datastore, error) {
 	var datastore *object.Datastore
@@ -1873,6 +4435,9 @@ func findDatastore(c *govmomi.Client, sps types.StoragePlacementSpec) (*object.D
 	}
 	log.Printf("[DEBUG] findDatastore: recommendDatastores: %#v\n", rds)
 
+	if len(rds.Recommendations) < 1 || len(rds.Recommendations[0].Action) < 1 {
+		return nil, errors.New("no Storage DRS recommendations were returned")
+	}
 	spa := rds.Recommendations[0].Action[0].(*types.StoragePlacementAction)
 	datastore = object.NewDatastore(c.Client, spa.Destination)
 	log.Printf("[DEBUG] findDatastore: datastore: %#v", datastore)
@@ -1883,10 +4448,11 @@ func findDatastore(c *govmomi.Client, sps types.StoragePlacementSpec) (*object.D
 // createCdroms is a helper function to attach virtual cdrom devices (and their attached disk images) to a virtual IDE controller.
 func createCdroms(client *govmomi.Client, vm *object.VirtualMachine, datacenter *object.Datacenter, cdroms []cdrom) error {
 	log.Printf("[DEBUG] add cdroms: %v", cdroms)
-	for _, cd := range cdroms {
-		log.Printf("[DEBUG] add cdrom (datastore): %v", cd.datastore)
-		log.Printf("[DEBUG] add cdrom (cd path): %v", cd.path)
-		err := addCdrom(client, vm, datacenter, cd.datastore, cd.path)
+	for i := range cdroms {
+		log.Printf("[DEBUG] add cdrom (type): %v", cdroms[i].cdromType)
+		log.Printf("[DEBUG] add cdrom (datastore): %v", cdroms[i].datastore)
+		log.Printf("[DEBUG] add cdrom (cd path): %v", cdroms[i].path)
+		err := addCdrom(client, vm, datacenter, &cdroms[i])
 		if err != nil {
 			return err
 		}
@@ -1895,6 +4461,55 @@ func createCdroms(client *govmomi.Client, vm *object.VirtualMachine, datacenter
 	return nil
 }
 
+// registerConfigSpec returns the config spec that a virtual machine that was
+// registered from register_vmx_path is reconfigured with. Unlike a new virtual
+// machine, a registered one already has a configuration, so only the settings
+// in the resource configuration are sent, and no defaults such as a guest ID
+// are filled in.
+func (vm *virtualMachine) registerConfigSpec() types.VirtualMachineConfigSpec {
+	spec := types.VirtualMachineConfigSpec{
+		NumCPUs:          vm.vcpu,
+		MemoryMB:         vm.memoryMb,
+		CpuAllocation:    vm.cpuAllocation,
+		MemoryAllocation: vm.memoryAllocation,
+		Annotation:       vm.annotation,
+		GuestId:          vm.guestID,
+		Firmware:         vm.firmware,
+		Uuid:             vm.uuid,
+		InstanceUuid:     vm.instanceUUID,
+	}
+	if vm.enableDiskUUID {
+		spec.Flags = &types.VirtualMachineFlagInfo{
+			DiskUuidEnabled: &vm.enableDiskUUID,
+		}
+	}
+	if vm.toolsUpgradePolicy != "" {
+		spec.Tools = &types.ToolsConfigInfo{
+			ToolsUpgradePolicy: vm.toolsUpgradePolicy,
+		}
+	}
+	if vm.nestedVirtualization {
+		spec.NestedHVEnabled = &vm.nestedVirtualization
+	}
+	if vm.vpmcEnabled {
+		spec.VPMCEnabled = &vm.vpmcEnabled
+	}
+	if vm.cpuHotAddEnabled {
+		spec.CpuHotAddEnabled = &vm.cpuHotAddEnabled
+	}
+	if vm.memoryHotAddEnabled {
+		spec.MemoryHotAddEnabled = &vm.memoryHotAddEnabled
+	}
+	for k, v := range vm.customConfigurations {
+		value := v
+		spec.ExtraConfig = append(spec.ExtraConfig, &types.OptionValue{
+			Key:   k,
+			Value: &value,
+		})
+	}
+	return spec
+}
+
 func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	var cw *virtualMachineCustomizationWaiter
 	dc, err := getDatacenter(c, vm.datacenter)
@@ -1908,6 +4523,15 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	var template *object.VirtualMachine
 	var template_mo mo.VirtualMachine
 	var vm_mo mo.VirtualMachine
+	// hardwareVersion is the hardware version that the VM will have once it
+	// has been created, or empty if the host's default is used. Cloned VMs
+	// keep the template's hardware version, so they are upgraded after the
+	// clone if a higher hardware_version is configured.
+	var hardwareVersion string
+	if vm.hardwareVersion > 0 {
+		hardwareVersion = hardwareVersionKey(vm.hardwareVersion)
+	}
+	upgradeHardware := false
 	if vm.template != "" {
 		template, err = finder.VirtualMachine(context.TODO(), vm.template)
 		if err != nil {
@@ -1915,10 +4539,24 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 		log.Printf("[DEBUG] template: %#v", template)
 
-		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName"}, &template_mo)
+		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName", "config.version"}, &template_mo)
 		if err != nil {
 			return err
 		}
+
+		if template_mo.Config != nil {
+			if vm.hardwareVersion > 0 {
+				upgradeHardware, err = validateHardwareVersionUpgrade(template_mo.Config.Version, vm.hardwareVersion)
+				if err != nil {
+					return err
+				}
+			} else {
+				hardwareVersion = template_mo.Config.Version
+			}
+			if err := validateVirtualMachineCPUFeatures(vm.nestedVirtualization, vm.vpmcEnabled, hardwareVersion); err != nil {
+				return err
+			}
+		}
 	}
 
 	var resourcePool *object.ResourcePool
@@ -1942,6 +4580,23 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 	log.Printf("[DEBUG] resource pool: %#v", resourcePool)
 
+	// Check guest_id against the guests that the target cluster or host
+	// supports, on the hardware version that the VM will have, before anything
+	// is created.
+	if vm.guestID != "" {
+		eb, err := resourcePoolEnvironmentBrowser(c, resourcePool.Reference())
+		if err != nil {
+			return fmt.Errorf("error fetching environment browser: %s", err)
+		}
+		descs, err := environmentBrowserGuestOsDescriptors(c, eb, hardwareVersion, nil)
+		if err != nil {
+			return fmt.Errorf("error fetching supported guest IDs: %s", err)
+		}
+		if err := validateGuestID(vm.guestID, descs); err != nil {
+			return err
+		}
+	}
+
 	dcFolders, err := dc.Folders(context.TODO())
 	if err != nil {
 		return err
@@ -1968,22 +4623,51 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		NumCPUs:           vm.vcpu,
 		NumCoresPerSocket: 1,
 		MemoryMB:          vm.memoryMb,
-		MemoryAllocation: &types.ResourceAllocationInfo{
-			Reservation: &vm.memoryAllocation.reservation,
-		},
+		CpuAllocation:     vm.cpuAllocation,
+		MemoryAllocation:  vm.memoryAllocation,
 		Flags: &types.VirtualMachineFlagInfo{
 			DiskUuidEnabled: &vm.enableDiskUUID,
 		},
-		Annotation: vm.annotation,
+		Annotation:   vm.annotation,
+		Uuid:         vm.uuid,
+		InstanceUuid: vm.instanceUUID,
+	}
+
+	if vm.toolsUpgradePolicy != "" {
+		configSpec.Tools = &types.ToolsConfigInfo{
+			ToolsUpgradePolicy: vm.toolsUpgradePolicy,
+		}
 	}
 
 	if vm.nestedVirtualization {
 		configSpec.NestedHVEnabled = &vm.nestedVirtualization
 	}
 
-	if vm.template == "" {
+	if vm.vpmcEnabled {
+		configSpec.VPMCEnabled = &vm.vpmcEnabled
+	}
+
+	if vm.cpuHotAddEnabled {
+		configSpec.CpuHotAddEnabled = &vm.cpuHotAddEnabled
+	}
+
+	if vm.memoryHotAddEnabled {
+		configSpec.MemoryHotAddEnabled = &vm.memoryHotAddEnabled
+	}
+
+	if vm.guestID != "" {
+		configSpec.GuestId = vm.guestID
+	} else if vm.template == "" && vm.registerVmxPath == "" {
 		configSpec.GuestId = "otherLinux64Guest"
 	}
+
+	if vm.template == "" && vm.registerVmxPath == "" {
+		configSpec.Version = hardwareVersion
+	}
+
+	if vm.firmware != "" {
+		configSpec.Firmware = vm.firmware
+	}
 	log.Printf("[DEBUG] virtual machine config spec: %v", configSpec)
 
 	// make ExtraConfig
@@ -2005,7 +4689,41 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 
 	var datastore *object.Datastore
-	if vm.datastore == "" {
+	if vm.datastore == "" && len(vm.candidateDatastoreIDs) > 0 {
+		var refs []types.ManagedObjectReference
+		for _, id := range vm.candidateDatastoreIDs {
+			refs = append(refs, types.ManagedObjectReference{Type: "Datastore", Value: id})
+		}
+		datastore, err = leastUsedDatastore(c, refs)
+		if err != nil {
+			return err
+		}
+	} else if vm.datastore == "" && vm.datastoreClusterID != "" && vm.datastorePolicy == datastoreSelectionPolicyLeastUsed {
+		refs, err := storagePodDatastores(c, vm.datastoreClusterID)
+		if err != nil {
+			return fmt.Errorf("error fetching datastore cluster members: %s", err)
+		}
+		datastore, err = leastUsedDatastore(c, refs)
+		if err != nil {
+			return err
+		}
+	} else if vm.datastore == "" && vm.datastoreClusterID != "" {
+		sp := object.StoragePod{
+			Folder: object.NewFolder(c.Client, types.ManagedObjectReference{Type: "StoragePod", Value: vm.datastoreClusterID}),
+		}
+
+		var sps types.StoragePlacementSpec
+		if vm.template != "" {
+			sps = buildStoragePlacementSpecClone(c, dcFolders, template, resourcePool, sp)
+		} else {
+			sps = buildStoragePlacementSpecCreate(dcFolders, resourcePool, sp, configSpec)
+		}
+
+		datastore, err = findDatastore(c, sps)
+		if err != nil {
+			return err
+		}
+	} else if vm.datastore == "" {
 		datastore, err = finder.DefaultDatastore(context.TODO())
 		if err != nil {
 			return err
@@ -2042,16 +4760,38 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 
 	log.Printf("[DEBUG] datastore: %#v", datastore)
+	vm.selectedDatastoreID = datastore.Reference().Value
 
 	// network
 	networkDevices := []types.BaseVirtualDeviceConfigSpec{}
 	networkConfigs := []types.CustomizationAdapterMapping{}
-	for _, network := range vm.networkInterfaces {
+	for i, network := range vm.networkInterfaces {
 		// network device
-		nd, err := buildNetworkDevice(finder, network.label, network.adapterType, network.macAddress)
+		nd, err := buildNetworkDevice(finder, network.label, network.adapterType, network.macAddress, network.pciSlotNumber)
 		if err != nil {
 			return err
 		}
+		// Only set the bandwidth allocation when it differs from the defaults, so
+		// that network interfaces can still be created on networks and versions
+		// that do not support it.
+		if network.resourceAllocation != nil && !virtualEthernetCardResourceAllocationIsDefault(network.resourceAllocation) {
+			card := nd.Device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			if err := validateVirtualEthernetCardReservation(c, card.Backing, *network.resourceAllocation.Reservation, i); err != nil {
+				return err
+			}
+			card.ResourceAllocation = network.resourceAllocation
+		}
+		if network.uptEnabled {
+			if err := validateVirtualEthernetCardUpt(c, nil, nd.Device, i); err != nil {
+				return err
+			}
+			nd.Device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().UptCompatibilityEnabled = boolPtr(true)
+		}
+		nd.Device.GetVirtualDevice().Connectable = &types.VirtualDeviceConnectInfo{
+			StartConnected:    network.startConnected,
+			Connected:         network.connected,
+			AllowGuestControl: true,
+		}
 		log.Printf("[DEBUG] network device: %+v", nd.Device)
 		networkDevices = append(networkDevices, nd)
 
@@ -2109,14 +4849,56 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	log.Printf("[DEBUG] network devices: %#v", networkDevices)
 	log.Printf("[DEBUG] network configs: %#v", networkConfigs)
 
+	// If the virtual machine is going into a DRS enabled cluster, ask DRS which
+	// host to place it on, so that initial placement honours the affinity rules
+	// of the cluster and is spread across hosts.
+	placementHost := virtualMachineInitialPlacement(c, resourcePool, datastore, template, vm.name, configSpec, networkDevices)
+
 	var task *object.Task
-	if vm.template == "" {
+	if vm.registerVmxPath != "" {
+		// A registered virtual machine keeps its hardware version, like a
+		// clone, so it is upgraded below if a higher hardware_version is set.
+		// The rest of the configuration is applied with a reconfigure once it
+		// has been registered.
+		var host *object.HostSystem
+		if placementHost != nil {
+			host = object.NewHostSystem(c.Client, *placementHost)
+		}
+		log.Printf("[DEBUG] registering virtual machine from %s", vm.registerVmxPath)
+		task, err = folder.RegisterVM(context.TODO(), vm.registerVmxPath, vm.name, false, resourcePool, host)
+		if err != nil {
+			return fmt.Errorf("error registering virtual machine from %s: %s", vm.registerVmxPath, err)
+		}
+		if _, err := waitForTask(task); err != nil {
+			return fmt.Errorf("error registering virtual machine from %s: %s", vm.registerVmxPath, err)
+		}
+		registered, err := finder.VirtualMachine(context.TODO(), vm.Path())
+		if err != nil {
+			return err
+		}
+		if vm.hardwareVersion > 0 {
+			var registered_mo mo.VirtualMachine
+			if err := registered.Properties(context.TODO(), registered.Reference(), []string{"config.version"}, &registered_mo); err != nil {
+				return err
+			}
+			if registered_mo.Config != nil {
+				upgradeHardware, err = validateHardwareVersionUpgrade(registered_mo.Config.Version, vm.hardwareVersion)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		task, err = registered.Reconfigure(context.TODO(), vm.registerConfigSpec())
+		if err != nil {
+			return err
+		}
+	} else if vm.template == "" {
 		var mds mo.Datastore
 		if err = datastore.Properties(context.TODO(), datastore.Reference(), []string{"name"}, &mds); err != nil {
 			return err
 		}
 		log.Printf("[DEBUG] datastore: %#v", mds.Name)
-		scsi, err := object.SCSIControllerTypes().CreateSCSIController("scsi")
+		scsi, err := object.SCSIControllerTypes().CreateSCSIController(firstSCSIControllerType(vm.hardDisks))
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
 		}
@@ -2128,22 +4910,27 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 
 		configSpec.Files = &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", mds.Name)}
 
-		task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, nil)
+		var host *object.HostSystem
+		if placementHost != nil {
+			host = object.NewHostSystem(c.Client, *placementHost)
+		}
+		task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, host)
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
 		}
 
-		err = task.Wait(context.TODO())
+		_, err = waitForTask(task)
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
 		}
 
 	} else {
 
-		relocateSpec, err := buildVMRelocateSpec(resourcePool, datastore, template, vm.linkedClone, vm.hardDisks[0].initType)
+		relocateSpec, err := buildVMRelocateSpec(resourcePool, datastore, template, vm.linkedClone, vm.hardDisks[0].initType, vm.hardDisks[0].diskMode)
 		if err != nil {
 			return err
 		}
+		relocateSpec.Host = placementHost
 
 		log.Printf("[DEBUG] relocate spec: %v", relocateSpec)
 
@@ -2168,7 +4955,7 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 	}
 
-	err = task.Wait(context.TODO())
+	_, err = waitForTask(task)
 	if err != nil {
 		log.Printf("[ERROR] %s", err)
 	}
@@ -2179,6 +4966,18 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 	}
 	log.Printf("[DEBUG] new vm: %v", newVM)
 
+	if upgradeHardware {
+		if err := upgradeVirtualMachineHardware(newVM, vm.hardwareVersion); err != nil {
+			return err
+		}
+	}
+
+	if vm.sataControllerCount >= 0 {
+		if err := setSATAControllerCount(newVM, vm.sataControllerCount); err != nil {
+			return err
+		}
+	}
+
 	devices, err := newVM.Device(context.TODO())
 	if err != nil {
 		log.Printf("[DEBUG] Template devices can't be found")
@@ -2194,6 +4993,21 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 			}
 		}
 	}
+	// Check host support for UPT now that the VM has been placed.
+	var hostMo mo.VirtualMachine
+	for i, network := range vm.networkInterfaces {
+		if !network.uptEnabled {
+			continue
+		}
+		if hostMo.Runtime.Host == nil {
+			if err := newVM.Properties(context.TODO(), newVM.Reference(), []string{"runtime.host"}, &hostMo); err != nil {
+				return err
+			}
+		}
+		if err := validateVirtualEthernetCardUpt(c, hostMo.Runtime.Host, networkDevices[i].GetVirtualDeviceConfigSpec().Device, i); err != nil {
+			return err
+		}
+	}
 	// Add Network devices
 	for _, dvc := range networkDevices {
 		err := newVM.AddDevice(
@@ -2208,6 +5022,12 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		return err
 	}
 
+	// Floppies cannot be hot added, so they need to be created before the
+	// virtual machine is powered on.
+	if err := createFloppies(c, newVM, dc, vm.floppies); err != nil {
+		return err
+	}
+
 	newVM.Properties(context.TODO(), newVM.Reference(), []string{"summary", "config"}, &vm_mo)
 	firstDisk := 0
 	if vm.template != "" {
@@ -2231,9 +5051,25 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		default:
 			return fmt.Errorf("[ERROR] setupVirtualMachine - Neither vmdk path nor vmdk name was given: %#v", vm.hardDisks[i])
 		}
-		err = addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller)
+		diskDatastore := datastore
+		if dp, ok := vmdkDatastorePath(diskPath); ok {
+			diskDatastore, err = finder.Datastore(context.TODO(), dp.Datastore)
+			if err != nil {
+				return fmt.Errorf("[ERROR] setupVirtualMachine - Couldn't find datastore %v for vmdk: %v", dp.Datastore, err)
+			}
+		} else if vm.hardDisks[i].datastore == "" && vm.datastoreClusterID != "" && vm.datastorePolicy == datastoreSelectionPolicyStorageDrs && vm.hardDisks[i].vmdkPath == "" && vm.hardDisks[i].rdm == nil {
+			// Let Storage DRS place new disks that do not have a datastore set.
+			diskDatastore, err = recommendDiskDatastore(c, newVM, vm.datastoreClusterID, vm.hardDisks[i].size, vm.hardDisks[i].initType)
+			if err != nil {
+				return err
+			}
+			if err := makeParentDirectory(c, diskDatastore, dc, diskPath); err != nil {
+				return fmt.Errorf("error creating disk directory on datastore %q: %s", diskDatastore.Name(), err)
+			}
+		}
+		err = addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, diskDatastore, diskPath, vm.hardDisks[i].controller, vm.hardDisks[i].busNumber, vm.hardDisks[i].unitNumber, vm.hardDisks[i].diskMode, vm.hardDisks[i].rdm)
 		if err != nil {
-			err2 := addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, datastore, diskPath, vm.hardDisks[i].controller)
+			err2 := addHardDisk(newVM, vm.hardDisks[i].size, vm.hardDisks[i].iops, vm.hardDisks[i].initType, diskDatastore, diskPath, vm.hardDisks[i].controller, vm.hardDisks[i].busNumber, vm.hardDisks[i].unitNumber, vm.hardDisks[i].diskMode, vm.hardDisks[i].rdm)
 			if err2 != nil {
 				return err2
 			}
@@ -2241,7 +5077,7 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 	}
 
-	if vm.skipCustomization || vm.template == "" {
+	if vm.skipCustomization || vm.template == "" || !vm.powerOn {
 		log.Printf("[DEBUG] VM customization skipped")
 	} else {
 		var identity_options types.BaseCustomizationIdentitySettings
@@ -2330,18 +5166,23 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		if err != nil {
 			return err
 		}
-		_, err = taskb.WaitForResult(context.TODO(), nil)
+		_, err = waitForTask(taskb)
 		if err != nil {
 			return err
 		}
 	}
 
-	if vm.hasBootableVmdk || vm.template != "" {
-		t, err := newVM.PowerOn(context.TODO())
-		if err != nil {
+	if !vm.powerOn {
+		log.Printf("[DEBUG] VM left powered off, as power_on is false")
+	} else if vm.hasBootableVmdk || vm.template != "" {
+		err := runWithVirtualMachineQuestionAnswers(c, newVM, vm.questionAnswers, func() error {
+			t, err := newVM.PowerOn(context.TODO())
+			if err != nil {
+				return err
+			}
+			_, err = waitForTask(t)
 			return err
-		}
-		_, err = t.WaitForResult(context.TODO(), nil)
+		})
 		if err != nil {
 			return err
 		}
@@ -2386,11 +5227,49 @@ func getNetworkName(c *govmomi.Client, vm *object.VirtualMachine, nic types.Base
 			return "", err
 		}
 		deviceName = dvp.Name
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		// An opaque network backing only carries the ID that the network is
+		// known by externally, ie: the ID of an NSX-T logical switch, so the
+		// name needs to be looked up from the networks of the virtual machine.
+		opaqueNetworkID := backingInfo.(*types.VirtualEthernetCardOpaqueNetworkBackingInfo).OpaqueNetworkId
+		name, err := opaqueNetworkNameFromID(c, vm, opaqueNetworkID)
+		if err != nil {
+			log.Printf("[ERROR]: Error retrieving opaque network %v", err)
+			return "", err
+		}
+		deviceName = name
 	}
 	log.Printf("network Port DeviceName %#v", deviceName)
 	return deviceName, nil
 }
 
+// suppressPowerStateWithoutPowerOn suppresses the diff on power_state of an
+// existing virtual machine while power_on is false. The power state is not
+// managed then, so that a virtual machine that was created powered off stays
+// that way without a diff.
+func suppressPowerStateWithoutPowerOn(k, old, new string, d *schema.ResourceData) bool {
+	return d.Id() != "" && !d.Get("power_on").(bool)
+}
+
+// suppressUUIDCaseDifferences suppresses the diff between two UUIDs that only
+// differ in case. vSphere reports UUIDs in lower case.
+func suppressUUIDCaseDifferences(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+// suppressAnnotationDifferences suppresses the diff between two annotations
+// that only differ in line endings or trailing whitespace. vSphere may store
+// notes with CRLF line endings, and heredocs add a trailing newline.
+func suppressAnnotationDifferences(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeAnnotation(old) == normalizeAnnotation(new)
+}
+
+// normalizeAnnotation returns an annotation with LF line endings and without
+// trailing whitespace.
+func normalizeAnnotation(s string) string {
+	return strings.TrimRight(strings.Replace(s, "\r\n", "\n", -1), " \t\r\n")
+}
+
 // Suppress Diff on equal ip
 func suppressIpDifferences(k, old, new string, d *schema.ResourceData) bool {
 	o := net.ParseIP(old)
@@ -3,6 +3,7 @@ package vsphere
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -15,10 +16,46 @@ func resourceVSphereVirtualMachineMigrateState(
 		return is, nil
 	}
 
+	var err error
 	switch v {
 	case 0:
 		log.Println("[INFO] Found Compute Instance State v0; migrating to v1")
-		is, err := migrateVSphereVirtualMachineStateV0toV1(is)
+		is, err = migrateVSphereVirtualMachineStateV0toV1(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 1:
+		log.Println("[INFO] Found Compute Instance State v1; migrating to v2")
+		is, err = migrateVSphereVirtualMachineStateV1toV2(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 2:
+		log.Println("[INFO] Found Compute Instance State v2; migrating to v3")
+		is, err = migrateVSphereVirtualMachineStateV2toV3(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 3:
+		log.Println("[INFO] Found Compute Instance State v3; migrating to v4")
+		is, err = migrateVSphereVirtualMachineStateV3toV4(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 4:
+		log.Println("[INFO] Found Compute Instance State v4; migrating to v5")
+		is, err = migrateVSphereVirtualMachineStateV4toV5(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 5:
+		log.Println("[INFO] Found Compute Instance State v5; migrating to v6")
+		is, err = migrateVSphereVirtualMachineStateV5toV6(is)
 		if err != nil {
 			return is, err
 		}
@@ -60,3 +97,110 @@ func migrateVSphereVirtualMachineStateV0toV1(is *terraform.InstanceState) (*terr
 	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
 	return is, nil
 }
+
+// migrateVSphereVirtualMachineStateV1toV2 sets controller_bus_number on
+// existing disks to -1, which does not place the disk on a specific SCSI bus.
+func migrateVSphereVirtualMachineStateV1toV2(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	return migrateVSphereVirtualMachineDiskDefault(is, "controller_bus_number", "-1")
+}
+
+// migrateVSphereVirtualMachineStateV2toV3 sets unit_number on existing disks
+// to -1, which does not place the disk at a specific unit number.
+func migrateVSphereVirtualMachineStateV2toV3(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	return migrateVSphereVirtualMachineDiskDefault(is, "unit_number", "-1")
+}
+
+// migrateVSphereVirtualMachineStateV3toV4 sets controller_bus_number and
+// unit_number on existing cdroms to -1, which does not place the cdrom at a
+// specific IDE address. cdrom is a list, so the cdroms are found by index.
+func migrateVSphereVirtualMachineStateV3toV4(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() || is.Attributes == nil {
+		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	count, _ := strconv.Atoi(is.Attributes["cdrom.#"])
+	for i := 0; i < count; i++ {
+		for _, attr := range []string{"controller_bus_number", "unit_number"} {
+			s := fmt.Sprintf("cdrom.%d.%s", i, attr)
+			if _, ok := is.Attributes[s]; !ok {
+				is.Attributes[s] = "-1"
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
+
+// migrateVSphereVirtualMachineDiskDefault sets the disk attribute attr to
+// value on all disks in state that do not have it set.
+func migrateVSphereVirtualMachineDiskDefault(is *terraform.InstanceState, attr, value string) (*terraform.InstanceState, error) {
+	if is.Empty() || is.Attributes == nil {
+		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	for k := range is.Attributes {
+		if strings.HasPrefix(k, "disk.") && strings.HasSuffix(k, ".size") {
+			diskParts := strings.Split(k, ".")
+			if len(diskParts) != 3 {
+				continue
+			}
+			s := strings.Join([]string{diskParts[0], diskParts[1], attr}, ".")
+			if _, ok := is.Attributes[s]; !ok {
+				is.Attributes[s] = value
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
+
+// migrateVSphereVirtualMachineStateV4toV5 sets connected and start_connected
+// to true for the network interfaces in state, matching the defaults they were
+// added with.
+func migrateVSphereVirtualMachineStateV4toV5(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() || is.Attributes == nil {
+		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	count, _ := strconv.Atoi(is.Attributes["network_interface.#"])
+	for i := 0; i < count; i++ {
+		for _, attr := range []string{"connected", "start_connected"} {
+			s := fmt.Sprintf("network_interface.%d.%s", i, attr)
+			if _, ok := is.Attributes[s]; !ok {
+				is.Attributes[s] = "true"
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
+
+// migrateVSphereVirtualMachineStateV5toV6 sets power_on to true, its default,
+// as existing virtual machines were all created powered on.
+func migrateVSphereVirtualMachineStateV5toV6(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() || is.Attributes == nil {
+		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	if is.Attributes["power_on"] == "" {
+		is.Attributes["power_on"] = "true"
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
@@ -44,6 +44,86 @@ func TestVSphereVirtualMachineMigrateState(t *testing.T) {
 				"disk.9999.controller_type": "ide",
 			},
 		},
+		"disk controller_bus_number": {
+			StateVersion: 1,
+			Attributes: map[string]string{
+				"disk.1234.size":                  "0",
+				"disk.1234.controller_type":       "scsi",
+				"disk.5678.size":                  "0",
+				"disk.5678.controller_type":       "scsi-paravirtual",
+				"disk.5678.controller_bus_number": "1",
+			},
+			Expected: map[string]string{
+				"disk.1234.controller_bus_number": "-1",
+				"disk.5678.controller_bus_number": "1",
+			},
+		},
+		"disk controller_bus_number from v0": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"disk.1234.size": "0",
+			},
+			Expected: map[string]string{
+				"disk.1234.controller_type":       "scsi",
+				"disk.1234.controller_bus_number": "-1",
+				"disk.1234.unit_number":           "-1",
+			},
+		},
+		"disk unit_number": {
+			StateVersion: 2,
+			Attributes: map[string]string{
+				"disk.1234.size":                  "0",
+				"disk.1234.controller_bus_number": "-1",
+				"disk.5678.size":                  "0",
+				"disk.5678.controller_bus_number": "1",
+				"disk.5678.unit_number":           "0",
+			},
+			Expected: map[string]string{
+				"disk.1234.unit_number": "-1",
+				"disk.5678.unit_number": "0",
+			},
+		},
+		"cdrom ide address": {
+			StateVersion: 3,
+			Attributes: map[string]string{
+				"cdrom.#":                       "2",
+				"cdrom.0.type":                  "iso",
+				"cdrom.1.type":                  "client_passthrough",
+				"cdrom.1.controller_bus_number": "1",
+				"cdrom.1.unit_number":           "0",
+			},
+			Expected: map[string]string{
+				"cdrom.0.controller_bus_number": "-1",
+				"cdrom.0.unit_number":           "-1",
+				"cdrom.1.controller_bus_number": "1",
+				"cdrom.1.unit_number":           "0",
+			},
+		},
+		"network interface connection state": {
+			StateVersion: 4,
+			Attributes: map[string]string{
+				"network_interface.#":                 "2",
+				"network_interface.0.label":           "VM Network",
+				"network_interface.1.label":           "VM Network",
+				"network_interface.1.connected":       "false",
+				"network_interface.1.start_connected": "false",
+			},
+			Expected: map[string]string{
+				"network_interface.0.connected":       "true",
+				"network_interface.0.start_connected": "true",
+				"network_interface.1.connected":       "false",
+				"network_interface.1.start_connected": "false",
+			},
+		},
+		"power on default": {
+			StateVersion: 5,
+			Attributes: map[string]string{
+				"power_state": "poweredOn",
+			},
+			Expected: map[string]string{
+				"power_on": "true",
+			},
+		},
 	}
 
 	for tn, tc := range cases {
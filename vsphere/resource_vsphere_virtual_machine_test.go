@@ -1,18 +1,23 @@
 package vsphere
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -21,14 +26,250 @@ const (
 	testAccResourceVSphereVirtualMachineDiskNameLazy      = "terraform-test-extra-lazy"
 	testAccResourceVSphereVirtualMachineDiskNameThin      = "terraform-test-extra-thin"
 	testAccResourceVSphereVirtualMachineDiskNameExtraVmdk = "terraform-test-vm-extra-disk.vmdk"
+	testAccResourceVSphereVirtualMachineDiskNameMode      = "terraform-test-extra-mode"
+	testAccResourceVSphereVirtualMachineDiskNameRDM       = "terraform-test-extra-rdm"
+	testAccResourceVSphereVirtualMachineDiskNameSDRS1     = "terraform-test-extra-sdrs1"
+	testAccResourceVSphereVirtualMachineDiskNameSDRS2     = "terraform-test-extra-sdrs2"
 	testAccResourceVSphereVirtualMachineStaticMacAddr     = "06:5c:89:2b:a0:64"
+	testAccResourceVSphereVirtualMachineStaticMacAddrVMW  = "00:50:56:01:02:03"
+	testAccResourceVSphereVirtualMachineStaticMacAddrBad  = "00:50:56:80:00:01"
 	testAccResourceVSphereVirtualMachineAnnotation        = "Managed by Terraform"
 	testAccResourceVSphereVirtualMachineSlashNetLabel     = "bar/baz"
 )
 
+func testIDEAddressMap(bus, unit int) map[string]interface{} {
+	return map[string]interface{}{
+		"controller_type":       "ide",
+		"controller_bus_number": bus,
+		"unit_number":           unit,
+	}
+}
+
+func TestValidateIDEAddresses(t *testing.T) {
+	cases := []struct {
+		Name        string
+		disks       []interface{}
+		cdroms      []interface{}
+		expectError bool
+	}{
+		{
+			Name:   "disks and cdroms on both buses",
+			disks:  []interface{}{testIDEAddressMap(0, 0), testIDEAddressMap(0, 1)},
+			cdroms: []interface{}{testIDEAddressMap(1, 0), testIDEAddressMap(-1, -1)},
+		},
+		{
+			Name: "scsi disks are ignored",
+			disks: []interface{}{
+				map[string]interface{}{"controller_type": "scsi", "controller_bus_number": 3, "unit_number": 15},
+			},
+			cdroms: []interface{}{testIDEAddressMap(0, 0)},
+		},
+		{
+			Name:        "three devices on one bus",
+			disks:       []interface{}{testIDEAddressMap(1, -1)},
+			cdroms:      []interface{}{testIDEAddressMap(1, 0), testIDEAddressMap(1, -1)},
+			expectError: true,
+		},
+		{
+			Name:        "same address",
+			disks:       []interface{}{testIDEAddressMap(0, 1)},
+			cdroms:      []interface{}{testIDEAddressMap(0, 1)},
+			expectError: true,
+		},
+		{
+			Name:        "unit number without bus number",
+			cdroms:      []interface{}{testIDEAddressMap(-1, 0)},
+			expectError: true,
+		},
+		{
+			Name:        "bus number out of range",
+			disks:       []interface{}{testIDEAddressMap(2, 0)},
+			expectError: true,
+		},
+		{
+			Name: "more than four devices",
+			cdroms: []interface{}{
+				testIDEAddressMap(-1, -1),
+				testIDEAddressMap(-1, -1),
+				testIDEAddressMap(-1, -1),
+				testIDEAddressMap(-1, -1),
+				testIDEAddressMap(-1, -1),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := validateIDEAddresses(tc.disks, tc.cdroms)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateVirtualMachineVmxPath(t *testing.T) {
+	cases := map[string]bool{
+		"[datastore1] vm/vm.vmx":     false,
+		"[datastore1] vm.vmx":        false,
+		"vm/vm.vmx":                  true,
+		"[datastore1] vm/vm-01.vmdk": true,
+		"":                           true,
+	}
+	for value, expectError := range cases {
+		_, errs := validateVirtualMachineVmxPath(value, "register_vmx_path")
+		if expectError && len(errs) < 1 {
+			t.Fatalf("%q: expected error, got none", value)
+		}
+		if !expectError && len(errs) > 0 {
+			t.Fatalf("%q: bad: %s", value, errs[0])
+		}
+	}
+}
+
+func TestValidateVirtualMachineUUID(t *testing.T) {
+	cases := map[string]bool{
+		"42010f4c-3a5c-57e1-8c26-4f0fb4b5c1d3":  false,
+		"42010F4C-3A5C-57E1-8C26-4F0FB4B5C1D3":  false,
+		"42010f4c3a5c57e18c264f0fb4b5c1d3":      true,
+		"42 01 0f 4c 3a 5c 57 e1-8c 26 4f 0f":   true,
+		"42010f4c-3a5c-57e1-8c26-4f0fb4b5c1d":   true,
+		"42010f4c-3a5c-57e1-8c26-4f0fb4b5c1dz":  true,
+		"42010f4c-3a5c-57e1-8c26-4f0fb4b5c1d3a": true,
+		"":                                      true,
+	}
+	for value, expectError := range cases {
+		_, errs := validateVirtualMachineUUID(value, "uuid")
+		if expectError && len(errs) < 1 {
+			t.Fatalf("%q: expected error, got none", value)
+		}
+		if !expectError && len(errs) > 0 {
+			t.Fatalf("%q: bad: %s", value, errs[0])
+		}
+	}
+}
+
+func TestSuppressAnnotationDifferences(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Old      string
+		New      string
+		Expected bool
+	}{
+		{"equal", "Managed by Terraform", "Managed by Terraform", true},
+		{"trailing newline", "line 1\nline 2", "line 1\nline 2\n", true},
+		{"crlf line endings", "line 1\r\nline 2\r\n", "line 1\nline 2\n", true},
+		{"cleared with a space", " ", "", true},
+		{"different text", "line 1\nline 2", "line 1\nline 3", false},
+		{"leading whitespace", "line 1", "  line 1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual := suppressAnnotationDifferences("annotation", tc.Old, tc.New, nil)
+			if actual != tc.Expected {
+				t.Fatalf("expected %t, got %t", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestExtraConfigChanges(t *testing.T) {
+	oldMap := map[string]interface{}{
+		"unchanged": "a",
+		"changed":   "b",
+		"removed":   "c",
+	}
+	newMap := map[string]interface{}{
+		"unchanged": "a",
+		"changed":   "d",
+		"added":     "e",
+	}
+	expected := map[string]interface{}{
+		"guestinfo.changed": "d",
+		"guestinfo.removed": "",
+		"guestinfo.added":   "e",
+	}
+
+	actual := make(map[string]interface{})
+	for _, bov := range extraConfigChanges(oldMap, newMap, guestInfoPrefix) {
+		opt := bov.GetOptionValue()
+		actual[opt.Key] = opt.Value
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestMatchNetworkInterfaceDevices(t *testing.T) {
+	card := func(key, slot int32) types.BaseVirtualDevice {
+		return &types.VirtualVmxnet3{
+			VirtualVmxnet: types.VirtualVmxnet{
+				VirtualEthernetCard: types.VirtualEthernetCard{
+					VirtualDevice: types.VirtualDevice{
+						Key:      key,
+						SlotInfo: &types.VirtualDevicePciBusSlotInfo{PciSlotNumber: slot},
+					},
+				},
+			},
+		}
+	}
+	devices := object.VirtualDeviceList{card(4000, 192), card(4001, 256)}
+	cases := []struct {
+		Name     string
+		slots    []int
+		expected []int32
+	}{
+		{
+			Name:     "inserted in the middle by pci slot",
+			slots:    []int{192, 224, 256},
+			expected: []int32{4000, 0, 4001},
+		},
+		{
+			Name:     "appended by position",
+			slots:    []int{0, 0, 0},
+			expected: []int32{4000, 4001, 0},
+		},
+		{
+			Name:     "moved to a new pci slot",
+			slots:    []int{192, 288},
+			expected: []int32{4000, 4001},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var nics []interface{}
+			for _, slot := range tc.slots {
+				nics = append(nics, map[string]interface{}{
+					"label":           "VM Network",
+					"pci_slot_number": slot,
+				})
+			}
+			d := schema.TestResourceDataRaw(t, resourceVSphereVirtualMachine().Schema, map[string]interface{}{
+				"network_interface": nics,
+			})
+			var actual []int32
+			for _, device := range matchNetworkInterfaceDevices(d, devices) {
+				var key int32
+				if device != nil {
+					key = device.GetVirtualDevice().Key
+				}
+				actual = append(actual, key)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 	var tp *testing.T
 	var state *terraform.State
+	nicMacs := make(map[int32]string)
 	testAccResourceVSphereVirtualMachineCases := []struct {
 		name     string
 		testCase resource.TestCase
@@ -161,6 +402,58 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				},
 			},
 		},
+		{
+			"mixed scsi controllers",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigMixedSCSIControllers(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckSCSIController(1, "pvscsi"),
+							testAccResourceVSphereVirtualMachineCheckDiskAddress(1, 0),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "scsi_controllers.#", "2"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"sata controllers",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigSATAControllers(2, true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckSATAControllerCount(2),
+							testAccResourceVSphereVirtualMachineCheckSATADiskAddress(1, 0),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "sata_controller_count", "2"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigSATAControllers(1, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckSATAControllerCount(1),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "sata_controller_count", "1"),
+						),
+					},
+				},
+			},
+		},
 		{
 			"custom config",
 			resource.TestCase{
@@ -179,11 +472,21 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "custom_configuration_parameters.baz", "qux"),
 						),
 					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigCustomConfigUpdated(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "custom_configuration_parameters.%", "1"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "custom_configuration_parameters.foo", "baz"),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "baz"),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("baz", ""),
+						),
+					},
 				},
 			},
 		},
 		{
-			"in folder",
+			"guestinfo and multiline annotation",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -193,37 +496,59 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+						Config: testAccResourceVSphereVirtualMachineConfigGuestInfo(),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "guestinfo.foo", "bar"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "guestinfo.baz", "qux"),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.foo", "bar"),
+						),
+					},
+					{
+						Config:   testAccResourceVSphereVirtualMachineConfigGuestInfo(),
+						PlanOnly: true,
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigGuestInfoUpdated(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "guestinfo.%", "1"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "guestinfo.foo", "baz"),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.foo", "baz"),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.baz", ""),
 						),
 					},
 				},
 			},
 		},
 		{
-			"attach existing vmdk",
+			"opaque network interface",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
 					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachineOpaqueNetworkPreCheck(tp)
 				},
 				Providers:    testAccProviders,
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+						Config: testAccResourceVSphereVirtualMachineConfigOpaqueNetwork(),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.label", os.Getenv("VSPHERE_OPAQUE_NETWORK")),
+							testAccResourceVSphereVirtualMachineCheckOpaqueNetworkBacking(),
 						),
 					},
+					{
+						Config:   testAccResourceVSphereVirtualMachineConfigOpaqueNetwork(),
+						PlanOnly: true,
+					},
 				},
 			},
 		},
 		{
-			"upgrade cpu and ram",
+			"in folder",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -233,23 +558,37 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
 						),
 					},
+				},
+			},
+		},
+		{
+			"attach existing vmdk",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigBeefy(),
+						Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckCPUMem(4, 8192),
+							testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
 						),
 					},
 				},
 			},
 		},
 		{
-			"dual-stack ipv4 and ipv6",
+			"attach existing vmdk by full datastore path",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -259,22 +598,17 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
+						Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkFullPath(),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
-							testAccResourceVSphereVirtualMachineCheckNet(
-								os.Getenv("VSPHERE_IPV4_ADDRESS"),
-								os.Getenv("VSPHERE_IPV4_PREFIX"),
-								os.Getenv("VSPHERE_IPV4_GATEWAY"),
-							),
+							testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
 						),
 					},
 				},
 			},
 		},
 		{
-			"static mac",
+			"change disk mode",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -284,17 +618,24 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
+						Config: testAccResourceVSphereVirtualMachineConfigDiskMode("persistent"),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckStaticMACAddr(),
+							testAccResourceVSphereVirtualMachineCheckDiskMode("persistent"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDiskMode("independent_nonpersistent"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDiskMode("independent_nonpersistent"),
 						),
 					},
 				},
 			},
 		},
 		{
-			"with annotation",
+			"hot add cpu and memory",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -304,18 +645,24 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWithAnnotation(),
+						Config: testAccResourceVSphereVirtualMachineConfigHotAdd(2, 1024, true, false),
 						Check: resource.ComposeTestCheckFunc(
-							copyStatePtr(&state),
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckAnnotation(),
+							testAccResourceVSphereVirtualMachineCheckCPUMemory(2, 1024),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigHotAdd(4, 2048, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckCPUMemory(4, 2048),
 						),
 					},
 				},
 			},
 		},
 		{
-			"windows template, customization events and proper IP",
+			"cpu change without hot add or auto power cycle",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -325,23 +672,20 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWindows(),
+						Config: testAccResourceVSphereVirtualMachineConfigHotAdd(2, 1024, false, false),
 						Check: resource.ComposeTestCheckFunc(
-							copyStatePtr(&state),
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
-							testAccResourceVSphereVirtualMachineCheckNet(
-								os.Getenv("VSPHERE_IPV4_ADDRESS"),
-								os.Getenv("VSPHERE_IPV4_PREFIX"),
-								os.Getenv("VSPHERE_IPV4_GATEWAY"),
-							),
 						),
 					},
+					{
+						Config:      testAccResourceVSphereVirtualMachineConfigHotAdd(4, 1024, false, false),
+						ExpectError: regexp.MustCompile("auto_power_cycle is disabled"),
+					},
 				},
 			},
 		},
 		{
-			"dhcp only, don't wait for guest net",
+			"power state transitions",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -351,17 +695,35 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigDHCPNoWait(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  power_state = "poweredOff"
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "poweredOff"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  power_state = "suspended"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "suspended"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  power_state = "poweredOn"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "poweredOn"),
 						),
 					},
 				},
 			},
 		},
 		{
-			"single tag",
+			"created powered off",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -371,17 +733,29 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWithTag(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  power_on = false
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+							testAccResourceVSphereVirtualMachineCheckPowerState(types.VirtualMachinePowerStatePoweredOff),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "poweredOff"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  power_on = true
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckPowerState(types.VirtualMachinePowerStatePoweredOn),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "poweredOn"),
 						),
 					},
 				},
 			},
 		},
 		{
-			"multiple tags",
+			"set uuids",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -391,17 +765,21 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWithMultiTags(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  uuid          = "4201ABCD-3a5c-57e1-8c26-4f0fb4b5c1d3"
+  instance_uuid = "5001abcd-3a5c-57e1-8c26-4f0fb4b5c1d3"
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "uuid", "4201abcd-3a5c-57e1-8c26-4f0fb4b5c1d3"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "instance_uuid", "5001abcd-3a5c-57e1-8c26-4f0fb4b5c1d3"),
 						),
 					},
 				},
 			},
 		},
 		{
-			"switch tags",
+			"graceful shutdown with retries",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -411,24 +789,34 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWithTag(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  shutdown_wait_timeout      = 2
+  graceful_shutdown_retries  = 1
+  force_power_off_on_update  = true
+  force_power_off_on_destroy = false
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
 						),
 					},
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigWithMultiTags(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  shutdown_wait_timeout      = 2
+  graceful_shutdown_retries  = 1
+  force_power_off_on_update  = true
+  force_power_off_on_destroy = false
+  memory_hot_add_enabled     = true
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "power_state", "poweredOn"),
 						),
 					},
 				},
 			},
 		},
 		{
-			"with slash in network name",
+			"resource allocation",
 			resource.TestCase{
 				PreCheck: func() {
 					testAccPreCheck(tp)
@@ -438,380 +826,2700 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 				Steps: []resource.TestStep{
 					{
-						Config: testAccResourceVSphereVirtualMachineConfigSlashNetwork(),
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  cpu_reservation    = 500
+  cpu_limit          = 2000
+  memory_reservation = 1024
+`),
 						Check: resource.ComposeTestCheckFunc(
 							testAccResourceVSphereVirtualMachineCheckExists(true),
-							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.#", "1"),
-							resource.TestCheckResourceAttr(
-								"vsphere_virtual_machine.vm",
-								"network_interface.0.label",
-								testAccResourceVSphereVirtualMachineSlashNetLabel,
-							),
+							testAccResourceVSphereVirtualMachineCheckResourceAllocation("cpu", 500, 2000, "normal", 0),
+							testAccResourceVSphereVirtualMachineCheckResourceAllocation("memory", 1024, -1, "normal", 0),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  cpu_share_level    = "custom"
+  cpu_share_count    = 3000
+  memory_limit       = 2048
+  memory_share_level = "high"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckResourceAllocation("cpu", 0, -1, "custom", 3000),
+							testAccResourceVSphereVirtualMachineCheckResourceAllocation("memory", 0, 2048, "high", 0),
 						),
 					},
 				},
 			},
 		},
-	}
-
-	for _, tc := range testAccResourceVSphereVirtualMachineCases {
-		t.Run(tc.name, func(t *testing.T) {
-			tp = t
-			resource.Test(t, tc.testCase)
-		})
-	}
-}
-
-func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
-	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
-	// speeds up tests greatly, but it's not a necessary variable, so we don't
-	// enforce it here.
-	if os.Getenv("VSPHERE_DATACENTER") == "" {
-		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_CLUSTER") == "" {
-		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
-		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
-	}
+		{
+			"custom share level without share count",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  memory_share_level = "custom"
+`),
+						ExpectError: regexp.MustCompile("memory_share_count is required when memory_share_level is custom"),
+					},
+				},
+			},
+		},
+		{
+			"tools upgrade policy",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  tools_upgrade_policy = "manual"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy("manual"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  tools_upgrade_policy = "upgradeAtPowerCycle"
+  upgrade_tools        = true
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy("upgradeAtPowerCycle"),
+							resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "tools_version"),
+							resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "tools_version_status"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"hardware version upgrade",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  hardware_version = 10
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckHardwareVersion("vmx-10"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  hardware_version = 13
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckHardwareVersion("vmx-13"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "hardware_version", "13"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourceAllocation(`
+  hardware_version = 10
+`),
+						ExpectError: regexp.MustCompile("cannot downgrade hardware version from vmx-13 to vmx-10"),
+					},
+				},
+			},
+		},
+		{
+			"wait for guest ip on network interface",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWaitForGuestIP("ipv4"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.wait_for_guest_ip", "ipv4"),
+							resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "network_interface.0.ipv4_address"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"insert network interface in the middle",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigPciSlots(`
+  network_interface {
+    label           = "${var.network_label}"
+    pci_slot_number = 256
+  }
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICSlots(192, 256),
+							testAccResourceVSphereVirtualMachineSaveNICMacs(nicMacs),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigPciSlots(`
+  network_interface {
+    label           = "${var.network_label}"
+    pci_slot_number = 224
+  }
+
+  network_interface {
+    label           = "${var.network_label}"
+    pci_slot_number = 256
+  }
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICSlots(192, 256, 224),
+							testAccResourceVSphereVirtualMachineCheckNICMacs(nicMacs),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.pci_slot_number", "192"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.1.pci_slot_number", "224"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.2.pci_slot_number", "256"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigPciSlots(`
+  network_interface {
+    label           = "${var.network_label}"
+    pci_slot_number = 256
+  }
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICSlots(192, 256),
+							testAccResourceVSphereVirtualMachineCheckNICMacs(nicMacs),
+						),
+					},
+				},
+			},
+		},
+		{
+			"network interface bandwidth allocation",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    bandwidth_limit       = 100
+    bandwidth_share_level = "custom"
+    bandwidth_share_count = 50
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICBandwidth(100, types.SharesLevelCustom, 50),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    bandwidth_limit       = 200
+    bandwidth_share_level = "high"
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICBandwidth(200, types.SharesLevelHigh, 0),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    bandwidth_limit       = 100
+    bandwidth_reservation = 200
+`),
+						ExpectError: regexp.MustCompile("bandwidth_reservation \\(200\\) cannot be higher than bandwidth_limit \\(100\\)"),
+					},
+				},
+			},
+		},
+		{
+			"network interface connection state",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    connected = false
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICConnection(false, true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.connected", "false"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    connected       = true
+    start_connected = false
+`),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNICConnection(true, false),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.connected", "true"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.start_connected", "false"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"upt on non-vmxnet3 network interface",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigNICBandwidth(`
+    adapter_type              = "e1000"
+    upt_compatibility_enabled = true
+`),
+						ExpectError: regexp.MustCompile("upt_compatibility_enabled is only supported on vmxnet3 network interfaces"),
+					},
+				},
+			},
+		},
+		{
+			"multiple disks placed by storage drs",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachineSDRSPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigSDRSDisks(datastoreSelectionPolicyStorageDrs),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDatastoreCluster(os.Getenv("VSPHERE_DATASTORE_CLUSTER_ID")),
+						),
+					},
+				},
+			},
+		},
+		{
+			"least used datastore in datastore cluster",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachineSDRSPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigSDRSDisks(datastoreSelectionPolicyLeastUsed),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDatastoreCluster(os.Getenv("VSPHERE_DATASTORE_CLUSTER_ID")),
+							resource.TestCheckResourceAttrSet("vsphere_virtual_machine.vm", "selected_datastore_id"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"physical mode rdm",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachineRDMPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigRDM("physical", ""),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckRDM(string(types.VirtualDiskCompatibilityModePhysicalMode)),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rdm with size",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachineRDMPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereVirtualMachineConfigRDM("virtual", "size = 10"),
+						ExpectError: regexp.MustCompile("RDMs cannot be resized"),
+					},
+				},
+			},
+		},
+		{
+			"upgrade cpu and ram",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBeefy(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckCPUMem(4, 8192),
+						),
+					},
+				},
+			},
+		},
+		{
+			"dual-stack ipv4 and ipv6",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+							testAccResourceVSphereVirtualMachineCheckNet(
+								os.Getenv("VSPHERE_IPV4_ADDRESS"),
+								os.Getenv("VSPHERE_IPV4_PREFIX"),
+								os.Getenv("VSPHERE_IPV4_GATEWAY"),
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"static mac",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckStaticMACAddr(testAccResourceVSphereVirtualMachineStaticMacAddr),
+						),
+					},
+				},
+			},
+		},
+		{
+			"use static mac",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigUseStaticMAC(testAccResourceVSphereVirtualMachineStaticMacAddr),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckStaticMACAddr(testAccResourceVSphereVirtualMachineStaticMacAddr),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.use_static_mac", "true"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigUseStaticMAC(testAccResourceVSphereVirtualMachineStaticMacAddrVMW),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckStaticMACAddr(testAccResourceVSphereVirtualMachineStaticMacAddrVMW),
+						),
+					},
+					{
+						Config:      testAccResourceVSphereVirtualMachineConfigUseStaticMAC(testAccResourceVSphereVirtualMachineStaticMacAddrBad),
+						ExpectError: regexp.MustCompile("outside of the allowed VMware static range"),
+					},
+				},
+			},
+		},
+		{
+			"with annotation",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWithAnnotation(),
+						Check: resource.ComposeTestCheckFunc(
+							copyStatePtr(&state),
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckAnnotation(),
+						),
+					},
+				},
+			},
+		},
+		{
+			"windows template, customization events and proper IP",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWindows(),
+						Check: resource.ComposeTestCheckFunc(
+							copyStatePtr(&state),
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+							testAccResourceVSphereVirtualMachineCheckNet(
+								os.Getenv("VSPHERE_IPV4_ADDRESS"),
+								os.Getenv("VSPHERE_IPV4_PREFIX"),
+								os.Getenv("VSPHERE_IPV4_GATEWAY"),
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"dhcp only, don't wait for guest net",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDHCPNoWait(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+						),
+					},
+				},
+			},
+		},
+		{
+			"single tag",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWithTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"multiple tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWithMultiTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"switch tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWithTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWithMultiTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"with slash in network name",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigSlashNetwork(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.#", "1"),
+							resource.TestCheckResourceAttr(
+								"vsphere_virtual_machine.vm",
+								"network_interface.0.label",
+								testAccResourceVSphereVirtualMachineSlashNetLabel,
+							),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVirtualMachineCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
+	// speeds up tests greatly, but it's not a necessary variable, so we don't
+	// enforce it here.
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
+	}
 	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
 		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine acceptance tests")
 	}
-	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
-		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine acceptance tests")
+	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
+		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
+		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
+		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineOpaqueNetworkPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_OPAQUE_NETWORK") == "" {
+		t.Skip("set VSPHERE_OPAQUE_NETWORK to run vsphere_virtual_machine opaque network acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineSDRSPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATASTORE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_DATASTORE_CLUSTER_ID to run vsphere_virtual_machine Storage DRS acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineRDMPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_RDM_LUN") == "" {
+		t.Skip("set VSPHERE_RDM_LUN to run vsphere_virtual_machine RDM acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			if ok, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error()); ok && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected VM to be missing")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
+// a VirtualMachine's power state.
+func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Runtime.PowerState
+		if expected != actual {
+			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
+// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
+// to be installed.
+func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Guest.HostName
+		if expected != actual {
+			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
+// parameters on the vsphere_virtual_machine extra disks test. This is a very
+// specific check that checks for the specific disk devices and respective
+// backings, and expects them in the exact order outlined in the function.
+func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var foundEager, foundLazy, foundThin bool
+		expectedEagerName := testAccResourceVSphereVirtualMachineDiskNameEager + ".vmdk"
+		expectedLazyName := testAccResourceVSphereVirtualMachineDiskNameLazy + ".vmdk"
+		expectedThinName := testAccResourceVSphereVirtualMachineDiskNameThin + ".vmdk"
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var eager bool
+					if info.EagerlyScrub != nil {
+						eager = *info.EagerlyScrub
+					}
+					switch {
+					case strings.HasSuffix(info.FileName, expectedEagerName) && eager:
+						foundEager = true
+					case strings.HasSuffix(info.FileName, expectedLazyName) && !eager:
+						foundLazy = true
+					case strings.HasSuffix(info.FileName, expectedThinName) && *info.ThinProvisioned:
+						foundThin = true
+					}
+				}
+			}
+		}
+
+		if !foundEager {
+			return fmt.Errorf("could not locate disk: %s", expectedEagerName)
+		}
+		if !foundLazy {
+			return fmt.Errorf("could not locate disk: %s", expectedLazyName)
+		}
+		if !foundThin {
+			return fmt.Errorf("could not locate disk: %s", expectedThinName)
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
+// virtual machine's folder matches the folder supplied with expected.
+func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expected, err := rootPathParticleVM.PathFromNewRoot(vm.InventoryPath, rootPathParticleVM, expected)
+		actual := path.Dir(vm.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
+// sure that the appropriate disk is attached in the existing VMDK test.
+func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					if strings.HasSuffix(info.FileName, expected) {
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find attached disk matching %q", expected)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskMode checks the disk mode of
+// the extra disk in the disk mode test.
+func testAccResourceVSphereVirtualMachineCheckDiskMode(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expectedName := testAccResourceVSphereVirtualMachineDiskNameMode + ".vmdk"
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					if strings.HasSuffix(info.FileName, expectedName) {
+						if info.DiskMode != expected {
+							return fmt.Errorf("expected disk mode to be %q, got %q", expected, info.DiskMode)
+						}
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not locate disk: %s", expectedName)
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.Tools == nil || props.Config.Tools.ToolsUpgradePolicy != expected {
+			return fmt.Errorf("expected tools upgrade policy to be %q, got %v", expected, props.Config.Tools)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckSCSIController checks that the VM
+// has a SCSI controller of the expected device type, ie: pvscsi, on SCSI bus
+// number bus.
+func testAccResourceVSphereVirtualMachineCheckSCSIController(bus int32, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device)
+		c := scsiControllerOnBus(devices, bus)
+		if c == nil {
+			return fmt.Errorf("no SCSI controller found on bus %d", bus)
+		}
+		if actual := devices.Type(c.(types.BaseVirtualDevice)); actual != expected {
+			return fmt.Errorf("expected SCSI controller on bus %d to be %q, got %q", bus, expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
-		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskAddress checks that the VM has
+// a disk at unit number unit of the SCSI controller on bus number bus.
+func testAccResourceVSphereVirtualMachineCheckDiskAddress(bus, unit int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device)
+		c := scsiControllerOnBus(devices, bus)
+		if c == nil {
+			return fmt.Errorf("no SCSI controller found on bus %d", bus)
+		}
+		if _, ok := unitNumberDevice(devices, c, unit).(*types.VirtualDisk); !ok {
+			return fmt.Errorf("no disk found at SCSI %d:%d", bus, unit)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
-		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckSATAControllerCount checks that the
+// VM has expected SATA controllers.
+func testAccResourceVSphereVirtualMachineCheckSATAControllerCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if actual := len(sataControllers(object.VirtualDeviceList(props.Config.Hardware.Device))); actual != expected {
+			return fmt.Errorf("expected %d SATA controllers, got %d", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_DATASTORE") == "" {
-		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckSATADiskAddress checks that the VM
+// has a disk at unit number unit of the SATA controller on bus number bus.
+func testAccResourceVSphereVirtualMachineCheckSATADiskAddress(bus, unit int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device)
+		c, err := sataControllerForDisk(devices, bus)
+		if err != nil {
+			return err
+		}
+		if _, ok := unitNumberDevice(devices, c, unit).(*types.VirtualDisk); !ok {
+			return fmt.Errorf("no disk found at SATA %d:%d", bus, unit)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckHardwareVersion(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.Version != expected {
+			return fmt.Errorf("expected hardware version to be %q, got %q", expected, props.Config.Version)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDatastoreCluster checks to make
+// sure that all of the VM's disks were placed on datastores in the datastore
+// cluster with the supplied managed object ID, and that the datastore each
+// disk was placed on was recorded in state.
+func testAccResourceVSphereVirtualMachineCheckDatastoreCluster(podID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		for _, disk := range object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+			backing, ok := disk.GetVirtualDevice().Backing.(types.BaseVirtualDeviceFileBackingInfo)
+			if !ok || backing.GetVirtualDeviceFileBackingInfo().Datastore == nil {
+				return fmt.Errorf("could not find datastore for disk %d", disk.GetVirtualDevice().Key)
+			}
+			ds := object.NewDatastore(client.Client, *backing.GetVirtualDeviceFileBackingInfo().Datastore)
+			var dsProps mo.Datastore
+			if err := ds.Properties(context.TODO(), ds.Reference(), []string{"parent"}, &dsProps); err != nil {
+				return err
+			}
+			if dsProps.Parent == nil || dsProps.Parent.Value != podID {
+				return fmt.Errorf("expected disk %d to be in datastore cluster %s, got parent %v", disk.GetVirtualDevice().Key, podID, dsProps.Parent)
+			}
+		}
+
+		rs := s.RootModule().Resources["vsphere_virtual_machine.vm"]
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "disk.") && strings.HasSuffix(k, ".placed_datastore") && v == "" {
+				return fmt.Errorf("expected %s to be set", k)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNICSlots checks the PCI slot
+// numbers of the VM's network interfaces, in device order.
+func testAccResourceVSphereVirtualMachineCheckNICSlots(expected ...int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+		var actual []int32
+		for _, device := range devices {
+			var slot int32
+			if info, ok := device.GetVirtualDevice().SlotInfo.(*types.VirtualDevicePciBusSlotInfo); ok {
+				slot = info.PciSlotNumber
+			}
+			actual = append(actual, slot)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected network interface PCI slots to be %v, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineSaveNICMacs saves the MAC address of
+// each of the VM's network interfaces in macs, keyed by PCI slot number.
+func testAccResourceVSphereVirtualMachineSaveNICMacs(macs map[int32]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, device := range object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil)) {
+			macs[virtualDevicePciSlotNumber(device)] = device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNICMacs checks that the network
+// interfaces in the PCI slots saved in macs by
+// testAccResourceVSphereVirtualMachineSaveNICMacs still have the same MAC
+// address, both on the VM and in state.
+func testAccResourceVSphereVirtualMachineCheckNICMacs(macs map[int32]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := make(map[int32]string)
+		for _, device := range object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil)) {
+			actual[virtualDevicePciSlotNumber(device)] = device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+		}
+		for slot, mac := range macs {
+			if actual[slot] != mac {
+				return fmt.Errorf("expected network interface in PCI slot %d to have MAC address %q, got %q", slot, mac, actual[slot])
+			}
+		}
+		rs := s.RootModule().Resources["vsphere_virtual_machine.vm"]
+		for i := 0; ; i++ {
+			slot, ok := rs.Primary.Attributes[fmt.Sprintf("network_interface.%d.pci_slot_number", i)]
+			if !ok {
+				break
+			}
+			n, _ := strconv.Atoi(slot)
+			if mac, ok := macs[int32(n)]; ok && rs.Primary.Attributes[fmt.Sprintf("network_interface.%d.mac_address", i)] != mac {
+				return fmt.Errorf("expected network_interface.%d.mac_address to be %q", i, mac)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNICBandwidth checks the bandwidth
+// allocation of the VM's first network interface. A share count of 0 skips
+// the share count check, as it is calculated by vSphere for non-custom share
+// levels.
+func testAccResourceVSphereVirtualMachineCheckNICBandwidth(limit int64, level types.SharesLevel, shares int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+		if len(devices) < 1 {
+			return errors.New("no network interfaces found on VM")
+		}
+		alloc := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().ResourceAllocation
+		if alloc == nil {
+			return errors.New("no bandwidth allocation found on network interface")
+		}
+		if alloc.Limit == nil || *alloc.Limit != limit {
+			return fmt.Errorf("expected bandwidth limit to be %d, got %v", limit, alloc.Limit)
+		}
+		if alloc.Share.Level != level {
+			return fmt.Errorf("expected bandwidth share level to be %s, got %s", level, alloc.Share.Level)
+		}
+		if shares != 0 && alloc.Share.Shares != shares {
+			return fmt.Errorf("expected bandwidth share count to be %d, got %d", shares, alloc.Share.Shares)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNICConnection checks the connection
+// state of the first network interface of the VM.
+func testAccResourceVSphereVirtualMachineCheckNICConnection(connected, startConnected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+		if len(devices) < 1 {
+			return errors.New("no network interfaces found on VM")
+		}
+		c := devices[0].GetVirtualDevice().Connectable
+		if c == nil {
+			return errors.New("no connection info found on network interface")
+		}
+		if c.Connected != connected {
+			return fmt.Errorf("expected network interface connected to be %t, got %t", connected, c.Connected)
+		}
+		if c.StartConnected != startConnected {
+			return fmt.Errorf("expected network interface start_connected to be %t, got %t", startConnected, c.StartConnected)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfig checks the value of a
+// key in the VM's ExtraConfig. An empty expected value checks that the key is
+// not set.
+func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var actual string
+		for _, bov := range props.Config.ExtraConfig {
+			if opt := bov.GetOptionValue(); opt.Key == key {
+				actual = fmt.Sprintf("%v", opt.Value)
+			}
+		}
+		if actual != expected {
+			return fmt.Errorf("expected ExtraConfig key %q to be %q, got %q", key, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckCPUMemory(expectedCPU, expectedMemory int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.Hardware.NumCPU != expectedCPU {
+			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, props.Config.Hardware.NumCPU)
+		}
+		if props.Config.Hardware.MemoryMB != expectedMemory {
+			return fmt.Errorf("expected memory to be %d, got %d", expectedMemory, props.Config.Hardware.MemoryMB)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckResourceAllocation checks the CPU
+// or memory allocation of the VM. The share count is only checked for the
+// custom share level.
+func testAccResourceVSphereVirtualMachineCheckResourceAllocation(key string, reservation, limit int64, level string, shares int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		base := props.Config.CpuAllocation
+		if key == "memory" {
+			base = props.Config.MemoryAllocation
+		}
+		if base == nil {
+			return fmt.Errorf("%s allocation not found", key)
+		}
+		alloc := base.GetResourceAllocationInfo()
+		if alloc.Reservation == nil || *alloc.Reservation != reservation {
+			return fmt.Errorf("expected %s reservation to be %d, got %v", key, reservation, alloc.Reservation)
+		}
+		if alloc.Limit == nil || *alloc.Limit != limit {
+			return fmt.Errorf("expected %s limit to be %d, got %v", key, limit, alloc.Limit)
+		}
+		if alloc.Shares == nil || string(alloc.Shares.Level) != level {
+			return fmt.Errorf("expected %s share level to be %q, got %v", key, level, alloc.Shares)
+		}
+		if level == "custom" && alloc.Shares.Shares != shares {
+			return fmt.Errorf("expected %s share count to be %d, got %d", key, shares, alloc.Shares.Shares)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckRDM checks that the extra disk in
+// the RDM test maps the LUN in VSPHERE_RDM_LUN with the expected
+// compatibility mode.
+func testAccResourceVSphereVirtualMachineCheckRDM(expectedMode string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expectedName := testAccResourceVSphereVirtualMachineDiskNameRDM + ".vmdk"
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskRawDiskMappingVer1BackingInfo); ok {
+					if strings.HasSuffix(info.FileName, expectedName) {
+						if !strings.HasSuffix(info.DeviceName, os.Getenv("VSPHERE_RDM_LUN")) {
+							return fmt.Errorf("expected RDM to map %q, got %q", os.Getenv("VSPHERE_RDM_LUN"), info.DeviceName)
+						}
+						if info.CompatibilityMode != expectedMode {
+							return fmt.Errorf("expected compatibility mode to be %q, got %q", expectedMode, info.CompatibilityMode)
+						}
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not locate RDM: %s", expectedName)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
+// VM.
+func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actualCPU := props.Summary.Config.NumCpu
+		actualMem := props.Summary.Config.MemorySizeMB
+		if expectedCPU != actualCPU {
+			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
+		}
+		if expectedMem != actualMem {
+			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
+// machine's primary NIC has the given IP address and netmask assigned to it,
+// and that the appropriate gateway is present.
+//
+// This uses VMware tools to check this, so it needs to be installed on the
+// guest.
+func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		res, err := strconv.Atoi(expectedPrefix)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expectedPrefixInt := int32(res)
+
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var v4gw, v6gw net.IP
+		for _, s := range props.Guest.IpStack {
+			if s.IpRouteConfig != nil {
+				for _, r := range s.IpRouteConfig.IpRoute {
+					switch r.Network {
+					case "0.0.0.0":
+						v4gw = net.ParseIP(r.Gateway.IpAddress)
+					case "::":
+						v6gw = net.ParseIP(r.Gateway.IpAddress)
+					}
+				}
+			}
+		}
+		for _, n := range props.Guest.Net {
+			if n.IpConfig != nil {
+				for _, addr := range n.IpConfig.IpAddress {
+					ip := net.ParseIP(addr.IpAddress)
+					if !ip.Equal(net.ParseIP(expectedAddr)) {
+						continue
+					}
+					if addr.PrefixLength != expectedPrefixInt {
+						continue
+					}
+					var mask net.IPMask
+					if ip.To4() != nil {
+						mask = net.CIDRMask(int(addr.PrefixLength), 32)
+					} else {
+						mask = net.CIDRMask(int(addr.PrefixLength), 128)
+					}
+					switch {
+					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v4gw) {
+							return nil
+						}
+					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v6gw) {
+							return nil
+						}
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
 	}
-	if os.Getenv("VSPHERE_TEMPLATE") == "" {
-		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
+// for the supplied MAC address on the first network interface.
+func testAccResourceVSphereVirtualMachineCheckStaticMACAddr(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Guest.Net[0].MacAddress
+		if expected != actual {
+			return fmt.Errorf("expected MAC address to be %s, got %s", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckOpaqueNetworkBacking is a check
+// to ensure that the first network interface of a VM is backed by an opaque
+// network.
+func testAccResourceVSphereVirtualMachineCheckOpaqueNetworkBacking() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+		if len(devices) < 1 {
+			return errors.New("expected a network interface, got none")
+		}
+		backing := devices[0].GetVirtualDevice().Backing
+		if _, ok := backing.(*types.VirtualEthernetCardOpaqueNetworkBackingInfo); !ok {
+			return fmt.Errorf("expected opaque network backing, got %T", backing)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
+// that a VM's annotation is correctly set in the annotation test.
+func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		expected := testAccResourceVSphereVirtualMachineAnnotation
+		actual := props.Config.Annotation
+		if expected != actual {
+			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
+// to ensure that events have been received for customization success on a VM.
+func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
+		if err != nil {
+			return err
+		}
+		if len(actual) < 1 {
+			return errors.New("customization success event was not received")
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
+// tags that have been created with supplied resource name have been attached
+// to the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, vm, tagResName)
 	}
-	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
-		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
+}
+
+func testAccResourceVSphereVirtualMachineConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigBeefy() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 4
+  memory = 8192
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigSeparateHostname() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  hostname = "terraform-test-renamed"
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigExtraDisks() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "disk_name_eager" {
+  default = "%s"
+}
+
+variable "disk_name_lazy" {
+  default = "%s"
+}
+
+variable "disk_name_thin" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  disk {
+    size = 1
+    type = "eager_zeroed"
+    name = "${var.disk_name_eager}"
+  }
+
+  disk {
+    size = 1
+    type = "lazy"
+    name = "${var.disk_name_lazy}"
+  }
+  
+	disk {
+    size = 1
+    type = "thin"
+    name = "${var.disk_name_thin}"
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameEager,
+		testAccResourceVSphereVirtualMachineDiskNameLazy,
+		testAccResourceVSphereVirtualMachineDiskNameThin,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigMixedSCSIControllers() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "disk_name" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  disk {
+    size                  = 1
+    type                  = "thin"
+    name                  = "${var.disk_name}"
+    controller_type       = "scsi-paravirtual"
+    controller_bus_number = 1
+    unit_number           = 0
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameThin,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigSATAControllers(count int, disk bool) string {
+	sataDisk := ""
+	if disk {
+		sataDisk = fmt.Sprintf(`
+  disk {
+    size                  = 1
+    type                  = "thin"
+    name                  = "%s"
+    controller_type       = "sata"
+    controller_bus_number = 1
+    unit_number           = 0
+  }
+`, testAccResourceVSphereVirtualMachineDiskNameThin)
 	}
+
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  sata_controller_count = %d
+%s
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		count,
+		sataDisk,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCustomConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  custom_configuration_parameters {
+    "foo" = "bar"
+    "baz" = "qux"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCustomConfigUpdated() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  custom_configuration_parameters {
+    "foo" = "baz"
+  }
+
+  managed_custom_configuration_keys = ["baz"]
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigGuestInfo() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  annotation = <<EOF
+Managed by Terraform
+Owner: terraform-test
+EOF
+
+  guestinfo {
+    "foo" = "bar"
+    "baz" = "qux"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigGuestInfoUpdated() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  guestinfo {
+    "foo" = "baz"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigOpaqueNetwork() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_OPAQUE_NETWORK"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
 }
 
-func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		_, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			if ok, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error()); ok && !expected {
-				// Expected missing
-				return nil
-			}
-			return err
-		}
-		if !expected {
-			return errors.New("expected VM to be missing")
-		}
-		return nil
-	}
+func testAccResourceVSphereVirtualMachineConfigInFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
-// a VirtualMachine's power state.
-func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Runtime.PowerState
-		if expected != actual {
-			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "cluster" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
-// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
-// to be installed.
-func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Guest.HostName
-		if expected != actual {
-			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
-// parameters on the vsphere_virtual_machine extra disks test. This is a very
-// specific check that checks for the specific disk devices and respective
-// backings, and expects them in the exact order outlined in the function.
-func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "network_label" {
+  default = "%s"
+}
 
-		var foundEager, foundLazy, foundThin bool
-		expectedEagerName := testAccResourceVSphereVirtualMachineDiskNameEager + ".vmdk"
-		expectedLazyName := testAccResourceVSphereVirtualMachineDiskNameLazy + ".vmdk"
-		expectedThinName := testAccResourceVSphereVirtualMachineDiskNameThin + ".vmdk"
+variable "ipv4_address" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var eager bool
-					if info.EagerlyScrub != nil {
-						eager = *info.EagerlyScrub
-					}
-					switch {
-					case strings.HasSuffix(info.FileName, expectedEagerName) && eager:
-						foundEager = true
-					case strings.HasSuffix(info.FileName, expectedLazyName) && !eager:
-						foundLazy = true
-					case strings.HasSuffix(info.FileName, expectedThinName) && *info.ThinProvisioned:
-						foundThin = true
-					}
-				}
-			}
-		}
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "terraform-test-vms"
+  type          = "vm"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+  folder        = "${vsphere_folder.folder.path}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigExistingVmdk() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "extra_vmdk_name" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_disk" "disk" {
+  size         = 1
+  vmdk_path    = "${var.extra_vmdk_name}"
+  datacenter   = "${var.datacenter}"
+  datastore    = "${var.datastore}"
+  type         = "thin"
+  adapter_type = "lsiLogic"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
 
-		if !foundEager {
-			return fmt.Errorf("could not locate disk: %s", expectedEagerName)
-		}
-		if !foundLazy {
-			return fmt.Errorf("could not locate disk: %s", expectedLazyName)
-		}
-		if !foundThin {
-			return fmt.Errorf("could not locate disk: %s", expectedThinName)
-		}
+  disk {
+    datastore      = "${var.datastore}"
+    vmdk           = "${vsphere_virtual_disk.disk.vmdk_path}"
+    keep_on_remove = true
+  }
 
-		return nil
-	}
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameExtraVmdk,
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
-// virtual machine's folder matches the folder supplied with expected.
-func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expected, err := rootPathParticleVM.PathFromNewRoot(vm.InventoryPath, rootPathParticleVM, expected)
-		actual := path.Dir(vm.InventoryPath)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+func testAccResourceVSphereVirtualMachineConfigExistingVmdkFullPath() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
-// sure that the appropriate disk is attached in the existing VMDK test.
-func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "cluster" {
+  default = "%s"
+}
 
-		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+variable "resource_pool" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					if strings.HasSuffix(info.FileName, expected) {
-						return nil
-					}
-				}
-			}
-		}
+variable "network_label" {
+  default = "%s"
+}
 
-		return fmt.Errorf("could not find attached disk matching %q", expected)
-	}
+variable "ipv4_address" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
-// VM.
-func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actualCPU := props.Summary.Config.NumCpu
-		actualMem := props.Summary.Config.MemorySizeMB
-		if expectedCPU != actualCPU {
-			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
-		}
-		if expectedMem != actualMem {
-			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
-		}
-		return nil
-	}
+variable "ipv4_prefix" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
-// machine's primary NIC has the given IP address and netmask assigned to it,
-// and that the appropriate gateway is present.
-//
-// This uses VMware tools to check this, so it needs to be installed on the
-// guest.
-func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		res, err := strconv.Atoi(expectedPrefix)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expectedPrefixInt := int32(res)
+variable "ipv4_gateway" {
+  default = "%s"
+}
 
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var v4gw, v6gw net.IP
-		for _, s := range props.Guest.IpStack {
-			if s.IpRouteConfig != nil {
-				for _, r := range s.IpRouteConfig.IpRoute {
-					switch r.Network {
-					case "0.0.0.0":
-						v4gw = net.ParseIP(r.Gateway.IpAddress)
-					case "::":
-						v6gw = net.ParseIP(r.Gateway.IpAddress)
-					}
-				}
-			}
-		}
-		for _, n := range props.Guest.Net {
-			if n.IpConfig != nil {
-				for _, addr := range n.IpConfig.IpAddress {
-					ip := net.ParseIP(addr.IpAddress)
-					if !ip.Equal(net.ParseIP(expectedAddr)) {
-						continue
-					}
-					if addr.PrefixLength != expectedPrefixInt {
-						continue
-					}
-					var mask net.IPMask
-					if ip.To4() != nil {
-						mask = net.CIDRMask(int(addr.PrefixLength), 32)
-					} else {
-						mask = net.CIDRMask(int(addr.PrefixLength), 128)
-					}
-					switch {
-					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v4gw) {
-							return nil
-						}
-					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v6gw) {
-							return nil
-						}
-					}
-				}
-			}
-		}
+variable "datastore" {
+  default = "%s"
+}
 
-		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
-	}
+variable "template" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
-// for the MAC address defined in the static MAC address test on the first
-// network interface.
-func testAccResourceVSphereVirtualMachineCheckStaticMACAddr() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Guest.Net[0].MacAddress
-		expected := testAccResourceVSphereVirtualMachineStaticMacAddr
-		if expected != actual {
-			return fmt.Errorf("expected MAC address to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "linked_clone" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
-// that a VM's annotation is correctly set in the annotation test.
-func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		expected := testAccResourceVSphereVirtualMachineAnnotation
-		actual := props.Config.Annotation
-		if expected != actual {
-			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+variable "extra_vmdk_name" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
-// to ensure that events have been received for customization success on a VM.
-func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
-		if err != nil {
-			return err
-		}
-		if len(actual) < 1 {
-			return errors.New("customization success event was not received")
-		}
-		return nil
-	}
+resource "vsphere_virtual_disk" "disk" {
+  size         = 1
+  vmdk_path    = "${var.extra_vmdk_name}"
+  datacenter   = "${var.datacenter}"
+  datastore    = "${var.datastore}"
+  type         = "thin"
+  adapter_type = "lsiLogic"
 }
 
-// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
-// tags that have been created with supplied resource name have been attached
-// to the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
-		if err != nil {
-			return err
-		}
-		return testObjectHasTags(s, tagsClient, vm, tagResName)
-	}
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  disk {
+    vmdk = "[${var.datastore}] ${vsphere_virtual_disk.disk.vmdk_path}"
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameExtraVmdk,
+	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasic() string {
+func testAccResourceVSphereVirtualMachineConfigDiskMode(mode string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -853,6 +3561,14 @@ variable "linked_clone" {
   default = "%s"
 }
 
+variable "disk_name_mode" {
+  default = "%s"
+}
+
+variable "disk_mode" {
+  default = "%s"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name          = "terraform-test"
   datacenter    = "${var.datacenter}"
@@ -875,6 +3591,13 @@ resource "vsphere_virtual_machine" "vm" {
     iops      = 500
   }
 
+  disk {
+    size      = 1
+    type      = "thin"
+    name      = "${var.disk_name_mode}"
+    disk_mode = "${var.disk_mode}"
+  }
+
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
 }
 `,
@@ -888,10 +3611,12 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameMode,
+		mode,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBeefy() string {
+func testAccResourceVSphereVirtualMachineConfigHotAdd(vcpu, memory int, hotAdd, autoPowerCycle bool) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -939,8 +3664,12 @@ resource "vsphere_virtual_machine" "vm" {
   cluster       = "${var.cluster}"
   resource_pool = "${var.resource_pool}"
 
-  vcpu   = 4
-  memory = 8192
+  vcpu   = %d
+  memory = %d
+
+  cpu_hot_add_enabled    = %t
+  memory_hot_add_enabled = %t
+  auto_power_cycle       = %t
 
   network_interface {
     label              = "${var.network_label}"
@@ -968,10 +3697,15 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		vcpu,
+		memory,
+		hotAdd,
+		hotAdd,
+		autoPowerCycle,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigSeparateHostname() string {
+func testAccResourceVSphereVirtualMachineConfigResourceAllocation(extra string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1021,9 +3755,7 @@ resource "vsphere_virtual_machine" "vm" {
 
   vcpu   = 2
   memory = 1024
-
-  hostname = "terraform-test-renamed"
-
+%s
   network_interface {
     label              = "${var.network_label}"
     ipv4_address       = "${var.ipv4_address}"
@@ -1050,10 +3782,11 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		extra,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigExtraDisks() string {
+func testAccResourceVSphereVirtualMachineConfigSDRSDisks(policy string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1083,7 +3816,7 @@ variable "ipv4_gateway" {
   default = "%s"
 }
 
-variable "datastore" {
+variable "datastore_cluster_id" {
   default = "%s"
 }
 
@@ -1095,15 +3828,15 @@ variable "linked_clone" {
   default = "%s"
 }
 
-variable "disk_name_eager" {
+variable "disk_name_sdrs1" {
   default = "%s"
 }
 
-variable "disk_name_lazy" {
+variable "disk_name_sdrs2" {
   default = "%s"
 }
 
-variable "disk_name_thin" {
+variable "datastore_selection_policy" {
   default = "%s"
 }
 
@@ -1116,6 +3849,9 @@ resource "vsphere_virtual_machine" "vm" {
   vcpu   = 2
   memory = 1024
 
+  datastore_cluster_id       = "${var.datastore_cluster_id}"
+  datastore_selection_policy = "${var.datastore_selection_policy}"
+
   network_interface {
     label              = "${var.network_label}"
     ipv4_address       = "${var.ipv4_address}"
@@ -1124,27 +3860,17 @@ resource "vsphere_virtual_machine" "vm" {
   }
 
   disk {
-    datastore = "${var.datastore}"
-    template  = "${var.template}"
-    iops      = 500
+    template = "${var.template}"
   }
 
   disk {
+    name = "${var.disk_name_sdrs1}"
     size = 1
-    type = "eager_zeroed"
-    name = "${var.disk_name_eager}"
   }
 
   disk {
+    name = "${var.disk_name_sdrs2}"
     size = 1
-    type = "lazy"
-    name = "${var.disk_name_lazy}"
-  }
-  
-	disk {
-    size = 1
-    type = "thin"
-    name = "${var.disk_name_thin}"
   }
 
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
@@ -1157,16 +3883,16 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_IPV4_ADDRESS"),
 		os.Getenv("VSPHERE_IPV4_PREFIX"),
 		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_DATASTORE_CLUSTER_ID"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
-		testAccResourceVSphereVirtualMachineDiskNameEager,
-		testAccResourceVSphereVirtualMachineDiskNameLazy,
-		testAccResourceVSphereVirtualMachineDiskNameThin,
+		testAccResourceVSphereVirtualMachineDiskNameSDRS1,
+		testAccResourceVSphereVirtualMachineDiskNameSDRS2,
+		policy,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigCustomConfig() string {
+func testAccResourceVSphereVirtualMachineConfigNICBandwidth(extra string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1222,12 +3948,7 @@ resource "vsphere_virtual_machine" "vm" {
     ipv4_address       = "${var.ipv4_address}"
     ipv4_prefix_length = "${var.ipv4_prefix}"
     ipv4_gateway       = "${var.ipv4_gateway}"
-  }
-
-  custom_configuration_parameters {
-    "foo" = "bar"
-    "baz" = "qux"
-  }
+%s  }
 
   disk {
     datastore = "${var.datastore}"
@@ -1248,10 +3969,11 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		extra,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigInFolder() string {
+func testAccResourceVSphereVirtualMachineConfigPciSlots(nics string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1293,14 +4015,86 @@ variable "linked_clone" {
   default = "%s"
 }
 
-data "vsphere_datacenter" "datacenter" {
-  name = "${var.datacenter}"
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+    pci_slot_number    = 192
+  }
+%s
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		nics,
+	)
 }
 
-resource "vsphere_folder" "folder" {
-  path          = "terraform-test-vms"
-  type          = "vm"
-  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+func testAccResourceVSphereVirtualMachineConfigWaitForGuestIP(family string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
 }
 
 resource "vsphere_virtual_machine" "vm" {
@@ -1308,7 +4102,6 @@ resource "vsphere_virtual_machine" "vm" {
   datacenter    = "${var.datacenter}"
   cluster       = "${var.cluster}"
   resource_pool = "${var.resource_pool}"
-  folder        = "${vsphere_folder.folder.path}"
 
   vcpu   = 2
   memory = 1024
@@ -1318,8 +4111,11 @@ resource "vsphere_virtual_machine" "vm" {
     ipv4_address       = "${var.ipv4_address}"
     ipv4_prefix_length = "${var.ipv4_prefix}"
     ipv4_gateway       = "${var.ipv4_gateway}"
+    wait_for_guest_ip  = "%s"
   }
 
+  wait_for_guest_ip_timeout = 10
+
   disk {
     datastore = "${var.datastore}"
     template  = "${var.template}"
@@ -1339,10 +4135,11 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		family,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigExistingVmdk() string {
+func testAccResourceVSphereVirtualMachineConfigRDM(mode, extra string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1384,17 +4181,12 @@ variable "linked_clone" {
   default = "%s"
 }
 
-variable "extra_vmdk_name" {
+variable "disk_name_rdm" {
   default = "%s"
 }
 
-resource "vsphere_virtual_disk" "disk" {
-  size         = 1
-  vmdk_path    = "${var.extra_vmdk_name}"
-  datacenter   = "${var.datacenter}"
-  datastore    = "${var.datastore}"
-  type         = "thin"
-  adapter_type = "lsiLogic"
+variable "rdm_lun" {
+  default = "%s"
 }
 
 resource "vsphere_virtual_machine" "vm" {
@@ -1420,9 +4212,10 @@ resource "vsphere_virtual_machine" "vm" {
   }
 
   disk {
-    datastore      = "${var.datastore}"
-    vmdk           = "${vsphere_virtual_disk.disk.vmdk_path}"
-    keep_on_remove = true
+    name                   = "${var.disk_name_rdm}"
+    rdm_lun                = "${var.rdm_lun}"
+    rdm_compatibility_mode = "%s"
+    %s
   }
 
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
@@ -1438,7 +4231,10 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
-		testAccResourceVSphereVirtualMachineDiskNameExtraVmdk,
+		testAccResourceVSphereVirtualMachineDiskNameRDM,
+		os.Getenv("VSPHERE_RDM_LUN"),
+		mode,
+		extra,
 	)
 }
 
@@ -1611,6 +4407,93 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigUseStaticMAC(mac string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "static_mac_addr" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    use_static_mac     = true
+    mac_address        = "${var.static_mac_addr}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		mac,
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigWithAnnotation() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
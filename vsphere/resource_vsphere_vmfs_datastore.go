@@ -81,6 +81,13 @@ func resourceVSphereVmfsDatastore() *schema.Resource {
 			MinItems:    1,
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
+		"mounted_host_system_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: "The managed object IDs of the hosts to mount the datastore on. Must include host_system_id. Defaults to the hosts that mount the datastore on their own.",
+			Optional:    true,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
 	}
 	mergeSchema(s, schemaDatastoreSummary())
 
@@ -110,6 +117,9 @@ func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}
 	}
 
 	hsID := d.Get("host_system_id").(string)
+	if err := validateVmfsDatastoreMountedHosts(d); err != nil {
+		return err
+	}
 	dss, err := hostDatastoreSystemFromHostSystemID(client, hsID)
 	if err != nil {
 		return fmt.Errorf("error loading host datastore system: %s", err)
@@ -181,6 +191,20 @@ func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}
 
 	d.SetId(ds.Reference().Value)
 
+	// Other hosts that can see the disks mount the new datastore on their own,
+	// so the mounted hosts are reconciled with the configuration, if it has
+	// any, once the datastore is complete.
+	if v, ok := d.GetOk("mounted_host_system_ids"); ok {
+		props, err := datastoreProperties(ds)
+		if err != nil {
+			return fmt.Errorf("could not get properties for datastore: %s", err)
+		}
+		current := sliceStringsToInterfaces(datastoreMountedHostSystemIDs(props))
+		if err := processVmfsDatastoreMounts(client, props, schema.NewSet(schema.HashString, current), v.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
 	// Done
 	return resourceVSphereVmfsDatastoreRead(d, meta)
 }
@@ -216,6 +240,11 @@ func resourceVSphereVmfsDatastoreRead(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	// Update mounted hosts
+	if err := d.Set("mounted_host_system_ids", datastoreMountedHostSystemIDs(props)); err != nil {
+		return err
+	}
+
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
 		if err := readTagsForResource(tagsClient, ds, d); err != nil {
@@ -270,6 +299,22 @@ func resourceVSphereVmfsDatastoreUpdate(d *schema.ResourceData, meta interface{}
 		}
 	}
 
+	// Mount or unmount the datastore on hosts whose mount state has drifted
+	// from the configuration.
+	if d.HasChange("mounted_host_system_ids") {
+		if err := validateVmfsDatastoreMountedHosts(d); err != nil {
+			return err
+		}
+		props, err := datastoreProperties(ds)
+		if err != nil {
+			return fmt.Errorf("could not get properties for datastore: %s", err)
+		}
+		o, n := d.GetChange("mounted_host_system_ids")
+		if err := processVmfsDatastoreMounts(client, props, o.(*schema.Set), n.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
 	// Veto this update if it means a disk was removed. Shrinking
 	// datastores/removing extents is not supported.
 	old, new := d.GetChange("disks")
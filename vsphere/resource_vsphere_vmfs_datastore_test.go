@@ -224,6 +224,36 @@ func TestAccResourceVSphereVmfsDatastore(t *testing.T) {
 				},
 			},
 		},
+		{
+			"mounted hosts",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVmfsDatastorePreCheck(tp)
+					if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
+						tp.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_vmfs_datastore mounted hosts acceptance tests")
+					}
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVmfsDatastoreExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVmfsDatastoreConfigMountedHosts(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVmfsDatastoreExists(true),
+							testAccResourceVSphereVmfsDatastoreMountedHostCount(2),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVmfsDatastoreConfigMountedHosts(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVmfsDatastoreExists(true),
+							testAccResourceVSphereVmfsDatastoreMountedHostCount(1),
+						),
+					},
+				},
+			},
+		},
 		{
 			"import",
 			resource.TestCase{
@@ -344,6 +374,64 @@ func testAccResourceVSphereVmfsDatastoreMatchInventoryPath(expected string) reso
 	}
 }
 
+func testAccResourceVSphereVmfsDatastoreMountedHostCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ds, err := testGetDatastore(s, "vsphere_vmfs_datastore.datastore")
+		if err != nil {
+			return err
+		}
+
+		props, err := datastoreProperties(ds)
+		if err != nil {
+			return err
+		}
+
+		actual := len(datastoreMountedHostSystemIDs(props))
+		if expected != actual {
+			return fmt.Errorf("expected datastore to be mounted on %d hosts, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVmfsDatastoreConfigMountedHosts(both bool) string {
+	mounted := `"${data.vsphere_host.esxi_host.id}"`
+	if both {
+		mounted += `, "${data.vsphere_host.esxi_host2.id}"`
+	}
+	return fmt.Sprintf(`
+variable "disk0" {
+  type    = "string"
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host" "esxi_host2" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_vmfs_datastore" "datastore" {
+  name           = "terraform-test"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  disks = [
+    "${var.disk0}",
+  ]
+
+  mounted_host_system_ids = [%s]
+}
+`, os.Getenv("VSPHERE_DS_VMFS_DISK0"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), os.Getenv("VSPHERE_ESXI_HOST2"), mounted)
+}
+
 func testAccResourceVSphereVmfsDatastoreConfigStaticSingle() string {
 	return fmt.Sprintf(`
 variable "disk0" {
@@ -0,0 +1,229 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereVNic() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"host_system_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the host to create the VMkernel adapter on.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"device": {
+			Type:        schema.TypeString,
+			Description: "The device name of the VMkernel adapter, ie: vmk1.",
+			Computed:    true,
+		},
+	}
+	mergeSchema(s, schemaHostVirtualNicSpec())
+
+	return &schema.Resource{
+		Create: resourceVSphereVNicCreate,
+		Read:   resourceVSphereVNicRead,
+		Update: resourceVSphereVNicUpdate,
+		Delete: resourceVSphereVNicDelete,
+		Schema: s,
+	}
+}
+
+func resourceVSphereVNicCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+	if err := validateVNicTarget(d, client, ns); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	spec := expandHostVirtualNicSpec(d)
+	device, err := ns.AddVirtualNic(ctx, d.Get("portgroup").(string), *spec)
+	if err != nil {
+		return fmt.Errorf("error adding VMkernel adapter: %s", err)
+	}
+
+	saveHostVirtualNicID(d, hsID, device)
+	return resourceVSphereVNicRead(d, meta)
+}
+
+func resourceVSphereVNicRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	nic, err := hostVirtualNicFromDevice(client, ns, device)
+	if err != nil {
+		return fmt.Errorf("error fetching VMkernel adapter data: %s", err)
+	}
+
+	d.Set("host_system_id", hsID)
+	if err := flattenHostVirtualNic(d, nic); err != nil {
+		return fmt.Errorf("error setting resource data: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereVNicUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	spec := expandHostVirtualNicSpec(d)
+	if d.HasChange("portgroup") || d.HasChange("distributed_switch_port") || d.HasChange("distributed_port_group") || d.HasChange("mtu") {
+		if err := validateVNicTarget(d, client, ns); err != nil {
+			return err
+		}
+	}
+	if d.HasChange("portgroup") || d.HasChange("distributed_switch_port") || d.HasChange("distributed_port_group") {
+		if err := migrateVNic(client, ns, device, d.Get("portgroup").(string), *spec); err != nil {
+			return err
+		}
+		return resourceVSphereVNicRead(d, meta)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ns.UpdateVirtualNic(ctx, device, *spec); err != nil {
+		return fmt.Errorf("error updating VMkernel adapter: %s", err)
+	}
+
+	return resourceVSphereVNicRead(d, meta)
+}
+
+func resourceVSphereVNicDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ns.RemoveVirtualNic(ctx, device); err != nil {
+		return fmt.Errorf("error deleting VMkernel adapter: %s", err)
+	}
+
+	return nil
+}
+
+// validateVNicTarget checks to make sure that exactly one of a standard port
+// group or a distributed port group has been defined for the adapter, and
+// that the port group exists and is available on the host. For standard port
+// groups, the adapter's MTU is also checked against the virtual switch.
+func validateVNicTarget(d *schema.ResourceData, client *govmomi.Client, ns *object.HostNetworkSystem) error {
+	pgName := d.Get("portgroup").(string)
+	dvsID := d.Get("distributed_switch_port").(string)
+	dvpgKey := d.Get("distributed_port_group").(string)
+
+	switch {
+	case pgName != "":
+		pg, err := hostPortGroupFromName(client, ns, pgName)
+		if err != nil {
+			return fmt.Errorf("cannot locate target port group: %s", err)
+		}
+		sw, err := hostVSwitchFromName(client, ns, pg.Spec.VswitchName)
+		if err != nil {
+			return fmt.Errorf("error fetching virtual switch data: %s", err)
+		}
+		// An adapter with an MTU higher than its virtual switch will drop large
+		// frames, so catch this before the adapter is created or moved.
+		if mtu := int32(d.Get("mtu").(int)); sw.Mtu != 0 && mtu > sw.Mtu {
+			return fmt.Errorf("mtu (%d) cannot be higher than the MTU of virtual switch %q (%d)", mtu, sw.Name, sw.Mtu)
+		}
+	case dvsID != "" && dvpgKey != "":
+		if err := validateVirtualCenter(client); err != nil {
+			return err
+		}
+		pg, err := dvPortgroupFromUUID(client, dvsID, dvpgKey)
+		if err != nil {
+			return fmt.Errorf("cannot locate target distributed port group: %s", err)
+		}
+		props, err := dvPortgroupProperties(pg)
+		if err != nil {
+			return fmt.Errorf("error fetching distributed port group properties: %s", err)
+		}
+		hsID := d.Get("host_system_id").(string)
+		var found bool
+		for _, ref := range props.Host {
+			if ref.Value == hsID {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("host %q is not a member of distributed port group %q", hsID, props.Name)
+		}
+	case dvsID != "" || dvpgKey != "":
+		return errors.New("distributed_switch_port and distributed_port_group must both be defined")
+	default:
+		return errors.New("one of portgroup or distributed_switch_port and distributed_port_group must be defined")
+	}
+	return nil
+}
+
+// migrateVNic moves a VMkernel adapter to the supplied standard port group,
+// or the distributed port group in spec if portgroup is empty.
+//
+// If the move fails, or the host cannot be reached through the adapter's host
+// network system after the move, an attempt is made to restore the adapter's
+// previous configuration, to avoid isolating the host.
+func migrateVNic(client *govmomi.Client, ns *object.HostNetworkSystem, device, portgroup string, spec types.HostVirtualNicSpec) error {
+	old, err := hostVirtualNicFromDevice(client, ns, device)
+	if err != nil {
+		return fmt.Errorf("error fetching VMkernel adapter data: %s", err)
+	}
+
+	log.Printf("[DEBUG] Migrating VMkernel adapter %q", device)
+	err = moveHostVirtualNic(ns, device, portgroup, spec)
+	if err == nil {
+		_, err = hostVirtualNicFromDevice(client, ns, device)
+	}
+	if err == nil {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Migration of VMkernel adapter %q failed, rolling back: %s", device, err)
+	rspec := old.Spec
+	if dvp := rspec.DistributedVirtualPort; dvp != nil {
+		// Reconnect to the port group, not the specific port that the adapter
+		// was connected to, as the port may have been released.
+		rspec.DistributedVirtualPort = &types.DistributedVirtualSwitchPortConnection{
+			SwitchUuid:   dvp.SwitchUuid,
+			PortgroupKey: dvp.PortgroupKey,
+		}
+	}
+	if rerr := moveHostVirtualNic(ns, device, old.Portgroup, rspec); rerr != nil {
+		return fmt.Errorf("error migrating VMkernel adapter: %s (additionally, rollback failed: %s)", err, rerr)
+	}
+	return fmt.Errorf("error migrating VMkernel adapter (changes rolled back): %s", err)
+}
@@ -0,0 +1,336 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereVNic(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVNicCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic, standard port group",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigStandard(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckPortgroup("PGTerraformTest"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"migrate from standard to distributed port group and back",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigStandard(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckPortgroup("PGTerraformTest"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNicConfigDistributed(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckDistributed(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNicConfigStandard(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckPortgroup("PGTerraformTest"),
+							testAccResourceVSphereVNicCheckDistributed(false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"jumbo frames",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigMtu(9000, 1500),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckMtu(1500),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNicConfigMtu(9000, 9000),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckMtu(9000),
+						),
+					},
+				},
+			},
+		},
+		{
+			"MTU higher than virtual switch",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccResourceVSphereVNicConfigMtu(1500, 9000),
+						ExpectError: regexp.MustCompile("cannot be higher than the MTU of virtual switch"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVNicCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVNicPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC1") == "" {
+		t.Skip("set VSPHERE_HOST_NIC1 to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_vnic acceptance tests")
+	}
+}
+
+// testGetVNic is a convenience method to fetch a VMkernel adapter by resource
+// name.
+func testGetVNic(s *terraform.State, resourceName string) (*types.HostVirtualNic, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_vnic.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+
+	hsID, device, err := splitHostVirtualNicID(tVars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(tVars.client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	return hostVirtualNicFromDevice(tVars.client, ns, device)
+}
+
+func testAccResourceVSphereVNicExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_vnic.vnic"]
+		if !ok {
+			if expected {
+				return fmt.Errorf("vsphere_vnic.vnic not found in state")
+			}
+			return nil
+		}
+		_, err := testGetVNic(s, "vnic")
+		if err != nil {
+			if err.Error() == fmt.Sprintf("could not find VMkernel adapter %s", rs.Primary.Attributes["device"]) && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected VMkernel adapter %s to be missing", rs.Primary.Attributes["device"])
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckPortgroup(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic.Portgroup != expected {
+			return fmt.Errorf("expected port group to be %q, got %q", expected, nic.Portgroup)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckDistributed(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		actual := nic.Spec.DistributedVirtualPort != nil
+		if expected != actual {
+			return fmt.Errorf("expected adapter to be on a distributed port group to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckMtu(expected int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic.Spec.Mtu != expected {
+			return fmt.Errorf("expected MTU to be %d, got %d", expected, nic.Spec.Mtu)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicConfigBase() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}"]
+  active_nics      = ["${var.host_nic0}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+
+  host {
+    host_system_id = "${data.vsphere_host.esxi_host.id}"
+    devices        = ["${var.host_nic1}"]
+  }
+}
+
+resource "vsphere_distributed_port_group" "dvpg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereVNicConfigStandard() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+}
+`, testAccResourceVSphereVNicConfigBase())
+}
+
+func testAccResourceVSphereVNicConfigDistributed() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id          = "${data.vsphere_host.esxi_host.id}"
+  distributed_switch_port = "${vsphere_distributed_virtual_switch.dvs.id}"
+  distributed_port_group  = "${vsphere_distributed_port_group.dvpg.key}"
+}
+`, testAccResourceVSphereVNicConfigBase())
+}
+
+func testAccResourceVSphereVNicConfigMtu(switchMtu, nicMtu int) string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  mtu            = %d
+
+  network_adapters = ["${var.host_nic0}"]
+  active_nics      = ["${var.host_nic0}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+}
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+  mtu            = %d
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), switchMtu, nicMtu)
+}
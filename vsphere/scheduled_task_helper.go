@@ -0,0 +1,92 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// scheduledTaskReferenceFromID returns a ManagedObjectReference for a
+// ScheduledTask from its managed object ID.
+func scheduledTaskReferenceFromID(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "ScheduledTask",
+		Value: id,
+	}
+}
+
+// scheduledTaskManagerReference returns the reference to the
+// ScheduledTaskManager, which is only available on vCenter.
+func scheduledTaskManagerReference(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.ServiceContent.ScheduledTaskManager == nil {
+		return types.ManagedObjectReference{}, errors.New("scheduled task manager is not available on this connection")
+	}
+	return *client.ServiceContent.ScheduledTaskManager, nil
+}
+
+// scheduledTaskProperties fetches the ScheduledTask MO for the scheduled task
+// with the supplied managed object ID.
+func scheduledTaskProperties(client *govmomi.Client, id string) (*mo.ScheduledTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ScheduledTask
+	st := object.NewCommon(client.Client, scheduledTaskReferenceFromID(id))
+	if err := st.Properties(ctx, st.Reference(), []string{"info"}, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createScheduledTask creates a scheduled task on the supplied entity, and
+// returns the managed object ID of the new task.
+func createScheduledTask(client *govmomi.Client, entity types.ManagedObjectReference, spec *types.ScheduledTaskSpec) (string, error) {
+	ref, err := scheduledTaskManagerReference(client)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.CreateScheduledTask{
+		This:   ref,
+		Entity: entity,
+		Spec:   spec,
+	}
+	res, err := methods.CreateScheduledTask(ctx, client, req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval.Value, nil
+}
+
+// reconfigureScheduledTask replaces the spec of the scheduled task with the
+// supplied managed object ID.
+func reconfigureScheduledTask(client *govmomi.Client, id string, spec *types.ScheduledTaskSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.ReconfigureScheduledTask{
+		This: scheduledTaskReferenceFromID(id),
+		Spec: spec,
+	}
+	_, err := methods.ReconfigureScheduledTask(ctx, client, req)
+	return err
+}
+
+// removeScheduledTask removes the scheduled task with the supplied managed
+// object ID.
+func removeScheduledTask(client *govmomi.Client, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.RemoveScheduledTask{
+		This: scheduledTaskReferenceFromID(id),
+	}
+	_, err := methods.RemoveScheduledTask(ctx, client, req)
+	return err
+}
@@ -0,0 +1,432 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	scheduledTaskActionPowerOn            = "power_on"
+	scheduledTaskActionPowerOff           = "power_off"
+	scheduledTaskActionCreateSnapshot     = "create_snapshot"
+	scheduledTaskActionRemoveAllSnapshots = "remove_all_snapshots"
+)
+
+// scheduledTaskActionMethods maps the actions that a scheduled task can run
+// to the names of the methods that are invoked on the target entity.
+var scheduledTaskActionMethods = map[string]string{
+	scheduledTaskActionPowerOn:            "PowerOnVM_Task",
+	scheduledTaskActionPowerOff:           "PowerOffVM_Task",
+	scheduledTaskActionCreateSnapshot:     "CreateSnapshot_Task",
+	scheduledTaskActionRemoveAllSnapshots: "RemoveAllSnapshots_Task",
+}
+
+var scheduledTaskActionAllowedValues = []string{
+	scheduledTaskActionPowerOn,
+	scheduledTaskActionPowerOff,
+	scheduledTaskActionCreateSnapshot,
+	scheduledTaskActionRemoveAllSnapshots,
+}
+
+const (
+	scheduledTaskFrequencyHourly  = "hourly"
+	scheduledTaskFrequencyDaily   = "daily"
+	scheduledTaskFrequencyWeekly  = "weekly"
+	scheduledTaskFrequencyMonthly = "monthly"
+)
+
+var scheduledTaskFrequencyAllowedValues = []string{
+	scheduledTaskFrequencyHourly,
+	scheduledTaskFrequencyDaily,
+	scheduledTaskFrequencyWeekly,
+	scheduledTaskFrequencyMonthly,
+}
+
+var dayOfWeekAllowedValues = []string{
+	string(types.DayOfWeekSunday),
+	string(types.DayOfWeekMonday),
+	string(types.DayOfWeekTuesday),
+	string(types.DayOfWeekWednesday),
+	string(types.DayOfWeekThursday),
+	string(types.DayOfWeekFriday),
+	string(types.DayOfWeekSaturday),
+}
+
+// schemaScheduledTaskRecurrence returns the schema for the recurrence
+// settings of a scheduled task.
+func schemaScheduledTaskRecurrence() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"frequency": {
+			Type:         schema.TypeString,
+			Description:  "How often the task runs. Can be one of hourly, daily, weekly, or monthly.",
+			Required:     true,
+			ValidateFunc: validation.StringInSlice(scheduledTaskFrequencyAllowedValues, false),
+		},
+		"interval": {
+			Type:         schema.TypeInt,
+			Description:  "The number of frequency units between runs, ie: 2 with a daily frequency runs the task every other day.",
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"minute": {
+			Type:         schema.TypeInt,
+			Description:  "The minute of the hour that the task runs at.",
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntBetween(0, 59),
+		},
+		"hour": {
+			Type:         schema.TypeInt,
+			Description:  "The hour of the day, in UTC, that the task runs at. Cannot be set for an hourly frequency.",
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntBetween(0, 23),
+		},
+		"days_of_week": {
+			Type:        schema.TypeList,
+			Description: "The days of the week that the task runs on, for a weekly frequency.",
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(dayOfWeekAllowedValues, false),
+			},
+		},
+		"day_of_month": {
+			Type:         schema.TypeInt,
+			Description:  "The day of the month that the task runs on, for a monthly frequency.",
+			Optional:     true,
+			ValidateFunc: validation.IntBetween(1, 31),
+		},
+		"start_time": {
+			Type:             schema.TypeString,
+			Description:      "The time, in RFC3339 format, after which the recurrence becomes active.",
+			Optional:         true,
+			ValidateFunc:     validateRFC3339Time,
+			DiffSuppressFunc: suppressRFC3339TimeDifferences,
+		},
+		"end_time": {
+			Type:             schema.TypeString,
+			Description:      "The time, in RFC3339 format, after which the task no longer runs.",
+			Optional:         true,
+			ValidateFunc:     validateRFC3339Time,
+			DiffSuppressFunc: suppressRFC3339TimeDifferences,
+		},
+	}
+}
+
+// validateRFC3339Time checks to make sure a string is a valid RFC3339 time.
+func validateRFC3339Time(v interface{}, k string) ([]string, []error) {
+	if _, err := time.Parse(time.RFC3339, v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid RFC3339 time: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// suppressRFC3339TimeDifferences suppresses diffs between RFC3339 times that
+// represent the same instant, as vCenter returns all times in UTC.
+func suppressRFC3339TimeDifferences(k, old, new string, d *schema.ResourceData) bool {
+	o, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	n, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return o.Equal(n)
+}
+
+// parseTimePtr parses an optional RFC3339 time. An empty string returns nil.
+func parseTimePtr(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// formatTimePtr formats an optional time in RFC3339 format. A nil time
+// returns an empty string.
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// expandRecurrentTaskScheduler reads the recurrence settings of a scheduled
+// task and returns the appropriate recurrent task scheduler.
+func expandRecurrentTaskScheduler(m map[string]interface{}) (types.BaseTaskScheduler, error) {
+	activeTime, err := parseTimePtr(m["start_time"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("recurrence: invalid start_time: %s", err)
+	}
+	expireTime, err := parseTimePtr(m["end_time"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("recurrence: invalid end_time: %s", err)
+	}
+	hourly := types.HourlyTaskScheduler{
+		RecurrentTaskScheduler: types.RecurrentTaskScheduler{
+			TaskScheduler: types.TaskScheduler{
+				ActiveTime: activeTime,
+				ExpireTime: expireTime,
+			},
+			Interval: int32(m["interval"].(int)),
+		},
+		Minute: int32(m["minute"].(int)),
+	}
+	daily := types.DailyTaskScheduler{
+		HourlyTaskScheduler: hourly,
+		Hour:                int32(m["hour"].(int)),
+	}
+
+	frequency := m["frequency"].(string)
+	days := m["days_of_week"].([]interface{})
+	day := m["day_of_month"].(int)
+	if frequency != scheduledTaskFrequencyWeekly && len(days) > 0 {
+		return nil, fmt.Errorf("recurrence: days_of_week can only be set when frequency is %s", scheduledTaskFrequencyWeekly)
+	}
+	if frequency == scheduledTaskFrequencyHourly && daily.Hour != 0 {
+		return nil, fmt.Errorf("recurrence: hour cannot be set when frequency is %s", scheduledTaskFrequencyHourly)
+	}
+	if frequency != scheduledTaskFrequencyMonthly && day != 0 {
+		return nil, fmt.Errorf("recurrence: day_of_month can only be set when frequency is %s", scheduledTaskFrequencyMonthly)
+	}
+
+	switch frequency {
+	case scheduledTaskFrequencyHourly:
+		return &hourly, nil
+	case scheduledTaskFrequencyDaily:
+		return &daily, nil
+	case scheduledTaskFrequencyWeekly:
+		if len(days) < 1 {
+			return nil, fmt.Errorf("recurrence: days_of_week is required when frequency is %s", scheduledTaskFrequencyWeekly)
+		}
+		weekly := &types.WeeklyTaskScheduler{
+			DailyTaskScheduler: daily,
+		}
+		for _, v := range days {
+			switch types.DayOfWeek(v.(string)) {
+			case types.DayOfWeekSunday:
+				weekly.Sunday = true
+			case types.DayOfWeekMonday:
+				weekly.Monday = true
+			case types.DayOfWeekTuesday:
+				weekly.Tuesday = true
+			case types.DayOfWeekWednesday:
+				weekly.Wednesday = true
+			case types.DayOfWeekThursday:
+				weekly.Thursday = true
+			case types.DayOfWeekFriday:
+				weekly.Friday = true
+			case types.DayOfWeekSaturday:
+				weekly.Saturday = true
+			}
+		}
+		return weekly, nil
+	case scheduledTaskFrequencyMonthly:
+		if day == 0 {
+			return nil, fmt.Errorf("recurrence: day_of_month is required when frequency is %s", scheduledTaskFrequencyMonthly)
+		}
+		return &types.MonthlyByDayTaskScheduler{
+			MonthlyTaskScheduler: types.MonthlyTaskScheduler{
+				DailyTaskScheduler: daily,
+			},
+			Day: int32(day),
+		}, nil
+	}
+	return nil, fmt.Errorf("recurrence: unsupported frequency %q", frequency)
+}
+
+// flattenRecurrentTaskScheduler returns the recurrence settings for a
+// recurrent task scheduler. The second return value is false if the scheduler
+// is not of a type that can be managed by the recurrence settings.
+func flattenRecurrentTaskScheduler(base types.BaseTaskScheduler) (map[string]interface{}, bool) {
+	var daily *types.DailyTaskScheduler
+	m := map[string]interface{}{
+		"days_of_week": []interface{}{},
+		"day_of_month": 0,
+	}
+	switch s := base.(type) {
+	case *types.HourlyTaskScheduler:
+		m["frequency"] = scheduledTaskFrequencyHourly
+		daily = &types.DailyTaskScheduler{HourlyTaskScheduler: *s}
+	case *types.DailyTaskScheduler:
+		m["frequency"] = scheduledTaskFrequencyDaily
+		daily = s
+	case *types.WeeklyTaskScheduler:
+		m["frequency"] = scheduledTaskFrequencyWeekly
+		daily = &s.DailyTaskScheduler
+		var days []interface{}
+		for _, v := range []struct {
+			enabled bool
+			day     types.DayOfWeek
+		}{
+			{s.Sunday, types.DayOfWeekSunday},
+			{s.Monday, types.DayOfWeekMonday},
+			{s.Tuesday, types.DayOfWeekTuesday},
+			{s.Wednesday, types.DayOfWeekWednesday},
+			{s.Thursday, types.DayOfWeekThursday},
+			{s.Friday, types.DayOfWeekFriday},
+			{s.Saturday, types.DayOfWeekSaturday},
+		} {
+			if v.enabled {
+				days = append(days, string(v.day))
+			}
+		}
+		m["days_of_week"] = days
+	case *types.MonthlyByDayTaskScheduler:
+		m["frequency"] = scheduledTaskFrequencyMonthly
+		m["day_of_month"] = int(s.Day)
+		daily = &s.DailyTaskScheduler
+	default:
+		return nil, false
+	}
+
+	m["interval"] = int(daily.Interval)
+	m["minute"] = int(daily.Minute)
+	m["hour"] = int(daily.Hour)
+	m["start_time"] = formatTimePtr(daily.ActiveTime)
+	m["end_time"] = formatTimePtr(daily.ExpireTime)
+	return m, true
+}
+
+// expandScheduledTaskScheduler reads the run_at and recurrence settings of a
+// scheduled task and returns the appropriate task scheduler. Exactly one of
+// the two needs to be set.
+func expandScheduledTaskScheduler(d *schema.ResourceData) (types.BaseTaskScheduler, error) {
+	runAt := d.Get("run_at").(string)
+	recurrence := d.Get("recurrence").([]interface{})
+	switch {
+	case runAt != "" && len(recurrence) > 0:
+		return nil, fmt.Errorf("only one of run_at or recurrence can be set")
+	case runAt != "":
+		t, err := parseTimePtr(runAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run_at: %s", err)
+		}
+		return &types.OnceTaskScheduler{RunAt: t}, nil
+	case len(recurrence) > 0 && recurrence[0] != nil:
+		return expandRecurrentTaskScheduler(recurrence[0].(map[string]interface{}))
+	}
+	return nil, fmt.Errorf("one of run_at or recurrence must be set")
+}
+
+// flattenScheduledTaskScheduler saves the run_at or recurrence settings of a
+// scheduled task to the supplied ResourceData.
+func flattenScheduledTaskScheduler(d *schema.ResourceData, base types.BaseTaskScheduler) error {
+	if s, ok := base.(*types.OnceTaskScheduler); ok {
+		d.Set("run_at", formatTimePtr(s.RunAt))
+		return d.Set("recurrence", nil)
+	}
+	m, ok := flattenRecurrentTaskScheduler(base)
+	if !ok {
+		log.Printf("[DEBUG] %s: unsupported task scheduler type %T", d.Id(), base)
+		d.Set("run_at", "")
+		return d.Set("recurrence", nil)
+	}
+	d.Set("run_at", "")
+	return d.Set("recurrence", []interface{}{m})
+}
+
+// expandScheduledTaskAction reads the action settings of a scheduled task and
+// returns the appropriate MethodAction.
+func expandScheduledTaskAction(d *schema.ResourceData) (*types.MethodAction, error) {
+	action := d.Get("action").(string)
+	name := d.Get("snapshot_name").(string)
+	if action != scheduledTaskActionCreateSnapshot {
+		for _, k := range []string{"snapshot_name", "snapshot_description", "snapshot_memory", "snapshot_quiesce"} {
+			if _, ok := d.GetOk(k); ok {
+				return nil, fmt.Errorf("%s can only be set when action is %s", k, scheduledTaskActionCreateSnapshot)
+			}
+		}
+	}
+
+	obj := &types.MethodAction{
+		Name: scheduledTaskActionMethods[action],
+	}
+	if action == scheduledTaskActionCreateSnapshot {
+		if name == "" {
+			return nil, fmt.Errorf("snapshot_name is required when action is %s", scheduledTaskActionCreateSnapshot)
+		}
+		// The arguments are positional, and follow the arguments to
+		// CreateSnapshot_Task.
+		obj.Argument = []types.MethodActionArgument{
+			{Value: name},
+			{Value: d.Get("snapshot_description").(string)},
+			{Value: d.Get("snapshot_memory").(bool)},
+			{Value: d.Get("snapshot_quiesce").(bool)},
+		}
+	}
+	return obj, nil
+}
+
+// flattenScheduledTaskAction saves the action settings of a scheduled task to
+// the supplied ResourceData.
+func flattenScheduledTaskAction(d *schema.ResourceData, base types.BaseAction) error {
+	obj, ok := base.(*types.MethodAction)
+	if !ok {
+		log.Printf("[DEBUG] %s: unsupported scheduled task action type %T", d.Id(), base)
+		return d.Set("action", "")
+	}
+	action := ""
+	for k, v := range scheduledTaskActionMethods {
+		if v == obj.Name {
+			action = k
+		}
+	}
+	if action == "" {
+		log.Printf("[DEBUG] %s: unsupported scheduled task method %q", d.Id(), obj.Name)
+	}
+	d.Set("action", action)
+
+	snapshot := map[string]interface{}{
+		"snapshot_name":        "",
+		"snapshot_description": "",
+		"snapshot_memory":      false,
+		"snapshot_quiesce":     false,
+	}
+	if action == scheduledTaskActionCreateSnapshot {
+		for i, k := range []string{"snapshot_name", "snapshot_description", "snapshot_memory", "snapshot_quiesce"} {
+			if i < len(obj.Argument) && obj.Argument[i].Value != nil {
+				snapshot[k] = obj.Argument[i].Value
+			}
+		}
+	}
+	for k, v := range snapshot {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("error setting %s: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// expandScheduledTaskSpec reads the settings of a scheduled task and returns
+// the appropriate ScheduledTaskSpec.
+func expandScheduledTaskSpec(d *schema.ResourceData) (*types.ScheduledTaskSpec, error) {
+	scheduler, err := expandScheduledTaskScheduler(d)
+	if err != nil {
+		return nil, err
+	}
+	action, err := expandScheduledTaskAction(d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ScheduledTaskSpec{
+		Name:         d.Get("name").(string),
+		Description:  d.Get("description").(string),
+		Enabled:      d.Get("enabled").(bool),
+		Notification: d.Get("notification").(string),
+		Scheduler:    scheduler,
+		Action:       action,
+	}, nil
+}
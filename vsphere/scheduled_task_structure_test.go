@@ -0,0 +1,93 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type testExpandRecurrentTaskScheduler struct {
+	Name string
+
+	recurrence  map[string]interface{}
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testExpandRecurrentTaskScheduler) Test(t *testing.T) {
+	s, err := expandRecurrentTaskScheduler(tc.recurrence)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	actual, ok := flattenRecurrentTaskScheduler(s)
+	if !ok {
+		t.Fatalf("could not flatten scheduler %T", s)
+	}
+	if !reflect.DeepEqual(tc.recurrence, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.recurrence, actual)
+	}
+}
+
+func testScheduledTaskRecurrence(frequency string, days []interface{}, day int) map[string]interface{} {
+	return map[string]interface{}{
+		"frequency":    frequency,
+		"interval":     1,
+		"minute":       30,
+		"hour":         0,
+		"days_of_week": days,
+		"day_of_month": day,
+		"start_time":   "",
+		"end_time":     "2030-01-01T00:00:00Z",
+	}
+}
+
+func TestExpandRecurrentTaskScheduler(t *testing.T) {
+	daily := testScheduledTaskRecurrence("daily", []interface{}{}, 0)
+	daily["hour"] = 22
+	hourly := testScheduledTaskRecurrence("hourly", []interface{}{}, 0)
+	hourly["hour"] = 22
+	cases := []testExpandRecurrentTaskScheduler{
+		{
+			Name:       "hourly",
+			recurrence: testScheduledTaskRecurrence("hourly", []interface{}{}, 0),
+		},
+		{
+			Name:       "daily",
+			recurrence: daily,
+		},
+		{
+			Name:       "weekly",
+			recurrence: testScheduledTaskRecurrence("weekly", []interface{}{"monday", "friday"}, 0),
+		},
+		{
+			Name:       "monthly",
+			recurrence: testScheduledTaskRecurrence("monthly", []interface{}{}, 15),
+		},
+		{
+			Name:        "weekly without days",
+			recurrence:  testScheduledTaskRecurrence("weekly", []interface{}{}, 0),
+			expectedErr: regexp.MustCompile("days_of_week is required when frequency is weekly"),
+		},
+		{
+			Name:        "monthly without day",
+			recurrence:  testScheduledTaskRecurrence("monthly", []interface{}{}, 0),
+			expectedErr: regexp.MustCompile("day_of_month is required when frequency is monthly"),
+		},
+		{
+			Name:        "days on daily",
+			recurrence:  testScheduledTaskRecurrence("daily", []interface{}{"monday"}, 0),
+			expectedErr: regexp.MustCompile("days_of_week can only be set when frequency is weekly"),
+		},
+		{
+			Name:        "hour on hourly",
+			recurrence:  hourly,
+			expectedErr: regexp.MustCompile("hour cannot be set when frequency is hourly"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
@@ -0,0 +1,256 @@
+package vsphere
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// sessionKeepAlive is a soap.RoundTripper that sends a request in the
+// background whenever the session has been idle for the supplied interval,
+// preventing the session from timing out. The keep alive starts on login, or
+// when start is called for a session that was restored without a login, and
+// stops on logout. Failed requests are logged, but do not stop the keep alive.
+type sessionKeepAlive struct {
+	roundTripper soap.RoundTripper
+
+	// The idle time after which a keep alive request is sent. The keep alive is
+	// disabled if this is zero.
+	interval time.Duration
+
+	// mu protects timer, which is nil while the keep alive is stopped.
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// newSessionKeepAlive wraps a soap.RoundTripper in a sessionKeepAlive.
+func newSessionKeepAlive(rt soap.RoundTripper, interval time.Duration) *sessionKeepAlive {
+	return &sessionKeepAlive{
+		roundTripper: rt,
+		interval:     interval,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for sessionKeepAlive.
+func (k *sessionKeepAlive) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if err := k.roundTripper.RoundTrip(ctx, req, res); err != nil {
+		return err
+	}
+	switch req.(type) {
+	case *methods.LoginBody, *methods.LoginExtensionByCertificateBody:
+		k.start()
+	case *methods.LogoutBody:
+		k.stop()
+	default:
+		k.reset()
+	}
+	return nil
+}
+
+// start starts the keep alive, if it is enabled and not already running.
+func (k *sessionKeepAlive) start() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.interval <= 0 || k.timer != nil {
+		return
+	}
+	k.timer = time.AfterFunc(k.interval, k.keepAlive)
+}
+
+// reset restarts the idle time, if the keep alive is running.
+func (k *sessionKeepAlive) reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.timer != nil {
+		k.timer.Reset(k.interval)
+	}
+}
+
+// stop stops the keep alive.
+func (k *sessionKeepAlive) stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.timer != nil {
+		k.timer.Stop()
+		k.timer = nil
+	}
+}
+
+// keepAlive sends the keep alive request once the session has gone idle.
+func (k *sessionKeepAlive) keepAlive() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if _, err := methods.GetCurrentTime(ctx, k.roundTripper); err != nil {
+		log.Printf("[WARN] Session keep alive failed: %s", err)
+	}
+	k.reset()
+}
+
+// reloginRoundTripper is a soap.RoundTripper that logs in again when a call
+// fails because the session has expired, and then sends the failed call again
+// once. Calls that fail with NotAuthenticated are rejected before the server
+// acts on them, so it is safe to send any call again.
+type reloginRoundTripper struct {
+	roundTripper soap.RoundTripper
+
+	// The function that logs in again, as returned by passwordLogin or
+	// certificateLogin.
+	loginFunc func(context.Context, soap.RoundTripper) error
+
+	// mu serializes logins, and generation counts them, so that calls that fail
+	// at the same time only cause a single login.
+	mu         sync.Mutex
+	generation int
+}
+
+// newReloginRoundTripper wraps a soap.RoundTripper in a reloginRoundTripper
+// that logs in again with the supplied login function.
+func newReloginRoundTripper(rt soap.RoundTripper, login func(context.Context, soap.RoundTripper) error) soap.RoundTripper {
+	return &reloginRoundTripper{
+		roundTripper: rt,
+		loginFunc:    login,
+	}
+}
+
+// passwordLogin returns a login function for reloginRoundTripper that logs in
+// to the supplied session manager with a user name and password.
+func passwordLogin(sessionManager types.ManagedObjectReference, user *url.Userinfo) func(context.Context, soap.RoundTripper) error {
+	return func(ctx context.Context, rt soap.RoundTripper) error {
+		password, _ := user.Password()
+		req := types.Login{
+			This:     sessionManager,
+			UserName: user.Username(),
+			Password: password,
+		}
+		_, err := methods.Login(ctx, rt, &req)
+		return err
+	}
+}
+
+// certificateLogin returns a login function for reloginRoundTripper that logs
+// in to the supplied session manager as the extension with the supplied key,
+// using the client certificate set on the SOAP client.
+func certificateLogin(sessionManager types.ManagedObjectReference, key string) func(context.Context, soap.RoundTripper) error {
+	return func(ctx context.Context, rt soap.RoundTripper) error {
+		req := types.LoginExtensionByCertificate{
+			This:         sessionManager,
+			ExtensionKey: key,
+		}
+		_, err := methods.LoginExtensionByCertificate(ctx, rt, &req)
+		return err
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for reloginRoundTripper.
+func (r *reloginRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+
+	err := r.roundTripper.RoundTrip(ctx, req, res)
+	if err == nil || !isNotAuthenticatedError(err) {
+		return err
+	}
+	switch req.(type) {
+	case *methods.LoginBody, *methods.LoginExtensionByCertificateBody:
+		return err
+	}
+
+	log.Printf("[WARN] Session is no longer authenticated, logging in again before retrying %s", apiMethodName(req))
+	if lerr := r.login(ctx, generation); lerr != nil {
+		log.Printf("[ERROR] Error logging in again: %s", lerr)
+		return err
+	}
+	resetSOAPResponse(res)
+	return r.roundTripper.RoundTrip(ctx, req, res)
+}
+
+// login logs in again, unless another call has already done so since
+// generation was read.
+func (r *reloginRoundTripper) login(ctx context.Context, generation int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.generation != generation {
+		return nil
+	}
+	if err := r.loginFunc(ctx, r.roundTripper); err != nil {
+		return err
+	}
+	r.generation++
+	return nil
+}
+
+// isNotAuthenticatedError returns true if an error is a NotAuthenticated
+// fault, which is returned when a session has expired.
+func isNotAuthenticatedError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.NotAuthenticated); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultVimSessionPath returns the directory that sessions are saved in when
+// persist_session is set and vim_session_path is not. This is the same
+// directory that govc saves its sessions in.
+func defaultVimSessionPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".govmomi", "sessions")
+}
+
+// vimSessionFile returns the path of the file in dir that the session for the
+// supplied server URL and user is saved in. The file is named after a hash of
+// both, so that sessions for different servers and users are kept apart.
+func vimSessionFile(dir string, u *url.URL) string {
+	if dir == "" {
+		dir = defaultVimSessionPath()
+	}
+	key := u.User.Username() + "@" + u.Host
+	return filepath.Join(dir, fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+}
+
+// saveVimSession saves the session cookies of a SOAP client to file, so that
+// the session can be restored by loadVimSession in a later run. The cookies
+// can be used in place of the user's credentials, so the file is only
+// readable by the current user.
+func saveVimSession(c *soap.Client, file string) error {
+	b, err := json.Marshal(c.Jar.Cookies(c.URL()))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0600)
+}
+
+// loadVimSession loads session cookies saved by saveVimSession into a SOAP
+// client. false is returned if there is no saved session.
+func loadVimSession(c *soap.Client, file string) (bool, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var cookies []*http.Cookie
+	if err := json.Unmarshal(b, &cookies); err != nil {
+		return false, fmt.Errorf("error decoding saved session in %s: %s", file, err)
+	}
+	c.Jar.SetCookies(c.URL(), cookies)
+	return true, nil
+}
@@ -129,11 +129,15 @@ func getBoolPolicy(d *schema.ResourceData, key string) *types.BoolPolicy {
 }
 
 // setBoolPolicy sets a ResourceData field depending on if a BoolPolicy exists
-// or not. The field is not set if it's nil.
+// or not. The field is not set if it's nil, and is cleared if the policy is
+// inherited from a parent object.
 func setBoolPolicy(d *schema.ResourceData, key string, val *types.BoolPolicy) error {
 	if val == nil {
 		return nil
 	}
+	if val.Inherited {
+		return d.Set(key, nil)
+	}
 	if err := d.Set(key, val.Value); err != nil {
 		return err
 	}
@@ -154,6 +158,9 @@ func setBoolPolicyReverse(d *schema.ResourceData, key string, val *types.BoolPol
 	if val == nil {
 		return nil
 	}
+	if val.Inherited {
+		return d.Set(key, nil)
+	}
 	if err := d.Set(key, !*val.Value); err != nil {
 		return err
 	}
@@ -179,11 +186,15 @@ func getStringPolicy(d *schema.ResourceData, key string) *types.StringPolicy {
 }
 
 // setStringPolicy sets a ResourceData field depending on if a StringPolicy
-// exists or not. The field is not set if it's nil.
+// exists or not. The field is not set if it's nil, and is cleared if the
+// policy is inherited from a parent object.
 func setStringPolicy(d *schema.ResourceData, key string, val *types.StringPolicy) error {
 	if val == nil {
 		return nil
 	}
+	if val.Inherited {
+		return d.Set(key, nil)
+	}
 	if err := d.Set(key, val.Value); err != nil {
 		return err
 	}
@@ -230,11 +241,15 @@ func getLongPolicy(d *schema.ResourceData, key string) *types.LongPolicy {
 }
 
 // setLongPolicy sets a ResourceData field depending on if a LongPolicy
-// exists or not. The field is not set if it's nil.
+// exists or not. The field is not set if it's nil, and is cleared if the
+// policy is inherited from a parent object.
 func setLongPolicy(d *schema.ResourceData, key string, val *types.LongPolicy) error {
 	if val == nil {
 		return nil
 	}
+	if val.Inherited {
+		return d.Set(key, nil)
+	}
 	if err := d.Set(key, val.Value); err != nil {
 		return err
 	}
@@ -47,6 +47,28 @@ func taskFault(err error) (types.BaseMethodFault, bool) {
 	return nil, false
 }
 
+// isFileAlreadyExistsError checks an error to see if it's of the
+// FileAlreadyExists type.
+func isFileAlreadyExistsError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.FileAlreadyExists); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isInvalidNameError checks an error to see if it's the InvalidName fault that
+// OptionManager returns when querying an option that does not exist.
+func isInvalidNameError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.InvalidName); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // isManagedObjectNotFoundError checks an error to see if it's of the
 // ManagedObjectNotFound type.
 func isManagedObjectNotFoundError(err error) bool {
@@ -135,6 +157,18 @@ func renameObject(client *govmomi.Client, ref types.ManagedObjectReference, new
 	return t.Wait(tctx)
 }
 
+// serverDescription returns a description of the server that a client is
+// connected to, for use in error messages. Managed object IDs are only unique
+// within a single vCenter Server, so when vCenter Servers are in linked mode,
+// this shows which one an ID was looked up on.
+func serverDescription(c *govmomi.Client) string {
+	host := c.URL().Host
+	if uuid := c.ServiceContent.About.InstanceUuid; uuid != "" {
+		return fmt.Sprintf("%s (instance %s)", host, uuid)
+	}
+	return host
+}
+
 // validateVirtualCenter ensures that the client is connected to vCenter.
 func validateVirtualCenter(c *govmomi.Client) error {
 	if c.ServiceContent.About.ApiType != "VirtualCenter" {
@@ -0,0 +1,183 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	cdromTypeIso               = "iso"
+	cdromTypeClientPassthrough = "client_passthrough"
+	cdromTypeClientAtapi       = "client_atapi"
+	cdromTypeHostAtapi         = "host_atapi"
+)
+
+var cdromTypeAllowedValues = []string{
+	cdromTypeIso,
+	cdromTypeClientPassthrough,
+	cdromTypeClientAtapi,
+	cdromTypeHostAtapi,
+}
+
+// expandCdrom reads an entry of the cdrom list and returns a cdrom, checking
+// that the arguments needed by the backing type are set.
+func expandCdrom(m map[string]interface{}) (cdrom, error) {
+	cd := cdrom{
+		cdromType:      m["type"].(string),
+		datastore:      m["datastore"].(string),
+		path:           m["path"].(string),
+		deviceName:     m["device_name"].(string),
+		connected:      m["connected"].(bool),
+		startConnected: m["start_connected"].(bool),
+		busNumber:      m["controller_bus_number"].(int),
+		unitNumber:     m["unit_number"].(int),
+	}
+	if cd.cdromType == "" {
+		cd.cdromType = cdromTypeIso
+	}
+	switch cd.cdromType {
+	case cdromTypeIso:
+		if cd.datastore == "" {
+			return cd, errors.New("Datastore argument must be specified when attaching a cdrom image.")
+		}
+		if cd.path == "" {
+			return cd, errors.New("Path argument must be specified when attaching a cdrom image.")
+		}
+	case cdromTypeHostAtapi:
+		if cd.deviceName == "" {
+			return cd, fmt.Errorf("device_name must be specified for cdrom type %s", cdromTypeHostAtapi)
+		}
+	}
+	if cd.cdromType != cdromTypeIso && (cd.datastore != "" || cd.path != "") {
+		return cd, fmt.Errorf("datastore and path can only be specified for cdrom type %s", cdromTypeIso)
+	}
+	if cd.cdromType != cdromTypeHostAtapi && cd.deviceName != "" {
+		return cd, fmt.Errorf("device_name can only be specified for cdrom type %s", cdromTypeHostAtapi)
+	}
+	if cd.unitNumber >= 0 && cd.busNumber < 0 {
+		return cd, errors.New("unit_number can only be specified for a cdrom together with controller_bus_number")
+	}
+	return cd, nil
+}
+
+// cdromBacking returns the device backing for a cdrom. iso is the full
+// datastore path of the ISO file, and is only used for ISO backed cdroms.
+func cdromBacking(cd cdrom, iso string) types.BaseVirtualDeviceBackingInfo {
+	switch cd.cdromType {
+	case cdromTypeClientPassthrough:
+		return &types.VirtualCdromRemotePassthroughBackingInfo{
+			VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
+				UseAutoDetect: boolPtr(false),
+			},
+		}
+	case cdromTypeClientAtapi:
+		return &types.VirtualCdromRemoteAtapiBackingInfo{
+			VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
+				UseAutoDetect: boolPtr(false),
+			},
+		}
+	case cdromTypeHostAtapi:
+		return &types.VirtualCdromAtapiBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+				DeviceName:    cd.deviceName,
+				UseAutoDetect: boolPtr(false),
+			},
+		}
+	}
+	return &types.VirtualCdromIsoBackingInfo{
+		VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+			FileName: iso,
+		},
+	}
+}
+
+// flattenCdrom updates an entry of the cdrom list from the cdrom device it
+// was created as, setting its type from the device backing. For an ISO
+// backing, the datastore from prev is kept when its base name matches the
+// datastore in the ISO path. connected is only read when poweredOn is true.
+func flattenCdrom(device *types.VirtualCdrom, prev map[string]interface{}, poweredOn bool) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range prev {
+		m[k] = v
+	}
+	m["key"] = int(device.Key)
+	m["datastore"] = ""
+	m["path"] = ""
+	m["device_name"] = ""
+	switch b := device.Backing.(type) {
+	case *types.VirtualCdromIsoBackingInfo:
+		m["type"] = cdromTypeIso
+		var dp object.DatastorePath
+		if dp.FromString(b.FileName) {
+			m["path"] = dp.Path
+			m["datastore"] = dp.Datastore
+			if ds, ok := prev["datastore"].(string); ok && path.Base(ds) == dp.Datastore {
+				m["datastore"] = ds
+			}
+		}
+	case *types.VirtualCdromRemotePassthroughBackingInfo:
+		m["type"] = cdromTypeClientPassthrough
+	case *types.VirtualCdromRemoteAtapiBackingInfo:
+		m["type"] = cdromTypeClientAtapi
+	case *types.VirtualCdromAtapiBackingInfo:
+		m["type"] = cdromTypeHostAtapi
+		m["device_name"] = b.DeviceName
+	}
+	if device.Connectable != nil {
+		m["start_connected"] = device.Connectable.StartConnected
+		if poweredOn {
+			m["connected"] = device.Connectable.Connected
+		}
+	}
+	return m
+}
+
+// flattenCdromAddress saves the IDE bus number of the controller that a cdrom
+// is attached to in m, and its unit number, if they are set in m.
+func flattenCdromAddress(devices object.VirtualDeviceList, device *types.VirtualCdrom, m map[string]interface{}) {
+	c, ok := devices.FindByKey(device.ControllerKey).(*types.VirtualIDEController)
+	if !ok {
+		return
+	}
+	if v, ok := m["controller_bus_number"].(int); ok && v >= 0 {
+		m["controller_bus_number"] = int(c.BusNumber)
+	}
+	if v, ok := m["unit_number"].(int); ok && v >= 0 && device.UnitNumber != nil {
+		m["unit_number"] = int(*device.UnitNumber)
+	}
+}
+
+// buildCdromConnectionDeviceChange returns the device changes needed to bring
+// the connected and start_connected settings of the cdroms in the cdrom list
+// in line with their devices. These can be changed while the virtual machine
+// is powered on. Entries without a device key are skipped.
+func buildCdromConnectionDeviceChange(devices object.VirtualDeviceList, cdroms []interface{}) []types.BaseVirtualDeviceConfigSpec {
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for _, v := range cdroms {
+		m := v.(map[string]interface{})
+		key := int32(m["key"].(int))
+		if key == 0 {
+			continue
+		}
+		device, ok := devices.FindByKey(key).(*types.VirtualCdrom)
+		if !ok || device.Connectable == nil {
+			continue
+		}
+		connected := m["connected"].(bool)
+		startConnected := m["start_connected"].(bool)
+		if device.Connectable.Connected == connected && device.Connectable.StartConnected == startConnected {
+			continue
+		}
+		device.Connectable.Connected = connected
+		device.Connectable.StartConnected = startConnected
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    device,
+		})
+	}
+	return spec
+}
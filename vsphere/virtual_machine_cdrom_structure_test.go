@@ -0,0 +1,262 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testCdromMap(typ, datastore, path, deviceName string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":                  typ,
+		"datastore":             datastore,
+		"path":                  path,
+		"device_name":           deviceName,
+		"connected":             true,
+		"start_connected":       true,
+		"controller_bus_number": -1,
+		"unit_number":           -1,
+		"key":                   0,
+	}
+}
+
+type testExpandCdrom struct {
+	Name string
+
+	cdrom       map[string]interface{}
+	expected    cdrom
+	expectError bool
+}
+
+func (tc *testExpandCdrom) Test(t *testing.T) {
+	actual, err := expandCdrom(tc.cdrom)
+	if tc.expectError {
+		if err == nil {
+			t.Fatalf("expected error, got none")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpandCdrom(t *testing.T) {
+	cases := []testExpandCdrom{
+		{
+			Name:  "iso with default type",
+			cdrom: testCdromMap("", "datastore1", "iso/installer.iso", ""),
+			expected: cdrom{
+				cdromType:      cdromTypeIso,
+				datastore:      "datastore1",
+				path:           "iso/installer.iso",
+				connected:      true,
+				startConnected: true,
+				busNumber:      -1,
+				unitNumber:     -1,
+			},
+		},
+		{
+			Name:        "iso without path",
+			cdrom:       testCdromMap(cdromTypeIso, "datastore1", "", ""),
+			expectError: true,
+		},
+		{
+			Name:  "client passthrough",
+			cdrom: testCdromMap(cdromTypeClientPassthrough, "", "", ""),
+			expected: cdrom{
+				cdromType:      cdromTypeClientPassthrough,
+				connected:      true,
+				startConnected: true,
+				busNumber:      -1,
+				unitNumber:     -1,
+			},
+		},
+		{
+			Name:        "client passthrough with path",
+			cdrom:       testCdromMap(cdromTypeClientPassthrough, "datastore1", "iso/installer.iso", ""),
+			expectError: true,
+		},
+		{
+			Name:        "host atapi without device name",
+			cdrom:       testCdromMap(cdromTypeHostAtapi, "", "", ""),
+			expectError: true,
+		},
+		{
+			Name: "unit number without bus number",
+			cdrom: func() map[string]interface{} {
+				m := testCdromMap(cdromTypeClientPassthrough, "", "", "")
+				m["unit_number"] = 1
+				return m
+			}(),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func testCdromDevice(key int32, backing types.BaseVirtualDeviceBackingInfo, connected bool) *types.VirtualCdrom {
+	return &types.VirtualCdrom{
+		VirtualDevice: types.VirtualDevice{
+			Key:     key,
+			Backing: backing,
+			Connectable: &types.VirtualDeviceConnectInfo{
+				Connected:      connected,
+				StartConnected: true,
+			},
+		},
+	}
+}
+
+type testFlattenCdrom struct {
+	Name string
+
+	device    *types.VirtualCdrom
+	prev      map[string]interface{}
+	poweredOn bool
+	expected  map[string]interface{}
+}
+
+func (tc *testFlattenCdrom) Test(t *testing.T) {
+	actual := flattenCdrom(tc.device, tc.prev, tc.poweredOn)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestFlattenCdrom(t *testing.T) {
+	iso := &types.VirtualCdromIsoBackingInfo{
+		VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+			FileName: "[datastore1] iso/installer.iso",
+		},
+	}
+	cases := []testFlattenCdrom{
+		{
+			Name:      "iso with datastore path kept",
+			device:    testCdromDevice(3000, iso, true),
+			prev:      testCdromMap("", "storage/datastore1", "iso/installer.iso", ""),
+			poweredOn: true,
+			expected: map[string]interface{}{
+				"type":                  cdromTypeIso,
+				"datastore":             "storage/datastore1",
+				"path":                  "iso/installer.iso",
+				"device_name":           "",
+				"connected":             true,
+				"start_connected":       true,
+				"controller_bus_number": -1,
+				"unit_number":           -1,
+				"key":                   3000,
+			},
+		},
+		{
+			Name:      "disconnected client passthrough",
+			device:    testCdromDevice(3001, &types.VirtualCdromRemotePassthroughBackingInfo{}, false),
+			prev:      testCdromMap(cdromTypeClientPassthrough, "", "", ""),
+			poweredOn: true,
+			expected: map[string]interface{}{
+				"type":                  cdromTypeClientPassthrough,
+				"datastore":             "",
+				"path":                  "",
+				"device_name":           "",
+				"connected":             false,
+				"start_connected":       true,
+				"controller_bus_number": -1,
+				"unit_number":           -1,
+				"key":                   3001,
+			},
+		},
+		{
+			Name: "host atapi while powered off",
+			device: testCdromDevice(3002, &types.VirtualCdromAtapiBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName: "/dev/cdrom",
+				},
+			}, false),
+			prev:      testCdromMap(cdromTypeHostAtapi, "", "", "/dev/cdrom"),
+			poweredOn: false,
+			expected: map[string]interface{}{
+				"type":                  cdromTypeHostAtapi,
+				"datastore":             "",
+				"path":                  "",
+				"device_name":           "/dev/cdrom",
+				"connected":             true,
+				"start_connected":       true,
+				"controller_bus_number": -1,
+				"unit_number":           -1,
+				"key":                   3002,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestFlattenCdromAddress(t *testing.T) {
+	devices := object.VirtualDeviceList{
+		&types.VirtualIDEController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 200},
+				BusNumber:     0,
+			},
+		},
+		&types.VirtualIDEController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 201},
+				BusNumber:     1,
+			},
+		},
+	}
+	device := testCdromDevice(3000, &types.VirtualCdromRemotePassthroughBackingInfo{}, true)
+	unit := int32(1)
+	device.ControllerKey = 201
+	device.UnitNumber = &unit
+
+	m := testCdromMap(cdromTypeClientPassthrough, "", "", "")
+	flattenCdromAddress(devices, device, m)
+	if m["controller_bus_number"] != -1 || m["unit_number"] != -1 {
+		t.Fatalf("expected unset address to be kept, got %d:%d", m["controller_bus_number"], m["unit_number"])
+	}
+
+	m["controller_bus_number"] = 0
+	m["unit_number"] = 0
+	flattenCdromAddress(devices, device, m)
+	if m["controller_bus_number"] != 1 || m["unit_number"] != 1 {
+		t.Fatalf("expected address 1:1, got %d:%d", m["controller_bus_number"], m["unit_number"])
+	}
+}
+
+func TestBuildCdromConnectionDeviceChange(t *testing.T) {
+	devices := object.VirtualDeviceList{
+		testCdromDevice(3000, &types.VirtualCdromRemotePassthroughBackingInfo{}, true),
+		testCdromDevice(3001, &types.VirtualCdromRemotePassthroughBackingInfo{}, true),
+	}
+	disconnect := testCdromMap(cdromTypeClientPassthrough, "", "", "")
+	disconnect["key"] = 3000
+	disconnect["connected"] = false
+	unchanged := testCdromMap(cdromTypeClientPassthrough, "", "", "")
+	unchanged["key"] = 3001
+	unknown := testCdromMap(cdromTypeClientPassthrough, "", "", "")
+
+	spec := buildCdromConnectionDeviceChange(devices, []interface{}{disconnect, unchanged, unknown})
+	if len(spec) != 1 {
+		t.Fatalf("expected 1 device change, got %d", len(spec))
+	}
+	change := spec[0].GetVirtualDeviceConfigSpec()
+	if change.Operation != types.VirtualDeviceConfigSpecOperationEdit {
+		t.Fatalf("expected edit operation, got %s", change.Operation)
+	}
+	device := change.Device.GetVirtualDevice()
+	if device.Key != 3000 || device.Connectable.Connected {
+		t.Fatalf("expected device 3000 to be disconnected, got %#v", device)
+	}
+}
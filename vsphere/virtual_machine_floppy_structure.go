@@ -0,0 +1,249 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	floppyTypeImage  = "image"
+	floppyTypeClient = "client"
+)
+
+var floppyTypeAllowedValues = []string{
+	floppyTypeImage,
+	floppyTypeClient,
+}
+
+// floppy describes a floppy drive in the floppy list.
+type floppy struct {
+	floppyType     string
+	datastore      string
+	path           string
+	connected      bool
+	startConnected bool
+	key            int32
+}
+
+// expandFloppy reads an entry of the floppy list and returns a floppy,
+// checking that the arguments needed by the backing type are set.
+func expandFloppy(m map[string]interface{}) (floppy, error) {
+	fl := floppy{
+		floppyType:     m["type"].(string),
+		datastore:      m["datastore"].(string),
+		path:           m["path"].(string),
+		connected:      m["connected"].(bool),
+		startConnected: m["start_connected"].(bool),
+	}
+	switch fl.floppyType {
+	case floppyTypeImage:
+		if fl.datastore == "" || fl.path == "" {
+			return fl, errors.New("datastore and path must be specified for floppy type image")
+		}
+	case floppyTypeClient:
+		if fl.datastore != "" || fl.path != "" {
+			return fl, errors.New("datastore and path can only be specified for floppy type image")
+		}
+	}
+	return fl, nil
+}
+
+// floppyBacking returns the device backing for a floppy. For image backed
+// floppies, the image is checked to exist on the datastore first.
+func floppyBacking(client *govmomi.Client, dc *object.Datacenter, fl floppy) (types.BaseVirtualDeviceBackingInfo, error) {
+	if fl.floppyType == floppyTypeClient {
+		return &types.VirtualFloppyRemoteDeviceBackingInfo{
+			VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
+				UseAutoDetect: boolPtr(false),
+			},
+		}, nil
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, fl.datastore)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if _, err := ds.Stat(ctx, fl.path); err != nil {
+		return nil, fmt.Errorf("error checking floppy image: %s", err)
+	}
+	return &types.VirtualFloppyImageBackingInfo{
+		VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+			FileName: ds.Path(fl.path),
+		},
+	}, nil
+}
+
+// newFloppyDevice returns a new floppy device for a floppy, attached to the
+// SIO controller in devices.
+func newFloppyDevice(client *govmomi.Client, dc *object.Datacenter, devices object.VirtualDeviceList, fl floppy) (*types.VirtualFloppy, error) {
+	device, err := devices.CreateFloppy()
+	if err != nil {
+		return nil, err
+	}
+	backing, err := floppyBacking(client, dc, fl)
+	if err != nil {
+		return nil, err
+	}
+	device.Backing = backing
+	device.Connectable.Connected = fl.connected
+	device.Connectable.StartConnected = fl.startConnected
+	return device, nil
+}
+
+// findFloppyDeviceKey returns the key that a floppy device was given when it
+// was added to a virtual machine, by looking up the device in the same slot on
+// the same controller. 0 is returned if the device cannot be found.
+func findFloppyDeviceKey(devices object.VirtualDeviceList, device *types.VirtualFloppy) int32 {
+	for _, d := range devices.SelectByType((*types.VirtualFloppy)(nil)) {
+		vd := d.GetVirtualDevice()
+		if vd.ControllerKey == device.ControllerKey && vd.UnitNumber != nil && device.UnitNumber != nil && *vd.UnitNumber == *device.UnitNumber {
+			return vd.Key
+		}
+	}
+	return 0
+}
+
+// createFloppies adds the floppy devices in floppies to a virtual machine,
+// and saves the keys of the new devices.
+func createFloppies(client *govmomi.Client, vm *object.VirtualMachine, dc *object.Datacenter, floppies []floppy) error {
+	for i := range floppies {
+		devices, err := vm.Device(context.TODO())
+		if err != nil {
+			return err
+		}
+		device, err := newFloppyDevice(client, dc, devices, floppies[i])
+		if err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] add floppy: %#v", device)
+		if err := vm.AddDevice(context.TODO(), device); err != nil {
+			return err
+		}
+		if devices, err = vm.Device(context.TODO()); err != nil {
+			return err
+		}
+		floppies[i].key = findFloppyDeviceKey(devices, device)
+	}
+	return nil
+}
+
+// buildFloppyDeviceChange returns the device changes needed to bring the
+// floppy devices of a virtual machine in line with the floppy list. Entries
+// are matched to devices by the keys in the old list. The new devices that are
+// added are returned by their index in the list, so that their keys can be
+// saved once the virtual machine has been reconfigured. Floppies cannot be
+// added or removed while the virtual machine is powered on, so true is
+// returned when the changes need the virtual machine to be powered off.
+func buildFloppyDeviceChange(client *govmomi.Client, dc *object.Datacenter, devices object.VirtualDeviceList, oldList, newList []interface{}) ([]types.BaseVirtualDeviceConfigSpec, map[int]*types.VirtualFloppy, bool, error) {
+	var spec []types.BaseVirtualDeviceConfigSpec
+	added := make(map[int]*types.VirtualFloppy)
+	powerOff := false
+	count := len(oldList)
+	if len(newList) > count {
+		count = len(newList)
+	}
+	for i := 0; i < count; i++ {
+		var device *types.VirtualFloppy
+		var om map[string]interface{}
+		if i < len(oldList) {
+			om = oldList[i].(map[string]interface{})
+			device, _ = devices.FindByKey(int32(om["key"].(int))).(*types.VirtualFloppy)
+		}
+		if i >= len(newList) {
+			if device != nil {
+				spec = append(spec, &types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationRemove,
+					Device:    device,
+				})
+				powerOff = true
+			}
+			continue
+		}
+		fl, err := expandFloppy(newList[i].(map[string]interface{}))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if device == nil {
+			nd, err := newFloppyDevice(client, dc, devices, fl)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			devices = append(devices, nd)
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    nd,
+			})
+			added[i] = nd
+			powerOff = true
+			continue
+		}
+		changed := false
+		if om["type"] != fl.floppyType || om["datastore"] != fl.datastore || om["path"] != fl.path {
+			backing, err := floppyBacking(client, dc, fl)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			device.Backing = backing
+			changed = true
+		}
+		if device.Connectable != nil && (om["connected"] != fl.connected || om["start_connected"] != fl.startConnected) {
+			device.Connectable.Connected = fl.connected
+			device.Connectable.StartConnected = fl.startConnected
+			changed = true
+		}
+		if changed {
+			spec = append(spec, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationEdit,
+				Device:    device,
+			})
+		}
+	}
+	return spec, added, powerOff, nil
+}
+
+// flattenFloppy updates an entry of the floppy list from its floppy device.
+// An image backed floppy gets its path and datastore from the image file name,
+// while a client device backed floppy has neither. Like with cdroms, connected
+// is left as it was in prev while the virtual machine is powered off.
+func flattenFloppy(device *types.VirtualFloppy, prev map[string]interface{}, poweredOn bool) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range prev {
+		m[k] = v
+	}
+	m["key"] = int(device.Key)
+	m["datastore"] = ""
+	m["path"] = ""
+	switch b := device.Backing.(type) {
+	case *types.VirtualFloppyImageBackingInfo:
+		m["type"] = floppyTypeImage
+		var dp object.DatastorePath
+		if dp.FromString(b.FileName) {
+			m["path"] = dp.Path
+			m["datastore"] = dp.Datastore
+			if ds, ok := prev["datastore"].(string); ok && path.Base(ds) == dp.Datastore {
+				m["datastore"] = ds
+			}
+		}
+	case *types.VirtualFloppyRemoteDeviceBackingInfo:
+		m["type"] = floppyTypeClient
+	}
+	if device.Connectable != nil {
+		m["start_connected"] = device.Connectable.StartConnected
+		if poweredOn {
+			m["connected"] = device.Connectable.Connected
+		}
+	}
+	return m
+}
@@ -0,0 +1,216 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testFloppyMap(typ, datastore, path string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            typ,
+		"datastore":       datastore,
+		"path":            path,
+		"connected":       true,
+		"start_connected": true,
+		"key":             0,
+	}
+}
+
+type testExpandFloppy struct {
+	Name string
+
+	floppy      map[string]interface{}
+	expected    floppy
+	expectError bool
+}
+
+func (tc *testExpandFloppy) Test(t *testing.T) {
+	actual, err := expandFloppy(tc.floppy)
+	if tc.expectError {
+		if err == nil {
+			t.Fatalf("expected error, got none")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpandFloppy(t *testing.T) {
+	cases := []testExpandFloppy{
+		{
+			Name:   "image",
+			floppy: testFloppyMap(floppyTypeImage, "datastore1", "floppy/drivers.flp"),
+			expected: floppy{
+				floppyType:     floppyTypeImage,
+				datastore:      "datastore1",
+				path:           "floppy/drivers.flp",
+				connected:      true,
+				startConnected: true,
+			},
+		},
+		{
+			Name:        "image without path",
+			floppy:      testFloppyMap(floppyTypeImage, "datastore1", ""),
+			expectError: true,
+		},
+		{
+			Name:   "client",
+			floppy: testFloppyMap(floppyTypeClient, "", ""),
+			expected: floppy{
+				floppyType:     floppyTypeClient,
+				connected:      true,
+				startConnected: true,
+			},
+		},
+		{
+			Name:        "client with path",
+			floppy:      testFloppyMap(floppyTypeClient, "datastore1", "floppy/drivers.flp"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func testFloppyDevice(key int32, backing types.BaseVirtualDeviceBackingInfo, connected bool) *types.VirtualFloppy {
+	return &types.VirtualFloppy{
+		VirtualDevice: types.VirtualDevice{
+			Key:     key,
+			Backing: backing,
+			Connectable: &types.VirtualDeviceConnectInfo{
+				Connected:      connected,
+				StartConnected: true,
+			},
+		},
+	}
+}
+
+type testFlattenFloppy struct {
+	Name string
+
+	device    *types.VirtualFloppy
+	prev      map[string]interface{}
+	poweredOn bool
+	expected  map[string]interface{}
+}
+
+func (tc *testFlattenFloppy) Test(t *testing.T) {
+	actual := flattenFloppy(tc.device, tc.prev, tc.poweredOn)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestFlattenFloppy(t *testing.T) {
+	image := &types.VirtualFloppyImageBackingInfo{
+		VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+			FileName: "[datastore1] floppy/drivers.flp",
+		},
+	}
+	cases := []testFlattenFloppy{
+		{
+			Name:      "image with datastore path kept",
+			device:    testFloppyDevice(8000, image, true),
+			prev:      testFloppyMap(floppyTypeImage, "storage/datastore1", "floppy/drivers.flp"),
+			poweredOn: true,
+			expected: map[string]interface{}{
+				"type":            floppyTypeImage,
+				"datastore":       "storage/datastore1",
+				"path":            "floppy/drivers.flp",
+				"connected":       true,
+				"start_connected": true,
+				"key":             8000,
+			},
+		},
+		{
+			Name:      "disconnected client",
+			device:    testFloppyDevice(8001, &types.VirtualFloppyRemoteDeviceBackingInfo{}, false),
+			prev:      testFloppyMap(floppyTypeClient, "", ""),
+			poweredOn: true,
+			expected: map[string]interface{}{
+				"type":            floppyTypeClient,
+				"datastore":       "",
+				"path":            "",
+				"connected":       false,
+				"start_connected": true,
+				"key":             8001,
+			},
+		},
+		{
+			Name:      "client while powered off",
+			device:    testFloppyDevice(8001, &types.VirtualFloppyRemoteDeviceBackingInfo{}, false),
+			prev:      testFloppyMap(floppyTypeClient, "", ""),
+			poweredOn: false,
+			expected: map[string]interface{}{
+				"type":            floppyTypeClient,
+				"datastore":       "",
+				"path":            "",
+				"connected":       true,
+				"start_connected": true,
+				"key":             8001,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestBuildFloppyDeviceChange(t *testing.T) {
+	devices := object.VirtualDeviceList{
+		&types.VirtualSIOController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 400},
+			},
+		},
+		testFloppyDevice(8000, &types.VirtualFloppyRemoteDeviceBackingInfo{}, true),
+	}
+	old := testFloppyMap(floppyTypeClient, "", "")
+	old["key"] = 8000
+	disconnect := testFloppyMap(floppyTypeClient, "", "")
+	disconnect["key"] = 8000
+	disconnect["connected"] = false
+	add := testFloppyMap(floppyTypeClient, "", "")
+
+	spec, added, powerOff, err := buildFloppyDeviceChange(nil, nil, devices, []interface{}{old}, []interface{}{disconnect, add})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(spec) != 2 {
+		t.Fatalf("expected 2 device changes, got %d", len(spec))
+	}
+	if op := spec[0].GetVirtualDeviceConfigSpec().Operation; op != types.VirtualDeviceConfigSpecOperationEdit {
+		t.Fatalf("expected first change to be edit, got %s", op)
+	}
+	if op := spec[1].GetVirtualDeviceConfigSpec().Operation; op != types.VirtualDeviceConfigSpecOperationAdd {
+		t.Fatalf("expected second change to be add, got %s", op)
+	}
+	if _, ok := added[1]; !ok || len(added) != 1 {
+		t.Fatalf("expected new device at index 1, got %#v", added)
+	}
+	if !powerOff {
+		t.Fatalf("expected power off to be required")
+	}
+
+	spec, _, powerOff, err = buildFloppyDeviceChange(nil, nil, devices, []interface{}{old}, nil)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(spec) != 1 || spec[0].GetVirtualDeviceConfigSpec().Operation != types.VirtualDeviceConfigSpecOperationRemove {
+		t.Fatalf("expected 1 remove device change, got %#v", spec)
+	}
+	if !powerOff {
+		t.Fatalf("expected power off to be required")
+	}
+}
@@ -4,28 +4,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
 // virtualMachineFromUUID locates a virtualMachine by its UUID.
 func virtualMachineFromUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, error) {
+	return virtualMachineFromSearchUUID(client, uuid, false)
+}
+
+// virtualMachineFromInstanceUUID locates a virtualMachine by its vCenter
+// instance UUID.
+func virtualMachineFromInstanceUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, error) {
+	return virtualMachineFromSearchUUID(client, uuid, true)
+}
+
+// virtualMachineFromSearchUUID locates a virtualMachine through the search
+// index, by its BIOS UUID, or by its instance UUID if instance is true.
+func virtualMachineFromSearchUUID(client *govmomi.Client, uuid string, instance bool) (*object.VirtualMachine, error) {
 	search := object.NewSearchIndex(client.Client)
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	result, err := search.FindByUuid(ctx, nil, uuid, true, boolPtr(false))
+	result, err := search.FindByUuid(ctx, nil, uuid, true, &instance)
 	if err != nil {
 		return nil, err
 	}
 
 	if result == nil {
+		if instance {
+			return nil, fmt.Errorf("virtual machine with instance UUID %q not found", uuid)
+		}
 		return nil, fmt.Errorf("virtual machine with UUID %q not found", uuid)
 	}
 
@@ -69,6 +91,100 @@ func virtualMachineFromManagedObjectID(client *govmomi.Client, id string) (*obje
 	return vm.(*object.VirtualMachine), nil
 }
 
+// virtualMachineUUIDRegexp matches a UUID in the 8-4-4-4-12 hexadecimal form
+// that vSphere uses for the BIOS and instance UUIDs of virtual machines.
+var virtualMachineUUIDRegexp = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// validateVirtualMachineUUIDUnused checks that no virtual machine in the
+// inventory already has the supplied BIOS UUID, or instance UUID if instance
+// is true.
+func validateVirtualMachineUUIDUnused(client *govmomi.Client, uuid string, instance bool) error {
+	search := object.NewSearchIndex(client.Client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	result, err := search.FindByUuid(ctx, nil, uuid, true, &instance)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		kind := "BIOS UUID"
+		if instance {
+			kind = "instance UUID"
+		}
+		return fmt.Errorf("%s %q is already used by virtual machine %q", kind, uuid, result.Reference().Value)
+	}
+	return nil
+}
+
+// virtualMachineFromName locates a virtualMachine by its name or inventory
+// path in the supplied datacenter.
+func virtualMachineFromName(client *govmomi.Client, name string, dc *object.Datacenter) (*object.VirtualMachine, error) {
+	finder := find.NewFinder(client.Client, false)
+	finder.SetDatacenter(dc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.VirtualMachine(ctx, name)
+}
+
+// virtualMachineFromMacAddress locates a virtualMachine by the MAC address of
+// one of its network interfaces. The search is limited to the supplied
+// datacenter. An error is returned if no virtual machine, or more than one,
+// has the MAC address.
+func virtualMachineFromMacAddress(client *govmomi.Client, mac string, dc *object.Datacenter) (*object.VirtualMachine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	v, err := view.NewManager(client.Client).CreateContainerView(ctx, dc.Reference(), []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		dctx, dcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer dcancel()
+		if err := v.Destroy(dctx); err != nil {
+			log.Printf("[DEBUG] Error destroying container view: %s", err)
+		}
+	}()
+
+	var vms []mo.VirtualMachine
+	if err := v.Retrieve(ctx, []string{"VirtualMachine"}, []string{"config.hardware.device"}, &vms); err != nil {
+		return nil, err
+	}
+	refs := virtualMachinesWithMacAddress(vms, mac)
+	switch len(refs) {
+	case 0:
+		return nil, fmt.Errorf("virtual machine with MAC address %q not found", mac)
+	case 1:
+		return virtualMachineFromManagedObjectID(client, refs[0].Value)
+	}
+	var ids []string
+	for _, ref := range refs {
+		ids = append(ids, ref.Value)
+	}
+	return nil, fmt.Errorf("MAC address %q is used by more than one virtual machine: %s", mac, strings.Join(ids, ", "))
+}
+
+// virtualMachinesWithMacAddress returns the references of the virtual machines
+// in vms that have a network interface with the supplied MAC address. MAC
+// addresses are compared without regard to case.
+func virtualMachinesWithMacAddress(vms []mo.VirtualMachine, mac string) []types.ManagedObjectReference {
+	var refs []types.ManagedObjectReference
+	for _, vm := range vms {
+		if vm.Config == nil {
+			continue
+		}
+		for _, device := range vm.Config.Hardware.Device {
+			card, ok := device.(types.BaseVirtualEthernetCard)
+			if ok && strings.EqualFold(card.GetVirtualEthernetCard().MacAddress, mac) {
+				refs = append(refs, vm.Self)
+				break
+			}
+		}
+	}
+	return refs
+}
+
 // virtualMachineProperties is a convenience method that wraps fetching the
 // VirtualMachine MO from its higher-level object.
 func virtualMachineProperties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
@@ -145,3 +261,793 @@ func waitForGuestVMNet(client *govmomi.Client, vm *object.VirtualMachine) error
 
 	return nil
 }
+
+// virtualMachineGuestOsDescriptor returns the GuestOsDescriptor for the guest
+// ID and hardware version that the virtual machine is configured with. The
+// descriptor reports what features the guest supports, such as CPU and memory
+// hot add.
+func virtualMachineGuestOsDescriptor(client *govmomi.Client, props *mo.VirtualMachine) (*types.GuestOsDescriptor, error) {
+	if props.Config == nil {
+		return nil, errors.New("virtual machine configuration not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.QueryConfigOption{
+		This: props.EnvironmentBrowser,
+		Key:  props.Config.Version,
+	}
+	res, err := methods.QueryConfigOption(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Returnval == nil {
+		return nil, fmt.Errorf("no configuration options found for hardware version %q", props.Config.Version)
+	}
+	for _, desc := range res.Returnval.GuestOSDescriptor {
+		if desc.Id == props.Config.GuestId {
+			return &desc, nil
+		}
+	}
+	return nil, fmt.Errorf("guest ID %q is not supported on hardware version %q", props.Config.GuestId, props.Config.Version)
+}
+
+// validateHardwareVersion checks that the hardware version in version, ie:
+// vmx-09, is at least min. feature is used to describe what needs the
+// version in the returned error.
+func validateHardwareVersion(version string, min int, feature string) error {
+	v, err := parseHardwareVersion(version)
+	if err != nil {
+		return err
+	}
+	if v < min {
+		return fmt.Errorf("%s requires hardware version %d or higher, virtual machine is %s", feature, min, version)
+	}
+	return nil
+}
+
+// parseHardwareVersion returns the number of a hardware version key, ie: 13
+// for vmx-13.
+func parseHardwareVersion(version string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimPrefix(version, "vmx-"))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse hardware version %q: %s", version, err)
+	}
+	return v, nil
+}
+
+// hardwareVersionKey returns the hardware version key for a hardware version
+// number, ie: vmx-13 for 13.
+func hardwareVersionKey(version int) string {
+	return fmt.Sprintf("vmx-%02d", version)
+}
+
+// validateHardwareVersionUpgrade checks that the hardware version target is
+// not lower than the current hardware version of a virtual machine, and
+// returns true if the virtual machine needs to be upgraded to reach it.
+// Hardware versions can't be downgraded.
+func validateHardwareVersionUpgrade(current string, target int) (bool, error) {
+	v, err := parseHardwareVersion(current)
+	if err != nil {
+		return false, err
+	}
+	if target < v {
+		return false, fmt.Errorf("cannot downgrade hardware version from %s to %s", current, hardwareVersionKey(target))
+	}
+	return target > v, nil
+}
+
+// validateVirtualMachineFirmwareChange checks that the firmware of an existing
+// virtual machine can be changed from old to new. Guests installed with BIOS
+// firmware usually boot from MBR partitioned disks, which EFI firmware can't
+// boot from, and EFI guests usually boot from GPT disks, which BIOS firmware
+// can't boot from, so the change is refused unless allow is true.
+func validateVirtualMachineFirmwareChange(old, new string, allow bool) error {
+	if old == "" || old == new || allow {
+		return nil
+	}
+	return fmt.Errorf(
+		"changing firmware from %s to %s can leave the guest unable to boot, as guests installed with %s firmware usually "+
+			"boot from disks partitioned for it. The guest's disks and boot loader must be converted in the guest OS before "+
+			"the firmware is changed, and repartitioning the disks to recover a guest that no longer boots can result in data "+
+			"loss. Set allow_firmware_change to true to change the firmware, which requires the virtual machine to be powered off",
+		old, new, old,
+	)
+}
+
+// upgradeVirtualMachineHardware upgrades the hardware version of a powered off
+// virtual machine to version, and waits for the upgrade to complete.
+func upgradeVirtualMachineHardware(vm *object.VirtualMachine, version int) error {
+	log.Printf("[DEBUG] %s: Upgrading hardware version to %s", vm.InventoryPath, hardwareVersionKey(version))
+	req := &types.UpgradeVM_Task{
+		This:    vm.Reference(),
+		Version: hardwareVersionKey(version),
+	}
+	res, err := methods.UpgradeVM_Task(context.TODO(), vm.Client(), req)
+	if err != nil {
+		return fmt.Errorf("error upgrading hardware version: %s", err)
+	}
+	if _, err := waitForTask(object.NewTask(vm.Client(), res.Returnval)); err != nil {
+		return fmt.Errorf("error upgrading hardware version: %s", err)
+	}
+	return nil
+}
+
+// virtualMachineConnectionStateError returns an error that explains why a
+// virtual machine in connection state state can't be managed, or nil if the
+// virtual machine can be managed. Disconnected virtual machines are left
+// alone, as vCenter still has their configuration, and they become available
+// again when their host reconnects.
+func virtualMachineConnectionStateError(name string, state types.VirtualMachineConnectionState) error {
+	switch state {
+	case types.VirtualMachineConnectionStateOrphaned:
+		return fmt.Errorf(
+			"virtual machine %q is orphaned: vCenter still lists it, but the host that it is registered on no longer knows "+
+				"about it, which usually happens after a host crash or a failed migration. Set repair_connection_state to true "+
+				"to register it again from its configuration file, or remove it from the inventory",
+			name,
+		)
+	case types.VirtualMachineConnectionStateInvalid:
+		return fmt.Errorf(
+			"virtual machine %q is invalid: its configuration could not be loaded by its host. Set repair_connection_state to "+
+				"true to reload it from its configuration file",
+			name,
+		)
+	case types.VirtualMachineConnectionStateInaccessible:
+		return fmt.Errorf(
+			"virtual machine %q is inaccessible: one or more of its files can't be reached, which usually means that one "+
+				"of its datastores is unavailable. Restore access to its datastores and try again",
+			name,
+		)
+	}
+	return nil
+}
+
+// virtualMachineConnectionStateRepairable returns true if a virtual machine in
+// connection state state can be repaired by repairVirtualMachineConnectionState.
+func virtualMachineConnectionStateRepairable(state types.VirtualMachineConnectionState) bool {
+	return state == types.VirtualMachineConnectionStateOrphaned || state == types.VirtualMachineConnectionStateInvalid
+}
+
+// repairVirtualMachineConnectionState repairs an orphaned or invalid virtual
+// machine. An invalid virtual machine is reloaded from its configuration file.
+// An orphaned virtual machine is removed from the inventory and registered
+// again from its configuration file, in the same folder and resource pool,
+// which gives it a new managed object ID, so the re-registered virtual
+// machine is returned.
+func repairVirtualMachineConnectionState(vm *object.VirtualMachine, state types.VirtualMachineConnectionState) (*object.VirtualMachine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if state == types.VirtualMachineConnectionStateInvalid {
+		log.Printf("[DEBUG] %s: Reloading virtual machine from its configuration file", vm.InventoryPath)
+		if _, err := methods.Reload(ctx, vm.Client(), &types.Reload{This: vm.Reference()}); err != nil {
+			return nil, fmt.Errorf("error reloading virtual machine: %s", err)
+		}
+		return vm, nil
+	}
+
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"name", "parent", "resourcePool", "summary"}, &props); err != nil {
+		return nil, fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	path := props.Summary.Config.VmPathName
+	if path == "" {
+		return nil, errors.New("the path to the configuration file of the virtual machine is unknown")
+	}
+	var dsPath object.DatastorePath
+	if !dsPath.FromString(path) || dsPath.Datastore == "" || !strings.HasSuffix(dsPath.Path, ".vmx") {
+		return nil, fmt.Errorf("%q is not a valid path to a virtual machine configuration file", path)
+	}
+	if props.Parent == nil || props.ResourcePool == nil {
+		return nil, errors.New("the folder or resource pool of the virtual machine is unknown")
+	}
+	folder := object.NewFolder(vm.Client(), *props.Parent)
+	pool := object.NewResourcePool(vm.Client(), *props.ResourcePool)
+
+	// The virtual machine can't be found again once it has been unregistered,
+	// so make sure that the folder and resource pool it is registered into are
+	// still usable first.
+	var folderProps mo.Folder
+	if err := folder.Properties(ctx, folder.Reference(), []string{"childType"}, &folderProps); err != nil {
+		return nil, fmt.Errorf("error fetching properties of folder %q: %s", folder.Reference().Value, err)
+	}
+	var vmFolder bool
+	for _, t := range folderProps.ChildType {
+		if t == "VirtualMachine" {
+			vmFolder = true
+		}
+	}
+	if !vmFolder {
+		return nil, fmt.Errorf("folder %q can't contain virtual machines", folder.Reference().Value)
+	}
+	var poolProps mo.ResourcePool
+	if err := pool.Properties(ctx, pool.Reference(), []string{"name"}, &poolProps); err != nil {
+		return nil, fmt.Errorf("error fetching properties of resource pool %q: %s", pool.Reference().Value, err)
+	}
+
+	log.Printf("[DEBUG] %s: Registering orphaned virtual machine again from %s", vm.InventoryPath, path)
+	if err := vm.Unregister(ctx); err != nil {
+		return nil, fmt.Errorf("error unregistering orphaned virtual machine: %s", err)
+	}
+	task, err := folder.RegisterVM(ctx, path, props.Name, false, pool, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error registering virtual machine from %s: %s", path, err)
+	}
+	info, err := waitForTask(task)
+	if err != nil {
+		return nil, fmt.Errorf("error registering virtual machine from %s: %s", path, err)
+	}
+	newVM := object.NewVirtualMachine(vm.Client(), info.Result.(types.ManagedObjectReference))
+	newVM.InventoryPath = vm.InventoryPath
+	return newVM, nil
+}
+
+// virtualMachineToolsOutOfDate returns true if the VMware Tools version
+// status of the virtual machine indicates that Tools can be upgraded.
+func virtualMachineToolsOutOfDate(props *mo.VirtualMachine) bool {
+	if props.Guest == nil {
+		return false
+	}
+	switch types.VirtualMachineToolsVersionStatus(props.Guest.ToolsVersionStatus2) {
+	case types.VirtualMachineToolsVersionStatusGuestToolsNeedUpgrade,
+		types.VirtualMachineToolsVersionStatusGuestToolsSupportedOld,
+		types.VirtualMachineToolsVersionStatusGuestToolsTooOld:
+		return true
+	}
+	return false
+}
+
+// upgradeVirtualMachineTools upgrades VMware Tools on a powered on virtual
+// machine if they are out of date, and waits for the upgrade to complete.
+// Nothing is done if Tools are current or are not managed by vSphere.
+func upgradeVirtualMachineTools(vm *object.VirtualMachine) error {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		return errors.New("virtual machine must be powered on to upgrade VMware Tools")
+	}
+	if !virtualMachineToolsOutOfDate(props) {
+		if props.Guest == nil {
+			log.Printf("[DEBUG] %s: VMware Tools do not need an upgrade (no guest information)", vm.InventoryPath)
+		} else {
+			log.Printf("[DEBUG] %s: VMware Tools do not need an upgrade (status: %s)", vm.InventoryPath, props.Guest.ToolsVersionStatus2)
+		}
+		return nil
+	}
+
+	log.Printf("[DEBUG] %s: Upgrading VMware Tools", vm.InventoryPath)
+	task, err := vm.UpgradeTools(context.TODO(), "")
+	if err != nil {
+		return fmt.Errorf("error upgrading VMware Tools: %s", err)
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error upgrading VMware Tools: %s", err)
+	}
+	return nil
+}
+
+// shutdownVirtualMachine shuts down a powered on virtual machine. If VMware
+// Tools is running in the guest, a guest shutdown is requested, and retried up
+// to retries more times if the guest is not powered off within timeout of each
+// request. If the guest never shuts down, or Tools is not running, the virtual
+// machine is powered off when force is true, and an error is returned
+// otherwise.
+func shutdownVirtualMachine(vm *object.VirtualMachine, timeout time.Duration, retries int, force bool) error {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	if props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff {
+		return nil
+	}
+
+	toolsRunning := props.Guest != nil && props.Guest.ToolsRunningStatus == string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)
+	if toolsRunning && props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		attempts := retries + 1
+		for i := 1; i <= attempts; i++ {
+			log.Printf("[DEBUG] %s: Requesting guest shutdown (attempt %d of %d)", vm.InventoryPath, i, attempts)
+			if err := virtualMachineShutdownGuest(vm, timeout); err != nil {
+				if err != context.DeadlineExceeded {
+					return err
+				}
+				log.Printf("[DEBUG] %s: Guest did not shut down within %s", vm.InventoryPath, timeout)
+				continue
+			}
+			log.Printf("[DEBUG] %s: Guest shutdown complete", vm.InventoryPath)
+			return nil
+		}
+		if !force {
+			return fmt.Errorf("guest did not shut down after %d attempt(s) of %s each, and forced power off is disabled", attempts, timeout)
+		}
+		log.Printf("[DEBUG] %s: Guest never shut down, forcing power off", vm.InventoryPath)
+	} else {
+		if !force {
+			return errors.New("VMware Tools is not running in the guest, so it cannot be shut down gracefully, and forced power off is disabled")
+		}
+		log.Printf("[DEBUG] %s: VMware Tools is not running, forcing power off", vm.InventoryPath)
+	}
+
+	task, err := vm.PowerOff(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error powering off virtual machine: %s", err)
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error powering off virtual machine: %s", err)
+	}
+	return nil
+}
+
+// powerOnVirtualMachine powers on a virtual machine, or resumes it if it is
+// suspended. On vCenter, the virtual machine is powered on through its
+// datacenter with the DRS automation level overridden to fully automated, so
+// that a DRS cluster in manual or partially automated mode places and starts
+// the virtual machine right away, rather than leaving a recommendation
+// waiting to be applied.
+func powerOnVirtualMachine(client *govmomi.Client, dc *object.Datacenter, vm *object.VirtualMachine) error {
+	if !client.IsVC() {
+		task, err := vm.PowerOn(context.TODO())
+		if err != nil {
+			return fmt.Errorf("error powering on virtual machine: %s", err)
+		}
+		if _, err := waitForTask(task); err != nil {
+			return fmt.Errorf("error powering on virtual machine: %s", err)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.PowerOnMultiVM_Task{
+		This: dc.Reference(),
+		Vm:   []types.ManagedObjectReference{vm.Reference()},
+		Option: []types.BaseOptionValue{
+			&types.OptionValue{
+				Key:   "OverrideAutomationLevel",
+				Value: string(types.DrsBehaviorFullyAutomated),
+			},
+		},
+	}
+	res, err := methods.PowerOnMultiVM_Task(ctx, client, req)
+	if err != nil {
+		return fmt.Errorf("error powering on virtual machine: %s", err)
+	}
+	info, err := waitForTask(object.NewTask(client.Client, res.Returnval))
+	if err != nil {
+		return fmt.Errorf("error powering on virtual machine: %s", err)
+	}
+	result, ok := info.Result.(types.ClusterPowerOnVmResult)
+	if !ok {
+		return nil
+	}
+	if len(result.NotAttempted) > 0 {
+		return fmt.Errorf("virtual machine was not powered on: %s", localizedMethodFaultMessage(result.NotAttempted[0].Fault))
+	}
+	// The power on task of each virtual machine is separate from the task of
+	// the request, so they need to be waited on as well.
+	for _, a := range result.Attempted {
+		if a.Task == nil {
+			continue
+		}
+		if _, err := waitForTask(object.NewTask(client.Client, *a.Task)); err != nil {
+			return fmt.Errorf("error powering on virtual machine: %s", err)
+		}
+	}
+	return nil
+}
+
+// suspendVirtualMachine suspends a powered on virtual machine.
+func suspendVirtualMachine(vm *object.VirtualMachine) error {
+	task, err := vm.Suspend(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error suspending virtual machine: %s", err)
+	}
+	if _, err := waitForTask(task); err != nil {
+		return fmt.Errorf("error suspending virtual machine: %s", err)
+	}
+	return nil
+}
+
+// virtualMachineShutdownGuest requests a guest shutdown and waits up to
+// timeout for the virtual machine to power off. context.DeadlineExceeded is
+// returned if the guest is still running when the timeout is reached.
+func virtualMachineShutdownGuest(vm *object.VirtualMachine, timeout time.Duration) error {
+	sctx, scancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer scancel()
+	if err := vm.ShutdownGuest(sctx); err != nil {
+		return fmt.Errorf("error requesting guest shutdown: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := vm.WaitForPowerState(ctx, types.VirtualMachinePowerStatePoweredOff); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return context.DeadlineExceeded
+		}
+		return fmt.Errorf("error waiting for guest shutdown: %s", err)
+	}
+	return nil
+}
+
+// guestIPWait describes a network interface that needs to get an IP address
+// of a specific family before the virtual machine is considered ready.
+type guestIPWait struct {
+	// The index of the interface in the network_interface list.
+	index int
+
+	// The device key of the network interface.
+	key int32
+
+	// The address family to wait for, either ipv4 or ipv6.
+	family string
+}
+
+// waitForGuestVMIPs waits for every network interface in waits to have an IP
+// address of the requested family, as reported by VMware Tools. Link-local
+// IPv6 addresses are not counted. The returned error names the first
+// interface that did not get an address before timeout.
+func waitForGuestVMIPs(client *govmomi.Client, vm *object.VirtualMachine, waits []guestIPWait, timeout time.Duration) error {
+	pending := make(map[int]guestIPWait)
+	for _, w := range waits {
+		pending[w.index] = w
+	}
+
+	p := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := property.Wait(ctx, p, vm.Reference(), []string{"guest.net"}, func(pc []types.PropertyChange) bool {
+		for _, c := range pc {
+			if c.Op != types.PropertyChangeOpAssign {
+				continue
+			}
+			nics, ok := c.Val.(types.ArrayOfGuestNicInfo)
+			if !ok {
+				continue
+			}
+			for i, w := range pending {
+				if guestNicHasIP(nics.GuestNicInfo, w.key, w.family) {
+					log.Printf("[DEBUG] %s: network interface %d has an %s address", vm.InventoryPath, w.index, w.family)
+					delete(pending, i)
+				}
+			}
+		}
+		return len(pending) == 0
+	})
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			var first *guestIPWait
+			for _, w := range pending {
+				if first == nil || w.index < first.index {
+					w := w
+					first = &w
+				}
+			}
+			return fmt.Errorf("timeout waiting for an %s address on network interface %d (device key %d)", first.family, first.index, first.key)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// guestNicHasIP returns true if the guest NIC with the supplied device key has
+// a non-link-local IP address of the supplied family.
+func guestNicHasIP(nics []types.GuestNicInfo, key int32, family string) bool {
+	for _, n := range nics {
+		if n.DeviceConfigId != key || n.IpConfig == nil {
+			continue
+		}
+		for _, addr := range n.IpConfig.IpAddress {
+			ip := net.ParseIP(addr.IpAddress)
+			switch {
+			case ip == nil:
+				continue
+			case family == "ipv4" && ip.To4() != nil:
+				return true
+			case family == "ipv6" && ip.To4() == nil && !ip.IsLinkLocalUnicast():
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// vmwareReservedOUIs are the OUIs that vSphere uses for generated MAC
+// addresses, and cannot be used for static MAC addresses. The VMware OUI
+// (00:50:56) is handled separately, as part of its range is allowed for static
+// addresses.
+var vmwareReservedOUIs = []string{
+	"00:05:69",
+	"00:0c:29",
+	"00:1c:14",
+}
+
+// validateStaticMacAddress checks to make sure that a MAC address can be used
+// as a static MAC address on a virtual machine network interface. The address
+// needs to be a unicast address, and either be in the range of static MAC
+// addresses that VMware allows (00:50:56:00:00:00 to 00:50:56:3f:ff:ff), or
+// use an OUI that is not reserved by VMware.
+func validateStaticMacAddress(mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) != 6 {
+		return fmt.Errorf("invalid MAC address %q", mac)
+	}
+	if hw[0]&0x01 != 0 {
+		return fmt.Errorf("MAC address %q is not a unicast address", mac)
+	}
+	oui := hw[:3].String()
+	if oui == "00:50:56" {
+		if hw[3] > 0x3f {
+			return fmt.Errorf("MAC address %q is outside of the allowed VMware static range (00:50:56:00:00:00 to 00:50:56:3f:ff:ff)", mac)
+		}
+		return nil
+	}
+	for _, r := range vmwareReservedOUIs {
+		if oui == r {
+			return fmt.Errorf("MAC address %q uses an OUI reserved by VMware for generated addresses", mac)
+		}
+	}
+	return nil
+}
+
+// orderNetworkInterfaces orders the network interfaces read from a virtual
+// machine to follow the order of the network interfaces in prev, matching them
+// by PCI slot number first, and then by MAC address. Interfaces that could not
+// be matched fill the remaining positions in the order they were read.
+func orderNetworkInterfaces(prev []interface{}, current []map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(current))
+	used := make([]bool, len(current))
+	for _, k := range []string{"pci_slot_number", "mac_address"} {
+		for i, v := range prev {
+			p, ok := v.(map[string]interface{})
+			if !ok || i >= len(current) || result[i] != nil {
+				continue
+			}
+			for j, c := range current {
+				if !used[j] && networkInterfaceValuesMatch(p[k], c[k]) {
+					result[i] = c
+					used[j] = true
+					break
+				}
+			}
+		}
+	}
+	j := 0
+	for i := range result {
+		if result[i] != nil {
+			continue
+		}
+		for used[j] {
+			j++
+		}
+		result[i] = current[j]
+		used[j] = true
+	}
+	return result
+}
+
+// networkInterfaceValuesMatch compares a network interface value from state
+// with one read from the virtual machine. Values read from the virtual machine
+// can be of a different type than the ones in state, so they are compared as
+// strings, ignoring case. An empty or zero value in state never matches.
+func networkInterfaceValuesMatch(prev, current interface{}) bool {
+	p := fmt.Sprint(prev)
+	if prev == nil || p == "" || p == "0" {
+		return false
+	}
+	return strings.EqualFold(p, fmt.Sprint(current))
+}
+
+// validateVirtualEthernetCardReservation checks to make sure that a bandwidth
+// reservation can be applied to a network interface with the supplied
+// backing. Reservations need the network interface to be on a distributed
+// port group, on a DVS that is running network I/O control version 3. If the
+// port group is assigned to a network resource pool, the reservation cannot be
+// higher than the reservation quota of the pool.
+func validateVirtualEthernetCardReservation(client *govmomi.Client, backing types.BaseVirtualDeviceBackingInfo, reservation int64, index int) error {
+	if reservation == 0 {
+		return nil
+	}
+	port, ok := backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+	if !ok {
+		return fmt.Errorf("network_interface.%d: bandwidth_reservation requires the network interface to be on a distributed port group", index)
+	}
+	dvs, err := dvsFromUUID(client, port.Port.SwitchUuid)
+	if err != nil {
+		return fmt.Errorf("error locating DVS for network_interface.%d: %s", index, err)
+	}
+	dvsProps, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("error fetching DVS properties: %s", err)
+	}
+	info := dvsProps.Config.GetDVSConfigInfo()
+	if info.NetworkResourceManagementEnabled == nil || !*info.NetworkResourceManagementEnabled || info.NetworkResourceControlVersion != string(types.DistributedVirtualSwitchNetworkResourceControlVersionVersion3) {
+		return fmt.Errorf("network_interface.%d: bandwidth_reservation requires network I/O control version 3 to be enabled on DVS %q", index, dvsProps.Name)
+	}
+
+	pg, err := dvPortgroupFromMOID(client, port.Port.PortgroupKey)
+	if err != nil {
+		return fmt.Errorf("error locating port group for network_interface.%d: %s", index, err)
+	}
+	pgProps, err := dvPortgroupProperties(pg)
+	if err != nil {
+		return fmt.Errorf("error fetching port group properties: %s", err)
+	}
+	if pgProps.Config.VmVnicNetworkResourcePoolKey == "" || pgProps.Config.VmVnicNetworkResourcePoolKey == "-1" {
+		return nil
+	}
+	for _, pool := range info.VmVnicNetworkResourcePool {
+		if pool.Key != pgProps.Config.VmVnicNetworkResourcePoolKey || pool.AllocationInfo == nil {
+			continue
+		}
+		if reservation > pool.AllocationInfo.ReservationQuota {
+			return fmt.Errorf("network_interface.%d: bandwidth_reservation (%d) is higher than the reservation quota of network resource pool %q (%d)", index, reservation, pool.Name, pool.AllocationInfo.ReservationQuota)
+		}
+	}
+	return nil
+}
+
+// validateVirtualEthernetCardUpt checks to make sure that UPT (DirectPath I/O
+// Gen2) can be enabled on a network interface. The network interface needs to
+// be a vmxnet3 adapter, and if it is on a distributed port group, the DVS
+// needs to support DirectPath I/O Gen2. If host is not nil, the host needs to
+// support it as well.
+func validateVirtualEthernetCardUpt(client *govmomi.Client, host *types.ManagedObjectReference, device types.BaseVirtualDevice, index int) error {
+	if _, ok := device.(*types.VirtualVmxnet3); !ok {
+		return fmt.Errorf("network_interface.%d: upt_compatibility_enabled is only supported on vmxnet3 network interfaces", index)
+	}
+
+	if port, ok := device.GetVirtualDevice().Backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo); ok {
+		dvs, err := dvsFromUUID(client, port.Port.SwitchUuid)
+		if err != nil {
+			return fmt.Errorf("error locating DVS for network_interface.%d: %s", index, err)
+		}
+		props, err := dvsProperties(dvs)
+		if err != nil {
+			return fmt.Errorf("error fetching DVS properties: %s", err)
+		}
+		if props.Capability.FeaturesSupported == nil || !props.Capability.FeaturesSupported.GetDVSFeatureCapability().VmDirectPathGen2Supported {
+			return fmt.Errorf("network_interface.%d: DVS %q does not support DirectPath I/O Gen2, required for upt_compatibility_enabled", index, props.Name)
+		}
+	}
+
+	if host == nil {
+		return nil
+	}
+	hs, err := hostSystemFromID(client, host.Value)
+	if err != nil {
+		return err
+	}
+	props, err := hostSystemProperties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.Capability == nil || props.Capability.VmDirectPathGen2Supported == nil || !*props.Capability.VmDirectPathGen2Supported {
+		return fmt.Errorf("network_interface.%d: host %q does not support DirectPath I/O Gen2, required for upt_compatibility_enabled", index, props.Name)
+	}
+	return nil
+}
+
+// virtualEthernetCardUptActive returns true if DirectPath I/O Gen2 is active
+// on the network interface with the supplied device key, according to the
+// virtual machine's runtime information.
+func virtualEthernetCardUptActive(runtime types.VirtualMachineRuntimeInfo, key int32) bool {
+	for _, info := range runtime.Device {
+		if info.Key != key {
+			continue
+		}
+		if state, ok := info.RuntimeState.(*types.VirtualMachineDeviceRuntimeInfoVirtualEthernetCardRuntimeState); ok {
+			return state.VmDirectPathGen2Active
+		}
+	}
+	return false
+}
+
+// virtualEthernetCardPortKey returns the key of the DVS port that the network
+// interface is connected to. On early binding portgroups, the port is
+// allocated when the network interface is added, so the key is always set. On
+// ephemeral portgroups, the port only exists while the network interface is
+// connected. An empty key is returned for network interfaces that are not on
+// a DVS port.
+func virtualEthernetCardPortKey(nic types.BaseVirtualEthernetCard) string {
+	if backing, ok := nic.GetVirtualEthernetCard().Backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo); ok {
+		return backing.Port.PortKey
+	}
+	return ""
+}
+
+// recommendDiskDatastore asks Storage DRS for a datastore in the datastore
+// cluster with the supplied managed object ID to place a new disk of the
+// supplied size, in GB, on. The disk is not created.
+func recommendDiskDatastore(client *govmomi.Client, vm *object.VirtualMachine, podID string, size int64, diskType string) (*object.Datastore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching VM devices: %s", err)
+	}
+	controller, err := devices.FindDiskController("")
+	if err != nil {
+		return nil, fmt.Errorf("error finding disk controller for placement: %s", err)
+	}
+	disk := devices.CreateDisk(controller, types.ManagedObjectReference{}, "")
+	disk.CapacityInKB = size * 1024 * 1024
+	backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	backing.Datastore = nil
+	if diskType == "eager_zeroed" || diskType == "lazy" {
+		backing.ThinProvisioned = types.NewBool(false)
+	}
+
+	pod := types.ManagedObjectReference{Type: "StoragePod", Value: podID}
+	vmr := vm.Reference()
+	sps := types.StoragePlacementSpec{
+		Type: string(types.StoragePlacementSpecPlacementTypeReconfigure),
+		Vm:   &vmr,
+		PodSelectionSpec: types.StorageDrsPodSelectionSpec{
+			InitialVmConfig: []types.VmPodConfigForPlacement{
+				{
+					StoragePod: pod,
+					Disk: []types.PodDiskLocator{
+						{
+							DiskId:          disk.Key,
+							DiskBackingInfo: disk.Backing,
+						},
+					},
+				},
+			},
+		},
+		ConfigSpec: &types.VirtualMachineConfigSpec{
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+					FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+					Device:        disk,
+				},
+			},
+		},
+	}
+	ds, err := findDatastore(client, sps)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Storage DRS recommendation for disk: %s", err)
+	}
+	return ds, nil
+}
+
+// virtualMachineClientDevices returns the connected devices in devices that
+// are backed by a device on a remote client, ie: a client cdrom, floppy, or
+// USB device. These devices block vMotion while they are connected.
+func virtualMachineClientDevices(devices object.VirtualDeviceList) object.VirtualDeviceList {
+	return devices.Select(func(device types.BaseVirtualDevice) bool {
+		d := device.GetVirtualDevice()
+		if d.Connectable == nil || !d.Connectable.Connected {
+			return false
+		}
+		_, ok := d.Backing.(types.BaseVirtualDeviceRemoteDeviceBackingInfo)
+		return ok
+	})
+}
+
+// setVirtualMachineDevicesConnected connects or disconnects the supplied
+// devices of a virtual machine.
+func setVirtualMachineDevicesConnected(vm *object.VirtualMachine, devices object.VirtualDeviceList, connected bool) error {
+	if len(devices) < 1 {
+		return nil
+	}
+	var spec []types.BaseVirtualDeviceConfigSpec
+	for _, device := range devices {
+		device.GetVirtualDevice().Connectable.Connected = connected
+		spec = append(spec, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    device,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: spec})
+	if err != nil {
+		return err
+	}
+	_, err = waitForTask(task)
+	return err
+}
@@ -0,0 +1,515 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testValidateHardwareVersion struct {
+	Name string
+
+	version     string
+	min         int
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testValidateHardwareVersion) Test(t *testing.T) {
+	err := validateHardwareVersion(tc.version, tc.min, "test feature")
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+	}
+}
+
+func TestValidateHardwareVersion(t *testing.T) {
+	cases := []testValidateHardwareVersion{
+		{
+			Name:    "equal",
+			version: "vmx-09",
+			min:     9,
+		},
+		{
+			Name:    "higher",
+			version: "vmx-13",
+			min:     9,
+		},
+		{
+			Name:        "lower",
+			version:     "vmx-08",
+			min:         9,
+			expectedErr: regexp.MustCompile("test feature requires hardware version 9 or higher, virtual machine is vmx-08"),
+		},
+		{
+			Name:        "bad version",
+			version:     "vmx-abc",
+			min:         9,
+			expectedErr: regexp.MustCompile("could not parse hardware version"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestValidateHardwareVersionUpgrade(t *testing.T) {
+	cases := []struct {
+		Name            string
+		current         string
+		target          int
+		expectedUpgrade bool
+		expectedErr     *regexp.Regexp
+	}{
+		{
+			Name:    "same",
+			current: "vmx-11",
+			target:  11,
+		},
+		{
+			Name:            "upgrade",
+			current:         "vmx-09",
+			target:          13,
+			expectedUpgrade: true,
+		},
+		{
+			Name:        "downgrade",
+			current:     "vmx-13",
+			target:      9,
+			expectedErr: regexp.MustCompile("cannot downgrade hardware version from vmx-13 to vmx-09"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			upgrade, err := validateHardwareVersionUpgrade(tc.current, tc.target)
+			if err != nil && tc.expectedErr == nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expectedErr != nil {
+				testMatchError(t, err, tc.expectedErr)
+			}
+			if upgrade != tc.expectedUpgrade {
+				t.Fatalf("expected upgrade to be %t, got %t", tc.expectedUpgrade, upgrade)
+			}
+		})
+	}
+}
+
+func TestValidateVirtualMachineFirmwareChange(t *testing.T) {
+	cases := []struct {
+		Name        string
+		old         string
+		new         string
+		allow       bool
+		expectedErr *regexp.Regexp
+	}{
+		{
+			Name: "unchanged",
+			old:  "bios",
+			new:  "bios",
+		},
+		{
+			Name: "not yet known",
+			old:  "",
+			new:  "efi",
+		},
+		{
+			Name:        "changed",
+			old:         "bios",
+			new:         "efi",
+			expectedErr: regexp.MustCompile("changing firmware from bios to efi can leave the guest unable to boot"),
+		},
+		{
+			Name:  "changed with override",
+			old:   "bios",
+			new:   "efi",
+			allow: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := validateVirtualMachineFirmwareChange(tc.old, tc.new, tc.allow)
+			if err != nil && tc.expectedErr == nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expectedErr != nil {
+				testMatchError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestVirtualMachineConnectionStateError(t *testing.T) {
+	cases := []struct {
+		Name        string
+		state       types.VirtualMachineConnectionState
+		repairable  bool
+		expectedErr *regexp.Regexp
+	}{
+		{
+			Name:  "connected",
+			state: types.VirtualMachineConnectionStateConnected,
+		},
+		{
+			Name:  "disconnected",
+			state: types.VirtualMachineConnectionStateDisconnected,
+		},
+		{
+			Name:        "orphaned",
+			state:       types.VirtualMachineConnectionStateOrphaned,
+			repairable:  true,
+			expectedErr: regexp.MustCompile(`virtual machine "/dc/vm/foo" is orphaned: .* Set repair_connection_state to true`),
+		},
+		{
+			Name:        "invalid",
+			state:       types.VirtualMachineConnectionStateInvalid,
+			repairable:  true,
+			expectedErr: regexp.MustCompile(`virtual machine "/dc/vm/foo" is invalid: .* Set repair_connection_state to true`),
+		},
+		{
+			Name:        "inaccessible",
+			state:       types.VirtualMachineConnectionStateInaccessible,
+			expectedErr: regexp.MustCompile(`virtual machine "/dc/vm/foo" is inaccessible: .* Restore access to its datastores`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := virtualMachineConnectionStateError("/dc/vm/foo", tc.state)
+			if err != nil && tc.expectedErr == nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expectedErr != nil {
+				testMatchError(t, err, tc.expectedErr)
+			}
+			if repairable := virtualMachineConnectionStateRepairable(tc.state); repairable != tc.repairable {
+				t.Fatalf("expected repairable to be %t, got %t", tc.repairable, repairable)
+			}
+		})
+	}
+}
+
+func TestGuestNicHasIP(t *testing.T) {
+	nics := []types.GuestNicInfo{
+		{
+			DeviceConfigId: 4000,
+			IpConfig: &types.NetIpConfigInfo{
+				IpAddress: []types.NetIpConfigInfoIpAddress{
+					{IpAddress: "fe80::250:56ff:fe01:203"},
+				},
+			},
+		},
+		{
+			DeviceConfigId: 4001,
+			IpConfig: &types.NetIpConfigInfo{
+				IpAddress: []types.NetIpConfigInfoIpAddress{
+					{IpAddress: "10.0.0.10"},
+					{IpAddress: "2001:db8::10"},
+				},
+			},
+		},
+		{
+			DeviceConfigId: 4002,
+		},
+	}
+	cases := []struct {
+		Name     string
+		key      int32
+		family   string
+		expected bool
+	}{
+		{Name: "link-local ipv6 only", key: 4000, family: "ipv6", expected: false},
+		{Name: "no ipv4 address", key: 4000, family: "ipv4", expected: false},
+		{Name: "ipv4 address", key: 4001, family: "ipv4", expected: true},
+		{Name: "ipv6 address", key: 4001, family: "ipv6", expected: true},
+		{Name: "no ip config", key: 4002, family: "ipv4", expected: false},
+		{Name: "missing nic", key: 4003, family: "ipv4", expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := guestNicHasIP(nics, tc.key, tc.family); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+type testValidateStaticMacAddress struct {
+	Name string
+
+	mac         string
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testValidateStaticMacAddress) Test(t *testing.T) {
+	err := validateStaticMacAddress(tc.mac)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+	}
+}
+
+func TestValidateStaticMacAddress(t *testing.T) {
+	cases := []testValidateStaticMacAddress{
+		{
+			Name: "vmware static range",
+			mac:  "00:50:56:3f:ff:ff",
+		},
+		{
+			Name: "custom oui",
+			mac:  "02:00:5e:10:00:01",
+		},
+		{
+			Name:        "vmware generated range",
+			mac:         "00:50:56:40:00:01",
+			expectedErr: regexp.MustCompile("outside of the allowed VMware static range"),
+		},
+		{
+			Name:        "reserved oui",
+			mac:         "00:0c:29:01:02:03",
+			expectedErr: regexp.MustCompile("OUI reserved by VMware"),
+		},
+		{
+			Name:        "multicast",
+			mac:         "01:00:5e:00:00:01",
+			expectedErr: regexp.MustCompile("not a unicast address"),
+		},
+		{
+			Name:        "invalid",
+			mac:         "00:50:56:zz:00:01",
+			expectedErr: regexp.MustCompile("invalid MAC address"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestOrderNetworkInterfaces(t *testing.T) {
+	current := []map[string]interface{}{
+		{"mac_address": "00:50:56:00:00:01", "pci_slot_number": int32(192)},
+		{"mac_address": "00:50:56:00:00:02", "pci_slot_number": int32(256)},
+		{"mac_address": "00:50:56:00:00:0a", "pci_slot_number": int32(224)},
+	}
+	cases := []struct {
+		Name     string
+		prev     []interface{}
+		expected []string
+	}{
+		{
+			Name:     "no previous state",
+			expected: []string{"00:50:56:00:00:01", "00:50:56:00:00:02", "00:50:56:00:00:0a"},
+		},
+		{
+			Name: "reordered",
+			prev: []interface{}{
+				map[string]interface{}{"mac_address": "00:50:56:00:00:0a"},
+				map[string]interface{}{"mac_address": "00:50:56:00:00:01"},
+				map[string]interface{}{"mac_address": "00:50:56:00:00:02"},
+			},
+			expected: []string{"00:50:56:00:00:0a", "00:50:56:00:00:01", "00:50:56:00:00:02"},
+		},
+		{
+			Name: "partially known",
+			prev: []interface{}{
+				map[string]interface{}{"mac_address": ""},
+				map[string]interface{}{"mac_address": "00:50:56:00:00:01"},
+			},
+			expected: []string{"00:50:56:00:00:02", "00:50:56:00:00:01", "00:50:56:00:00:0a"},
+		},
+		{
+			Name: "case insensitive",
+			prev: []interface{}{
+				map[string]interface{}{"mac_address": "00:50:56:00:00:0A"},
+			},
+			expected: []string{"00:50:56:00:00:0a", "00:50:56:00:00:01", "00:50:56:00:00:02"},
+		},
+		{
+			Name: "inserted by pci slot",
+			prev: []interface{}{
+				map[string]interface{}{"mac_address": "00:50:56:00:00:01", "pci_slot_number": 192},
+				map[string]interface{}{"mac_address": "00:50:56:00:00:02", "pci_slot_number": 224},
+				map[string]interface{}{"mac_address": "", "pci_slot_number": 256},
+			},
+			expected: []string{"00:50:56:00:00:01", "00:50:56:00:00:0a", "00:50:56:00:00:02"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result := orderNetworkInterfaces(tc.prev, current)
+			var actual []string
+			for _, v := range result {
+				actual = append(actual, v["mac_address"].(string))
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVirtualMachineClientDevices(t *testing.T) {
+	connected := func(c bool) *types.VirtualDeviceConnectInfo {
+		return &types.VirtualDeviceConnectInfo{Connected: c}
+	}
+	devices := object.VirtualDeviceList{
+		&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{
+			Key:         3000,
+			Connectable: connected(true),
+			Backing:     &types.VirtualCdromRemotePassthroughBackingInfo{},
+		}},
+		&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{
+			Key:         3001,
+			Connectable: connected(true),
+			Backing:     &types.VirtualCdromIsoBackingInfo{},
+		}},
+		&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{
+			Key:         3002,
+			Connectable: connected(false),
+			Backing:     &types.VirtualCdromRemoteAtapiBackingInfo{},
+		}},
+		&types.VirtualFloppy{VirtualDevice: types.VirtualDevice{
+			Key:         8000,
+			Connectable: connected(true),
+			Backing:     &types.VirtualFloppyRemoteDeviceBackingInfo{},
+		}},
+		&types.VirtualUSB{VirtualDevice: types.VirtualDevice{
+			Key:         4000,
+			Connectable: connected(true),
+			Backing:     &types.VirtualUSBRemoteClientBackingInfo{},
+		}},
+		&types.VirtualUSB{VirtualDevice: types.VirtualDevice{
+			Key:     4001,
+			Backing: &types.VirtualUSBRemoteClientBackingInfo{},
+		}},
+	}
+
+	var keys []int32
+	for _, device := range virtualMachineClientDevices(devices) {
+		keys = append(keys, device.GetVirtualDevice().Key)
+	}
+	expected := []int32{3000, 8000, 4000}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Fatalf("expected client devices %v, got %v", expected, keys)
+	}
+}
+
+func TestVirtualMachinesWithMacAddress(t *testing.T) {
+	vmWithMacs := func(id string, macs ...string) mo.VirtualMachine {
+		vm := mo.VirtualMachine{
+			Config: &types.VirtualMachineConfigInfo{},
+		}
+		vm.Self = types.ManagedObjectReference{Type: "VirtualMachine", Value: id}
+		for _, mac := range macs {
+			vm.Config.Hardware.Device = append(vm.Config.Hardware.Device, &types.VirtualVmxnet3{
+				VirtualVmxnet: types.VirtualVmxnet{
+					VirtualEthernetCard: types.VirtualEthernetCard{MacAddress: mac},
+				},
+			})
+		}
+		return vm
+	}
+	orphaned := mo.VirtualMachine{}
+	orphaned.Self = types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-4"}
+	vms := []mo.VirtualMachine{
+		vmWithMacs("vm-1", "00:50:56:01:02:03"),
+		vmWithMacs("vm-2", "00:50:56:0a:0b:0c", "00:50:56:04:05:06"),
+		vmWithMacs("vm-3", "00:50:56:04:05:06"),
+		orphaned,
+	}
+
+	cases := []struct {
+		name     string
+		mac      string
+		expected []string
+	}{
+		{
+			name:     "single match",
+			mac:      "00:50:56:01:02:03",
+			expected: []string{"vm-1"},
+		},
+		{
+			name:     "case insensitive",
+			mac:      "00:50:56:0A:0B:0C",
+			expected: []string{"vm-2"},
+		},
+		{
+			name:     "multiple matches",
+			mac:      "00:50:56:04:05:06",
+			expected: []string{"vm-2", "vm-3"},
+		},
+		{
+			name: "no match",
+			mac:  "00:50:56:ff:ff:ff",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			for _, ref := range virtualMachinesWithMacAddress(vms, tc.mac) {
+				actual = append(actual, ref.Value)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVirtualEthernetCardPortKey(t *testing.T) {
+	cases := []struct {
+		name     string
+		backing  types.BaseVirtualDeviceBackingInfo
+		expected string
+	}{
+		{
+			name: "early binding port",
+			backing: &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+				Port: types.DistributedVirtualSwitchPortConnection{
+					PortgroupKey: "dvportgroup-21",
+					PortKey:      "12",
+				},
+			},
+			expected: "12",
+		},
+		{
+			name: "disconnected ephemeral port",
+			backing: &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+				Port: types.DistributedVirtualSwitchPortConnection{
+					PortgroupKey: "dvportgroup-22",
+				},
+			},
+			expected: "",
+		},
+		{
+			name: "standard port group",
+			backing: &types.VirtualEthernetCardNetworkBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName: "VM Network",
+				},
+			},
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nic := &types.VirtualVmxnet3{
+				VirtualVmxnet: types.VirtualVmxnet{
+					VirtualEthernetCard: types.VirtualEthernetCard{
+						VirtualDevice: types.VirtualDevice{Backing: tc.backing},
+					},
+				},
+			}
+			if actual := virtualEthernetCardPortKey(nic); actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package vsphere
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// defaultVirtualMachineQuestionAnswers are the answers given to known
+// questions that block power operations, keyed by message ID. These can be
+// overridden with the question_answers attribute.
+var defaultVirtualMachineQuestionAnswers = map[string]string{
+	// "This virtual machine might have been moved or copied." Answering that
+	// it was moved keeps the UUID, the same as setting uuid.action to keep.
+	"msg.uuid.altered": "button.uuid.movedTheVM",
+	// The guest has locked the CD-ROM door and is asked whether to disconnect
+	// it anyway.
+	"msg.cdromdisconnect.locked": "button.yes",
+}
+
+// virtualMachineQuestionAnswers merges the supplied answers over the default
+// question answers.
+func virtualMachineQuestionAnswers(answers map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for k, v := range defaultVirtualMachineQuestionAnswers {
+		result[k] = v
+	}
+	for k, v := range answers {
+		result[k] = v.(string)
+	}
+	return result
+}
+
+// virtualMachineQuestionAnswer returns the key of the choice to answer a
+// pending question with. The answer for a question is looked up by the IDs of
+// the messages in the question, and can match the key, label, or summary of a
+// choice. Matching is case insensitive and ignores the underscores used to
+// mark keyboard shortcuts in labels, ie: "I _moved It". false is returned if
+// there is no answer for the question, or the answer does not match a choice.
+func virtualMachineQuestionAnswer(q *types.VirtualMachineQuestionInfo, answers map[string]string) (string, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Replace(s, "_", "", -1))
+	}
+	for _, m := range q.Message {
+		answer, ok := answers[m.Id]
+		if !ok {
+			continue
+		}
+		for _, c := range q.Choice.ChoiceInfo {
+			e := c.GetElementDescription()
+			for _, s := range []string{e.Key, e.Label, e.Summary} {
+				if normalize(s) == normalize(answer) {
+					return e.Key, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// runWithVirtualMachineQuestionAnswers runs f, while answering any questions
+// that come up on the virtual machine with the supplied answers. Questions
+// without an answer are logged, and need to be answered outside of Terraform
+// for f to complete.
+func runWithVirtualMachineQuestionAnswers(client *govmomi.Client, vm *object.VirtualMachine, answers map[string]string, f func() error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		answered := make(map[string]struct{})
+		p := client.PropertyCollector()
+		err := property.Wait(ctx, p, vm.Reference(), []string{"runtime.question"}, func(pc []types.PropertyChange) bool {
+			for _, c := range pc {
+				if c.Op != types.PropertyChangeOpAssign {
+					continue
+				}
+				var q types.VirtualMachineQuestionInfo
+				switch v := c.Val.(type) {
+				case types.VirtualMachineQuestionInfo:
+					q = v
+				case *types.VirtualMachineQuestionInfo:
+					q = *v
+				default:
+					continue
+				}
+				if _, ok := answered[q.Id]; ok {
+					continue
+				}
+				key, ok := virtualMachineQuestionAnswer(&q, answers)
+				if !ok {
+					log.Printf("[WARN] %s: No answer configured for pending question %q (messages: %s), it needs to be answered manually", vm.InventoryPath, q.Text, virtualMachineQuestionMessageIDs(&q))
+					answered[q.Id] = struct{}{}
+					continue
+				}
+				log.Printf("[DEBUG] %s: Answering question %q with choice %q", vm.InventoryPath, q.Text, key)
+				if err := vm.Answer(ctx, q.Id, key); err != nil {
+					log.Printf("[WARN] %s: Error answering question %q: %s", vm.InventoryPath, q.Text, err)
+				}
+				answered[q.Id] = struct{}{}
+			}
+			return false
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[WARN] %s: Error watching for pending questions: %s", vm.InventoryPath, err)
+		}
+	}()
+
+	err := f()
+	cancel()
+	<-done
+	return err
+}
+
+// virtualMachineQuestionMessageIDs returns the message IDs of a question as a
+// comma separated list, for use in log messages.
+func virtualMachineQuestionMessageIDs(q *types.VirtualMachineQuestionInfo) string {
+	var ids []string
+	for _, m := range q.Message {
+		ids = append(ids, m.Id)
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,109 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testVirtualMachineQuestion(messageID string) *types.VirtualMachineQuestionInfo {
+	choice := func(key, label, summary string) types.BaseElementDescription {
+		return &types.ElementDescription{
+			Key: key,
+			Description: types.Description{
+				Label:   label,
+				Summary: summary,
+			},
+		}
+	}
+	return &types.VirtualMachineQuestionInfo{
+		Id:   "_vmx1",
+		Text: "This virtual machine might have been moved or copied.",
+		Choice: types.ChoiceOption{
+			ChoiceInfo: []types.BaseElementDescription{
+				choice("0", "button.uuid.cancel", "Cancel"),
+				choice("1", "button.uuid.movedTheVM", "I _moved It"),
+				choice("2", "button.uuid.copiedTheVM", "I _copied It"),
+			},
+		},
+		Message: []types.VirtualMachineMessage{
+			{Id: messageID},
+		},
+	}
+}
+
+type testVirtualMachineQuestionAnswer struct {
+	Name string
+
+	question    *types.VirtualMachineQuestionInfo
+	answers     map[string]string
+	expectedKey string
+	expectedOK  bool
+}
+
+func (tc *testVirtualMachineQuestionAnswer) Test(t *testing.T) {
+	key, ok := virtualMachineQuestionAnswer(tc.question, tc.answers)
+	if key != tc.expectedKey || ok != tc.expectedOK {
+		t.Fatalf("expected (%q, %t), got (%q, %t)", tc.expectedKey, tc.expectedOK, key, ok)
+	}
+}
+
+func TestVirtualMachineQuestionAnswer(t *testing.T) {
+	cases := []testVirtualMachineQuestionAnswer{
+		{
+			Name:        "default answer by label",
+			question:    testVirtualMachineQuestion("msg.uuid.altered"),
+			answers:     virtualMachineQuestionAnswers(nil),
+			expectedKey: "1",
+			expectedOK:  true,
+		},
+		{
+			Name:        "answer by summary",
+			question:    testVirtualMachineQuestion("msg.uuid.altered"),
+			answers:     map[string]string{"msg.uuid.altered": "I copied it"},
+			expectedKey: "2",
+			expectedOK:  true,
+		},
+		{
+			Name:        "answer by key",
+			question:    testVirtualMachineQuestion("msg.uuid.altered"),
+			answers:     map[string]string{"msg.uuid.altered": "0"},
+			expectedKey: "0",
+			expectedOK:  true,
+		},
+		{
+			Name:        "no answer for question",
+			question:    testVirtualMachineQuestion("msg.unknown"),
+			answers:     virtualMachineQuestionAnswers(nil),
+			expectedKey: "",
+			expectedOK:  false,
+		},
+		{
+			Name:        "answer does not match a choice",
+			question:    testVirtualMachineQuestion("msg.uuid.altered"),
+			answers:     map[string]string{"msg.uuid.altered": "maybe"},
+			expectedKey: "",
+			expectedOK:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestVirtualMachineQuestionAnswers(t *testing.T) {
+	expected := map[string]string{
+		"msg.uuid.altered":           "button.uuid.copiedTheVM",
+		"msg.cdromdisconnect.locked": "button.yes",
+		"msg.other":                  "button.no",
+	}
+	actual := virtualMachineQuestionAnswers(map[string]interface{}{
+		"msg.uuid.altered": "button.uuid.copiedTheVM",
+		"msg.other":        "button.no",
+	})
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
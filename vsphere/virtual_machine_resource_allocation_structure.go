@@ -0,0 +1,255 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineResourceAllocationTypes are the kinds of resources that can
+// have their allocation controlled on a virtual machine. These are used as
+// the prefixes for the resource allocation keys.
+var virtualMachineResourceAllocationTypes = []string{
+	"cpu",
+	"memory",
+}
+
+// virtualMachineResourceAllocationUnits are the units that the reservation
+// and limit values for each resource allocation type are expressed in.
+var virtualMachineResourceAllocationUnits = map[string]string{
+	"cpu":    "MHz",
+	"memory": "MB",
+}
+
+// schemaVirtualMachineResourceAllocation returns the respective schema keys
+// for the CPU and memory allocation settings of a virtual machine.
+func schemaVirtualMachineResourceAllocation() map[string]*schema.Schema {
+	s := make(map[string]*schema.Schema)
+	shareLevelFmt := "The allocation level for %s resources. Can be one of high, low, normal, or custom."
+	shareCountFmt := "The amount of shares to allocate to %s for a custom share level."
+	limitFmt := "The maximum amount of %s resources the virtual machine can use, in %s. -1 means unlimited."
+	reservationFmt := "The amount of %s resources that are guaranteed to the virtual machine, in %s."
+
+	for _, t := range virtualMachineResourceAllocationTypes {
+		shareLevelKey := fmt.Sprintf("%s_share_level", t)
+		shareCountKey := fmt.Sprintf("%s_share_count", t)
+		limitKey := fmt.Sprintf("%s_limit", t)
+		reservationKey := fmt.Sprintf("%s_reservation", t)
+		units := virtualMachineResourceAllocationUnits[t]
+
+		s[shareLevelKey] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.SharesLevelNormal),
+			Description:  fmt.Sprintf(shareLevelFmt, t),
+			ValidateFunc: validation.StringInSlice(sharesLevelAllowedValues, false),
+		}
+		s[shareCountKey] = &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  fmt.Sprintf(shareCountFmt, t),
+			ValidateFunc: validation.IntAtLeast(0),
+		}
+		s[limitKey] = &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      -1,
+			Description:  fmt.Sprintf(limitFmt, t, units),
+			ValidateFunc: validation.IntAtLeast(-1),
+		}
+		s[reservationKey] = &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  fmt.Sprintf(reservationFmt, t, units),
+			ValidateFunc: validation.IntAtLeast(0),
+		}
+	}
+
+	return s
+}
+
+// expandVirtualMachineResourceAllocation reads the resource allocation keys
+// for the resource type supplied by key and returns an appropriate
+// types.ResourceAllocationInfo reference.
+func expandVirtualMachineResourceAllocation(d *schema.ResourceData, key string) *types.ResourceAllocationInfo {
+	shareLevelKey := fmt.Sprintf("%s_share_level", key)
+	shareCountKey := fmt.Sprintf("%s_share_count", key)
+	limitKey := fmt.Sprintf("%s_limit", key)
+	reservationKey := fmt.Sprintf("%s_reservation", key)
+
+	obj := &types.ResourceAllocationInfo{
+		Limit:       getInt64Ptr(d, limitKey),
+		Reservation: getInt64Ptr(d, reservationKey),
+	}
+	shares := &types.SharesInfo{
+		Level: types.SharesLevel(d.Get(shareLevelKey).(string)),
+	}
+	// The share count is only honored for a custom share level, and is
+	// calculated by vSphere otherwise.
+	if shares.Level == types.SharesLevelCustom {
+		shares.Shares = int32(d.Get(shareCountKey).(int))
+	}
+	obj.Shares = shares
+	return obj
+}
+
+// flattenVirtualMachineResourceAllocation reads various fields from a
+// ResourceAllocationInfo and sets appropriate keys in the supplied
+// ResourceData.
+func flattenVirtualMachineResourceAllocation(d *schema.ResourceData, base types.BaseResourceAllocationInfo, key string) error {
+	if base == nil {
+		return nil
+	}
+	obj := base.GetResourceAllocationInfo()
+	shareLevelKey := fmt.Sprintf("%s_share_level", key)
+	shareCountKey := fmt.Sprintf("%s_share_count", key)
+	limitKey := fmt.Sprintf("%s_limit", key)
+	reservationKey := fmt.Sprintf("%s_reservation", key)
+
+	if err := setInt64Ptr(d, limitKey, obj.Limit); err != nil {
+		return err
+	}
+	if err := setInt64Ptr(d, reservationKey, obj.Reservation); err != nil {
+		return err
+	}
+	if obj.Shares != nil {
+		d.Set(shareLevelKey, obj.Shares.Level)
+		d.Set(shareCountKey, obj.Shares.Shares)
+	}
+	return nil
+}
+
+// validateVirtualMachineResourceAllocation checks the resource allocation
+// settings for the virtual machine. Custom share levels need a share count,
+// reservations cannot be higher than the limit, and the memory reservation
+// cannot be higher than the amount of memory configured for the virtual
+// machine. A reservation equal to the configured memory is a full reservation
+// and is allowed.
+func validateVirtualMachineResourceAllocation(d *schema.ResourceData) error {
+	for _, t := range virtualMachineResourceAllocationTypes {
+		shareLevelKey := fmt.Sprintf("%s_share_level", t)
+		shareCountKey := fmt.Sprintf("%s_share_count", t)
+		limitKey := fmt.Sprintf("%s_limit", t)
+		reservationKey := fmt.Sprintf("%s_reservation", t)
+
+		if d.Get(shareLevelKey).(string) == string(types.SharesLevelCustom) {
+			if _, ok := d.GetOk(shareCountKey); !ok {
+				return fmt.Errorf("%s is required when %s is custom", shareCountKey, shareLevelKey)
+			}
+		}
+		limit := d.Get(limitKey).(int)
+		reservation := d.Get(reservationKey).(int)
+		if limit != -1 && reservation > limit {
+			return fmt.Errorf("%s (%d) cannot be higher than %s (%d)", reservationKey, reservation, limitKey, limit)
+		}
+	}
+
+	if reservation, memory := d.Get("memory_reservation").(int), d.Get("memory").(int); reservation > memory {
+		return fmt.Errorf("memory_reservation (%d) cannot be higher than memory (%d)", reservation, memory)
+	}
+	return nil
+}
+
+// schemaVirtualEthernetCardResourceAllocation returns the schema keys for the
+// bandwidth allocation settings of a virtual machine network interface. These
+// map to network I/O control settings on the virtual NIC.
+func schemaVirtualEthernetCardResourceAllocation() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bandwidth_limit": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      -1,
+			Description:  "The upper bandwidth limit of this network interface, in Mbits/sec. -1 means unlimited.",
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		"bandwidth_reservation": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "The bandwidth reservation of this network interface, in Mbits/sec.",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"bandwidth_share_level": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.SharesLevelNormal),
+			Description:  "The bandwidth share allocation level for this network interface. Can be one of high, low, normal, or custom.",
+			ValidateFunc: validation.StringInSlice(sharesLevelAllowedValues, false),
+		},
+		"bandwidth_share_count": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The share count for this network interface when the share level is custom.",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+	}
+}
+
+// expandVirtualEthernetCardResourceAllocation reads the bandwidth allocation
+// keys from a network_interface entry and returns an appropriate
+// types.VirtualEthernetCardResourceAllocation reference.
+func expandVirtualEthernetCardResourceAllocation(m map[string]interface{}) *types.VirtualEthernetCardResourceAllocation {
+	limit := int64(m["bandwidth_limit"].(int))
+	reservation := int64(m["bandwidth_reservation"].(int))
+	obj := &types.VirtualEthernetCardResourceAllocation{
+		Limit:       &limit,
+		Reservation: &reservation,
+		Share: types.SharesInfo{
+			Level: types.SharesLevel(m["bandwidth_share_level"].(string)),
+		},
+	}
+	if obj.Share.Level == types.SharesLevelCustom {
+		obj.Share.Shares = int32(m["bandwidth_share_count"].(int))
+	}
+	return obj
+}
+
+// flattenVirtualEthernetCardResourceAllocation sets the bandwidth allocation
+// keys of a network_interface entry from a
+// types.VirtualEthernetCardResourceAllocation. A nil allocation sets the
+// defaults, as this means the NIC has no allocation set.
+func flattenVirtualEthernetCardResourceAllocation(m map[string]interface{}, obj *types.VirtualEthernetCardResourceAllocation) {
+	m["bandwidth_limit"] = -1
+	m["bandwidth_reservation"] = 0
+	m["bandwidth_share_level"] = string(types.SharesLevelNormal)
+	if obj == nil {
+		return
+	}
+	if obj.Limit != nil {
+		m["bandwidth_limit"] = int(*obj.Limit)
+	}
+	if obj.Reservation != nil {
+		m["bandwidth_reservation"] = int(*obj.Reservation)
+	}
+	if obj.Share.Level != "" {
+		m["bandwidth_share_level"] = string(obj.Share.Level)
+	}
+	m["bandwidth_share_count"] = int(obj.Share.Shares)
+}
+
+// virtualEthernetCardResourceAllocationIsDefault returns true if the
+// allocation has the default settings: no limit, no reservation, and a normal
+// share level.
+func virtualEthernetCardResourceAllocationIsDefault(obj *types.VirtualEthernetCardResourceAllocation) bool {
+	return *obj.Limit == -1 && *obj.Reservation == 0 && obj.Share.Level == types.SharesLevelNormal
+}
+
+// validateVirtualEthernetCardResourceAllocation checks the bandwidth
+// allocation settings of a network_interface entry. Custom share levels need a
+// share count, and the reservation cannot be higher than the limit.
+func validateVirtualEthernetCardResourceAllocation(m map[string]interface{}, index int) error {
+	if m["bandwidth_share_level"].(string) == string(types.SharesLevelCustom) && m["bandwidth_share_count"].(int) == 0 {
+		return fmt.Errorf("network_interface.%d: bandwidth_share_count is required when bandwidth_share_level is custom", index)
+	}
+	limit := m["bandwidth_limit"].(int)
+	reservation := m["bandwidth_reservation"].(int)
+	if limit != -1 && reservation > limit {
+		return fmt.Errorf("network_interface.%d: bandwidth_reservation (%d) cannot be higher than bandwidth_limit (%d)", index, reservation, limit)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vmCompatibilityCheckerReference returns the reference to the
+// VirtualMachineCompatibilityChecker, which is only available on vCenter.
+func vmCompatibilityCheckerReference(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.ServiceContent.VmCompatibilityChecker == nil {
+		return types.ManagedObjectReference{}, errors.New("virtual machine compatibility checker is not available on this connection")
+	}
+	return *client.ServiceContent.VmCompatibilityChecker, nil
+}
+
+// checkVMCompatibility runs the supplied compatibility tests for a virtual
+// machine against a host, and returns the results.
+func checkVMCompatibility(client *govmomi.Client, vm, host types.ManagedObjectReference, tests []string) ([]types.CheckResult, error) {
+	ref, err := vmCompatibilityCheckerReference(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := &types.CheckCompatibility_Task{
+		This:     ref,
+		Vm:       vm,
+		Host:     &host,
+		TestType: tests,
+	}
+	res, err := methods.CheckCompatibility_Task(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if info.Result == nil {
+		return nil, nil
+	}
+	return info.Result.(types.ArrayOfCheckResult).CheckResult, nil
+}
@@ -0,0 +1,64 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// validateVmfsDatastoreMountedHosts checks that the host that a VMFS datastore
+// is set up on is one of the hosts in mounted_host_system_ids. The datastore
+// is managed through that host, so it can't be unmounted from it.
+func validateVmfsDatastoreMountedHosts(d *schema.ResourceData) error {
+	v, ok := d.GetOk("mounted_host_system_ids")
+	if !ok {
+		return nil
+	}
+	hsID := d.Get("host_system_id").(string)
+	if !v.(*schema.Set).Contains(hsID) {
+		return fmt.Errorf("mounted_host_system_ids must include host_system_id %q", hsID)
+	}
+	return nil
+}
+
+// processVmfsDatastoreMounts mounts a VMFS datastore on the hosts in new that
+// are not in old, and then unmounts it from the hosts in old that are not in
+// new. The hosts to mount the datastore on must already be able to see its
+// disks, which is checked against the host mounts in props.
+func processVmfsDatastoreMounts(client *govmomi.Client, props *mo.Datastore, old, new *schema.Set) error {
+	mounts := sliceInterfacesToStrings(new.Difference(old).List())
+	unmounts := sliceInterfacesToStrings(old.Difference(new).List())
+	if len(mounts) < 1 && len(unmounts) < 1 {
+		// Nothing to do
+		return nil
+	}
+	if err := validateVirtualCenter(client); err != nil {
+		return fmt.Errorf("cannot change the hosts that the datastore is mounted on: %s", err)
+	}
+	info, ok := props.Info.(*types.VmfsDatastoreInfo)
+	if !ok || info.Vmfs == nil {
+		return fmt.Errorf("datastore %q is not a VMFS datastore", props.Reference().Value)
+	}
+
+	known := make(map[string]bool)
+	for _, mount := range props.Host {
+		known[mount.Key.Value] = true
+	}
+	for _, hsID := range mounts {
+		if !known[hsID] {
+			return fmt.Errorf("host %q cannot see the disks of the datastore: present them to the host and rescan its storage adapters first", hostSystemNameOrID(client, hsID))
+		}
+		if err := mountVmfsVolume(client, hsID, info.Vmfs.Uuid); err != nil {
+			return fmt.Errorf("host %q: error mounting datastore: %s", hostSystemNameOrID(client, hsID), err)
+		}
+	}
+	for _, hsID := range unmounts {
+		if err := unmountVmfsVolume(client, hsID, info.Vmfs.Uuid); err != nil {
+			return fmt.Errorf("host %q: error unmounting datastore: %s", hostSystemNameOrID(client, hsID), err)
+		}
+	}
+	return nil
+}